@@ -0,0 +1,121 @@
+package broker
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+)
+
+// AnyPacticipant is the scope granting the access to the data of every pacticipant
+const AnyPacticipant = "*"
+
+// Token grants the requests bearing its secret the access to the data of some pacticipants,
+// e.g. a team publishing the contracts of its consumers and the verifications of its providers
+type Token struct {
+	Name   string `yaml:"name"`
+	Secret string `yaml:"secret"`
+	// Read and Write list the pacticipants whose data is read and written with the token, the
+	// pacticipants written are read as well. AnyPacticipant grants the access to every one.
+	Read  []string `yaml:"read"`
+	Write []string `yaml:"write"`
+}
+
+// ReadTokens reads the tokens of a YAML file, listed by its tokens field.
+// The unknown fields are rejected.
+func ReadTokens(path string) ([]Token, error) {
+	file := struct {
+		Tokens []Token `yaml:"tokens"`
+	}{}
+	if err := readYAMLFile(path, "tokens", &file); err != nil {
+		return nil, err
+	}
+
+	return file.Tokens, nil
+}
+
+// validateTokens checks that the tokens are named and that their secrets are unique
+func validateTokens(tokens []Token) error {
+	secrets := make(map[string]string, len(tokens))
+	for _, token := range tokens {
+		if token.Name == "" || token.Secret == "" {
+			return fmt.Errorf("invalid token %q: the name and the secret are required", token.Name)
+		}
+		if other, found := secrets[token.Secret]; found {
+			return fmt.Errorf("invalid token %s: the secret of %s is reused", token.Name, other)
+		}
+		secrets[token.Secret] = token.Name
+	}
+
+	return nil
+}
+
+// grant is the access of a request to the broker, a grant without token accesses everything
+type grant struct {
+	token *Token
+}
+
+// authenticate returns the grant of the secret, the secret is only required when the broker
+// has tokens
+func (b *Broker) authenticate(secret string) (grant, error) {
+	if len(b.config.Tokens) == 0 {
+		return grant{}, nil
+	}
+	if secret == "" {
+		return grant{}, fmt.Errorf("%w: a bearer token is required", ErrUnauthenticated)
+	}
+
+	for index := range b.config.Tokens {
+		token := &b.config.Tokens[index]
+		if subtle.ConstantTimeCompare([]byte(token.Secret), []byte(secret)) == 1 {
+			return grant{token: token}, nil
+		}
+	}
+
+	return grant{}, fmt.Errorf("%w: unknown token", ErrUnauthenticated)
+}
+
+// canRead tells whether the data of one of the pacticipants is read, e.g. the consumer or the
+// provider of a contract
+func (g grant) canRead(pacticipants ...string) bool {
+	if g.token == nil {
+		return true
+	}
+
+	for _, pacticipant := range pacticipants {
+		if inScope(g.token.Read, pacticipant) || inScope(g.token.Write, pacticipant) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canWrite tells whether the data of the pacticipant is written
+func (g grant) canWrite(pacticipant string) bool {
+	return g.token == nil || inScope(g.token.Write, pacticipant)
+}
+
+// authorizeRead fails when the data of none of the pacticipants is read
+func (g grant) authorizeRead(pacticipants ...string) error {
+	if g.canRead(pacticipants...) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: the token %s can't read %s", ErrForbidden, g.token.Name, strings.Join(pacticipants, " or "),
+	)
+}
+
+// authorizeWrite fails when the data of the pacticipant isn't written. The requests without
+// pacticipant are let through, the broker rejects them as invalid.
+func (g grant) authorizeWrite(pacticipant string) error {
+	if pacticipant == "" || g.canWrite(pacticipant) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: the token %s can't write %s", ErrForbidden, g.token.Name, pacticipant)
+}
+
+func inScope(scope []string, pacticipant string) bool {
+	return contains(scope, AnyPacticipant) || contains(scope, pacticipant)
+}
@@ -0,0 +1,358 @@
+package broker_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/faunists/deal-go/broker"
+	"github.com/faunists/deal-go/brokerpb"
+)
+
+// newTestAuthBroker returns a broker whose tokens are named after their owner: web writes the
+// web consumer, users writes the users provider and auditor reads everything
+func newTestAuthBroker(t *testing.T) *broker.Broker {
+	t.Helper()
+
+	contractBroker, err := broker.NewBroker("", broker.WithTokens(
+		broker.Token{Name: "web", Secret: "web-secret", Write: []string{"web"}},
+		broker.Token{Name: "users", Secret: "users-secret", Write: []string{"users"}},
+		broker.Token{Name: "auditor", Secret: "auditor-secret", Read: []string{broker.AnyPacticipant}},
+	))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	publishTestContract(t, contractBroker, "1", "john")
+	_, err = contractBroker.PublishContract(broker.PublishedContract{
+		Consumer: "mobile", Provider: "users", Version: "1", Contract: newTestContract("mary"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return contractBroker
+}
+
+func TestHTTPHandlerTokens(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(broker.NewHTTPHandler(newTestAuthBroker(t)))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		authorization      string
+		body               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "should require a token",
+			method:             http.MethodGet,
+			path:               "/matrix",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "should reject an unknown token",
+			method:             http.MethodGet,
+			path:               "/matrix",
+			authorization:      "Bearer secret",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "should publish the contracts of the consumers of the token",
+			method:             http.MethodPost,
+			path:               "/contracts",
+			authorization:      "bearer web-secret",
+			body:               `{"consumer": "web", "provider": "users", "version": "2", "contract": {}}`,
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "should forbid publishing the contracts of another consumer",
+			method:             http.MethodPost,
+			path:               "/contracts",
+			authorization:      "Bearer users-secret",
+			body:               `{"consumer": "web", "provider": "users", "version": "3", "contract": {}}`,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:          "should publish the verifications of the providers of the token",
+			method:        http.MethodPost,
+			path:          "/verifications",
+			authorization: "Bearer users-secret",
+			body: `{"consumer": "web", "consumerVersion": "1", "provider": "users", ` +
+				`"providerVersion": "a", "success": true}`,
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:          "should forbid publishing the verifications of another provider",
+			method:        http.MethodPost,
+			path:          "/verifications",
+			authorization: "Bearer web-secret",
+			body: `{"consumer": "web", "consumerVersion": "1", "provider": "users", ` +
+				`"providerVersion": "b", "success": true}`,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "should forbid tagging with a read-only token",
+			method:             http.MethodPut,
+			path:               "/pacticipants/web/versions/1/tags/prod",
+			authorization:      "Bearer auditor-secret",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "should read the contracts of a consumer of the token",
+			method:             http.MethodGet,
+			path:               "/contracts/provider/users/consumer/web/version/1",
+			authorization:      "Bearer web-secret",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "should read the contracts of a provider of the token",
+			method:             http.MethodGet,
+			path:               "/contracts/provider/users/consumer/mobile/latest",
+			authorization:      "Bearer users-secret",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "should forbid reading the contracts of other pacticipants",
+			method:             http.MethodGet,
+			path:               "/contracts/provider/users/consumer/mobile/latest",
+			authorization:      "Bearer web-secret",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "should forbid reading the versions of another pacticipant",
+			method:             http.MethodGet,
+			path:               "/pacticipants/mobile/versions",
+			authorization:      "Bearer web-secret",
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			request, err := http.NewRequest(
+				test.method, server.URL+test.path, bytes.NewReader([]byte(test.body)),
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+			response, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer response.Body.Close()
+
+			if response.StatusCode != test.expectedStatusCode {
+				t.Fatalf("Given: %d, expected: %d", response.StatusCode, test.expectedStatusCode)
+			}
+			challenge := response.Header.Get("WWW-Authenticate")
+			if (challenge != "") != (test.expectedStatusCode == http.StatusUnauthorized) {
+				t.Errorf("Unexpected WWW-Authenticate header: %q", challenge)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerTokensFilterLists(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(broker.NewHTTPHandler(newTestAuthBroker(t)))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name              string
+		secret            string
+		expectedConsumers []string
+		// expectedGraph are the pacticipants of the dependency graph
+		expectedGraph []string
+	}{
+		{
+			name:              "should list the rows of the consumer",
+			secret:            "web-secret",
+			expectedConsumers: []string{"web"},
+			expectedGraph:     []string{"users", "web"},
+		},
+		{
+			name:              "should list the rows of the provider",
+			secret:            "users-secret",
+			expectedConsumers: []string{"web", "mobile"},
+			expectedGraph:     []string{"mobile", "users", "web"},
+		},
+		{
+			name:              "should list every row",
+			secret:            "auditor-secret",
+			expectedConsumers: []string{"web", "mobile"},
+			expectedGraph:     []string{"mobile", "users", "web"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			request, err := http.NewRequest(http.MethodGet, server.URL+"/matrix", nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			request.Header.Set("Authorization", "Bearer "+test.secret)
+			response, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer response.Body.Close()
+
+			matrix := struct {
+				Rows []broker.MatrixRow `json:"rows"`
+			}{}
+			if err = json.NewDecoder(response.Body).Decode(&matrix); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			consumers := []string{}
+			for _, row := range matrix.Rows {
+				consumers = append(consumers, row.Consumer)
+			}
+			if !reflect.DeepEqual(consumers, test.expectedConsumers) {
+				t.Errorf("Given: %v, expected: %v", consumers, test.expectedConsumers)
+			}
+
+			request, err = http.NewRequest(http.MethodGet, server.URL+"/graph", nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			request.Header.Set("Authorization", "Bearer "+test.secret)
+			graphResponse, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer graphResponse.Body.Close()
+
+			graph := broker.Graph{}
+			if err = json.NewDecoder(graphResponse.Body).Decode(&graph); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(graph.Pacticipants, test.expectedGraph) {
+				t.Errorf("Given: %v, expected: %v", graph.Pacticipants, test.expectedGraph)
+			}
+		})
+	}
+}
+
+func TestGRPCServerTokens(t *testing.T) {
+	t.Parallel()
+
+	client := newTestBrokerClient(t, newTestAuthBroker(t))
+	withToken := func(secret string) context.Context {
+		return metadata.AppendToOutgoingContext(
+			context.Background(), "authorization", "Bearer "+secret,
+		)
+	}
+
+	_, err := client.GetMatrix(context.Background(), &brokerpb.GetMatrixRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Given: %v, expected: %v", status.Code(err), codes.Unauthenticated)
+	}
+
+	_, err = client.PublishContract(withToken("web-secret"), &brokerpb.PublishContractRequest{
+		Contract: &brokerpb.PublishedContract{
+			Consumer: "mobile", Provider: "users", Version: "2", Contract: &structpb.Struct{},
+		},
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Given: %v, expected: %v", status.Code(err), codes.PermissionDenied)
+	}
+
+	response, err := client.GetMatrix(withToken("web-secret"), &brokerpb.GetMatrixRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(response.GetRows()) != 1 || response.GetRows()[0].GetConsumer() != "web" {
+		t.Errorf("Given: %+v, expected: the row of web", response.GetRows())
+	}
+}
+
+func TestNewBrokerRejectsInvalidTokens(t *testing.T) {
+	t.Parallel()
+
+	for _, tokens := range [][]broker.Token{
+		{{Name: "web"}},
+		{{Name: "web", Secret: "secret"}, {Name: "users", Secret: "secret"}},
+	} {
+		if _, err := broker.NewBroker("", broker.WithTokens(tokens...)); err == nil {
+			t.Errorf("Expected an error for %+v, given nil", tokens)
+		}
+	}
+}
+
+func TestReadTokens(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+	tests := []struct {
+		name           string
+		content        string
+		expectedTokens []broker.Token
+		expectedError  bool
+	}{
+		{
+			name: "should read the tokens",
+			content: "tokens:\n" +
+				"  - name: web\n" +
+				"    secret: web-secret\n" +
+				"    read: ['*']\n" +
+				"    write: [web]\n",
+			expectedTokens: []broker.Token{{
+				Name:   "web",
+				Secret: "web-secret",
+				Read:   []string{broker.AnyPacticipant},
+				Write:  []string{"web"},
+			}},
+		},
+		{
+			name:          "should reject the unknown fields",
+			content:       "tokens:\n  - name: web\n    admin: true\n",
+			expectedError: true,
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+		path := filepath.Join(directory, string(rune('a'+index))+".yaml")
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := ioutil.WriteFile(path, []byte(test.content), 0o600); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			tokens, err := broker.ReadTokens(path)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tokens, test.expectedTokens) {
+				t.Errorf("Given: %+v, expected: %+v", tokens, test.expectedTokens)
+			}
+		})
+	}
+}
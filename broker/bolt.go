@@ -0,0 +1,123 @@
+package broker
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltOpenTimeout bounds the wait for the lock of a database file opened by another process
+const boltOpenTimeout = time.Second
+
+// BoltStorage keeps the data in a bbolt database file, e.g. to run the broker from a laptop
+// without a database server. Each record is a key of the bucket of its kind, each save is a
+// transaction writing the records of a change, a crash keeps the previous data.
+type BoltStorage struct {
+	db     *bolt.DB
+	loaded loadedRecords
+}
+
+// NewBoltStorage opens the database file, it's created when it doesn't exist. The file is
+// locked until the storage is closed.
+func NewBoltStorage(path string) (*BoltStorage, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the broker database %s: %w", path, err)
+	}
+
+	return &BoltStorage{db: db}, nil
+}
+
+// Load reads the records of the data, the keys of a bucket are sorted by position. The records
+// are kept to detect the conflicts of the next saves.
+func (s *BoltStorage) Load() (Data, error) {
+	data := Data{}
+	loaded := make(loadedRecords)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		for _, kind := range recordKinds {
+			bucket := tx.Bucket([]byte(kind))
+			if bucket == nil {
+				continue
+			}
+
+			err := bucket.ForEach(func(key, content []byte) error {
+				position := int(binary.BigEndian.Uint64(key))
+				loaded[recordKey{kind: kind, position: position}] = string(content)
+
+				return decodeRecord(&data, kind, position, content)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return data, err
+	}
+
+	s.loaded = loaded
+	return data, nil
+}
+
+// Save writes the records in a transaction, the file lock keeps the other processes from
+// writing concurrently. The stored records must be the loaded or saved ones.
+func (s *BoltStorage) Save(records []Record) error {
+	saved := make(loadedRecords, len(records))
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		for _, record := range records {
+			bucket, err := tx.CreateBucketIfNotExists([]byte(record.Kind))
+			if err != nil {
+				return err
+			}
+
+			key := recordKey{kind: record.Kind, position: record.Position}
+			if _, written := saved[key]; !written {
+				stored := bucket.Get(boltKey(record.Position))
+				if err = s.loaded.check(key, stored, stored != nil); err != nil {
+					return err
+				}
+			}
+
+			content, err := json.Marshal(record.Value)
+			if err != nil {
+				return err
+			}
+			if err = bucket.Put(boltKey(record.Position), content); err != nil {
+				return err
+			}
+			saved[key] = string(content)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.loaded == nil {
+		s.loaded = make(loadedRecords)
+	}
+	for key, content := range saved {
+		s.loaded[key] = content
+	}
+
+	return nil
+}
+
+// boltKey is the key of the record at the position, big-endian so the keys sort by position
+func boltKey(position int) []byte {
+	key := make([]byte, 8) //nolint:revive // the size of a uint64
+	binary.BigEndian.PutUint64(key, uint64(position))
+
+	return key
+}
+
+// Close releases the database file
+func (s *BoltStorage) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,601 @@
+// Package broker implements a contract broker: the consumers publish the contracts they expect,
+// the providers fetch them and publish the results of their verification, and the deployments
+// are gated on the matrix of the verified versions.
+package broker
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// The kinds of the broker errors, the errors returned by the broker wrap one of them
+var (
+	ErrInvalid         = errors.New("invalid request")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+)
+
+// PublishedContract is a contract expected by a consumer version from a provider.
+// Branch, Commit, Semver and Tags describe the consumer version, see Version.
+type PublishedContract struct {
+	Consumer string            `json:"consumer"`
+	Provider string            `json:"provider"`
+	Version  string            `json:"version"`
+	Branch   string            `json:"branch,omitempty"`
+	Commit   string            `json:"commit,omitempty"`
+	Semver   string            `json:"semver,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Contract entities.Contract `json:"contract"`
+	// PublishedAt and Digest are set by the broker, the digest identifies the contract content
+	PublishedAt time.Time `json:"publishedAt"`
+	Digest      string    `json:"digest"`
+	// Pending is set by the broker while no version of the provider verified the contract content
+	// successfully, the failed verifications of a pending contract don't fail the provider build
+	Pending bool `json:"pending,omitempty"`
+}
+
+// VerificationResult is the outcome of the verification of a contract by a provider version,
+// optionally along with the outcome of each case of the contract
+type VerificationResult struct {
+	Consumer        string       `json:"consumer"`
+	ConsumerVersion string       `json:"consumerVersion"`
+	Provider        string       `json:"provider"`
+	ProviderVersion string       `json:"providerVersion"`
+	Success         bool         `json:"success"`
+	Cases           []CaseResult `json:"cases,omitempty"`
+	// VerifiedAt and Digest are set by the broker, the digest is the one of the verified contract
+	VerifiedAt time.Time `json:"verifiedAt"`
+	Digest     string    `json:"digest"`
+}
+
+// CaseResult is the outcome of a case of a verified contract, e.g. a generated test
+type CaseResult struct {
+	Name     string        `json:"name"`
+	Success  bool          `json:"success"`
+	Duration time.Duration `json:"duration"`
+}
+
+// MatrixQuery filters the matrix, the empty fields match every value.
+// A missing version is the one deployed to the environment when it's given.
+type MatrixQuery struct {
+	Consumer        string
+	ConsumerVersion string
+	Provider        string
+	ProviderVersion string
+	Environment     string
+	// Limit keeps the rows of the latest contracts matching the query, zero keeps every row
+	Limit int
+}
+
+// MatrixRow is a consumer version and a provider version, along with the latest verification
+// of the contract of the consumer version by the provider version
+type MatrixRow struct {
+	Consumer        string `json:"consumer"`
+	ConsumerVersion string `json:"consumerVersion"`
+	Provider        string `json:"provider"`
+	// ProviderVersion is empty when no provider version verified the contract
+	ProviderVersion string `json:"providerVersion,omitempty"`
+	// Success is nil when the provider version didn't verify the contract
+	Success    *bool      `json:"success,omitempty"`
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty"`
+}
+
+// Data is everything a broker knows, in the order it was published.
+// The stored contracts don't hold the metadata of their version, it's kept by the versions.
+type Data struct {
+	Versions      []Version            `json:"versions"`
+	Contracts     []PublishedContract  `json:"contracts"`
+	Verifications []VerificationResult `json:"verifications"`
+	Deployments   []DeployedVersion    `json:"deployments"`
+}
+
+// BrokerConfig holds the settings of a broker
+type BrokerConfig struct {
+	// Storage persists the data, it's kept in memory only by default
+	Storage Storage
+	// ContractBlobs keeps the contents of the contracts apart from the other data of the storage
+	ContractBlobs BlobStore
+	// Tokens authenticate the requests of the APIs, they're open to everyone without tokens
+	Tokens        []Token
+	Webhooks      []Webhook
+	WebhookClient *http.Client
+	// WebhookErrorHandler is called with the failed webhook calls, they're ignored by default
+	WebhookErrorHandler func(webhook Webhook, err error)
+}
+
+// BrokerOption configures a broker
+type BrokerOption func(*BrokerConfig)
+
+// WithStorage persists the data of the broker in the storage, instead of its data file
+func WithStorage(storage Storage) BrokerOption {
+	return func(config *BrokerConfig) {
+		config.Storage = storage
+	}
+}
+
+// WithContractBlobs keeps the contents of the contracts in the blob store, e.g. a bucket, while
+// the storage keeps their digest along with the other data
+func WithContractBlobs(blobs BlobStore) BrokerOption {
+	return func(config *BrokerConfig) {
+		config.ContractBlobs = blobs
+	}
+}
+
+// WithTokens requires the requests of the APIs to bear one of the tokens, the tokens restrict
+// the pacticipants whose data is read and written
+func WithTokens(tokens ...Token) BrokerOption {
+	return func(config *BrokerConfig) {
+		config.Tokens = append(config.Tokens, tokens...)
+	}
+}
+
+// WithWebhooks calls the webhooks when a contract changes
+func WithWebhooks(webhooks ...Webhook) BrokerOption {
+	return func(config *BrokerConfig) {
+		config.Webhooks = append(config.Webhooks, webhooks...)
+	}
+}
+
+// WithWebhookClient calls the webhooks through the HTTP client, e.g. to customize its transport
+func WithWebhookClient(httpClient *http.Client) BrokerOption {
+	return func(config *BrokerConfig) {
+		config.WebhookClient = httpClient
+	}
+}
+
+// WithWebhookErrorHandler reports the failed webhook calls, e.g. to log them
+func WithWebhookErrorHandler(handler func(webhook Webhook, err error)) BrokerOption {
+	return func(config *BrokerConfig) {
+		config.WebhookErrorHandler = handler
+	}
+}
+
+// Broker keeps the contracts and the verification results in memory, the records of every change
+// are written to the storage when it's given
+type Broker struct {
+	mutex      sync.RWMutex
+	storage    Storage
+	data       Data
+	config     BrokerConfig
+	webhooks   []webhookTemplate
+	deliveries sync.WaitGroup
+}
+
+// NewBroker returns a broker, loading the data file when it exists. The data file is a
+// FileStorage, WithStorage replaces it.
+func NewBroker(dataPath string, opts ...BrokerOption) (*Broker, error) {
+	config := BrokerConfig{WebhookClient: &http.Client{Timeout: DefaultWebhookTimeout}}
+	if dataPath != "" {
+		config.Storage = NewFileStorage(dataPath)
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if err := validateTokens(config.Tokens); err != nil {
+		return nil, err
+	}
+
+	broker := &Broker{storage: config.Storage, config: config}
+	if broker.storage != nil && config.ContractBlobs != nil {
+		broker.storage = &blobStorage{Storage: broker.storage, blobs: config.ContractBlobs}
+	}
+	for _, webhook := range config.Webhooks {
+		parsed, err := parseWebhook(webhook)
+		if err != nil {
+			return nil, err
+		}
+		broker.webhooks = append(broker.webhooks, parsed)
+	}
+
+	if broker.storage == nil {
+		return broker, nil
+	}
+	if err := broker.load(); err != nil {
+		return nil, err
+	}
+
+	return broker, nil
+}
+
+// load replaces the data with the one of the storage
+func (b *Broker) load() error {
+	data, err := b.storage.Load()
+	if err != nil {
+		return err
+	}
+	b.data = data
+
+	// The data files written before the versions were tracked kept the metadata in the contracts
+	for index, published := range b.data.Contracts {
+		_, record := b.mergeVersion(versionOf(published), published.PublishedAt)
+		if err = applyRecords(&b.data, record); err != nil {
+			return err
+		}
+		b.data.Contracts[index] = withoutVersion(published)
+	}
+
+	return nil
+}
+
+// PublishContract stores the contract of the consumer version along with the metadata of the
+// version, publishing the same contract again adds its tags while another contract for the same
+// version is a conflict
+func (b *Broker) PublishContract(published PublishedContract) (PublishedContract, error) {
+	if published.Consumer == "" || published.Provider == "" || published.Version == "" {
+		return PublishedContract{}, fmt.Errorf(
+			"%w: the consumer, the provider and the version are required", ErrInvalid,
+		)
+	}
+	if err := validateVersion(versionOf(published)); err != nil {
+		return PublishedContract{}, err
+	}
+
+	digest, err := contractDigest(published.Contract)
+	if err != nil {
+		return PublishedContract{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	published.Pending = false
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	existing, err := b.findContract(published.Consumer, published.Provider, published.Version)
+	isNew := err != nil
+	previousDigest := ""
+	records := make([]Record, 0, 2) //nolint:revive // the contract and its version
+	switch {
+	case !isNew && existing.Digest != digest:
+		return PublishedContract{}, fmt.Errorf(
+			"%w: version %s of %s already published another contract with %s",
+			ErrConflict, published.Version, published.Consumer, published.Provider,
+		)
+	case isNew:
+		previousDigest = b.latestDigest(published.Consumer, published.Provider)
+		published.PublishedAt = time.Now().UTC()
+		published.Digest = digest
+		records = append(records, Record{
+			Kind: ContractRecord, Position: len(b.data.Contracts), Value: withoutVersion(published),
+		})
+	default:
+		published.PublishedAt, published.Digest = existing.PublishedAt, existing.Digest
+	}
+
+	version, record := b.mergeVersion(versionOf(published), published.PublishedAt)
+	if err = b.save(append(records, record)...); err != nil {
+		return PublishedContract{}, err
+	}
+
+	published = withVersion(published, version)
+	if isNew && previousDigest != digest {
+		b.notifyContractChanged(published, previousDigest)
+	}
+
+	return b.withPending(published), nil
+}
+
+// latestDigest returns the digest of the latest contract between the consumer and the provider,
+// an empty one when they have none
+func (b *Broker) latestDigest(consumer, provider string) string {
+	for index := len(b.data.Contracts) - 1; index >= 0; index-- {
+		published := b.data.Contracts[index]
+		if published.Consumer == consumer && published.Provider == provider {
+			return published.Digest
+		}
+	}
+
+	return ""
+}
+
+// Contract returns the contract of the consumer version with the provider
+func (b *Broker) Contract(consumer, provider, version string) (PublishedContract, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	published, err := b.findContract(consumer, provider, version)
+	if err != nil {
+		return PublishedContract{}, err
+	}
+
+	return b.withPending(published), nil
+}
+
+// LatestContract returns the latest published contract of the consumer with the provider,
+// among the consumer versions selected by the selector
+func (b *Broker) LatestContract(
+	consumer, provider string,
+	selector Selector,
+) (PublishedContract, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for index := len(b.data.Contracts) - 1; index >= 0; index-- {
+		published := b.data.Contracts[index]
+		if published.Consumer != consumer || published.Provider != provider {
+			continue
+		}
+
+		version, _ := b.findVersion(published.Consumer, published.Version)
+		if selector.matches(version) {
+			return b.withPending(withVersion(published, version)), nil
+		}
+	}
+
+	return PublishedContract{}, fmt.Errorf(
+		"%w: no contract of %s%s with %s", ErrNotFound, consumer, selector, provider,
+	)
+}
+
+// LatestContracts returns the latest published contract of every consumer of the provider,
+// among the consumer versions selected by the selector, sorted by consumer
+func (b *Broker) LatestContracts(provider string, selector Selector) []PublishedContract {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	latest := make(map[string]PublishedContract)
+	for _, published := range b.data.Contracts {
+		if published.Provider != provider {
+			continue
+		}
+
+		version, _ := b.findVersion(published.Consumer, published.Version)
+		if selector.matches(version) {
+			latest[published.Consumer] = b.withPending(withVersion(published, version))
+		}
+	}
+
+	contracts := make([]PublishedContract, 0, len(latest))
+	for _, published := range latest {
+		contracts = append(contracts, published)
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		return contracts[i].Consumer < contracts[j].Consumer
+	})
+
+	return contracts
+}
+
+// PublishVerificationResult stores the result of the verification of the contract of the
+// consumer version by the provider version
+func (b *Broker) PublishVerificationResult(result VerificationResult) (VerificationResult, error) {
+	if result.Consumer == "" || result.ConsumerVersion == "" || result.Provider == "" ||
+		result.ProviderVersion == "" {
+		return VerificationResult{}, fmt.Errorf(
+			"%w: the consumer, the provider and their versions are required", ErrInvalid,
+		)
+	}
+
+	for _, caseResult := range result.Cases {
+		if caseResult.Name == "" {
+			return VerificationResult{}, fmt.Errorf("%w: the cases must be named", ErrInvalid)
+		}
+		if result.Success && !caseResult.Success {
+			return VerificationResult{}, fmt.Errorf(
+				"%w: the verification can't succeed, the case %s failed", ErrInvalid, caseResult.Name,
+			)
+		}
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	published, err := b.findContract(result.Consumer, result.Provider, result.ConsumerVersion)
+	if err != nil {
+		return VerificationResult{}, err
+	}
+
+	result.VerifiedAt = time.Now().UTC()
+	result.Digest = published.Digest
+	err = b.save(Record{
+		Kind: VerificationRecord, Position: len(b.data.Verifications), Value: result,
+	})
+	if err != nil {
+		return VerificationResult{}, err
+	}
+
+	return result, nil
+}
+
+// Matrix returns the contracts matching the query along with their latest verification by each
+// provider version, sorted by publication then by verification time. The contracts identical to a
+// verified one share its verifications. The contracts whose missing versions aren't deployed to
+// the environment of the query are left out.
+func (b *Broker) Matrix(query MatrixQuery) ([]MatrixRow, error) {
+	if query.Limit < 0 {
+		return nil, fmt.Errorf("%w: the limit can't be negative", ErrInvalid)
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	contracts := make([][]MatrixRow, 0)
+	for _, published := range b.data.Contracts {
+		if !matches(query.Consumer, published.Consumer) ||
+			!matches(query.Provider, published.Provider) {
+			continue
+		}
+
+		consumerVersion, deployed := b.resolveVersion(
+			query.ConsumerVersion, published.Consumer, query.Environment,
+		)
+		if !deployed || !matches(consumerVersion, published.Version) {
+			continue
+		}
+		providerVersion, deployed := b.resolveVersion(
+			query.ProviderVersion, published.Provider, query.Environment,
+		)
+		if !deployed {
+			continue
+		}
+
+		row := MatrixRow{
+			Consumer:        published.Consumer,
+			ConsumerVersion: published.Version,
+			Provider:        published.Provider,
+		}
+		verified := b.latestVerifications(published, providerVersion)
+		if len(verified) == 0 {
+			row.ProviderVersion = providerVersion
+			contracts = append(contracts, []MatrixRow{row})
+			continue
+		}
+
+		contractRows := make([]MatrixRow, 0, len(verified))
+		for _, verification := range verified {
+			verification := verification
+			row.ProviderVersion = verification.ProviderVersion
+			row.Success = &verification.Success
+			row.VerifiedAt = &verification.VerifiedAt
+			contractRows = append(contractRows, row)
+		}
+		contracts = append(contracts, contractRows)
+	}
+
+	if query.Limit > 0 && len(contracts) > query.Limit {
+		contracts = contracts[len(contracts)-query.Limit:]
+	}
+
+	rows := make([]MatrixRow, 0)
+	for _, contractRows := range contracts {
+		rows = append(rows, contractRows...)
+	}
+
+	return rows, nil
+}
+
+// latestVerifications returns the latest verification of the contract by each provider version,
+// sorted by verification time
+func (b *Broker) latestVerifications(
+	published PublishedContract,
+	providerVersion string,
+) []VerificationResult {
+	latest := make(map[string]int)
+	for index, verification := range b.data.Verifications {
+		if verification.Consumer == published.Consumer &&
+			verification.Provider == published.Provider && verification.Digest == published.Digest &&
+			matches(providerVersion, verification.ProviderVersion) {
+			latest[verification.ProviderVersion] = index
+		}
+	}
+
+	indexes := make([]int, 0, len(latest))
+	for _, index := range latest {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	verifications := make([]VerificationResult, 0, len(indexes))
+	for _, index := range indexes {
+		verifications = append(verifications, b.data.Verifications[index])
+	}
+
+	return verifications
+}
+
+// findContract returns the contract of the consumer version, along with the version metadata
+func (b *Broker) findContract(consumer, provider, version string) (PublishedContract, error) {
+	for _, published := range b.data.Contracts {
+		if published.Consumer == consumer && published.Provider == provider &&
+			published.Version == version {
+			consumerVersion, _ := b.findVersion(consumer, version)
+			return withVersion(published, consumerVersion), nil
+		}
+	}
+
+	return PublishedContract{}, fmt.Errorf(
+		"%w: no contract of %s %s with %s", ErrNotFound, consumer, version, provider,
+	)
+}
+
+// withPending marks the contract as pending while no version of its provider verified its
+// content successfully
+func (b *Broker) withPending(published PublishedContract) PublishedContract {
+	published.Pending = true
+	for _, result := range b.data.Verifications {
+		if result.Provider == published.Provider && result.Digest == published.Digest &&
+			result.Success {
+			published.Pending = false
+			break
+		}
+	}
+
+	return published
+}
+
+// save writes the records of a change to the storage, then to the data so the data only holds
+// the saved changes. A conflict reloads the data, the change is retried by the caller. It's
+// called with the lock of the broker held.
+func (b *Broker) save(records ...Record) error {
+	if b.storage != nil {
+		if err := b.storage.Save(records); err != nil {
+			if !errors.Is(err, ErrConflict) {
+				return err
+			}
+			if loadErr := b.load(); loadErr != nil {
+				return fmt.Errorf("%w, then failed to reload the broker data: %v", err, loadErr)
+			}
+
+			return err
+		}
+	}
+
+	return applyRecords(&b.data, records...)
+}
+
+// decodeJSON decodes the JSON data, the numbers of the contracts are kept as written since a
+// float64 can't represent every 64-bit integer
+func decodeJSON(data []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(value)
+}
+
+// readYAMLFile decodes the YAML file describing the kind of settings, its unknown fields are
+// rejected
+func readYAMLFile(path, kind string, value interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(value); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid %s file %s: %w", kind, path, err)
+	}
+
+	return nil
+}
+
+// contractDigest returns the SHA-256 of the contract encoded as JSON, whose object keys are sorted
+func contractDigest(contract entities.Contract) (string, error) {
+	data, err := json.Marshal(contract)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// matches tells whether the value is accepted by the filter, an empty filter accepts everything
+func matches(filter, value string) bool {
+	return filter == "" || filter == value
+}
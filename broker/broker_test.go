@@ -0,0 +1,372 @@
+package broker_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/broker"
+	"github.com/faunists/deal-go/entities"
+)
+
+// newTestContract returns a contract whose single case returns the name
+func newTestContract(name string) entities.Contract {
+	return entities.Contract{Services: map[string]entities.Service{"UserService": {
+		"GetUser": {SuccessCases: []entities.SuccessCase{{
+			Request:  map[string]interface{}{"id": "1"},
+			Response: map[string]interface{}{"name": name},
+		}}},
+	}}}
+}
+
+// publishTestContract publishes the contract of the consumer version with the users provider
+func publishTestContract(
+	t *testing.T,
+	contractBroker *broker.Broker,
+	version, name string,
+	tags ...string,
+) broker.PublishedContract {
+	t.Helper()
+
+	published, err := contractBroker.PublishContract(broker.PublishedContract{
+		Consumer: "web",
+		Provider: "users",
+		Version:  version,
+		Tags:     tags,
+		Contract: newTestContract(name),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return published
+}
+
+func TestBrokerPublishContract(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	first := publishTestContract(t, contractBroker, "1", "john", "main")
+	if first.Digest == "" || first.PublishedAt.IsZero() {
+		t.Errorf("Given: %+v, expected: a digest and a publication time", first)
+	}
+
+	again := publishTestContract(t, contractBroker, "1", "john", "prod", "main")
+	if !reflect.DeepEqual(again.Tags, []string{"main", "prod"}) {
+		t.Errorf("Given: %v, expected: %v", again.Tags, []string{"main", "prod"})
+	}
+	if again.Digest != first.Digest || !again.PublishedAt.Equal(first.PublishedAt) {
+		t.Errorf("Given: %+v, expected: %+v", again, first)
+	}
+
+	tests := []struct {
+		name          string
+		published     broker.PublishedContract
+		expectedError error
+	}{
+		{
+			name: "should fail when another contract is published for the same version",
+			published: broker.PublishedContract{
+				Consumer: "web", Provider: "users", Version: "1", Contract: newTestContract("mary"),
+			},
+			expectedError: broker.ErrConflict,
+		},
+		{
+			name: "should fail when the version is missing",
+			published: broker.PublishedContract{
+				Consumer: "web", Provider: "users", Contract: newTestContract("john"),
+			},
+			expectedError: broker.ErrInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := contractBroker.PublishContract(test.published)
+			if !errors.Is(err, test.expectedError) {
+				t.Errorf("Given: %v, expected: %v", err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestBrokerLatestContract(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishTestContract(t, contractBroker, "1", "john", "prod")
+	_, err = contractBroker.PublishContract(broker.PublishedContract{
+		Consumer: "web", Provider: "users", Version: "2", Branch: "feature",
+		Contract: newTestContract("mary"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishTestContract(t, contractBroker, "3", "mary", "main")
+
+	tests := []struct {
+		name            string
+		consumer        string
+		selector        broker.Selector
+		expectedVersion string
+		expectedError   error
+	}{
+		{
+			name:            "should return the latest version",
+			consumer:        "web",
+			expectedVersion: "3",
+		},
+		{
+			name:            "should return the latest version with the tag",
+			consumer:        "web",
+			selector:        broker.Selector{Tag: "prod"},
+			expectedVersion: "1",
+		},
+		{
+			name:            "should return the latest version on the branch",
+			consumer:        "web",
+			selector:        broker.Selector{Branch: "feature"},
+			expectedVersion: "2",
+		},
+		{
+			name:          "should fail when no version has the tag",
+			consumer:      "web",
+			selector:      broker.Selector{Tag: "staging"},
+			expectedError: broker.ErrNotFound,
+		},
+		{
+			name:          "should fail when no version with the tag is on the branch",
+			consumer:      "web",
+			selector:      broker.Selector{Tag: "prod", Branch: "feature"},
+			expectedError: broker.ErrNotFound,
+		},
+		{
+			name:          "should fail when the consumer is unknown",
+			consumer:      "mobile",
+			expectedError: broker.ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			published, err := contractBroker.LatestContract(test.consumer, "users", test.selector)
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("Given: %v, expected: %v", err, test.expectedError)
+			}
+			if published.Version != test.expectedVersion {
+				t.Errorf("Given: %s, expected: %s", published.Version, test.expectedVersion)
+			}
+		})
+	}
+
+	latest := contractBroker.LatestContracts("users", broker.Selector{Tag: "prod"})
+	if len(latest) != 1 || latest[0].Version != "1" {
+		t.Errorf("Given: %+v, expected: the version 1 of web", latest)
+	}
+}
+
+func TestBrokerMatrix(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishTestContract(t, contractBroker, "1", "john")
+	publishTestContract(t, contractBroker, "2", "john")
+	publishTestContract(t, contractBroker, "3", "mary")
+
+	for _, result := range []broker.VerificationResult{
+		{ConsumerVersion: "1", ProviderVersion: "a", Success: false},
+		{ConsumerVersion: "1", ProviderVersion: "a", Success: true},
+		{ConsumerVersion: "1", ProviderVersion: "b", Success: false},
+	} {
+		result.Consumer, result.Provider = "web", "users"
+		if _, err = contractBroker.PublishVerificationResult(result); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	success, failure := true, false
+	tests := []struct {
+		name         string
+		query        broker.MatrixQuery
+		expectedRows []broker.MatrixRow
+	}{
+		{
+			name:  "should return the latest verification of each provider version",
+			query: broker.MatrixQuery{ConsumerVersion: "1"},
+			expectedRows: []broker.MatrixRow{
+				{ConsumerVersion: "1", ProviderVersion: "a", Success: &success},
+				{ConsumerVersion: "1", ProviderVersion: "b", Success: &failure},
+			},
+		},
+		{
+			name:  "should share the verifications of the same contract",
+			query: broker.MatrixQuery{ConsumerVersion: "2", ProviderVersion: "a"},
+			expectedRows: []broker.MatrixRow{
+				{ConsumerVersion: "2", ProviderVersion: "a", Success: &success},
+			},
+		},
+		{
+			name:         "should return the contracts without verification",
+			query:        broker.MatrixQuery{ConsumerVersion: "3", ProviderVersion: "a"},
+			expectedRows: []broker.MatrixRow{{ConsumerVersion: "3", ProviderVersion: "a"}},
+		},
+		{
+			name:  "should keep the rows of the latest contracts",
+			query: broker.MatrixQuery{ProviderVersion: "a", Limit: 2},
+			expectedRows: []broker.MatrixRow{
+				{ConsumerVersion: "2", ProviderVersion: "a", Success: &success},
+				{ConsumerVersion: "3", ProviderVersion: "a"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows, err := contractBroker.Matrix(test.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for index := range rows {
+				if rows[index].Success != nil && rows[index].VerifiedAt == nil {
+					t.Errorf("Given: %+v, expected: a verification time", rows[index])
+				}
+				rows[index].Consumer, rows[index].Provider, rows[index].VerifiedAt = "", "", nil
+			}
+			if !reflect.DeepEqual(rows, test.expectedRows) {
+				t.Errorf("Given: %+v, expected: %+v", rows, test.expectedRows)
+			}
+		})
+	}
+
+	_, err = contractBroker.Matrix(broker.MatrixQuery{Limit: -1})
+	if !errors.Is(err, broker.ErrInvalid) {
+		t.Errorf("Given: %v, expected: %v", err, broker.ErrInvalid)
+	}
+
+	_, err = contractBroker.PublishVerificationResult(broker.VerificationResult{
+		Consumer: "web", ConsumerVersion: "4", Provider: "users", ProviderVersion: "a",
+	})
+	if !errors.Is(err, broker.ErrNotFound) {
+		t.Errorf("Given: %v, expected: %v", err, broker.ErrNotFound)
+	}
+
+	_, err = contractBroker.PublishVerificationResult(broker.VerificationResult{
+		Consumer: "web", ConsumerVersion: "1", Provider: "users", ProviderVersion: "c",
+		Success: true, Cases: []broker.CaseResult{{Name: "GetUser/found_0", Success: false}},
+	})
+	if !errors.Is(err, broker.ErrInvalid) {
+		t.Errorf("Given: %v, expected: %v", err, broker.ErrInvalid)
+	}
+}
+
+func TestBrokerPendingContracts(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if published := publishTestContract(t, contractBroker, "1", "john"); !published.Pending {
+		t.Errorf("Given: %+v, expected: a pending contract", published)
+	}
+	publishTestContract(t, contractBroker, "2", "mary")
+
+	for _, result := range []broker.VerificationResult{
+		{ConsumerVersion: "1", ProviderVersion: "a", Success: false},
+		{ConsumerVersion: "2", ProviderVersion: "a", Success: true},
+	} {
+		result.Consumer, result.Provider = "web", "users"
+		if _, err = contractBroker.PublishVerificationResult(result); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	publishTestContract(t, contractBroker, "3", "mary")
+
+	tests := []struct {
+		name            string
+		version         string
+		expectedPending bool
+	}{
+		{
+			name:            "should keep the contract pending after a failed verification",
+			version:         "1",
+			expectedPending: true,
+		},
+		{
+			name:            "should settle the contract after a successful verification",
+			version:         "2",
+			expectedPending: false,
+		},
+		{
+			name:            "should settle the versions publishing a verified content",
+			version:         "3",
+			expectedPending: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			published, err := contractBroker.Contract("web", "users", test.version)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if published.Pending != test.expectedPending {
+				t.Errorf("Given: %v, expected: %v", published.Pending, test.expectedPending)
+			}
+		})
+	}
+
+	latest, err := contractBroker.LatestContract("web", "users", broker.Selector{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if latest.Version != "3" || latest.Pending {
+		t.Errorf("Given: %+v, expected: the settled version 3 of web", latest)
+	}
+}
+
+func TestNewBrokerLoadsTheDataFile(t *testing.T) {
+	t.Parallel()
+
+	dataPath := filepath.Join(t.TempDir(), "broker.json")
+	contractBroker, err := broker.NewBroker(dataPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	published := publishTestContract(t, contractBroker, "1", "john", "main")
+
+	reloadedBroker, err := broker.NewBroker(dataPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reloaded, err := reloadedBroker.Contract("web", "users", "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reloaded.Digest != published.Digest || !reflect.DeepEqual(reloaded.Tags, published.Tags) {
+		t.Errorf("Given: %+v, expected: %+v", reloaded, published)
+	}
+}
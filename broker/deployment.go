@@ -0,0 +1,117 @@
+package broker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DeployedVersion records that a version of a consumer or a provider is deployed to an
+// environment, deploying another version to the environment undeploys it
+type DeployedVersion struct {
+	Pacticipant string `json:"pacticipant"`
+	Version     string `json:"version"`
+	Environment string `json:"environment"`
+	// DeployedAt and UndeployedAt are set by the broker
+	DeployedAt   time.Time  `json:"deployedAt"`
+	UndeployedAt *time.Time `json:"undeployedAt,omitempty"`
+}
+
+// RecordDeployment records the deployment of the version to the environment, replacing the
+// version of the pacticipant deployed there, the version is created when the broker doesn't
+// know it yet
+func (b *Broker) RecordDeployment(
+	pacticipant, version, environment string,
+) (DeployedVersion, error) {
+	if pacticipant == "" || version == "" || environment == "" {
+		return DeployedVersion{}, fmt.Errorf(
+			"%w: the pacticipant, the version and the environment are required", ErrInvalid,
+		)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now().UTC()
+	records := make([]Record, 0)
+	for index, deployed := range b.data.Deployments {
+		if deployed.Pacticipant == pacticipant && deployed.Environment == environment &&
+			deployed.UndeployedAt == nil {
+			deployed.UndeployedAt = &now
+			records = append(records, Record{Kind: DeploymentRecord, Position: index, Value: deployed})
+		}
+	}
+
+	_, record := b.mergeVersion(Version{Pacticipant: pacticipant, Number: version}, now)
+	deployed := DeployedVersion{
+		Pacticipant: pacticipant,
+		Version:     version,
+		Environment: environment,
+		DeployedAt:  now,
+	}
+	records = append(records, record, Record{
+		Kind: DeploymentRecord, Position: len(b.data.Deployments), Value: deployed,
+	})
+	if err := b.save(records...); err != nil {
+		return DeployedVersion{}, err
+	}
+
+	return deployed, nil
+}
+
+// DeployedVersion returns the version of the pacticipant currently deployed to the environment
+func (b *Broker) DeployedVersion(pacticipant, environment string) (DeployedVersion, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	deployed, found := b.findDeployment(pacticipant, environment)
+	if !found {
+		return DeployedVersion{}, fmt.Errorf(
+			"%w: no version of %s is deployed to %s", ErrNotFound, pacticipant, environment,
+		)
+	}
+
+	return deployed, nil
+}
+
+// DeployedVersions returns the versions currently deployed to the environment, sorted by
+// pacticipant
+func (b *Broker) DeployedVersions(environment string) []DeployedVersion {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	deployments := make([]DeployedVersion, 0)
+	for _, deployed := range b.data.Deployments {
+		if deployed.Environment == environment && deployed.UndeployedAt == nil {
+			deployments = append(deployments, deployed)
+		}
+	}
+	sort.Slice(deployments, func(i, j int) bool {
+		return deployments[i].Pacticipant < deployments[j].Pacticipant
+	})
+
+	return deployments
+}
+
+func (b *Broker) findDeployment(pacticipant, environment string) (DeployedVersion, bool) {
+	for _, deployed := range b.data.Deployments {
+		if deployed.Pacticipant == pacticipant && deployed.Environment == environment &&
+			deployed.UndeployedAt == nil {
+			return deployed, true
+		}
+	}
+
+	return DeployedVersion{}, false
+}
+
+// resolveVersion returns the version given by the query, or the one of the pacticipant deployed
+// to the environment of the query, false means no version is deployed there
+func (b *Broker) resolveVersion(version, pacticipant, environment string) (string, bool) {
+	if version != "" || environment == "" {
+		return version, true
+	}
+
+	deployed, found := b.findDeployment(pacticipant, environment)
+
+	return deployed.Version, found
+}
@@ -0,0 +1,141 @@
+package broker_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/broker"
+)
+
+func TestBrokerRecordDeployment(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, deployment := range []broker.DeployedVersion{
+		{Pacticipant: "web", Version: "1", Environment: "production"},
+		{Pacticipant: "users", Version: "a", Environment: "production"},
+		{Pacticipant: "web", Version: "2", Environment: "production"},
+		{Pacticipant: "web", Version: "3", Environment: "staging"},
+	} {
+		_, err = contractBroker.RecordDeployment(
+			deployment.Pacticipant, deployment.Version, deployment.Environment,
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	deployed, err := contractBroker.DeployedVersion("web", "production")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deployed.Version != "2" || deployed.UndeployedAt != nil {
+		t.Errorf("Given: %+v, expected: the version 2 of web", deployed)
+	}
+
+	versions := make([]string, 0)
+	for _, deployed := range contractBroker.DeployedVersions("production") {
+		versions = append(versions, deployed.Pacticipant+" "+deployed.Version)
+	}
+	if expected := []string{"users a", "web 2"}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Given: %v, expected: %v", versions, expected)
+	}
+
+	// The deployed versions are known by the broker, even without contract
+	if _, err = contractBroker.Version("users", "a"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	_, err = contractBroker.DeployedVersion("users", "staging")
+	if !errors.Is(err, broker.ErrNotFound) {
+		t.Errorf("Given: %v, expected: %v", err, broker.ErrNotFound)
+	}
+	_, err = contractBroker.RecordDeployment("web", "4", "")
+	if !errors.Is(err, broker.ErrInvalid) {
+		t.Errorf("Given: %v, expected: %v", err, broker.ErrInvalid)
+	}
+}
+
+func TestBrokerMatrixOfEnvironment(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishTestContract(t, contractBroker, "1", "john")
+	publishTestContract(t, contractBroker, "2", "mary")
+
+	for _, result := range []broker.VerificationResult{
+		{ConsumerVersion: "1", ProviderVersion: "a", Success: true},
+		{ConsumerVersion: "2", ProviderVersion: "b", Success: true},
+	} {
+		result.Consumer, result.Provider = "web", "users"
+		if _, err = contractBroker.PublishVerificationResult(result); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	for pacticipant, version := range map[string]string{"web": "1", "users": "a"} {
+		if _, err = contractBroker.RecordDeployment(pacticipant, version, "production"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	success := true
+	tests := []struct {
+		name         string
+		query        broker.MatrixQuery
+		expectedRows []broker.MatrixRow
+	}{
+		{
+			name:  "should check the consumer version against the deployed provider version",
+			query: broker.MatrixQuery{ConsumerVersion: "2", Environment: "production"},
+			expectedRows: []broker.MatrixRow{
+				{ConsumerVersion: "2", ProviderVersion: "a"},
+			},
+		},
+		{
+			name:  "should check the provider version against the deployed consumer version",
+			query: broker.MatrixQuery{ProviderVersion: "b", Environment: "production"},
+			expectedRows: []broker.MatrixRow{
+				{ConsumerVersion: "1", ProviderVersion: "b"},
+			},
+		},
+		{
+			name:  "should return the versions deployed to the environment",
+			query: broker.MatrixQuery{Environment: "production"},
+			expectedRows: []broker.MatrixRow{
+				{ConsumerVersion: "1", ProviderVersion: "a", Success: &success},
+			},
+		},
+		{
+			name:         "should return nothing when no version is deployed to the environment",
+			query:        broker.MatrixQuery{ConsumerVersion: "2", Environment: "staging"},
+			expectedRows: []broker.MatrixRow{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.query.Consumer, test.query.Provider = "web", "users"
+			rows, err := contractBroker.Matrix(test.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for index := range rows {
+				rows[index].Consumer, rows[index].Provider, rows[index].VerifiedAt = "", "", nil
+			}
+			if !reflect.DeepEqual(rows, test.expectedRows) {
+				t.Errorf("Given: %+v, expected: %+v", rows, test.expectedRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package broker
+
+import (
+	"sort"
+	"time"
+)
+
+// Dependency is an edge of the dependency graph: the consumer calls the methods of the provider
+// expected by its latest contract
+type Dependency struct {
+	Consumer string `json:"consumer"`
+	Provider string `json:"provider"`
+	// ConsumerVersion is the version of the latest contract, published at PublishedAt
+	ConsumerVersion string    `json:"consumerVersion"`
+	PublishedAt     time.Time `json:"publishedAt"`
+	// Methods are the methods of the contract, e.g. UserService/GetUser
+	Methods []string `json:"methods"`
+	// Pending is set while no version of the provider verified the contract successfully
+	Pending bool `json:"pending,omitempty"`
+}
+
+// Graph is the graph of the consumers and the providers, derived from the published contracts
+type Graph struct {
+	// Pacticipants are the consumers and the providers of the dependencies, sorted by name
+	Pacticipants []string     `json:"pacticipants"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// NewGraph returns the graph of the dependencies
+func NewGraph(dependencies []Dependency) Graph {
+	pacticipants := make([]string, 0)
+	known := make(map[string]bool)
+	for _, dependency := range dependencies {
+		for _, pacticipant := range []string{dependency.Consumer, dependency.Provider} {
+			if !known[pacticipant] {
+				known[pacticipant] = true
+				pacticipants = append(pacticipants, pacticipant)
+			}
+		}
+	}
+	sort.Strings(pacticipants)
+
+	return Graph{Pacticipants: pacticipants, Dependencies: dependencies}
+}
+
+// Graph returns the dependencies of the latest contracts between each consumer and provider,
+// among the consumer versions selected by the selector, sorted by consumer and provider
+func (b *Broker) Graph(selector Selector) Graph {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	latest := make(map[[2]string]PublishedContract)
+	for _, published := range b.data.Contracts {
+		version, _ := b.findVersion(published.Consumer, published.Version)
+		if selector.matches(version) {
+			latest[[2]string{published.Consumer, published.Provider}] = published
+		}
+	}
+
+	dependencies := make([]Dependency, 0, len(latest))
+	for _, published := range latest {
+		methods := make([]string, 0)
+		for serviceName, service := range published.Contract.Services {
+			for methodName := range service {
+				methods = append(methods, serviceName+"/"+methodName)
+			}
+		}
+		sort.Strings(methods)
+
+		dependencies = append(dependencies, Dependency{
+			Consumer:        published.Consumer,
+			Provider:        published.Provider,
+			ConsumerVersion: published.Version,
+			PublishedAt:     published.PublishedAt,
+			Methods:         methods,
+			Pending:         b.withPending(published).Pending,
+		})
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].Consumer != dependencies[j].Consumer {
+			return dependencies[i].Consumer < dependencies[j].Consumer
+		}
+		return dependencies[i].Provider < dependencies[j].Provider
+	})
+
+	return NewGraph(dependencies)
+}
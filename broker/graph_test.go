@@ -0,0 +1,89 @@
+package broker_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/faunists/deal-go/broker"
+)
+
+func TestBrokerGraph(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishTestContract(t, contractBroker, "1", "john", "prod")
+	publishTestContract(t, contractBroker, "2", "mary")
+	_, err = contractBroker.PublishContract(broker.PublishedContract{
+		Consumer: "mobile", Provider: "orders", Version: "1", Contract: newTestContract("john"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err = contractBroker.PublishVerificationResult(broker.VerificationResult{
+		Consumer: "web", ConsumerVersion: "2", Provider: "users", ProviderVersion: "a", Success: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	methods := []string{"UserService/GetUser"}
+	tests := []struct {
+		name          string
+		selector      broker.Selector
+		expectedGraph broker.Graph
+	}{
+		{
+			name: "should return the latest contract of each consumer and provider",
+			expectedGraph: broker.Graph{
+				Pacticipants: []string{"mobile", "orders", "users", "web"},
+				Dependencies: []broker.Dependency{
+					{
+						Consumer: "mobile", Provider: "orders", ConsumerVersion: "1", Methods: methods,
+						Pending: true,
+					},
+					{Consumer: "web", Provider: "users", ConsumerVersion: "2", Methods: methods},
+				},
+			},
+		},
+		{
+			name:     "should only use the selected consumer versions",
+			selector: broker.Selector{Tag: "prod"},
+			expectedGraph: broker.Graph{
+				Pacticipants: []string{"users", "web"},
+				Dependencies: []broker.Dependency{
+					{
+						Consumer: "web", Provider: "users", ConsumerVersion: "1", Methods: methods,
+						Pending: true,
+					},
+				},
+			},
+		},
+		{
+			name:          "should return an empty graph without selected versions",
+			selector:      broker.Selector{Branch: "main"},
+			expectedGraph: broker.Graph{Pacticipants: []string{}, Dependencies: []broker.Dependency{}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			graph := contractBroker.Graph(test.selector)
+			for index := range graph.Dependencies {
+				if graph.Dependencies[index].PublishedAt.IsZero() {
+					t.Errorf("Given: %+v, expected: a publication time", graph.Dependencies[index])
+				}
+				graph.Dependencies[index].PublishedAt = time.Time{}
+			}
+			if !reflect.DeepEqual(graph, test.expectedGraph) {
+				t.Errorf("Given: %+v, expected: %+v", graph, test.expectedGraph)
+			}
+		})
+	}
+}
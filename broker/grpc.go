@@ -0,0 +1,368 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/faunists/deal-go/brokerpb"
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+// grpcServer serves the broker over gRPC, the contracts are sent as JSON structs
+type grpcServer struct {
+	brokerpb.UnimplementedContractBrokerServer
+	broker *Broker
+}
+
+// NewGRPCServer returns the gRPC API of the broker, to register with
+// brokerpb.RegisterContractBrokerServer. When the broker has tokens, the calls bear one in their
+// authorization metadata, e.g. "Bearer <token>".
+func NewGRPCServer(broker *Broker) brokerpb.ContractBrokerServer {
+	return &grpcServer{broker: broker}
+}
+
+func (s *grpcServer) PublishContract(
+	ctx context.Context,
+	request *brokerpb.PublishContractRequest,
+) (*brokerpb.PublishContractResponse, error) {
+	access, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err = access.authorizeWrite(request.GetContract().GetConsumer()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	published, err := contractFromProto(request.GetContract())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	published, err = s.broker.PublishContract(published)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	contract, err := contractToProto(published)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &brokerpb.PublishContractResponse{Contract: contract}, nil
+}
+
+func (s *grpcServer) GetContract(
+	ctx context.Context,
+	request *brokerpb.GetContractRequest,
+) (*brokerpb.GetContractResponse, error) {
+	access, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err = access.authorizeRead(request.GetConsumer(), request.GetProvider()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	var published PublishedContract
+	if request.GetVersion() != "" {
+		published, err = s.broker.Contract(
+			request.GetConsumer(), request.GetProvider(), request.GetVersion(),
+		)
+	} else {
+		published, err = s.broker.LatestContract(
+			request.GetConsumer(), request.GetProvider(),
+			Selector{Tag: request.GetTag(), Branch: request.GetBranch()},
+		)
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	contract, err := contractToProto(published)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &brokerpb.GetContractResponse{Contract: contract}, nil
+}
+
+func (s *grpcServer) PublishVerificationResult(
+	ctx context.Context,
+	request *brokerpb.PublishVerificationResultRequest,
+) (*brokerpb.PublishVerificationResultResponse, error) {
+	access, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err = access.authorizeWrite(request.GetResult().GetProvider()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	cases := make([]CaseResult, 0, len(request.GetResult().GetCases()))
+	for _, caseResult := range request.GetResult().GetCases() {
+		cases = append(cases, CaseResult{
+			Name:     caseResult.GetName(),
+			Success:  caseResult.GetSuccess(),
+			Duration: caseResult.GetDuration().AsDuration(),
+		})
+	}
+
+	result, err := s.broker.PublishVerificationResult(VerificationResult{
+		Consumer:        request.GetResult().GetConsumer(),
+		ConsumerVersion: request.GetResult().GetConsumerVersion(),
+		Provider:        request.GetResult().GetProvider(),
+		ProviderVersion: request.GetResult().GetProviderVersion(),
+		Success:         request.GetResult().GetSuccess(),
+		Cases:           cases,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	protoResult := &brokerpb.VerificationResult{
+		Consumer:        result.Consumer,
+		ConsumerVersion: result.ConsumerVersion,
+		Provider:        result.Provider,
+		ProviderVersion: result.ProviderVersion,
+		Success:         result.Success,
+		VerifiedAt:      timestamppb.New(result.VerifiedAt),
+	}
+	for _, caseResult := range result.Cases {
+		protoResult.Cases = append(protoResult.Cases, &brokerpb.CaseResult{
+			Name:     caseResult.Name,
+			Success:  caseResult.Success,
+			Duration: durationpb.New(caseResult.Duration),
+		})
+	}
+
+	return &brokerpb.PublishVerificationResultResponse{Result: protoResult}, nil
+}
+
+// GetMatrix answers the rows whose consumer or provider is read with the token
+func (s *grpcServer) GetMatrix(
+	ctx context.Context,
+	request *brokerpb.GetMatrixRequest,
+) (*brokerpb.GetMatrixResponse, error) {
+	access, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	rows, err := s.broker.Matrix(MatrixQuery{
+		Consumer:        request.GetConsumer(),
+		ConsumerVersion: request.GetConsumerVersion(),
+		Provider:        request.GetProvider(),
+		ProviderVersion: request.GetProviderVersion(),
+		Environment:     request.GetEnvironment(),
+		Limit:           int(request.GetLimit()),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	response := &brokerpb.GetMatrixResponse{}
+	for _, row := range rows {
+		if !access.canRead(row.Consumer, row.Provider) {
+			continue
+		}
+
+		protoRow := &brokerpb.MatrixRow{
+			Consumer:        row.Consumer,
+			ConsumerVersion: row.ConsumerVersion,
+			Provider:        row.Provider,
+			ProviderVersion: row.ProviderVersion,
+			Success:         row.Success,
+		}
+		if row.VerifiedAt != nil {
+			protoRow.VerifiedAt = timestamppb.New(*row.VerifiedAt)
+		}
+		response.Rows = append(response.Rows, protoRow)
+	}
+
+	return response, nil
+}
+
+func (s *grpcServer) TagVersion(
+	ctx context.Context,
+	request *brokerpb.TagVersionRequest,
+) (*brokerpb.TagVersionResponse, error) {
+	access, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err = access.authorizeWrite(request.GetPacticipant()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	version, err := s.broker.TagVersion(
+		request.GetPacticipant(), request.GetVersion(), request.GetTag(),
+	)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &brokerpb.TagVersionResponse{Version: &brokerpb.Version{
+		Pacticipant: version.Pacticipant,
+		Number:      version.Number,
+		Branch:      version.Branch,
+		Commit:      version.Commit,
+		Semver:      version.Semver,
+		Tags:        version.Tags,
+		CreatedAt:   timestamppb.New(version.CreatedAt),
+	}}, nil
+}
+
+func (s *grpcServer) RecordDeployment(
+	ctx context.Context,
+	request *brokerpb.RecordDeploymentRequest,
+) (*brokerpb.RecordDeploymentResponse, error) {
+	access, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err = access.authorizeWrite(request.GetPacticipant()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	deployed, err := s.broker.RecordDeployment(
+		request.GetPacticipant(), request.GetVersion(), request.GetEnvironment(),
+	)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &brokerpb.RecordDeploymentResponse{Deployment: deploymentToProto(deployed)}, nil
+}
+
+// ListDeployedVersions answers the deployments of the pacticipants read with the token
+func (s *grpcServer) ListDeployedVersions(
+	ctx context.Context,
+	request *brokerpb.ListDeployedVersionsRequest,
+) (*brokerpb.ListDeployedVersionsResponse, error) {
+	access, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	response := &brokerpb.ListDeployedVersionsResponse{}
+	for _, deployed := range s.broker.DeployedVersions(request.GetEnvironment()) {
+		if !access.canRead(deployed.Pacticipant) {
+			continue
+		}
+		response.Deployments = append(response.Deployments, deploymentToProto(deployed))
+	}
+
+	return response, nil
+}
+
+// authenticate returns the grant of the bearer token of the authorization metadata
+func (s *grpcServer) authenticate(ctx context.Context) (grant, error) {
+	authorization := ""
+	if md, found := metadata.FromIncomingContext(ctx); found && len(md.Get("authorization")) > 0 {
+		authorization = md.Get("authorization")[0]
+	}
+
+	return s.broker.authenticate(bearerToken(authorization))
+}
+
+func deploymentToProto(deployed DeployedVersion) *brokerpb.DeployedVersion {
+	protoDeployment := &brokerpb.DeployedVersion{
+		Pacticipant: deployed.Pacticipant,
+		Version:     deployed.Version,
+		Environment: deployed.Environment,
+		DeployedAt:  timestamppb.New(deployed.DeployedAt),
+	}
+	if deployed.UndeployedAt != nil {
+		protoDeployment.UndeployedAt = timestamppb.New(*deployed.UndeployedAt)
+	}
+
+	return protoDeployment
+}
+
+// contractFromProto converts the published contract, its JSON text, or its JSON struct when the
+// text isn't set, is decoded as a contract
+func contractFromProto(protoContract *brokerpb.PublishedContract) (PublishedContract, error) {
+	published := PublishedContract{
+		Consumer: protoContract.GetConsumer(),
+		Provider: protoContract.GetProvider(),
+		Version:  protoContract.GetVersion(),
+		Branch:   protoContract.GetBranch(),
+		Commit:   protoContract.GetCommit(),
+		Semver:   protoContract.GetSemver(),
+		Tags:     protoContract.GetTags(),
+	}
+
+	data := []byte(protoContract.GetContractJson())
+	if len(data) == 0 {
+		var err error
+		if data, err = protojson.Marshal(protoContract.GetContract()); err != nil {
+			return PublishedContract{}, err
+		}
+	}
+	contract := entities.Contract{}
+	if err := processors.DecodeJSONContract(data, &contract); err != nil {
+		return PublishedContract{}, status.Errorf(codes.InvalidArgument, "invalid contract: %v", err)
+	}
+	published.Contract = contract
+
+	return published, nil
+}
+
+func contractToProto(published PublishedContract) (*brokerpb.PublishedContract, error) {
+	data, err := json.Marshal(published.Contract)
+	if err != nil {
+		return nil, err
+	}
+	contract := &structpb.Struct{}
+	if err = protojson.Unmarshal(data, contract); err != nil {
+		return nil, err
+	}
+
+	return &brokerpb.PublishedContract{
+		Consumer:     published.Consumer,
+		Provider:     published.Provider,
+		Version:      published.Version,
+		Branch:       published.Branch,
+		Commit:       published.Commit,
+		Semver:       published.Semver,
+		Tags:         published.Tags,
+		Contract:     contract,
+		ContractJson: string(data),
+		PublishedAt:  timestamppb.New(published.PublishedAt),
+		Digest:       published.Digest,
+		Pending:      published.Pending,
+	}, nil
+}
+
+// grpcError returns the status of the kind of the error
+func grpcError(err error) error {
+	if _, isStatus := status.FromError(err); isStatus {
+		return err
+	}
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, ErrInvalid):
+		code = codes.InvalidArgument
+	case errors.Is(err, ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, ErrConflict):
+		code = codes.AlreadyExists
+	case errors.Is(err, ErrUnauthenticated):
+		code = codes.Unauthenticated
+	case errors.Is(err, ErrForbidden):
+		code = codes.PermissionDenied
+	}
+
+	return status.Error(code, err.Error())
+}
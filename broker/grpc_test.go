@@ -0,0 +1,159 @@
+package broker_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/faunists/deal-go/broker"
+	"github.com/faunists/deal-go/brokerpb"
+)
+
+// newTestBrokerClient serves the broker over an in-memory connection
+func newTestBrokerClient(
+	t *testing.T,
+	contractBroker *broker.Broker,
+) brokerpb.ContractBrokerClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20) //nolint:revive // the buffer size of the connection
+	server := grpc.NewServer()
+	brokerpb.RegisterContractBrokerServer(server, broker.NewGRPCServer(contractBroker))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return brokerpb.NewContractBrokerClient(conn)
+}
+
+func TestGRPCServer(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client := newTestBrokerClient(t, contractBroker)
+	ctx := context.Background()
+
+	contract, err := structpb.NewStruct(map[string]interface{}{"services": map[string]interface{}{
+		"UserService": map[string]interface{}{"GetUser": map[string]interface{}{
+			"successCases": []interface{}{map[string]interface{}{
+				"request":  map[string]interface{}{"id": "1"},
+				"response": map[string]interface{}{"name": "john"},
+			}},
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = client.PublishContract(ctx, &brokerpb.PublishContractRequest{
+		Contract: &brokerpb.PublishedContract{
+			Consumer: "web", Provider: "users", Version: "1", Contract: contract,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The contract published through gRPC is the same as the one published through Go
+	published := publishTestContract(t, contractBroker, "1", "john")
+
+	response, err := client.GetContract(ctx, &brokerpb.GetContractRequest{
+		Consumer: "web", Provider: "users",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.GetContract().GetDigest() != published.Digest {
+		t.Errorf("Given: %s, expected: %s", response.GetContract().GetDigest(), published.Digest)
+	}
+
+	verified, err := client.PublishVerificationResult(ctx, &brokerpb.PublishVerificationResultRequest{
+		Result: &brokerpb.VerificationResult{
+			Consumer: "web", ConsumerVersion: "1", Provider: "users", ProviderVersion: "a",
+			Success: true,
+			Cases: []*brokerpb.CaseResult{{
+				Name: "GetUser/found_0", Success: true, Duration: durationpb.New(time.Millisecond),
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cases := verified.GetResult().GetCases(); len(cases) != 1 ||
+		cases[0].GetDuration().AsDuration() != time.Millisecond {
+		t.Errorf("Given: %v, expected: the GetUser/found_0 case", cases)
+	}
+
+	matrix, err := client.GetMatrix(ctx, &brokerpb.GetMatrixRequest{Consumer: "web"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rows := matrix.GetRows()
+	if len(rows) != 1 || rows[0].GetProviderVersion() != "a" || !rows[0].GetSuccess() {
+		t.Errorf("Given: %v, expected: the successful verification by a", rows)
+	}
+
+	tagged, err := client.TagVersion(ctx, &brokerpb.TagVersionRequest{
+		Pacticipant: "web", Version: "1", Tag: "prod",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tags := tagged.GetVersion().GetTags(); len(tags) != 1 || tags[0] != "prod" {
+		t.Errorf("Given: %v, expected: %v", tags, []string{"prod"})
+	}
+
+	_, err = client.GetContract(ctx, &brokerpb.GetContractRequest{
+		Consumer: "web", Provider: "users", Tag: "prod", Branch: "main",
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Given: %v, expected: %v", status.Code(err), codes.NotFound)
+	}
+
+	_, err = client.RecordDeployment(ctx, &brokerpb.RecordDeploymentRequest{
+		Pacticipant: "web", Version: "1", Environment: "production",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	deployed, err := client.ListDeployedVersions(ctx, &brokerpb.ListDeployedVersionsRequest{
+		Environment: "production",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deployments := deployed.GetDeployments(); len(deployments) != 1 ||
+		deployments[0].GetVersion() != "1" {
+		t.Errorf("Given: %v, expected: the version 1 of web", deployments)
+	}
+
+	_, err = client.GetContract(ctx, &brokerpb.GetContractRequest{
+		Consumer: "web", Provider: "users", Version: "2",
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Given: %v, expected: %v", status.Code(err), codes.NotFound)
+	}
+}
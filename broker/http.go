@@ -0,0 +1,554 @@
+package broker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxBodySize bounds the bodies of the requests, the contracts included
+const maxBodySize = 32 << 20
+
+// route is an endpoint of the HTTP API, the `{}` segments of the pattern are its parameters
+type route struct {
+	method  string
+	pattern []string
+	handle  func(writer http.ResponseWriter, request *http.Request, params []string, access grant)
+}
+
+// httpAPI serves the broker over HTTP, the bodies are JSON
+type httpAPI struct {
+	broker *Broker
+	routes []route
+}
+
+// NewHTTPHandler returns the HTTP API of the broker:
+//   - POST /contracts publishes a contract
+//   - GET /contracts/provider/{provider}/latest[/{tag}], the latest contract of each consumer
+//   - GET /contracts/provider/{provider}/consumer/{consumer}/version/{version}
+//   - GET /contracts/provider/{provider}/consumer/{consumer}/latest[/{tag}]
+//   - POST /verifications publishes a verification result
+//   - GET /matrix returns the verification matrix, filtered by the consumer, consumerVersion,
+//     provider, providerVersion, environment and limit query parameters
+//   - GET /pacticipants/{pacticipant}/versions lists the versions of a consumer or a provider
+//   - GET /pacticipants/{pacticipant}/versions/latest[/{tag}]
+//   - GET /pacticipants/{pacticipant}/versions/{version}
+//   - PUT /pacticipants/{pacticipant}/versions/{version}/tags/{tag} tags a version
+//   - POST /deployments records the deployment of a version to an environment
+//   - GET /environments/{environment}/deployments, the versions deployed to an environment
+//   - GET /graph returns the dependency graph of the consumers and the providers, filtered by
+//     the tag and branch query parameters of the consumer versions
+//
+// The latest endpoints accept a branch query parameter, selecting the versions of the branch.
+// When the broker has tokens, the requests bear one in their Authorization header and only
+// access the pacticipants of the token, the lists omit the data of the other pacticipants.
+// The failures are answered with a JSON body whose error field describes them.
+func NewHTTPHandler(broker *Broker) http.Handler {
+	api := &httpAPI{broker: broker}
+	api.routes = []route{
+		{method: http.MethodPost, pattern: []string{"contracts"}, handle: api.publishContract},
+		{
+			method:  http.MethodGet,
+			pattern: []string{"contracts", "provider", "{}", "latest"},
+			handle:  api.latestContracts,
+		},
+		{
+			method:  http.MethodGet,
+			pattern: []string{"contracts", "provider", "{}", "latest", "{}"},
+			handle:  api.latestContracts,
+		},
+		{
+			method:  http.MethodGet,
+			pattern: []string{"contracts", "provider", "{}", "consumer", "{}", "version", "{}"},
+			handle:  api.contract,
+		},
+		{
+			method:  http.MethodGet,
+			pattern: []string{"contracts", "provider", "{}", "consumer", "{}", "latest"},
+			handle:  api.latestContract,
+		},
+		{
+			method:  http.MethodGet,
+			pattern: []string{"contracts", "provider", "{}", "consumer", "{}", "latest", "{}"},
+			handle:  api.latestContract,
+		},
+		{
+			method:  http.MethodPost,
+			pattern: []string{"verifications"},
+			handle:  api.publishVerificationResult,
+		},
+		{method: http.MethodGet, pattern: []string{"matrix"}, handle: api.matrix},
+		{
+			method:  http.MethodGet,
+			pattern: []string{"pacticipants", "{}", "versions"},
+			handle:  api.versions,
+		},
+		// The latest versions are matched before the version numbers
+		{
+			method:  http.MethodGet,
+			pattern: []string{"pacticipants", "{}", "versions", "latest"},
+			handle:  api.latestVersion,
+		},
+		{
+			method:  http.MethodGet,
+			pattern: []string{"pacticipants", "{}", "versions", "latest", "{}"},
+			handle:  api.latestVersion,
+		},
+		{
+			method:  http.MethodGet,
+			pattern: []string{"pacticipants", "{}", "versions", "{}"},
+			handle:  api.version,
+		},
+		{
+			method:  http.MethodPut,
+			pattern: []string{"pacticipants", "{}", "versions", "{}", "tags", "{}"},
+			handle:  api.tagVersion,
+		},
+		{method: http.MethodPost, pattern: []string{"deployments"}, handle: api.recordDeployment},
+		{
+			method:  http.MethodGet,
+			pattern: []string{"environments", "{}", "deployments"},
+			handle:  api.deployedVersions,
+		},
+		{method: http.MethodGet, pattern: []string{"graph"}, handle: api.graph},
+	}
+
+	return api
+}
+
+func (a *httpAPI) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	segments, err := pathSegments(request.URL)
+	if err != nil {
+		writeError(writer, fmt.Errorf("%w: %v", ErrInvalid, err))
+		return
+	}
+
+	access, err := a.broker.authenticate(bearerToken(request.Header.Get("Authorization")))
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	allowed := make([]string, 0)
+	for _, route := range a.routes {
+		params, matched := matchPath(route.pattern, segments)
+		if !matched {
+			continue
+		}
+		if route.method == request.Method {
+			route.handle(writer, request, params, access)
+			return
+		}
+		if !contains(allowed, route.method) {
+			allowed = append(allowed, route.method)
+		}
+	}
+
+	if len(allowed) > 0 {
+		writer.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeJSON(writer, http.StatusMethodNotAllowed, errorBody{Error: "method not allowed"})
+		return
+	}
+	writeError(writer, fmt.Errorf("%w: unknown endpoint %s", ErrNotFound, request.URL.Path))
+}
+
+func (a *httpAPI) publishContract(
+	writer http.ResponseWriter,
+	request *http.Request,
+	_ []string,
+	access grant,
+) {
+	published := PublishedContract{}
+	if err := readJSON(writer, request, &published); err != nil {
+		writeError(writer, err)
+		return
+	}
+	if err := access.authorizeWrite(published.Consumer); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	published, err := a.broker.PublishContract(published)
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusCreated, published)
+}
+
+func (a *httpAPI) latestContracts(
+	writer http.ResponseWriter,
+	request *http.Request,
+	params []string,
+	access grant,
+) {
+	provider, selector := params[0], requestSelector(request, params, 1)
+	contracts := make([]PublishedContract, 0)
+	for _, published := range a.broker.LatestContracts(provider, selector) {
+		if access.canRead(published.Consumer, published.Provider) {
+			contracts = append(contracts, published)
+		}
+	}
+
+	writeJSON(writer, http.StatusOK, struct {
+		Contracts []PublishedContract `json:"contracts"`
+	}{Contracts: contracts})
+}
+
+func (a *httpAPI) contract(
+	writer http.ResponseWriter,
+	_ *http.Request,
+	params []string,
+	access grant,
+) {
+	if err := access.authorizeRead(params[1], params[0]); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	published, err := a.broker.Contract(params[1], params[0], params[2])
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, published)
+}
+
+func (a *httpAPI) latestContract(
+	writer http.ResponseWriter,
+	request *http.Request,
+	params []string,
+	access grant,
+) {
+	if err := access.authorizeRead(params[1], params[0]); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	published, err := a.broker.LatestContract(
+		params[1], params[0], requestSelector(request, params, 2),
+	)
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, published)
+}
+
+func (a *httpAPI) publishVerificationResult(
+	writer http.ResponseWriter,
+	request *http.Request,
+	_ []string,
+	access grant,
+) {
+	result := VerificationResult{}
+	if err := readJSON(writer, request, &result); err != nil {
+		writeError(writer, err)
+		return
+	}
+	if err := access.authorizeWrite(result.Provider); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	result, err := a.broker.PublishVerificationResult(result)
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusCreated, result)
+}
+
+// matrix answers the rows whose consumer or provider is read with the token
+func (a *httpAPI) matrix(
+	writer http.ResponseWriter,
+	request *http.Request,
+	_ []string,
+	access grant,
+) {
+	query := request.URL.Query()
+	limit := 0
+	if query.Get("limit") != "" {
+		var err error
+		if limit, err = strconv.Atoi(query.Get("limit")); err != nil {
+			writeError(writer, fmt.Errorf("%w: invalid limit %q", ErrInvalid, query.Get("limit")))
+			return
+		}
+	}
+
+	rows, err := a.broker.Matrix(MatrixQuery{
+		Consumer:        query.Get("consumer"),
+		ConsumerVersion: query.Get("consumerVersion"),
+		Provider:        query.Get("provider"),
+		ProviderVersion: query.Get("providerVersion"),
+		Environment:     query.Get("environment"),
+		Limit:           limit,
+	})
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	readRows := make([]MatrixRow, 0, len(rows))
+	for _, row := range rows {
+		if access.canRead(row.Consumer, row.Provider) {
+			readRows = append(readRows, row)
+		}
+	}
+
+	writeJSON(writer, http.StatusOK, struct {
+		Rows []MatrixRow `json:"rows"`
+	}{Rows: readRows})
+}
+
+func (a *httpAPI) versions(
+	writer http.ResponseWriter,
+	_ *http.Request,
+	params []string,
+	access grant,
+) {
+	if err := access.authorizeRead(params[0]); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, struct {
+		Versions []Version `json:"versions"`
+	}{Versions: a.broker.Versions(params[0])})
+}
+
+func (a *httpAPI) latestVersion(
+	writer http.ResponseWriter,
+	request *http.Request,
+	params []string,
+	access grant,
+) {
+	if err := access.authorizeRead(params[0]); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	version, err := a.broker.LatestVersion(params[0], requestSelector(request, params, 1))
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, version)
+}
+
+func (a *httpAPI) version(
+	writer http.ResponseWriter,
+	_ *http.Request,
+	params []string,
+	access grant,
+) {
+	if err := access.authorizeRead(params[0]); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	version, err := a.broker.Version(params[0], params[1])
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, version)
+}
+
+func (a *httpAPI) tagVersion(
+	writer http.ResponseWriter,
+	_ *http.Request,
+	params []string,
+	access grant,
+) {
+	if err := access.authorizeWrite(params[0]); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	version, err := a.broker.TagVersion(params[0], params[1], params[2])
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, version)
+}
+
+func (a *httpAPI) recordDeployment(
+	writer http.ResponseWriter,
+	request *http.Request,
+	_ []string,
+	access grant,
+) {
+	deployed := DeployedVersion{}
+	if err := readJSON(writer, request, &deployed); err != nil {
+		writeError(writer, err)
+		return
+	}
+	if err := access.authorizeWrite(deployed.Pacticipant); err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	deployed, err := a.broker.RecordDeployment(
+		deployed.Pacticipant, deployed.Version, deployed.Environment,
+	)
+	if err != nil {
+		writeError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusCreated, deployed)
+}
+
+// deployedVersions answers the deployments of the pacticipants read with the token
+func (a *httpAPI) deployedVersions(
+	writer http.ResponseWriter,
+	_ *http.Request,
+	params []string,
+	access grant,
+) {
+	deployments := make([]DeployedVersion, 0)
+	for _, deployed := range a.broker.DeployedVersions(params[0]) {
+		if access.canRead(deployed.Pacticipant) {
+			deployments = append(deployments, deployed)
+		}
+	}
+
+	writeJSON(writer, http.StatusOK, struct {
+		Deployments []DeployedVersion `json:"deployments"`
+	}{Deployments: deployments})
+}
+
+// graph answers the dependencies whose consumer or provider is read with the token
+func (a *httpAPI) graph(
+	writer http.ResponseWriter,
+	request *http.Request,
+	_ []string,
+	access grant,
+) {
+	query := request.URL.Query()
+	graph := a.broker.Graph(Selector{Tag: query.Get("tag"), Branch: query.Get("branch")})
+
+	dependencies := make([]Dependency, 0, len(graph.Dependencies))
+	for _, dependency := range graph.Dependencies {
+		if access.canRead(dependency.Consumer, dependency.Provider) {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	writeJSON(writer, http.StatusOK, NewGraph(dependencies))
+}
+
+// requestSelector returns the selector of the optional tag parameter and of the branch query
+func requestSelector(request *http.Request, params []string, tagIndex int) Selector {
+	return Selector{
+		Tag:    optionalParam(params, tagIndex),
+		Branch: request.URL.Query().Get("branch"),
+	}
+}
+
+// bearerToken returns the token of an Authorization header, e.g. "Bearer <token>"
+func bearerToken(authorization string) string {
+	// The header holds the scheme and the token
+	fields := strings.Fields(authorization)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") { //nolint:revive
+		return ""
+	}
+
+	return fields[1]
+}
+
+// pathSegments returns the unescaped segments of the path, the names may contain slashes
+func pathSegments(requestURL *url.URL) ([]string, error) {
+	segments := strings.Split(strings.Trim(requestURL.EscapedPath(), "/"), "/")
+	for index, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, err
+		}
+		segments[index] = unescaped
+	}
+
+	return segments, nil
+}
+
+// matchPath returns the parameters of the path when it matches the pattern
+func matchPath(pattern, segments []string) ([]string, bool) {
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+
+	params := make([]string, 0)
+	for index, segment := range pattern {
+		switch {
+		case segment == "{}" && segments[index] != "":
+			params = append(params, segments[index])
+		case segment != segments[index]:
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func optionalParam(params []string, index int) string {
+	if index < len(params) {
+		return params[index]
+	}
+
+	return ""
+}
+
+// errorBody is the body of the failed responses
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// readJSON decodes the body of the request
+func readJSON(writer http.ResponseWriter, request *http.Request, value interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxBodySize))
+	decoder.UseNumber()
+	if err := decoder.Decode(value); err != nil {
+		return fmt.Errorf("%w: invalid body: %v", ErrInvalid, err)
+	}
+
+	return nil
+}
+
+func writeJSON(writer http.ResponseWriter, statusCode int, value interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(value)
+}
+
+// writeError answers with the status code of the kind of the error
+func writeError(writer http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrInvalid):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, ErrNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		statusCode = http.StatusConflict
+	case errors.Is(err, ErrUnauthenticated):
+		statusCode = http.StatusUnauthorized
+		writer.Header().Set("WWW-Authenticate", `Bearer realm="deal broker"`)
+	case errors.Is(err, ErrForbidden):
+		statusCode = http.StatusForbidden
+	}
+
+	writeJSON(writer, statusCode, errorBody{Error: err.Error()})
+}
@@ -0,0 +1,237 @@
+package broker_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/faunists/deal-go/broker"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishTestContract(t, contractBroker, "1", "john", "prod")
+	server := httptest.NewServer(broker.NewHTTPHandler(contractBroker))
+	t.Cleanup(server.Close)
+
+	body, err := json.Marshal(broker.PublishedContract{
+		Consumer: "web", Provider: "users", Version: "2", Contract: newTestContract("mary"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	deploymentBody, err := json.Marshal(broker.DeployedVersion{
+		Pacticipant: "web", Version: "1", Environment: "production",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		body               []byte
+		expectedStatusCode int
+		expectedVersion    string
+	}{
+		{
+			name:               "should publish a contract",
+			method:             http.MethodPost,
+			path:               "/contracts",
+			body:               body,
+			expectedStatusCode: http.StatusCreated,
+			expectedVersion:    "2",
+		},
+		{
+			name:               "should reject an invalid body",
+			method:             http.MethodPost,
+			path:               "/contracts",
+			body:               []byte(`{"consumer":`),
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "should return the contract of a version",
+			method:             http.MethodGet,
+			path:               "/contracts/provider/users/consumer/web/version/1",
+			expectedStatusCode: http.StatusOK,
+			expectedVersion:    "1",
+		},
+		{
+			name:               "should return the latest contract with the tag",
+			method:             http.MethodGet,
+			path:               "/contracts/provider/users/consumer/web/latest/prod",
+			expectedStatusCode: http.StatusOK,
+			expectedVersion:    "1",
+		},
+		{
+			name:               "should fail when the contract doesn't exist",
+			method:             http.MethodGet,
+			path:               "/contracts/provider/users/consumer/web/version/3",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "should record a deployment",
+			method:             http.MethodPost,
+			path:               "/deployments",
+			body:               deploymentBody,
+			expectedStatusCode: http.StatusCreated,
+			expectedVersion:    "1",
+		},
+		{
+			name:               "should reject a deployment without environment",
+			method:             http.MethodPost,
+			path:               "/deployments",
+			body:               []byte(`{"pacticipant": "web", "version": "1"}`),
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "should list the versions deployed to an environment",
+			method:             http.MethodGet,
+			path:               "/environments/production/deployments",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "should reject an invalid matrix limit",
+			method:             http.MethodGet,
+			path:               "/matrix?limit=ten",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "should fail when the method isn't supported",
+			method:             http.MethodDelete,
+			path:               "/matrix",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			request, err := http.NewRequest(
+				test.method, server.URL+test.path, bytes.NewReader(test.body),
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			response, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer response.Body.Close()
+
+			if response.StatusCode != test.expectedStatusCode {
+				t.Fatalf("Given: %d, expected: %d", response.StatusCode, test.expectedStatusCode)
+			}
+
+			published := struct {
+				broker.PublishedContract
+				Error string `json:"error"`
+			}{}
+			if err = json.NewDecoder(response.Body).Decode(&published); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if published.Version != test.expectedVersion {
+				t.Errorf("Given: %s, expected: %s", published.Version, test.expectedVersion)
+			}
+			if test.expectedStatusCode >= http.StatusBadRequest && published.Error == "" {
+				t.Error("Expected an error message, given an empty one")
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerVersions(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishTestContract(t, contractBroker, "1", "john", "prod")
+	publishTestContract(t, contractBroker, "2", "mary")
+	server := httptest.NewServer(broker.NewHTTPHandler(contractBroker))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		expectedStatusCode int
+		expectedNumber     string
+	}{
+		{
+			name:               "should return a version",
+			method:             http.MethodGet,
+			path:               "/pacticipants/web/versions/1",
+			expectedStatusCode: http.StatusOK,
+			expectedNumber:     "1",
+		},
+		{
+			name:               "should return the latest version",
+			method:             http.MethodGet,
+			path:               "/pacticipants/web/versions/latest",
+			expectedStatusCode: http.StatusOK,
+			expectedNumber:     "2",
+		},
+		{
+			name:               "should return the latest version with the tag",
+			method:             http.MethodGet,
+			path:               "/pacticipants/web/versions/latest/prod",
+			expectedStatusCode: http.StatusOK,
+			expectedNumber:     "1",
+		},
+		{
+			name:               "should fail when no version is on the branch",
+			method:             http.MethodGet,
+			path:               "/pacticipants/web/versions/latest?branch=feature",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "should tag a version",
+			method:             http.MethodPut,
+			path:               "/pacticipants/users/versions/a/tags/prod",
+			expectedStatusCode: http.StatusOK,
+			expectedNumber:     "a",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			request, err := http.NewRequest(test.method, server.URL+test.path, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			response, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer response.Body.Close()
+
+			if response.StatusCode != test.expectedStatusCode {
+				t.Fatalf("Given: %d, expected: %d", response.StatusCode, test.expectedStatusCode)
+			}
+
+			version := broker.Version{}
+			if err = json.NewDecoder(response.Body).Decode(&version); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version.Number != test.expectedNumber {
+				t.Errorf("Given: %s, expected: %s", version.Number, test.expectedNumber)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package broker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// postgresTimeout bounds the queries of the Postgres storage
+const postgresTimeout = 30 * time.Second
+
+// postgresSchema creates the table of the data, each record of the data is a row of the table.
+// The revision of a row is incremented by every update.
+const postgresSchema = `CREATE TABLE IF NOT EXISTS deal_broker_records (
+	kind TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	revision INTEGER NOT NULL,
+	data JSONB NOT NULL,
+	PRIMARY KEY (kind, position)
+)`
+
+// PostgresStorage keeps the data in the deal_broker_records table of a Postgres database, e.g. a
+// managed database in production. The instances of a broker may share the database: a record
+// written by another instance since the data was loaded is a conflict, the broker reloads the
+// data then. The changes of the other instances aren't seen until then.
+type PostgresStorage struct {
+	db    *sql.DB
+	mutex sync.Mutex
+	// revisions are the revisions of the rows loaded or saved by the storage
+	revisions map[recordKey]int
+}
+
+// NewPostgresStorage creates the table of the data when it doesn't exist. The database is opened
+// with a Postgres driver, e.g. github.com/lib/pq, and it's closed by the caller.
+func NewPostgresStorage(db *sql.DB) (*PostgresStorage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+
+	if _, err := db.ExecContext(ctx, postgresSchema); err != nil {
+		return nil, fmt.Errorf("failed to create the broker table: %w", err)
+	}
+
+	return &PostgresStorage{db: db, revisions: make(map[recordKey]int)}, nil
+}
+
+// Load reads the rows of the data along with their revision
+func (s *PostgresStorage) Load() (Data, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+
+	data := Data{}
+	rows, err := s.db.QueryContext(
+		ctx,
+		"SELECT kind, position, revision, data FROM deal_broker_records ORDER BY kind, position",
+	)
+	if err != nil {
+		return data, err
+	}
+	defer rows.Close()
+
+	revisions := make(map[recordKey]int)
+	for rows.Next() {
+		var key recordKey
+		var revision int
+		var content []byte
+		if err = rows.Scan(&key.kind, &key.position, &revision, &content); err != nil {
+			return data, err
+		}
+
+		if err = decodeRecord(&data, key.kind, key.position, content); err != nil {
+			return data, err
+		}
+		revisions[key] = revision
+	}
+	if err = rows.Err(); err != nil {
+		return data, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.revisions = revisions
+
+	return data, nil
+}
+
+// Save writes the rows of the records in a transaction. A row is only inserted when it doesn't
+// exist and only updated when its revision is the loaded one, otherwise it's a conflict.
+func (s *PostgresStorage) Save(records []Record) error {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresTimeout)
+	defer cancel()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, record := range records {
+		content, err := json.Marshal(record.Value)
+		if err != nil {
+			return err
+		}
+
+		var result sql.Result
+		key := recordKey{kind: record.Kind, position: record.Position}
+		if revision, loaded := s.revisions[key]; loaded {
+			result, err = tx.ExecContext(
+				ctx,
+				"UPDATE deal_broker_records SET data = $1, revision = revision + 1 "+
+					"WHERE kind = $2 AND position = $3 AND revision = $4",
+				string(content), string(key.kind), key.position, revision,
+			)
+		} else {
+			result, err = tx.ExecContext(
+				ctx,
+				"INSERT INTO deal_broker_records (kind, position, revision, data) "+
+					"VALUES ($1, $2, 1, $3) ON CONFLICT DO NOTHING",
+				string(key.kind), key.position, string(content),
+			)
+		}
+		if err != nil {
+			return err
+		}
+
+		written, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if written == 0 {
+			return conflictError(key)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	for _, record := range records {
+		s.revisions[recordKey{kind: record.Kind, position: record.Position}]++
+	}
+
+	return nil
+}
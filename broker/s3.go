@@ -0,0 +1,220 @@
+package broker
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultS3Region is the region of the S3 blob stores without their own region
+const DefaultS3Region = "us-east-1"
+
+// DefaultS3Timeout bounds the requests of the S3 blob stores without their own HTTP client
+const DefaultS3Timeout = 30 * time.Second
+
+// S3Config locates the bucket of an S3BlobStore along with its credentials
+type S3Config struct {
+	// Endpoint is the URL of the S3 API, https://s3.<region>.amazonaws.com by default. The
+	// compatible APIs work as well, e.g. https://storage.googleapis.com with the HMAC keys of a
+	// Google Cloud Storage service account and the auto region.
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is prepended to the keys of the blobs, e.g. "contracts/"
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// HTTPClient sends the requests, its timeout is DefaultS3Timeout by default
+	HTTPClient *http.Client
+}
+
+// S3BlobStore keeps the blobs as the objects of an S3 bucket, the requests are signed with the
+// AWS signature version 4
+type S3BlobStore struct {
+	endpoint *url.URL
+	config   S3Config
+}
+
+// NewS3BlobStore returns a blob store of the bucket, the bucket is addressed by the path of the
+// requests rather than by their host
+func NewS3BlobStore(config S3Config) (*S3BlobStore, error) {
+	if config.Bucket == "" || config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("the bucket and the credentials of the S3 blob store are required")
+	}
+	if config.Region == "" {
+		config.Region = DefaultS3Region
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: DefaultS3Timeout}
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf(
+			"invalid S3 endpoint %q: an http or https URL is expected", config.Endpoint,
+		)
+	}
+
+	return &S3BlobStore{endpoint: endpoint, config: config}, nil
+}
+
+// Put uploads the object of the key
+func (s *S3BlobStore) Put(key string, content []byte) error {
+	response, err := s.do(http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return s3Error(response)
+	}
+
+	return nil
+}
+
+// Get downloads the object of the key
+func (s *S3BlobStore) Get(key string) ([]byte, error) {
+	response, err := s.do(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		return response.content, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: no object %s in %s", ErrNotFound, key, s.config.Bucket)
+	default:
+		return nil, s3Error(response)
+	}
+}
+
+// s3Response is a response of the S3 API along with its body
+type s3Response struct {
+	*http.Response
+	content []byte
+}
+
+// do sends the signed request of the object of the key
+func (s *S3BlobStore) do(method, key string, content []byte) (s3Response, error) {
+	endpoint := *s.endpoint
+	endpoint.Path += "/" + s.config.Bucket + "/" + s.config.Prefix + key
+	endpoint.RawPath = s3Escape(endpoint.Path)
+
+	request, err := http.NewRequest(method, endpoint.String(), bytes.NewReader(content))
+	if err != nil {
+		return s3Response{}, err
+	}
+	if content != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	s.sign(request, content, time.Now())
+
+	response, err := s.config.HTTPClient.Do(request)
+	if err != nil {
+		return s3Response{}, fmt.Errorf("S3 unavailable: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return s3Response{}, fmt.Errorf("S3 unavailable: %w", err)
+	}
+
+	return s3Response{Response: response, content: body}, nil
+}
+
+// sign adds the AWS signature version 4 of the request to its headers, every header set so far
+// is signed
+func (s *S3BlobStore) sign(request *http.Request, content []byte, now time.Time) {
+	payloadHash := sha256.Sum256(content)
+	amzDate := now.UTC().Format("20060102T150405Z")
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	if s.config.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := strings.Builder{}
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	date := amzDate[:8]
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" +
+		hex.EncodeToString(canonicalHash[:])
+
+	key := []byte("AWS4" + s.config.SecretAccessKey)
+	for _, part := range []string{date, s.config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+// s3Escape encodes the path the way the signature expects it, every byte but the unreserved
+// characters and the slashes is escaped
+func s3Escape(path string) string {
+	escaped := strings.Builder{}
+	for _, char := range []byte(path) {
+		switch {
+		case 'A' <= char && char <= 'Z', 'a' <= char && char <= 'z', '0' <= char && char <= '9',
+			strings.IndexByte("-_.~/", char) >= 0:
+			escaped.WriteByte(char)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", char)
+		}
+	}
+
+	return escaped.String()
+}
+
+// s3Error describes a failed response of the S3 API
+func s3Error(response s3Response) error {
+	return fmt.Errorf(
+		"S3 responded %d %s: %s", response.StatusCode, http.StatusText(response.StatusCode),
+		strings.TrimSpace(string(response.content)),
+	)
+}
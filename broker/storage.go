@@ -0,0 +1,368 @@
+package broker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+// Storage persists the data of a broker. The broker keeps its data in memory, it loads the data
+// once and saves the records written by every change, one change at a time.
+type Storage interface {
+	// Load returns the saved data, the empty data when nothing was saved yet
+	Load() (Data, error)
+	// Save writes the records of a change, all of them or none. A record written by another
+	// broker since the data was loaded is an error wrapping ErrConflict.
+	Save(records []Record) error
+}
+
+// RecordKind names the field of the data holding a record
+type RecordKind string
+
+// The kinds of the records, named after the JSON fields of the data
+const (
+	VersionRecord      RecordKind = "versions"
+	ContractRecord     RecordKind = "contracts"
+	VerificationRecord RecordKind = "verifications"
+	DeploymentRecord   RecordKind = "deployments"
+)
+
+// recordKinds are the kinds of the records, in the order of the fields of the data
+var recordKinds = []RecordKind{
+	VersionRecord, ContractRecord, VerificationRecord, DeploymentRecord,
+}
+
+// Record is a record added or replaced by a change of the data. Position is its index in the
+// field of its kind, Value is a Version, a PublishedContract, a VerificationResult or a
+// DeployedVersion.
+type Record struct {
+	Kind     RecordKind
+	Position int
+	Value    interface{}
+}
+
+// recordKey identifies a record by its kind and position
+type recordKey struct {
+	kind     RecordKind
+	position int
+}
+
+// conflictError is the error of a record written by another broker
+func conflictError(key recordKey) error {
+	return fmt.Errorf(
+		"%w: the broker %s record %d was written by another broker",
+		ErrConflict, key.kind, key.position,
+	)
+}
+
+// loadedRecords are the contents of the records loaded or saved by a storage, the records
+// written by another broker since then have another content
+type loadedRecords map[recordKey]string
+
+// check returns a conflict error when the stored record isn't the loaded one
+func (r loadedRecords) check(key recordKey, stored []byte, exists bool) error {
+	loaded, wasLoaded := r[key]
+	if wasLoaded != exists || loaded != string(stored) {
+		return conflictError(key)
+	}
+
+	return nil
+}
+
+// dataRecords returns the JSON contents of the records of the data
+func dataRecords(data *Data) (loadedRecords, error) {
+	records := make(loadedRecords)
+	for _, kind := range recordKinds {
+		field, err := recordField(data, kind)
+		if err != nil {
+			return nil, err
+		}
+
+		for position := 0; position < field.Len(); position++ {
+			content, err := json.Marshal(field.Index(position).Interface())
+			if err != nil {
+				return nil, err
+			}
+			records[recordKey{kind: kind, position: position}] = string(content)
+		}
+	}
+
+	return records, nil
+}
+
+// BlobStore keeps the contents of the contracts by key, e.g. in a bucket. The missing blobs
+// are errors matching ErrNotFound.
+type BlobStore interface {
+	Put(key string, content []byte) error
+	Get(key string) ([]byte, error)
+}
+
+// recordField returns the field of the data holding the records of the kind
+func recordField(data *Data, kind RecordKind) (reflect.Value, error) {
+	fields := map[RecordKind]interface{}{
+		VersionRecord:      &data.Versions,
+		ContractRecord:     &data.Contracts,
+		VerificationRecord: &data.Verifications,
+		DeploymentRecord:   &data.Deployments,
+	}
+	field, known := fields[kind]
+	if !known {
+		return reflect.Value{}, fmt.Errorf("unknown broker record kind %q", kind)
+	}
+
+	return reflect.ValueOf(field).Elem(), nil
+}
+
+// applyRecords writes the records into the data, a record following the last one of its kind
+// is appended
+func applyRecords(data *Data, records ...Record) error {
+	for _, record := range records {
+		field, err := recordField(data, record.Kind)
+		if err != nil {
+			return err
+		}
+
+		value := reflect.ValueOf(record.Value)
+		if !value.IsValid() || value.Type() != field.Type().Elem() {
+			return fmt.Errorf("invalid broker %s record: %T", record.Kind, record.Value)
+		}
+		switch {
+		case record.Position >= 0 && record.Position < field.Len():
+			field.Index(record.Position).Set(value)
+		case record.Position == field.Len():
+			field.Set(reflect.Append(field, value))
+		default:
+			return fmt.Errorf(
+				"missing broker %s records before the position %d", record.Kind, record.Position,
+			)
+		}
+	}
+
+	return nil
+}
+
+// decodeRecord decodes the JSON record into the data, the records of a kind are decoded in the
+// order of their positions
+func decodeRecord(data *Data, kind RecordKind, position int, content []byte) error {
+	field, err := recordField(data, kind)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.New(field.Type().Elem())
+	if err = decodeJSON(content, value.Interface()); err != nil {
+		return fmt.Errorf("invalid broker %s record %d: %w", kind, position, err)
+	}
+
+	return applyRecords(data, Record{Kind: kind, Position: position, Value: value.Elem().Interface()})
+}
+
+// FileStorage keeps the data in a JSON file, the whole file is rewritten by every save
+type FileStorage struct {
+	path   string
+	loaded loadedRecords
+}
+
+// NewFileStorage returns a storage writing the data to the JSON file, the file is created by
+// the first save
+func NewFileStorage(path string) *FileStorage {
+	return &FileStorage{path: path}
+}
+
+// Load reads the data file, its records are kept to detect the conflicts of the next saves
+func (s *FileStorage) Load() (Data, error) {
+	data, err := s.read()
+	if err != nil {
+		return data, err
+	}
+
+	s.loaded, err = dataRecords(&data)
+	return data, err
+}
+
+// read decodes the data file
+func (s *FileStorage) read() (Data, error) {
+	data := Data{}
+	content, err := ioutil.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return data, err
+	}
+	if err = decodeJSON(content, &data); err != nil {
+		return data, fmt.Errorf("invalid broker data file %s: %w", s.path, err)
+	}
+
+	return data, nil
+}
+
+// Save writes the records into the data file, through a temporary file so a failure keeps the
+// previous data. The records of the file must be the loaded or saved ones.
+func (s *FileStorage) Save(records []Record) error {
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	stored, err := dataRecords(&data)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		key := recordKey{kind: record.Kind, position: record.Position}
+		content, exists := stored[key]
+		if err = s.loaded.check(key, []byte(content), exists); err != nil {
+			return err
+		}
+	}
+
+	if err = applyRecords(&data, records...); err != nil {
+		return err
+	}
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if err = writeFileAtomically(s.path, content); err != nil {
+		return err
+	}
+
+	// The records are compared as the file decodes them
+	saved := Data{}
+	if err = decodeJSON(content, &saved); err != nil {
+		return err
+	}
+	s.loaded, err = dataRecords(&saved)
+	return err
+}
+
+// DirectoryBlobStore keeps the blobs as the files of a directory
+type DirectoryBlobStore struct {
+	directory string
+}
+
+// NewDirectoryBlobStore returns a blob store writing to the directory, the directory is created
+// by the first blob
+func NewDirectoryBlobStore(directory string) *DirectoryBlobStore {
+	return &DirectoryBlobStore{directory: directory}
+}
+
+// Put writes the blob file
+func (s *DirectoryBlobStore) Put(key string, content []byte) error {
+	if err := os.MkdirAll(s.directory, 0o700); err != nil {
+		return err
+	}
+
+	return writeFileAtomically(filepath.Join(s.directory, key), content)
+}
+
+// Get reads the blob file
+func (s *DirectoryBlobStore) Get(key string) ([]byte, error) {
+	content, err := ioutil.ReadFile(filepath.Join(s.directory, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: no blob %s in %s", ErrNotFound, key, s.directory)
+	}
+
+	return content, err
+}
+
+// blobStorage keeps the contents of the contracts in a blob store, keyed by their digest, and
+// the rest of the data in its storage
+type blobStorage struct {
+	Storage
+	blobs BlobStore
+	// stored are the digests known by the blob store, the contents never change
+	stored map[string]bool
+	// unmoved are the records of the contracts saved with their content, before the blob store
+	// was used
+	unmoved []Record
+}
+
+// Load fetches the contents of the contracts. The contracts saved before the blob store was
+// used keep their own content, it's moved to the blob store by the next save.
+func (s *blobStorage) Load() (Data, error) {
+	data, err := s.Storage.Load()
+	if err != nil {
+		return data, err
+	}
+
+	s.stored = make(map[string]bool)
+	s.unmoved = nil
+	for index, published := range data.Contracts {
+		content, err := s.blobs.Get(blobKey(published.Digest))
+		if errors.Is(err, ErrNotFound) {
+			s.unmoved = append(s.unmoved, Record{
+				Kind: ContractRecord, Position: index, Value: published,
+			})
+			continue
+		}
+		if err != nil {
+			return data, err
+		}
+
+		err = processors.DecodeJSONContract(content, &data.Contracts[index].Contract)
+		if err != nil {
+			return data, fmt.Errorf("invalid contract blob %s: %w", published.Digest, err)
+		}
+		s.stored[published.Digest] = true
+	}
+
+	return data, nil
+}
+
+// Save puts the contents of the new contracts before saving the records without them
+func (s *blobStorage) Save(records []Record) error {
+	if s.stored == nil {
+		s.stored = make(map[string]bool)
+	}
+
+	saved := make([]Record, 0, len(s.unmoved)+len(records))
+	for _, record := range append(append([]Record{}, s.unmoved...), records...) {
+		if published, isContract := record.Value.(PublishedContract); isContract {
+			if !s.stored[published.Digest] {
+				content, err := json.Marshal(published.Contract)
+				if err != nil {
+					return err
+				}
+				if err = s.blobs.Put(blobKey(published.Digest), content); err != nil {
+					return err
+				}
+				s.stored[published.Digest] = true
+			}
+
+			published.Contract = entities.Contract{}
+			record.Value = published
+		}
+		saved = append(saved, record)
+	}
+
+	if err := s.Storage.Save(saved); err != nil {
+		return err
+	}
+	s.unmoved = nil
+
+	return nil
+}
+
+// blobKey is the key of the content of a contract
+func blobKey(digest string) string {
+	return digest + ".json"
+}
+
+// writeFileAtomically writes the file through a temporary file, so a failure keeps the previous
+// content
+func writeFileAtomically(path string, content []byte) error {
+	temporaryPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := ioutil.WriteFile(temporaryPath, content, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(temporaryPath, path)
+}
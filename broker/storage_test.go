@@ -0,0 +1,662 @@
+package broker_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	_ "github.com/lib/pq"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/faunists/deal-go/broker"
+	"github.com/faunists/deal-go/brokerclient"
+	"github.com/faunists/deal-go/brokerpb"
+)
+
+// postgresURLVariable holds the URL of the database of the Postgres storage tests, they're
+// skipped without it
+const postgresURLVariable = "DEAL_TEST_POSTGRES_URL"
+
+// assertBrokerData checks the data published by publishBrokerData once the broker is reloaded
+func assertBrokerData(
+	t *testing.T,
+	contractBroker *broker.Broker,
+	expected broker.PublishedContract,
+) {
+	t.Helper()
+
+	published, err := contractBroker.Contract("web", "users", "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(published, expected) {
+		t.Errorf("Given: %+v, expected: %+v", published, expected)
+	}
+
+	rows, err := contractBroker.Matrix(broker.MatrixQuery{Consumer: "web"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].ProviderVersion != "a" {
+		t.Errorf("Given: %+v, expected: the verification of users a", rows)
+	}
+
+	deployed, err := contractBroker.DeployedVersion("web", "production")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deployed.Version != "1" {
+		t.Errorf("Given: %s, expected: %s", deployed.Version, "1")
+	}
+}
+
+// publishBrokerData publishes a contract, its verification and its deployment, the contract is
+// returned as the broker shows it afterwards
+func publishBrokerData(t *testing.T, contractBroker *broker.Broker) broker.PublishedContract {
+	t.Helper()
+
+	published := publishTestContract(t, contractBroker, "1", "john", "main")
+	// The deployment of 1 replaces the one of 0, which is updated
+	if _, err := contractBroker.RecordDeployment("web", "0", "production"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err := contractBroker.PublishVerificationResult(broker.VerificationResult{
+		Consumer: "web", ConsumerVersion: "1", Provider: "users", ProviderVersion: "a", Success: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err = contractBroker.RecordDeployment("web", "1", "production"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The successful verification settles the contract
+	published.Pending = false
+
+	return published
+}
+
+func TestBrokerStorages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// open returns the options of a broker using the storage of the directory, along with
+		// the function releasing the storage
+		open func(t *testing.T, directory string) ([]broker.BrokerOption, func())
+	}{
+		{
+			name: "should keep the data in a JSON file",
+			open: func(_ *testing.T, directory string) ([]broker.BrokerOption, func()) {
+				storage := broker.NewFileStorage(filepath.Join(directory, "broker.json"))
+				return []broker.BrokerOption{broker.WithStorage(storage)}, func() {}
+			},
+		},
+		{
+			name: "should keep the data in a bbolt file",
+			open: func(t *testing.T, directory string) ([]broker.BrokerOption, func()) {
+				storage, err := broker.NewBoltStorage(filepath.Join(directory, "broker.db"))
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return []broker.BrokerOption{broker.WithStorage(storage)}, func() {
+					if err := storage.Close(); err != nil {
+						t.Errorf("Unexpected error: %v", err)
+					}
+				}
+			},
+		},
+		{
+			name: "should keep the contracts in a blob directory",
+			open: func(_ *testing.T, directory string) ([]broker.BrokerOption, func()) {
+				return []broker.BrokerOption{
+					broker.WithStorage(broker.NewFileStorage(filepath.Join(directory, "broker.json"))),
+					broker.WithContractBlobs(
+						broker.NewDirectoryBlobStore(filepath.Join(directory, "contracts")),
+					),
+				}, func() {}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+			opts, closeStorage := test.open(t, directory)
+			contractBroker, err := broker.NewBroker("", opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			published := publishBrokerData(t, contractBroker)
+			closeStorage()
+
+			opts, closeStorage = test.open(t, directory)
+			defer closeStorage()
+			reloadedBroker, err := broker.NewBroker("", opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			assertBrokerData(t, reloadedBroker, published)
+		})
+	}
+}
+
+func TestBrokerContractBlobs(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+	dataPath := filepath.Join(directory, "broker.json")
+	blobs := broker.NewDirectoryBlobStore(filepath.Join(directory, "contracts"))
+
+	// The contracts published before the blobs are moved to the blob store by the next change
+	contractBroker, err := broker.NewBroker(dataPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	published := publishTestContract(t, contractBroker, "1", "john")
+
+	contractBroker, err = broker.NewBroker(dataPath, broker.WithContractBlobs(blobs))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishTestContract(t, contractBroker, "2", "mary")
+
+	data, err := ioutil.ReadFile(dataPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "john") || strings.Contains(string(data), "mary") {
+		t.Errorf("Given: %s, expected: the data without the contents of the contracts", data)
+	}
+	if _, err = blobs.Get(published.Digest + ".json"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	reloadedBroker, err := broker.NewBroker(dataPath, broker.WithContractBlobs(blobs))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reloaded, err := reloadedBroker.Contract("web", "users", "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(reloaded.Contract, published.Contract) {
+		t.Errorf("Given: %+v, expected: %+v", reloaded.Contract, published.Contract)
+	}
+
+	if _, err = blobs.Get("missing.json"); !errors.Is(err, broker.ErrNotFound) {
+		t.Errorf("Given: %v, expected: %v", err, broker.ErrNotFound)
+	}
+}
+
+// fakeS3 is an in-memory S3 API checking the shape of the signatures
+type fakeS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	authorization := request.Header.Get("Authorization")
+	if request.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) ||
+		!strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=key/") ||
+		!strings.Contains(authorization, "/auto/s3/aws4_request, SignedHeaders=") {
+		writer.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	switch request.Method {
+	case http.MethodPut:
+		f.objects[request.URL.EscapedPath()] = body
+	case http.MethodGet:
+		object, found := f.objects[request.URL.EscapedPath()]
+		if !found {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = writer.Write(object)
+	default:
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3BlobStore(t *testing.T) {
+	t.Parallel()
+
+	s3 := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(s3)
+	t.Cleanup(server.Close)
+
+	blobs, err := broker.NewS3BlobStore(broker.S3Config{
+		Endpoint:        server.URL,
+		Region:          "auto",
+		Bucket:          "deal",
+		Prefix:          "contracts/",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err = blobs.Put("a b.json", []byte(`{"services": {}}`)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, found := s3.objects["/deal/contracts/a%20b.json"]; !found {
+		t.Errorf("Given: %v, expected: the object /deal/contracts/a%%20b.json", s3.objects)
+	}
+
+	content, err := blobs.Get("a b.json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(content, []byte(`{"services": {}}`)) {
+		t.Errorf("Given: %s, expected: %s", content, `{"services": {}}`)
+	}
+
+	if _, err = blobs.Get("missing.json"); !errors.Is(err, broker.ErrNotFound) {
+		t.Errorf("Given: %v, expected: %v", err, broker.ErrNotFound)
+	}
+
+	unsigned, err := broker.NewS3BlobStore(broker.S3Config{
+		Endpoint: server.URL, Bucket: "deal", AccessKeyID: "other", SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err = unsigned.Put("a.json", nil); err == nil {
+		t.Error("Expected an error, given nil")
+	}
+}
+
+func TestNewS3BlobStoreRejectsInvalidConfigs(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []broker.S3Config{
+		{AccessKeyID: "key", SecretAccessKey: "secret"},
+		{Bucket: "deal"},
+		{Bucket: "deal", AccessKeyID: "key", SecretAccessKey: "secret", Endpoint: "s3.local"},
+	} {
+		if _, err := broker.NewS3BlobStore(config); err == nil {
+			t.Errorf("Expected an error for %+v, given nil", config)
+		}
+	}
+}
+
+func TestPostgresStorage(t *testing.T) {
+	t.Parallel()
+
+	postgresURL := os.Getenv(postgresURLVariable)
+	if postgresURL == "" {
+		t.Skipf("%s isn't set", postgresURLVariable)
+	}
+
+	db, err := sql.Open("postgres", postgresURL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if _, err = db.Exec("DROP TABLE IF EXISTS deal_broker_records"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	storage, err := broker.NewPostgresStorage(db)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	contractBroker, err := broker.NewBroker("", broker.WithStorage(storage))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	published := publishBrokerData(t, contractBroker)
+
+	reloadedBroker, err := broker.NewBroker("", broker.WithStorage(storage))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertBrokerData(t, reloadedBroker, published)
+
+	// Another instance of the broker conflicts with the contract published since it loaded the
+	// data, it succeeds once the data is reloaded
+	otherStorage, err := broker.NewPostgresStorage(db)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	otherBroker, err := broker.NewBroker("", broker.WithStorage(otherStorage))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishTestContract(t, reloadedBroker, "2", "mary")
+	_, err = otherBroker.PublishContract(broker.PublishedContract{
+		Consumer: "web", Provider: "users", Version: "3", Contract: newTestContract("jane"),
+	})
+	if !errors.Is(err, broker.ErrConflict) {
+		t.Fatalf("Given: %v, expected: %v", err, broker.ErrConflict)
+	}
+	publishTestContract(t, otherBroker, "3", "jane")
+	if _, err = otherBroker.Contract("web", "users", "2"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// failingStorage fails the saves of its storage with err once it's set
+type failingStorage struct {
+	broker.Storage
+	err error
+}
+
+func (s *failingStorage) Save(records []broker.Record) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	return s.Storage.Save(records)
+}
+
+func TestBrokerKeepsTheDataOfFailedSaves(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		change func(contractBroker *broker.Broker) error
+	}{
+		{
+			name: "should not publish the contract",
+			change: func(contractBroker *broker.Broker) error {
+				_, err := contractBroker.PublishContract(broker.PublishedContract{
+					Consumer: "web", Provider: "users", Version: "2", Contract: newTestContract("mary"),
+				})
+				return err
+			},
+		},
+		{
+			name: "should not publish the verification result",
+			change: func(contractBroker *broker.Broker) error {
+				_, err := contractBroker.PublishVerificationResult(broker.VerificationResult{
+					Consumer: "web", ConsumerVersion: "1", Provider: "users", ProviderVersion: "b",
+				})
+				return err
+			},
+		},
+		{
+			name: "should not tag the version",
+			change: func(contractBroker *broker.Broker) error {
+				_, err := contractBroker.TagVersion("web", "1", "prod")
+				return err
+			},
+		},
+		{
+			name: "should not record the deployment",
+			change: func(contractBroker *broker.Broker) error {
+				_, err := contractBroker.RecordDeployment("web", "2", "production")
+				return err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := &failingStorage{
+				Storage: broker.NewFileStorage(filepath.Join(t.TempDir(), "broker.json")),
+			}
+			contractBroker, err := broker.NewBroker("", broker.WithStorage(storage))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			published := publishBrokerData(t, contractBroker)
+
+			storage.err = errors.New("disk full")
+			if err = test.change(contractBroker); !errors.Is(err, storage.err) {
+				t.Fatalf("Given: %v, expected: %v", err, storage.err)
+			}
+
+			assertBrokerData(t, contractBroker, published)
+			versions := contractBroker.Versions("web")
+			if len(versions) != 2 || !reflect.DeepEqual(versions[0].Tags, []string{"main"}) {
+				t.Errorf("Given: %+v, expected: the version 1 tagged main then 0", versions)
+			}
+		})
+	}
+}
+
+func TestBrokerReloadsTheDataOnConflict(t *testing.T) {
+	t.Parallel()
+
+	dataPath := filepath.Join(t.TempDir(), "broker.json")
+	contractBroker, err := broker.NewBroker(dataPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	storage := &failingStorage{Storage: broker.NewFileStorage(dataPath)}
+	otherBroker, err := broker.NewBroker("", broker.WithStorage(storage))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	publishTestContract(t, contractBroker, "1", "john")
+	storage.err = fmt.Errorf("%w: the contract was published by another broker", broker.ErrConflict)
+	_, err = otherBroker.PublishContract(broker.PublishedContract{
+		Consumer: "web", Provider: "users", Version: "2", Contract: newTestContract("mary"),
+	})
+	if !errors.Is(err, broker.ErrConflict) {
+		t.Fatalf("Given: %v, expected: %v", err, broker.ErrConflict)
+	}
+	if _, err = otherBroker.Contract("web", "users", "1"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestBoltStorageWritesEachRecord(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "broker.db")
+	storage, err := broker.NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	contractBroker, err := broker.NewBroker("", broker.WithStorage(storage))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishBrokerData(t, contractBroker)
+	if err = storage.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	counts := make(map[string]int)
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			counts[string(name)] = bucket.Stats().KeyN
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]int{"versions": 2, "contracts": 1, "verifications": 1, "deployments": 2}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Given: %+v, expected: %+v", counts, expected)
+	}
+}
+
+// Another broker sharing the data file conflicts with the contract published since it loaded
+// the data, it succeeds once the data is reloaded
+func TestFileStorageDetectsConflicts(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "broker.json")
+	contractBroker, err := broker.NewBroker("", broker.WithStorage(broker.NewFileStorage(path)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	otherBroker, err := broker.NewBroker("", broker.WithStorage(broker.NewFileStorage(path)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	publishTestContract(t, contractBroker, "1", "john")
+	_, err = otherBroker.PublishContract(broker.PublishedContract{
+		Consumer: "web", Provider: "users", Version: "2", Contract: newTestContract("mary"),
+	})
+	if !errors.Is(err, broker.ErrConflict) {
+		t.Fatalf("Given: %v, expected: %v", err, broker.ErrConflict)
+	}
+
+	publishTestContract(t, otherBroker, "2", "mary")
+	for _, version := range []string{"1", "2"} {
+		if _, err = otherBroker.Contract("web", "users", version); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
+
+// The records saved without loading the data must not exist yet
+func TestBoltStorageDetectsConflicts(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "broker.db")
+	version := broker.Record{
+		Kind: broker.VersionRecord, Position: 0, Value: broker.Version{Pacticipant: "web", Number: "1"},
+	}
+
+	storage, err := broker.NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err = storage.Save([]broker.Record{version}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err = storage.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	otherStorage, err := broker.NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = otherStorage.Close() })
+
+	if err = otherStorage.Save([]broker.Record{version}); !errors.Is(err, broker.ErrConflict) {
+		t.Fatalf("Given: %v, expected: %v", err, broker.ErrConflict)
+	}
+	if _, err = otherStorage.Load(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err = otherStorage.Save([]broker.Record{version}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestBrokerKeepsLargeIntegers(t *testing.T) {
+	t.Parallel()
+
+	// 2^53 + 1 isn't a float64, it becomes 2^53 once decoded as one
+	const largeInteger = "9007199254740993"
+	directory := t.TempDir()
+	opts := []broker.BrokerOption{
+		broker.WithStorage(broker.NewFileStorage(filepath.Join(directory, "broker.json"))),
+		broker.WithContractBlobs(broker.NewDirectoryBlobStore(filepath.Join(directory, "contracts"))),
+	}
+
+	contractBroker, err := broker.NewBroker("", opts...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server := httptest.NewServer(broker.NewHTTPHandler(contractBroker))
+	t.Cleanup(server.Close)
+	body := `{"consumer": "web", "provider": "users", "version": "1", "contract": {"services": {` +
+		`"UserService": {"GetUser": {"successCases": [{` +
+		`"request": {"id": ` + largeInteger + `}, "response": {"name": "john"}}]}}}}}`
+	response, err := server.Client().Post(
+		server.URL+"/contracts", "application/json", strings.NewReader(body),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("Given: %d, expected: %d", response.StatusCode, http.StatusCreated)
+	}
+
+	reloadedBroker, err := broker.NewBroker("", opts...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reloadedServer := httptest.NewServer(broker.NewHTTPHandler(reloadedBroker))
+	t.Cleanup(reloadedServer.Close)
+	client, err := brokerclient.NewClient(reloadedServer.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	published, err := client.FetchContract(context.Background(), "web", "users", "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	request := published.Contract.Services["UserService"]["GetUser"].SuccessCases[0].Request
+	if id := fmt.Sprint(request.(map[string]interface{})["id"]); id != largeInteger {
+		t.Errorf("Given: %s, expected: %s", id, largeInteger)
+	}
+
+	grpcClient := newTestBrokerClient(t, reloadedBroker)
+	grpcResponse, err := grpcClient.GetContract(context.Background(), &brokerpb.GetContractRequest{
+		Consumer: "web", Provider: "users", Version: "1",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	contractJSON := grpcResponse.GetContract().GetContractJson()
+	if !strings.Contains(contractJSON, largeInteger) {
+		t.Errorf("Given: %s, expected: the integer %s", contractJSON, largeInteger)
+	}
+
+	_, err = grpcClient.PublishContract(context.Background(), &brokerpb.PublishContractRequest{
+		Contract: &brokerpb.PublishedContract{
+			Consumer: "web", Provider: "users", Version: "2", ContractJson: contractJSON,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	republished, err := reloadedBroker.Contract("web", "users", "2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if republished.Digest != published.Digest {
+		t.Errorf("Given: %s, expected: %s", republished.Digest, published.Digest)
+	}
+}
@@ -0,0 +1,209 @@
+package broker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+// Version is a version of a pacticipant, i.e. a consumer or a provider, along with its metadata.
+// The number identifies the version, e.g. a git SHA, the semver is only informative.
+type Version struct {
+	Pacticipant string   `json:"pacticipant"`
+	Number      string   `json:"number"`
+	Branch      string   `json:"branch,omitempty"`
+	Commit      string   `json:"commit,omitempty"`
+	Semver      string   `json:"semver,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// CreatedAt is set by the broker when the version is first seen
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Selector selects the versions with the tag and on the branch, the empty fields select
+// every version
+type Selector struct {
+	Tag    string
+	Branch string
+}
+
+func (s Selector) matches(version Version) bool {
+	return (s.Tag == "" || contains(version.Tags, s.Tag)) && matches(s.Branch, version.Branch)
+}
+
+// String describes the selector the way the errors mention it
+func (s Selector) String() string {
+	description := ""
+	if s.Tag != "" {
+		description += " tagged " + s.Tag
+	}
+	if s.Branch != "" {
+		description += " on branch " + s.Branch
+	}
+
+	return description
+}
+
+// TagVersion adds the tag to the version of the pacticipant, the version is created when the
+// broker doesn't know it yet
+func (b *Broker) TagVersion(pacticipant, number, tag string) (Version, error) {
+	if pacticipant == "" || number == "" || tag == "" {
+		return Version{}, fmt.Errorf(
+			"%w: the pacticipant, the version and the tag are required", ErrInvalid,
+		)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	version, record := b.mergeVersion(
+		Version{Pacticipant: pacticipant, Number: number, Tags: []string{tag}}, time.Now().UTC(),
+	)
+	if err := b.save(record); err != nil {
+		return Version{}, err
+	}
+
+	return version, nil
+}
+
+// Version returns the version of the pacticipant
+func (b *Broker) Version(pacticipant, number string) (Version, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	version, found := b.findVersion(pacticipant, number)
+	if !found {
+		return Version{}, fmt.Errorf("%w: no version %s of %s", ErrNotFound, number, pacticipant)
+	}
+
+	return version, nil
+}
+
+// Versions returns the versions of the pacticipant, from the oldest to the latest
+func (b *Broker) Versions(pacticipant string) []Version {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	versions := []Version{}
+	for _, version := range b.data.Versions {
+		if version.Pacticipant == pacticipant {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions
+}
+
+// LatestVersion returns the latest version of the pacticipant selected by the selector
+func (b *Broker) LatestVersion(pacticipant string, selector Selector) (Version, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for index := len(b.data.Versions) - 1; index >= 0; index-- {
+		version := b.data.Versions[index]
+		if version.Pacticipant == pacticipant && selector.matches(version) {
+			return version, nil
+		}
+	}
+
+	return Version{}, fmt.Errorf("%w: no version of %s%s", ErrNotFound, pacticipant, selector)
+}
+
+func (b *Broker) findVersion(pacticipant, number string) (Version, bool) {
+	for _, version := range b.data.Versions {
+		if version.Pacticipant == pacticipant && version.Number == number {
+			return version, true
+		}
+	}
+
+	return Version{}, false
+}
+
+// mergeVersion returns the version to store along with its record, the metadata of a known
+// version is merged: its tags are added and its other fields are replaced when they're given
+func (b *Broker) mergeVersion(version Version, createdAt time.Time) (Version, Record) {
+	for index, existing := range b.data.Versions {
+		if existing.Pacticipant != version.Pacticipant || existing.Number != version.Number {
+			continue
+		}
+
+		existing.Tags = appendTags(existing.Tags, version.Tags...)
+		for _, field := range []struct{ existing, given *string }{
+			{&existing.Branch, &version.Branch},
+			{&existing.Commit, &version.Commit},
+			{&existing.Semver, &version.Semver},
+		} {
+			if *field.given != "" {
+				*field.existing = *field.given
+			}
+		}
+
+		return existing, Record{Kind: VersionRecord, Position: index, Value: existing}
+	}
+
+	version.Tags = appendTags(nil, version.Tags...)
+	version.CreatedAt = createdAt
+
+	return version, Record{Kind: VersionRecord, Position: len(b.data.Versions), Value: version}
+}
+
+// validateVersion checks the metadata of a version before it's stored
+func validateVersion(version Version) error {
+	if version.Semver == "" {
+		return nil
+	}
+	if _, err := processors.ParseVersion(version.Semver); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	return nil
+}
+
+// versionOf returns the consumer version of the contract
+func versionOf(published PublishedContract) Version {
+	return Version{
+		Pacticipant: published.Consumer,
+		Number:      published.Version,
+		Branch:      published.Branch,
+		Commit:      published.Commit,
+		Semver:      published.Semver,
+		Tags:        published.Tags,
+	}
+}
+
+// withVersion fills the contract with the metadata of its consumer version
+func withVersion(published PublishedContract, version Version) PublishedContract {
+	published.Branch = version.Branch
+	published.Commit = version.Commit
+	published.Semver = version.Semver
+	published.Tags = version.Tags
+
+	return published
+}
+
+// withoutVersion clears the metadata of the consumer version from the contract
+func withoutVersion(published PublishedContract) PublishedContract {
+	return withVersion(published, Version{})
+}
+
+// appendTags adds the tags missing from the list
+func appendTags(tags []string, newTags ...string) []string {
+	merged := append([]string{}, tags...)
+	for _, tag := range newTags {
+		if tag != "" && !contains(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+
+	return merged
+}
+
+func contains(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,161 @@
+package broker_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/broker"
+)
+
+func TestBrokerVersions(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	published, err := contractBroker.PublishContract(broker.PublishedContract{
+		Consumer: "web", Provider: "users", Version: "1", Branch: "main", Commit: "8a3f2c1",
+		Semver: "1.2.0", Tags: []string{"main"}, Contract: newTestContract("john"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	publishTestContract(t, contractBroker, "2", "mary")
+
+	tagged, err := contractBroker.TagVersion("web", "1", "prod")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := broker.Version{
+		Pacticipant: "web", Number: "1", Branch: "main", Commit: "8a3f2c1", Semver: "1.2.0",
+		Tags: []string{"main", "prod"}, CreatedAt: published.PublishedAt,
+	}
+	if !reflect.DeepEqual(tagged, expected) {
+		t.Errorf("Given: %+v, expected: %+v", tagged, expected)
+	}
+
+	// The tags of the version are the tags of its contracts
+	fetched, err := contractBroker.Contract("web", "users", "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(fetched.Tags, expected.Tags) || fetched.Commit != expected.Commit {
+		t.Errorf("Given: %+v, expected: the metadata of %+v", fetched, expected)
+	}
+
+	// A provider version is created by its first tag
+	if _, err = contractBroker.TagVersion("users", "a", "prod"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		pacticipant    string
+		selector       broker.Selector
+		expectedNumber string
+		expectedError  error
+	}{
+		{
+			name:           "should return the latest version",
+			pacticipant:    "web",
+			expectedNumber: "2",
+		},
+		{
+			name:           "should return the latest version with the tag",
+			pacticipant:    "web",
+			selector:       broker.Selector{Tag: "prod"},
+			expectedNumber: "1",
+		},
+		{
+			name:           "should return the latest version on the branch",
+			pacticipant:    "web",
+			selector:       broker.Selector{Branch: "main"},
+			expectedNumber: "1",
+		},
+		{
+			name:           "should return the tagged provider version",
+			pacticipant:    "users",
+			selector:       broker.Selector{Tag: "prod"},
+			expectedNumber: "a",
+		},
+		{
+			name:          "should fail when no version is on the branch",
+			pacticipant:   "web",
+			selector:      broker.Selector{Branch: "feature"},
+			expectedError: broker.ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			version, err := contractBroker.LatestVersion(test.pacticipant, test.selector)
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("Given: %v, expected: %v", err, test.expectedError)
+			}
+			if version.Number != test.expectedNumber {
+				t.Errorf("Given: %s, expected: %s", version.Number, test.expectedNumber)
+			}
+		})
+	}
+
+	if versions := contractBroker.Versions("web"); len(versions) != 2 {
+		t.Errorf("Given: %+v, expected: the versions 1 and 2", versions)
+	}
+	if _, err = contractBroker.Version("web", "3"); !errors.Is(err, broker.ErrNotFound) {
+		t.Errorf("Given: %v, expected: %v", err, broker.ErrNotFound)
+	}
+}
+
+func TestBrokerRejectsInvalidVersions(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = contractBroker.PublishContract(broker.PublishedContract{
+		Consumer: "web", Provider: "users", Version: "1", Semver: "latest",
+		Contract: newTestContract("john"),
+	})
+	if !errors.Is(err, broker.ErrInvalid) {
+		t.Errorf("Given: %v, expected: %v", err, broker.ErrInvalid)
+	}
+
+	if _, err = contractBroker.TagVersion("web", "1", ""); !errors.Is(err, broker.ErrInvalid) {
+		t.Errorf("Given: %v, expected: %v", err, broker.ErrInvalid)
+	}
+}
+
+func TestNewBrokerMigratesTheContractTags(t *testing.T) {
+	t.Parallel()
+
+	dataPath := filepath.Join(t.TempDir(), "broker.json")
+	data := []byte(`{"contracts": [{
+		"consumer": "web", "provider": "users", "version": "1", "branch": "main",
+		"tags": ["prod"], "contract": {}, "publishedAt": "2022-01-02T03:04:05Z", "digest": "abc"
+	}]}`)
+	if err := ioutil.WriteFile(dataPath, data, 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	contractBroker, err := broker.NewBroker(dataPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	version, err := contractBroker.LatestVersion("web", broker.Selector{Tag: "prod"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if version.Number != "1" || version.Branch != "main" {
+		t.Errorf("Given: %+v, expected: the version 1 on main", version)
+	}
+}
@@ -0,0 +1,167 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultWebhookTimeout bounds the webhook calls of the brokers without their own HTTP client
+const DefaultWebhookTimeout = 10 * time.Second
+
+// ContractChangedEvent is sent when a contract is published for the first time between a consumer
+// and a provider, or when its content differs from the previous contract between them
+const ContractChangedEvent = "contractChanged"
+
+// Webhook is an HTTP endpoint called by the broker when a contract changes, e.g. to trigger the
+// pipeline of the provider verifying it
+type Webhook struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// Consumer and Provider restrict the contracts triggering the webhook, the empty fields
+	// match every contract
+	Consumer string            `yaml:"consumer"`
+	Provider string            `yaml:"provider"`
+	Headers  map[string]string `yaml:"headers"`
+	// Body is the text/template of the payload, executed with the WebhookEvent. The json
+	// function encodes a value, the payload is the event as JSON by default.
+	Body string `yaml:"body"`
+}
+
+// WebhookEvent describes the published contract to the webhooks
+type WebhookEvent struct {
+	Event       string    `json:"event"`
+	Consumer    string    `json:"consumer"`
+	Provider    string    `json:"provider"`
+	Version     string    `json:"version"`
+	Branch      string    `json:"branch,omitempty"`
+	Commit      string    `json:"commit,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Digest      string    `json:"digest"`
+	PublishedAt time.Time `json:"publishedAt"`
+	// PreviousDigest is the digest of the previous contract between the consumer and the
+	// provider, it's empty for their first contract
+	PreviousDigest string `json:"previousDigest,omitempty"`
+}
+
+// ReadWebhooks reads the webhooks of a YAML file, listed by its webhooks field.
+// The unknown fields are rejected.
+func ReadWebhooks(path string) ([]Webhook, error) {
+	file := struct {
+		Webhooks []Webhook `yaml:"webhooks"`
+	}{}
+	if err := readYAMLFile(path, "webhooks", &file); err != nil {
+		return nil, err
+	}
+
+	return file.Webhooks, nil
+}
+
+// webhookTemplate is a webhook along with its parsed body
+type webhookTemplate struct {
+	Webhook
+	body *template.Template
+}
+
+// webhookFuncs are the functions of the body templates
+var webhookFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+}
+
+// parseWebhook validates the webhook and parses its body
+func parseWebhook(webhook Webhook) (webhookTemplate, error) {
+	endpoint, err := url.Parse(webhook.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return webhookTemplate{}, fmt.Errorf(
+			"invalid webhook %s: an http or https URL is expected, given %q", webhook.Name, webhook.URL,
+		)
+	}
+
+	body := webhook.Body
+	if body == "" {
+		body = "{{json .}}"
+	}
+	parsed, err := template.New(webhook.Name).Funcs(webhookFuncs).Parse(body)
+	if err != nil {
+		return webhookTemplate{}, fmt.Errorf("invalid webhook %s: %w", webhook.Name, err)
+	}
+
+	return webhookTemplate{Webhook: webhook, body: parsed}, nil
+}
+
+// notifyContractChanged calls the webhooks of the contract in the background, it's called with
+// the lock of the broker held
+func (b *Broker) notifyContractChanged(published PublishedContract, previousDigest string) {
+	event := WebhookEvent{
+		Event:          ContractChangedEvent,
+		Consumer:       published.Consumer,
+		Provider:       published.Provider,
+		Version:        published.Version,
+		Branch:         published.Branch,
+		Commit:         published.Commit,
+		Tags:           published.Tags,
+		Digest:         published.Digest,
+		PublishedAt:    published.PublishedAt,
+		PreviousDigest: previousDigest,
+	}
+
+	for _, webhook := range b.webhooks {
+		if !matches(webhook.Consumer, event.Consumer) || !matches(webhook.Provider, event.Provider) {
+			continue
+		}
+
+		b.deliveries.Add(1)
+		go func(webhook webhookTemplate) {
+			defer b.deliveries.Done()
+
+			if err := b.deliver(webhook, event); err != nil && b.config.WebhookErrorHandler != nil {
+				b.config.WebhookErrorHandler(webhook.Webhook, err)
+			}
+		}(webhook)
+	}
+}
+
+// deliver posts the payload of the event to the webhook, the responses other than 2xx fail.
+// The errors are reported along with the webhook, they don't repeat its name.
+func (b *Broker) deliver(webhook webhookTemplate, event WebhookEvent) error {
+	payload := strings.Builder{}
+	if err := webhook.body.Execute(&payload, event); err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, strings.NewReader(payload.String()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range webhook.Headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := b.config.WebhookClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("responded %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	return nil
+}
+
+// Wait blocks until the webhooks being called are done, e.g. before the process exits
+func (b *Broker) Wait() {
+	b.deliveries.Wait()
+}
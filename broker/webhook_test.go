@@ -0,0 +1,197 @@
+package broker_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/faunists/deal-go/broker"
+)
+
+// webhookRecorder is a webhook endpoint keeping the requests it receives
+type webhookRecorder struct {
+	mutex    sync.Mutex
+	requests []*http.Request
+	bodies   []string
+}
+
+func (r *webhookRecorder) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	body, _ := ioutil.ReadAll(request.Body)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.requests = append(r.requests, request)
+	r.bodies = append(r.bodies, string(body))
+
+	if request.URL.Path == "/failing" {
+		writer.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func TestBrokerWebhooks(t *testing.T) {
+	t.Parallel()
+
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+
+	contractBroker, err := broker.NewBroker("", broker.WithWebhooks(
+		broker.Webhook{Name: "users", URL: server.URL + "/users", Provider: "users"},
+		broker.Webhook{Name: "mobile", URL: server.URL + "/mobile", Consumer: "mobile"},
+	))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	first := publishTestContract(t, contractBroker, "1", "john")
+	publishTestContract(t, contractBroker, "1", "john", "prod")
+	publishTestContract(t, contractBroker, "2", "john")
+	changed := publishTestContract(t, contractBroker, "3", "mary")
+	contractBroker.Wait()
+
+	// Only the new contents between web and users trigger the webhook
+	if len(recorder.bodies) != 2 {
+		t.Fatalf("Given: %v, expected: the events of the versions 1 and 3", recorder.bodies)
+	}
+
+	events := make([]broker.WebhookEvent, 0, len(recorder.bodies))
+	for index, body := range recorder.bodies {
+		if recorder.requests[index].URL.Path != "/users" {
+			t.Errorf("Given: %s, expected: %s", recorder.requests[index].URL.Path, "/users")
+		}
+
+		event := broker.WebhookEvent{}
+		if err = json.Unmarshal([]byte(body), &event); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		events = append(events, event)
+	}
+	// The webhooks are called in the background, in any order
+	sort.Slice(events, func(i, j int) bool { return events[i].Version < events[j].Version })
+	if events[0].Version != "1" || events[0].PreviousDigest != "" {
+		t.Errorf("Given: %+v, expected: the first contract of web", events[0])
+	}
+	if events[1].Event != broker.ContractChangedEvent || events[1].Digest != changed.Digest ||
+		events[1].PreviousDigest != first.Digest {
+		t.Errorf("Given: %+v, expected: the change of the contract of web", events[1])
+	}
+}
+
+func TestBrokerWebhookTemplates(t *testing.T) {
+	t.Parallel()
+
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+
+	failures := make([]string, 0)
+	contractBroker, err := broker.NewBroker(
+		"",
+		broker.WithWebhooks(
+			broker.Webhook{
+				Name:    "pipeline",
+				URL:     server.URL + "/pipeline",
+				Headers: map[string]string{"Authorization": "Bearer token"},
+				Body:    `{"ref": "main", "variables": {"CONSUMER_VERSION": {{json .Version}}}}`,
+			},
+			broker.Webhook{Name: "failing", URL: server.URL + "/failing"},
+		),
+		broker.WithWebhookErrorHandler(func(webhook broker.Webhook, err error) {
+			failures = append(failures, webhook.Name)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	publishTestContract(t, contractBroker, `"1"`, "john")
+	contractBroker.Wait()
+
+	for index, request := range recorder.requests {
+		if request.URL.Path != "/pipeline" {
+			continue
+		}
+
+		expected := `{"ref": "main", "variables": {"CONSUMER_VERSION": "\"1\""}}`
+		if recorder.bodies[index] != expected {
+			t.Errorf("Given: %s, expected: %s", recorder.bodies[index], expected)
+		}
+		if request.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Given: %s, expected: %s", request.Header.Get("Authorization"), "Bearer token")
+		}
+	}
+	if !reflect.DeepEqual(failures, []string{"failing"}) {
+		t.Errorf("Given: %v, expected: %v", failures, []string{"failing"})
+	}
+}
+
+func TestNewBrokerRejectsInvalidWebhooks(t *testing.T) {
+	t.Parallel()
+
+	for _, webhook := range []broker.Webhook{
+		{Name: "relative", URL: "/hooks"},
+		{Name: "template", URL: "https://ci.example.com", Body: "{{.Version"},
+	} {
+		if _, err := broker.NewBroker("", broker.WithWebhooks(webhook)); err == nil {
+			t.Errorf("Expected an error for %+v, given nil", webhook)
+		}
+	}
+}
+
+func TestReadWebhooks(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+	tests := []struct {
+		name             string
+		content          string
+		expectedWebhooks []broker.Webhook
+		expectedError    bool
+	}{
+		{
+			name: "should read the webhooks",
+			content: "webhooks:\n" +
+				"  - name: users\n" +
+				"    url: https://ci.example.com/users\n" +
+				"    provider: users\n" +
+				"    headers: {Authorization: Bearer token}\n",
+			expectedWebhooks: []broker.Webhook{{
+				Name:     "users",
+				URL:      "https://ci.example.com/users",
+				Provider: "users",
+				Headers:  map[string]string{"Authorization": "Bearer token"},
+			}},
+		},
+		{
+			name:          "should reject the unknown fields",
+			content:       "webhooks:\n  - name: users\n    method: PUT\n",
+			expectedError: true,
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+		path := filepath.Join(directory, string(rune('a'+index))+".yaml")
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := ioutil.WriteFile(path, []byte(test.content), 0o600); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			webhooks, err := broker.ReadWebhooks(path)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(webhooks, test.expectedWebhooks) {
+				t.Errorf("Given: %+v, expected: %+v", webhooks, test.expectedWebhooks)
+			}
+		})
+	}
+}
@@ -0,0 +1,553 @@
+// Package brokerclient talks to a contract broker through its HTTP API, e.g. to publish the
+// contracts of a consumer or the verification results of a provider from its tooling.
+package brokerclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// DefaultTimeout bounds the requests of the clients without their own HTTP client
+const DefaultTimeout = 30 * time.Second
+
+// The kinds of the broker failures, the errors of the failed responses match them with errors.Is
+var (
+	ErrInvalid         = errors.New("invalid request")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+)
+
+// PublishedContract is a contract expected by a consumer version from a provider.
+// Branch, Commit, Semver and Tags describe the consumer version.
+type PublishedContract struct {
+	Consumer string            `json:"consumer"`
+	Provider string            `json:"provider"`
+	Version  string            `json:"version"`
+	Branch   string            `json:"branch,omitempty"`
+	Commit   string            `json:"commit,omitempty"`
+	Semver   string            `json:"semver,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Contract entities.Contract `json:"contract"`
+	// PublishedAt and Digest are set by the broker, the digest identifies the contract content
+	PublishedAt time.Time `json:"publishedAt"`
+	Digest      string    `json:"digest,omitempty"`
+	// Pending is set by the broker while no version of the provider verified the contract content
+	// successfully
+	Pending bool `json:"pending,omitempty"`
+}
+
+// Version is a version of a consumer or a provider, along with its metadata
+type Version struct {
+	Pacticipant string   `json:"pacticipant"`
+	Number      string   `json:"number"`
+	Branch      string   `json:"branch,omitempty"`
+	Commit      string   `json:"commit,omitempty"`
+	Semver      string   `json:"semver,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// CreatedAt is set by the broker
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Selector selects the versions with the tag and on the branch, the empty fields select
+// every version
+type Selector struct {
+	Tag    string
+	Branch string
+}
+
+// DeployedVersion records that a version of a consumer or a provider is deployed to an
+// environment
+type DeployedVersion struct {
+	Pacticipant string `json:"pacticipant"`
+	Version     string `json:"version"`
+	Environment string `json:"environment"`
+	// DeployedAt and UndeployedAt are set by the broker, the version is undeployed once another
+	// version is deployed to the environment
+	DeployedAt   time.Time  `json:"deployedAt"`
+	UndeployedAt *time.Time `json:"undeployedAt,omitempty"`
+}
+
+// VerificationResult is the outcome of the verification of a contract by a provider version,
+// optionally along with the outcome of each case of the contract
+type VerificationResult struct {
+	Consumer        string       `json:"consumer"`
+	ConsumerVersion string       `json:"consumerVersion"`
+	Provider        string       `json:"provider"`
+	ProviderVersion string       `json:"providerVersion"`
+	Success         bool         `json:"success"`
+	Cases           []CaseResult `json:"cases,omitempty"`
+	// VerifiedAt is set by the broker
+	VerifiedAt time.Time `json:"verifiedAt"`
+}
+
+// CaseResult is the outcome of a case of a verified contract, e.g. a generated test
+type CaseResult struct {
+	Name     string        `json:"name"`
+	Success  bool          `json:"success"`
+	Duration time.Duration `json:"duration"`
+}
+
+// MatrixQuery filters the matrix, the empty fields match every value.
+// A missing version is the one deployed to the environment when it's given.
+type MatrixQuery struct {
+	Consumer        string
+	ConsumerVersion string
+	Provider        string
+	ProviderVersion string
+	Environment     string
+	// Limit keeps the rows of the latest contracts matching the query, zero keeps every row
+	Limit int
+}
+
+// MatrixRow is a consumer version and a provider version, along with the latest verification
+// of the contract of the consumer version by the provider version
+type MatrixRow struct {
+	Consumer        string `json:"consumer"`
+	ConsumerVersion string `json:"consumerVersion"`
+	Provider        string `json:"provider"`
+	// ProviderVersion is empty when no provider version verified the contract
+	ProviderVersion string `json:"providerVersion,omitempty"`
+	// Success is nil when the provider version didn't verify the contract
+	Success    *bool      `json:"success,omitempty"`
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty"`
+}
+
+// Deployment tells whether the versions of a matrix query can be deployed together
+type Deployment struct {
+	Deployable bool        `json:"deployable"`
+	Reason     string      `json:"reason"`
+	Rows       []MatrixRow `json:"rows"`
+}
+
+// Dependency is an edge of the dependency graph: the consumer calls the methods of the provider
+// expected by its latest contract
+type Dependency struct {
+	Consumer        string    `json:"consumer"`
+	Provider        string    `json:"provider"`
+	ConsumerVersion string    `json:"consumerVersion"`
+	PublishedAt     time.Time `json:"publishedAt"`
+	// Methods are the methods of the contract, e.g. UserService/GetUser
+	Methods []string `json:"methods"`
+	// Pending is set while no version of the provider verified the contract successfully
+	Pending bool `json:"pending,omitempty"`
+}
+
+// Graph is the graph of the consumers and the providers, derived from the published contracts
+type Graph struct {
+	Pacticipants []string     `json:"pacticipants"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// Error is a failed response of the broker
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf(
+		"broker responded %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message,
+	)
+}
+
+// Is matches the kind of the failure
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrInvalid:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnauthenticated:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	default:
+		return false
+	}
+}
+
+// ClientConfig holds the settings of a client
+type ClientConfig struct {
+	HTTPClient *http.Client
+	// Token is sent as the bearer token of the requests, see broker.Token
+	Token string
+}
+
+// ClientOption configures a client
+type ClientOption func(*ClientConfig)
+
+// WithHTTPClient sends the requests through the HTTP client, e.g. to customize its transport
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(config *ClientConfig) {
+		config.HTTPClient = httpClient
+	}
+}
+
+// WithToken authenticates the requests with the token of the broker
+func WithToken(token string) ClientOption {
+	return func(config *ClientConfig) {
+		config.Token = token
+	}
+}
+
+// Client sends the requests to a broker
+type Client struct {
+	baseURL *url.URL
+	config  ClientConfig
+}
+
+// NewClient returns a client of the broker located at the URL
+func NewClient(brokerURL string, opts ...ClientOption) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(brokerURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL: %w", err)
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid broker URL %q: an http or https URL is expected", brokerURL)
+	}
+
+	config := ClientConfig{HTTPClient: &http.Client{Timeout: DefaultTimeout}}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return &Client{baseURL: baseURL, config: config}, nil
+}
+
+// PublishContract publishes the contract of the consumer version
+func (c *Client) PublishContract(
+	ctx context.Context,
+	published PublishedContract,
+) (PublishedContract, error) {
+	result := PublishedContract{}
+	err := c.do(ctx, http.MethodPost, []string{"contracts"}, nil, published, &result)
+
+	return result, err
+}
+
+// FetchContract returns the contract of the consumer version with the provider
+func (c *Client) FetchContract(
+	ctx context.Context,
+	consumer, provider, version string,
+) (PublishedContract, error) {
+	result := PublishedContract{}
+	err := c.do(
+		ctx, http.MethodGet,
+		[]string{"contracts", "provider", provider, "consumer", consumer, "version", version},
+		nil, nil, &result,
+	)
+
+	return result, err
+}
+
+// FetchLatestContract returns the latest contract of the consumer with the provider, among the
+// consumer versions selected by the selector
+func (c *Client) FetchLatestContract(
+	ctx context.Context,
+	consumer, provider string,
+	selector Selector,
+) (PublishedContract, error) {
+	path, query := selector.path(
+		"contracts", "provider", provider, "consumer", consumer, "latest",
+	)
+
+	result := PublishedContract{}
+	err := c.do(ctx, http.MethodGet, path, query, nil, &result)
+
+	return result, err
+}
+
+// FetchLatestContracts returns the latest contract of every consumer of the provider, among the
+// consumer versions selected by the selector
+func (c *Client) FetchLatestContracts(
+	ctx context.Context,
+	provider string,
+	selector Selector,
+) ([]PublishedContract, error) {
+	path, query := selector.path("contracts", "provider", provider, "latest")
+
+	result := struct {
+		Contracts []PublishedContract `json:"contracts"`
+	}{}
+	err := c.do(ctx, http.MethodGet, path, query, nil, &result)
+
+	return result.Contracts, err
+}
+
+// FetchVersion returns the version of the consumer or provider
+func (c *Client) FetchVersion(ctx context.Context, pacticipant, number string) (Version, error) {
+	result := Version{}
+	err := c.do(
+		ctx, http.MethodGet, []string{"pacticipants", pacticipant, "versions", number}, nil, nil,
+		&result,
+	)
+
+	return result, err
+}
+
+// FetchLatestVersion returns the latest version of the consumer or provider selected by the
+// selector
+func (c *Client) FetchLatestVersion(
+	ctx context.Context,
+	pacticipant string,
+	selector Selector,
+) (Version, error) {
+	path, query := selector.path("pacticipants", pacticipant, "versions", "latest")
+
+	result := Version{}
+	err := c.do(ctx, http.MethodGet, path, query, nil, &result)
+
+	return result, err
+}
+
+// TagVersion adds the tag to the version of the consumer or provider, e.g. prod once it's
+// deployed, the broker creates the version when it doesn't know it yet
+func (c *Client) TagVersion(ctx context.Context, pacticipant, number, tag string) (Version, error) {
+	result := Version{}
+	err := c.do(
+		ctx, http.MethodPut, []string{"pacticipants", pacticipant, "versions", number, "tags", tag},
+		nil, nil, &result,
+	)
+
+	return result, err
+}
+
+// RecordDeployment records the deployment of the version of the consumer or provider to the
+// environment, replacing the version deployed there before
+func (c *Client) RecordDeployment(
+	ctx context.Context,
+	pacticipant, version, environment string,
+) (DeployedVersion, error) {
+	deployment := DeployedVersion{
+		Pacticipant: pacticipant,
+		Version:     version,
+		Environment: environment,
+	}
+
+	result := DeployedVersion{}
+	err := c.do(ctx, http.MethodPost, []string{"deployments"}, nil, deployment, &result)
+
+	return result, err
+}
+
+// DeployedVersions returns the versions currently deployed to the environment
+func (c *Client) DeployedVersions(
+	ctx context.Context,
+	environment string,
+) ([]DeployedVersion, error) {
+	result := struct {
+		Deployments []DeployedVersion `json:"deployments"`
+	}{}
+	err := c.do(
+		ctx, http.MethodGet, []string{"environments", environment, "deployments"}, nil, nil, &result,
+	)
+
+	return result.Deployments, err
+}
+
+// PublishVerificationResult publishes the result of the verification of the contract of the
+// consumer version by the provider version
+func (c *Client) PublishVerificationResult(
+	ctx context.Context,
+	result VerificationResult,
+) (VerificationResult, error) {
+	published := VerificationResult{}
+	err := c.do(ctx, http.MethodPost, []string{"verifications"}, nil, result, &published)
+
+	return published, err
+}
+
+// Matrix returns the consumer and provider versions matching the query, along with their
+// verification
+func (c *Client) Matrix(ctx context.Context, query MatrixQuery) ([]MatrixRow, error) {
+	values := url.Values{}
+	for name, value := range map[string]string{
+		"consumer":        query.Consumer,
+		"consumerVersion": query.ConsumerVersion,
+		"provider":        query.Provider,
+		"providerVersion": query.ProviderVersion,
+		"environment":     query.Environment,
+	} {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+	if query.Limit != 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+
+	result := struct {
+		Rows []MatrixRow `json:"rows"`
+	}{}
+	err := c.do(ctx, http.MethodGet, []string{"matrix"}, values, nil, &result)
+
+	return result.Rows, err
+}
+
+// Graph returns the dependency graph of the latest contracts, among the consumer versions
+// selected by the selector
+func (c *Client) Graph(ctx context.Context, selector Selector) (Graph, error) {
+	values := url.Values{}
+	if selector.Tag != "" {
+		values.Set("tag", selector.Tag)
+	}
+	if selector.Branch != "" {
+		values.Set("branch", selector.Branch)
+	}
+
+	result := Graph{}
+	err := c.do(ctx, http.MethodGet, []string{"graph"}, values, nil, &result)
+
+	return result, err
+}
+
+// CanIDeploy allows the deployment when every row of the matrix of the query was successfully
+// verified, a matrix without rows means the versions were never published or deployed
+func (c *Client) CanIDeploy(ctx context.Context, query MatrixQuery) (Deployment, error) {
+	rows, err := c.Matrix(ctx, query)
+	if err != nil {
+		return Deployment{}, err
+	}
+
+	deployment := Deployment{Rows: rows}
+	if len(rows) == 0 && query.Environment != "" &&
+		(query.ConsumerVersion == "" || query.ProviderVersion == "") {
+		deployment.Reason = fmt.Sprintf(
+			"no contract found between the versions, or no version is deployed to %s",
+			query.Environment,
+		)
+		return deployment, nil
+	}
+	if len(rows) == 0 {
+		deployment.Reason = "no contract found between the versions"
+		return deployment, nil
+	}
+
+	for _, row := range rows {
+		pair := fmt.Sprintf(
+			"%s %s and %s %s", row.Consumer, row.ConsumerVersion, row.Provider, row.ProviderVersion,
+		)
+		switch {
+		case row.ProviderVersion == "":
+			deployment.Reason = fmt.Sprintf(
+				"no version of %s verified the contract of %s %s",
+				row.Provider, row.Consumer, row.ConsumerVersion,
+			)
+			return deployment, nil
+		case row.Success == nil:
+			deployment.Reason = fmt.Sprintf("the contract between %s is not verified", pair)
+			return deployment, nil
+		case !*row.Success:
+			deployment.Reason = fmt.Sprintf("the verification of the contract between %s failed", pair)
+			return deployment, nil
+		}
+	}
+
+	deployment.Deployable = true
+	deployment.Reason = "every contract between the versions is verified"
+
+	return deployment, nil
+}
+
+// path returns the path segments of a latest endpoint along with the query of the selector,
+// the tag is the last segment
+func (s Selector) path(segments ...string) ([]string, url.Values) {
+	if s.Tag != "" {
+		segments = append(segments, s.Tag)
+	}
+
+	query := url.Values{}
+	if s.Branch != "" {
+		query.Set("branch", s.Branch)
+	}
+
+	return segments, query
+}
+
+// do sends the body as JSON to the endpoint of the path segments, the JSON response is
+// decoded into the result
+func (c *Client) do(
+	ctx context.Context,
+	method string,
+	path []string,
+	query url.Values,
+	body, result interface{},
+) error {
+	// The names are escaped, they may contain slashes
+	endpoint := *c.baseURL
+	escapedPath := c.baseURL.EscapedPath()
+	for _, segment := range path {
+		endpoint.Path += "/" + segment
+		escapedPath += "/" + url.PathEscape(segment)
+	}
+	endpoint.RawPath = escapedPath
+	endpoint.RawQuery = query.Encode()
+
+	var requestBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, endpoint.String(), requestBody)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if c.config.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.config.HTTPClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("broker unavailable: %w", err)
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("broker unavailable: %w", err)
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		failure := struct {
+			Error string `json:"error"`
+		}{}
+		if json.Unmarshal(data, &failure) != nil || failure.Error == "" {
+			failure.Error = strings.TrimSpace(string(data))
+		}
+		return &Error{StatusCode: response.StatusCode, Message: failure.Error}
+	}
+
+	// The numbers of the contracts are kept as written, a float64 can't represent every 64-bit
+	// integer
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(result); err != nil {
+		return fmt.Errorf("invalid broker response: %w", err)
+	}
+
+	return nil
+}
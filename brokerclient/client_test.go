@@ -0,0 +1,388 @@
+package brokerclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/faunists/deal-go/broker"
+	"github.com/faunists/deal-go/brokerclient"
+	"github.com/faunists/deal-go/entities"
+)
+
+// newTestClient returns a client of an empty in-memory broker
+func newTestClient(t *testing.T) *brokerclient.Client {
+	t.Helper()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server := httptest.NewServer(broker.NewHTTPHandler(contractBroker))
+	t.Cleanup(server.Close)
+
+	client, err := brokerclient.NewClient(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return client
+}
+
+func newTestContract(name string) entities.Contract {
+	return entities.Contract{Services: map[string]entities.Service{"UserService": {
+		"GetUser": {SuccessCases: []entities.SuccessCase{{
+			Request:  map[string]interface{}{"id": "1"},
+			Response: map[string]interface{}{"name": name},
+		}}},
+	}}}
+}
+
+func TestClientContracts(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	for _, published := range []brokerclient.PublishedContract{
+		{Consumer: "web/app", Version: "1", Tags: []string{"prod"}, Contract: newTestContract("john")},
+		{Consumer: "web/app", Version: "2", Contract: newTestContract("mary")},
+	} {
+		published.Provider = "users"
+		result, err := client.PublishContract(ctx, published)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Digest == "" || result.PublishedAt.IsZero() || !result.Pending {
+			t.Errorf("Given: %+v, expected: a pending contract with a digest and a publication time", result)
+		}
+	}
+
+	fetched, err := client.FetchContract(ctx, "web/app", "users", "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fetched.Version != "1" {
+		t.Errorf("Given: %s, expected: %s", fetched.Version, "1")
+	}
+
+	latest, err := client.FetchLatestContract(ctx, "web/app", "users", brokerclient.Selector{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if latest.Version != "2" {
+		t.Errorf("Given: %s, expected: %s", latest.Version, "2")
+	}
+
+	latestContracts, err := client.FetchLatestContracts(
+		ctx, "users", brokerclient.Selector{Tag: "prod"},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(latestContracts) != 1 || latestContracts[0].Version != "1" {
+		t.Errorf("Given: %+v, expected: the version 1 of web/app", latestContracts)
+	}
+
+	_, err = client.PublishContract(ctx, brokerclient.PublishedContract{
+		Consumer: "web/app", Provider: "users", Version: "1", Contract: newTestContract("mary"),
+	})
+	if !errors.Is(err, brokerclient.ErrConflict) {
+		t.Errorf("Given: %v, expected: %v", err, brokerclient.ErrConflict)
+	}
+
+	_, err = client.FetchLatestContract(
+		ctx, "web/app", "users", brokerclient.Selector{Tag: "staging"},
+	)
+	if !errors.Is(err, brokerclient.ErrNotFound) {
+		t.Errorf("Given: %v, expected: %v", err, brokerclient.ErrNotFound)
+	}
+}
+
+func TestClientVersions(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := client.PublishContract(ctx, brokerclient.PublishedContract{
+		Consumer: "web/app", Provider: "users", Version: "1", Branch: "feature/login",
+		Commit: "8a3f2c1", Semver: "1.2.0", Contract: newTestContract("john"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tagged, err := client.TagVersion(ctx, "web/app", "1", "prod")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tagged.Tags) != 1 || tagged.Tags[0] != "prod" || tagged.Commit != "8a3f2c1" {
+		t.Errorf("Given: %+v, expected: the version 1 tagged prod", tagged)
+	}
+
+	fetched, err := client.FetchVersion(ctx, "web/app", "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fetched.Semver != "1.2.0" {
+		t.Errorf("Given: %s, expected: %s", fetched.Semver, "1.2.0")
+	}
+
+	latest, err := client.FetchLatestVersion(
+		ctx, "web/app", brokerclient.Selector{Tag: "prod", Branch: "feature/login"},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if latest.Number != "1" {
+		t.Errorf("Given: %s, expected: %s", latest.Number, "1")
+	}
+
+	contract, err := client.FetchLatestContract(
+		ctx, "web/app", "users", brokerclient.Selector{Branch: "feature/login"},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if contract.Version != "1" || contract.Branch != "feature/login" {
+		t.Errorf("Given: %+v, expected: the version 1 on feature/login", contract)
+	}
+
+	_, err = client.FetchLatestVersion(ctx, "web/app", brokerclient.Selector{Branch: "main"})
+	if !errors.Is(err, brokerclient.ErrNotFound) {
+		t.Errorf("Given: %v, expected: %v", err, brokerclient.ErrNotFound)
+	}
+}
+
+func TestClientCanIDeploy(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	for _, published := range []brokerclient.PublishedContract{
+		{Version: "1", Contract: newTestContract("john")},
+		{Version: "2", Contract: newTestContract("mary")},
+	} {
+		published.Consumer, published.Provider = "web", "users"
+		_, err := client.PublishContract(ctx, published)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	for _, result := range []brokerclient.VerificationResult{
+		{ConsumerVersion: "1", ProviderVersion: "a", Success: true},
+		{ConsumerVersion: "2", ProviderVersion: "a", Success: false},
+	} {
+		result.Consumer, result.Provider = "web", "users"
+		if _, err := client.PublishVerificationResult(ctx, result); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if _, err := client.RecordDeployment(ctx, "users", "a", "prod"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rows, err := client.Matrix(ctx, brokerclient.MatrixQuery{Consumer: "web", Limit: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].ConsumerVersion != "2" {
+		t.Errorf("Given: %+v, expected: the row of the version 2", rows)
+	}
+
+	tests := []struct {
+		name               string
+		query              brokerclient.MatrixQuery
+		expectedDeployable bool
+		expectedReason     string
+	}{
+		{
+			name:               "should allow the verified versions",
+			query:              brokerclient.MatrixQuery{ConsumerVersion: "1", ProviderVersion: "a"},
+			expectedDeployable: true,
+			expectedReason:     "every contract between the versions is verified",
+		},
+		{
+			name:           "should prevent the versions whose verification failed",
+			query:          brokerclient.MatrixQuery{ConsumerVersion: "2", ProviderVersion: "a"},
+			expectedReason: "the verification of the contract between web 2 and users a failed",
+		},
+		{
+			name:           "should prevent the versions without verification",
+			query:          brokerclient.MatrixQuery{ConsumerVersion: "1", ProviderVersion: "b"},
+			expectedReason: "the contract between web 1 and users b is not verified",
+		},
+		{
+			name:           "should prevent the unknown versions",
+			query:          brokerclient.MatrixQuery{ConsumerVersion: "3", ProviderVersion: "a"},
+			expectedReason: "no contract found between the versions",
+		},
+		{
+			name:               "should allow the version verified by the deployed version",
+			query:              brokerclient.MatrixQuery{ConsumerVersion: "1", Environment: "prod"},
+			expectedDeployable: true,
+			expectedReason:     "every contract between the versions is verified",
+		},
+		{
+			name:           "should prevent the version failing with the deployed version",
+			query:          brokerclient.MatrixQuery{ConsumerVersion: "2", Environment: "prod"},
+			expectedReason: "the verification of the contract between web 2 and users a failed",
+		},
+		{
+			name:  "should prevent the deployments to an environment without version",
+			query: brokerclient.MatrixQuery{ConsumerVersion: "1", Environment: "staging"},
+			expectedReason: "no contract found between the versions, or no version is deployed " +
+				"to staging",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.query.Consumer, test.query.Provider = "web", "users"
+			deployment, err := client.CanIDeploy(ctx, test.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if deployment.Deployable != test.expectedDeployable {
+				t.Errorf("Given: %v, expected: %v", deployment.Deployable, test.expectedDeployable)
+			}
+			if deployment.Reason != test.expectedReason {
+				t.Errorf("Given: %s, expected: %s", deployment.Reason, test.expectedReason)
+			}
+		})
+	}
+}
+
+func TestNewClientRejectsInvalidURLs(t *testing.T) {
+	t.Parallel()
+
+	for _, brokerURL := range []string{"", "broker.example.com", "ftp://broker.example.com"} {
+		if _, err := brokerclient.NewClient(brokerURL); err == nil {
+			t.Errorf("Expected an error for %q, given nil", brokerURL)
+		}
+	}
+}
+
+func TestClientDeployments(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	for _, version := range []string{"1", "2"} {
+		if _, err := client.RecordDeployment(ctx, "web/app", version, "production"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	deployments, err := client.DeployedVersions(ctx, "production")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deployments) != 1 || deployments[0].Version != "2" {
+		t.Errorf("Given: %+v, expected: the version 2 of web/app", deployments)
+	}
+
+	_, err = client.RecordDeployment(ctx, "web/app", "3", "")
+	if !errors.Is(err, brokerclient.ErrInvalid) {
+		t.Errorf("Given: %v, expected: %v", err, brokerclient.ErrInvalid)
+	}
+}
+
+func TestClientGraph(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	for _, published := range []brokerclient.PublishedContract{
+		{Consumer: "web/app", Provider: "users", Version: "1", Tags: []string{"prod"}},
+		{Consumer: "mobile", Provider: "users", Version: "1"},
+	} {
+		published.Contract = newTestContract("john")
+		if _, err := client.PublishContract(ctx, published); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	graph, err := client.Graph(ctx, brokerclient.Selector{Tag: "prod"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(graph.Dependencies) != 1 || graph.Dependencies[0].Consumer != "web/app" ||
+		len(graph.Pacticipants) != 2 {
+		t.Errorf("Given: %+v, expected: the dependency of web/app on users", graph)
+	}
+
+	graph, err = client.Graph(ctx, brokerclient.Selector{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(graph.Dependencies) != 2 || !graph.Dependencies[0].Pending {
+		t.Errorf("Given: %+v, expected: the pending dependencies of mobile and web/app", graph)
+	}
+}
+
+func TestClientTokens(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("", broker.WithTokens(
+		broker.Token{Name: "web", Secret: "web-secret", Write: []string{"web"}},
+	))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server := httptest.NewServer(broker.NewHTTPHandler(contractBroker))
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		opts          []brokerclient.ClientOption
+		pacticipant   string
+		expectedError error
+	}{
+		{
+			name:        "should tag the versions of the token",
+			opts:        []brokerclient.ClientOption{brokerclient.WithToken("web-secret")},
+			pacticipant: "web",
+		},
+		{
+			name:          "should fail without token",
+			pacticipant:   "web",
+			expectedError: brokerclient.ErrUnauthenticated,
+		},
+		{
+			name:          "should fail to tag the versions of another pacticipant",
+			opts:          []brokerclient.ClientOption{brokerclient.WithToken("web-secret")},
+			pacticipant:   "users",
+			expectedError: brokerclient.ErrForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := brokerclient.NewClient(server.URL, test.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			_, err = client.TagVersion(ctx, test.pacticipant, "1", "prod")
+			if test.expectedError == nil && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !errors.Is(err, test.expectedError) {
+				t.Errorf("Given: %v, expected: %v", err, test.expectedError)
+			}
+		})
+	}
+}
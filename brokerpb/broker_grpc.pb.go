@@ -0,0 +1,333 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: deal/broker/v1/broker.proto
+
+package brokerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ContractBrokerClient is the client API for ContractBroker service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ContractBrokerClient interface {
+	PublishContract(ctx context.Context, in *PublishContractRequest, opts ...grpc.CallOption) (*PublishContractResponse, error)
+	// GetContract returns the contract of a consumer version, or the latest one
+	GetContract(ctx context.Context, in *GetContractRequest, opts ...grpc.CallOption) (*GetContractResponse, error)
+	PublishVerificationResult(ctx context.Context, in *PublishVerificationResultRequest, opts ...grpc.CallOption) (*PublishVerificationResultResponse, error)
+	// GetMatrix returns the consumer and provider versions along with their verification
+	GetMatrix(ctx context.Context, in *GetMatrixRequest, opts ...grpc.CallOption) (*GetMatrixResponse, error)
+	// TagVersion adds a tag to a consumer or provider version, e.g. prod once it's deployed
+	TagVersion(ctx context.Context, in *TagVersionRequest, opts ...grpc.CallOption) (*TagVersionResponse, error)
+	// RecordDeployment records that a version is deployed to an environment, replacing the
+	// version deployed there before
+	RecordDeployment(ctx context.Context, in *RecordDeploymentRequest, opts ...grpc.CallOption) (*RecordDeploymentResponse, error)
+	// ListDeployedVersions returns the versions currently deployed to an environment
+	ListDeployedVersions(ctx context.Context, in *ListDeployedVersionsRequest, opts ...grpc.CallOption) (*ListDeployedVersionsResponse, error)
+}
+
+type contractBrokerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewContractBrokerClient(cc grpc.ClientConnInterface) ContractBrokerClient {
+	return &contractBrokerClient{cc}
+}
+
+func (c *contractBrokerClient) PublishContract(ctx context.Context, in *PublishContractRequest, opts ...grpc.CallOption) (*PublishContractResponse, error) {
+	out := new(PublishContractResponse)
+	err := c.cc.Invoke(ctx, "/deal.broker.v1.ContractBroker/PublishContract", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contractBrokerClient) GetContract(ctx context.Context, in *GetContractRequest, opts ...grpc.CallOption) (*GetContractResponse, error) {
+	out := new(GetContractResponse)
+	err := c.cc.Invoke(ctx, "/deal.broker.v1.ContractBroker/GetContract", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contractBrokerClient) PublishVerificationResult(ctx context.Context, in *PublishVerificationResultRequest, opts ...grpc.CallOption) (*PublishVerificationResultResponse, error) {
+	out := new(PublishVerificationResultResponse)
+	err := c.cc.Invoke(ctx, "/deal.broker.v1.ContractBroker/PublishVerificationResult", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contractBrokerClient) GetMatrix(ctx context.Context, in *GetMatrixRequest, opts ...grpc.CallOption) (*GetMatrixResponse, error) {
+	out := new(GetMatrixResponse)
+	err := c.cc.Invoke(ctx, "/deal.broker.v1.ContractBroker/GetMatrix", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contractBrokerClient) TagVersion(ctx context.Context, in *TagVersionRequest, opts ...grpc.CallOption) (*TagVersionResponse, error) {
+	out := new(TagVersionResponse)
+	err := c.cc.Invoke(ctx, "/deal.broker.v1.ContractBroker/TagVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contractBrokerClient) RecordDeployment(ctx context.Context, in *RecordDeploymentRequest, opts ...grpc.CallOption) (*RecordDeploymentResponse, error) {
+	out := new(RecordDeploymentResponse)
+	err := c.cc.Invoke(ctx, "/deal.broker.v1.ContractBroker/RecordDeployment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contractBrokerClient) ListDeployedVersions(ctx context.Context, in *ListDeployedVersionsRequest, opts ...grpc.CallOption) (*ListDeployedVersionsResponse, error) {
+	out := new(ListDeployedVersionsResponse)
+	err := c.cc.Invoke(ctx, "/deal.broker.v1.ContractBroker/ListDeployedVersions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ContractBrokerServer is the server API for ContractBroker service.
+// All implementations must embed UnimplementedContractBrokerServer
+// for forward compatibility
+type ContractBrokerServer interface {
+	PublishContract(context.Context, *PublishContractRequest) (*PublishContractResponse, error)
+	// GetContract returns the contract of a consumer version, or the latest one
+	GetContract(context.Context, *GetContractRequest) (*GetContractResponse, error)
+	PublishVerificationResult(context.Context, *PublishVerificationResultRequest) (*PublishVerificationResultResponse, error)
+	// GetMatrix returns the consumer and provider versions along with their verification
+	GetMatrix(context.Context, *GetMatrixRequest) (*GetMatrixResponse, error)
+	// TagVersion adds a tag to a consumer or provider version, e.g. prod once it's deployed
+	TagVersion(context.Context, *TagVersionRequest) (*TagVersionResponse, error)
+	// RecordDeployment records that a version is deployed to an environment, replacing the
+	// version deployed there before
+	RecordDeployment(context.Context, *RecordDeploymentRequest) (*RecordDeploymentResponse, error)
+	// ListDeployedVersions returns the versions currently deployed to an environment
+	ListDeployedVersions(context.Context, *ListDeployedVersionsRequest) (*ListDeployedVersionsResponse, error)
+	mustEmbedUnimplementedContractBrokerServer()
+}
+
+// UnimplementedContractBrokerServer must be embedded to have forward compatible implementations.
+type UnimplementedContractBrokerServer struct {
+}
+
+func (UnimplementedContractBrokerServer) PublishContract(context.Context, *PublishContractRequest) (*PublishContractResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishContract not implemented")
+}
+func (UnimplementedContractBrokerServer) GetContract(context.Context, *GetContractRequest) (*GetContractResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContract not implemented")
+}
+func (UnimplementedContractBrokerServer) PublishVerificationResult(context.Context, *PublishVerificationResultRequest) (*PublishVerificationResultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishVerificationResult not implemented")
+}
+func (UnimplementedContractBrokerServer) GetMatrix(context.Context, *GetMatrixRequest) (*GetMatrixResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMatrix not implemented")
+}
+func (UnimplementedContractBrokerServer) TagVersion(context.Context, *TagVersionRequest) (*TagVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TagVersion not implemented")
+}
+func (UnimplementedContractBrokerServer) RecordDeployment(context.Context, *RecordDeploymentRequest) (*RecordDeploymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordDeployment not implemented")
+}
+func (UnimplementedContractBrokerServer) ListDeployedVersions(context.Context, *ListDeployedVersionsRequest) (*ListDeployedVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeployedVersions not implemented")
+}
+func (UnimplementedContractBrokerServer) mustEmbedUnimplementedContractBrokerServer() {}
+
+// UnsafeContractBrokerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ContractBrokerServer will
+// result in compilation errors.
+type UnsafeContractBrokerServer interface {
+	mustEmbedUnimplementedContractBrokerServer()
+}
+
+func RegisterContractBrokerServer(s grpc.ServiceRegistrar, srv ContractBrokerServer) {
+	s.RegisterService(&ContractBroker_ServiceDesc, srv)
+}
+
+func _ContractBroker_PublishContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishContractRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractBrokerServer).PublishContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deal.broker.v1.ContractBroker/PublishContract",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractBrokerServer).PublishContract(ctx, req.(*PublishContractRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContractBroker_GetContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetContractRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractBrokerServer).GetContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deal.broker.v1.ContractBroker/GetContract",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractBrokerServer).GetContract(ctx, req.(*GetContractRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContractBroker_PublishVerificationResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishVerificationResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractBrokerServer).PublishVerificationResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deal.broker.v1.ContractBroker/PublishVerificationResult",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractBrokerServer).PublishVerificationResult(ctx, req.(*PublishVerificationResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContractBroker_GetMatrix_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMatrixRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractBrokerServer).GetMatrix(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deal.broker.v1.ContractBroker/GetMatrix",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractBrokerServer).GetMatrix(ctx, req.(*GetMatrixRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContractBroker_TagVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractBrokerServer).TagVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deal.broker.v1.ContractBroker/TagVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractBrokerServer).TagVersion(ctx, req.(*TagVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContractBroker_RecordDeployment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordDeploymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractBrokerServer).RecordDeployment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deal.broker.v1.ContractBroker/RecordDeployment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractBrokerServer).RecordDeployment(ctx, req.(*RecordDeploymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContractBroker_ListDeployedVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeployedVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContractBrokerServer).ListDeployedVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/deal.broker.v1.ContractBroker/ListDeployedVersions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContractBrokerServer).ListDeployedVersions(ctx, req.(*ListDeployedVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ContractBroker_ServiceDesc is the grpc.ServiceDesc for ContractBroker service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ContractBroker_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deal.broker.v1.ContractBroker",
+	HandlerType: (*ContractBrokerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PublishContract",
+			Handler:    _ContractBroker_PublishContract_Handler,
+		},
+		{
+			MethodName: "GetContract",
+			Handler:    _ContractBroker_GetContract_Handler,
+		},
+		{
+			MethodName: "PublishVerificationResult",
+			Handler:    _ContractBroker_PublishVerificationResult_Handler,
+		},
+		{
+			MethodName: "GetMatrix",
+			Handler:    _ContractBroker_GetMatrix_Handler,
+		},
+		{
+			MethodName: "TagVersion",
+			Handler:    _ContractBroker_TagVersion_Handler,
+		},
+		{
+			MethodName: "RecordDeployment",
+			Handler:    _ContractBroker_RecordDeployment_Handler,
+		},
+		{
+			MethodName: "ListDeployedVersions",
+			Handler:    _ContractBroker_ListDeployedVersions_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "deal/broker/v1/broker.proto",
+}
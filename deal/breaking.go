@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var breakingCommand = command{
+	name:  "breaking",
+	usage: "breaking [-format text|json] <base contract> <revision contract>",
+	description: "Fails when the revision weakens the guarantees of the base contract " +
+		"given to the existing consumers",
+}
+
+func init() {
+	breakingCommand.run = runBreaking
+}
+
+// runBreaking reports the breaking changes, the command fails when there's any
+func runBreaking(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&breakingCommand, stderr)
+	format := flags.String("format", textFormat, "Output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format, textFormat, jsonFormat); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 { //nolint:revive // the base and the revision
+		return errors.New("the base and the revision contract files must be provided")
+	}
+
+	base, err := processors.ReadContractFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	revision, err := processors.ReadContractFile(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	breakingChanges, err := processors.BreakingChanges(base, revision)
+	if err != nil {
+		return err
+	}
+
+	if *format == jsonFormat {
+		err = writeJSON(stdout, struct {
+			BreakingChanges []processors.ContractIssue `json:"breakingChanges"`
+		}{BreakingChanges: breakingChanges})
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, breakingChange := range breakingChanges {
+			fmt.Fprintf(stdout, "%s: %s\n", flags.Arg(0), breakingChange)
+		}
+	}
+
+	if len(breakingChanges) > 0 {
+		return errIssuesFound
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	_ "github.com/lib/pq" // the driver of the Postgres storage
+	"google.golang.org/grpc"
+
+	"github.com/faunists/deal-go/broker"
+	"github.com/faunists/deal-go/brokerpb"
+)
+
+// brokerShutdownTimeout bounds the completion of the requests once the broker is stopped
+const brokerShutdownTimeout = 10 * time.Second
+
+// The storages of the broker data
+const (
+	fileStorage     = "file"
+	boltStorage     = "bolt"
+	postgresStorage = "postgres"
+)
+
+var brokerCommand = command{
+	name: "broker",
+	usage: "broker serve [-http host:port] [-grpc host:port] [-storage file|bolt|postgres] " +
+		"[-data <file or URL>] [-blobs <directory or s3://bucket/prefix>] [-tokens <file>] " +
+		"[-webhooks <file>]",
+	description: "Runs a contract broker storing the contracts and their verification results",
+}
+
+func init() {
+	brokerCommand.run = runBroker
+}
+
+// runBroker runs the subcommand of the broker
+func runBroker(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		newFlagSet(&brokerCommand, stderr).Usage()
+		return errors.New("missing subcommand, expected serve")
+	}
+
+	switch {
+	case isHelp(args[0]):
+		newFlagSet(&brokerCommand, stderr).Usage()
+		return flag.ErrHelp
+	case args[0] == "serve":
+		return runBrokerServe(args[1:], stdout, stderr)
+	default:
+		return fmt.Errorf("unknown subcommand %q, expected serve", args[0])
+	}
+}
+
+// runBrokerServe serves the HTTP and gRPC APIs of the broker until the process is interrupted
+// or terminated
+func runBrokerServe(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&brokerCommand, stderr)
+	httpAddress := flags.String("http", ":9292", "Address of the HTTP API")
+	grpcAddress := flags.String("grpc", ":9293", "Address of the gRPC API, disabled when empty")
+	storageKind := flags.String(
+		"storage", fileStorage, "Storage of the data: a JSON file, a bbolt file or a Postgres database",
+	)
+	dataLocation := flags.String(
+		"data", "", "File or Postgres URL keeping the data of the broker, the data is lost on exit "+
+			"by default",
+	)
+	blobsLocation := flags.String(
+		"blobs", "", "Directory or S3 bucket keeping the contents of the contracts apart from the "+
+			"other data, the AWS_* variables configure the bucket",
+	)
+	tokensPath := flags.String(
+		"tokens", "", "YAML file of the tokens of the broker, its APIs are open to everyone by default",
+	)
+	webhooksPath := flags.String(
+		"webhooks", "", "YAML file of the webhooks called when a contract changes",
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+	if *blobsLocation != "" && *dataLocation == "" {
+		return errors.New("the -blobs flag requires the -data flag")
+	}
+
+	opts := []broker.BrokerOption{
+		broker.WithWebhookErrorHandler(func(webhook broker.Webhook, err error) {
+			fmt.Fprintf(stderr, "deal broker: webhook %s failed: %v\n", webhook.Name, err)
+		}),
+	}
+	if *tokensPath != "" {
+		tokens, err := broker.ReadTokens(*tokensPath)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, broker.WithTokens(tokens...))
+	}
+	if *webhooksPath != "" {
+		webhooks, err := broker.ReadWebhooks(*webhooksPath)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, broker.WithWebhooks(webhooks...))
+	}
+
+	if *blobsLocation != "" {
+		blobs, err := brokerBlobs(*blobsLocation)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, broker.WithContractBlobs(blobs))
+	}
+
+	storage, closeStorage, err := brokerStorage(*storageKind, *dataLocation)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+	if storage != nil {
+		opts = append(opts, broker.WithStorage(storage))
+	}
+
+	contractBroker, err := broker.NewBroker("", opts...)
+	if err != nil {
+		return err
+	}
+	defer contractBroker.Wait()
+
+	httpListener, err := net.Listen("tcp", *httpAddress)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{
+		Handler:           broker.NewHTTPHandler(contractBroker),
+		ReadHeaderTimeout: brokerShutdownTimeout,
+	}
+	failures := make(chan error, 2) //nolint:revive // one failure for each server
+	go func() {
+		fmt.Fprintf(stdout, "serving the HTTP API on %s\n", httpListener.Addr())
+		if err := httpServer.Serve(httpListener); !errors.Is(err, http.ErrServerClosed) {
+			failures <- err
+		}
+	}()
+
+	grpcServer := grpc.NewServer()
+	if *grpcAddress != "" {
+		grpcListener, err := net.Listen("tcp", *grpcAddress)
+		if err != nil {
+			_ = httpServer.Close()
+			return err
+		}
+
+		brokerpb.RegisterContractBrokerServer(grpcServer, broker.NewGRPCServer(contractBroker))
+		go func() {
+			fmt.Fprintf(stdout, "serving the gRPC API on %s\n", grpcListener.Addr())
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				failures <- err
+			}
+		}()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err = <-failures:
+	case <-signals:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), brokerShutdownTimeout)
+	defer cancel()
+	grpcServer.GracefulStop()
+	if shutdownErr := httpServer.Shutdown(ctx); err == nil {
+		err = shutdownErr
+	}
+
+	return err
+}
+
+// brokerStorage opens the storage of the data, the data is kept in memory without location.
+// The storage is released by the returned function.
+func brokerStorage(kind, location string) (broker.Storage, func(), error) {
+	if location == "" && kind != fileStorage {
+		return nil, nil, fmt.Errorf("the %s storage requires the -data flag", kind)
+	}
+
+	switch kind {
+	case fileStorage:
+		if location == "" {
+			return nil, func() {}, nil
+		}
+		return broker.NewFileStorage(location), func() {}, nil
+	case boltStorage:
+		storage, err := broker.NewBoltStorage(location)
+		if err != nil {
+			return nil, nil, err
+		}
+		return storage, func() { _ = storage.Close() }, nil
+	case postgresStorage:
+		db, err := sql.Open("postgres", location)
+		if err != nil {
+			return nil, nil, err
+		}
+		storage, err := broker.NewPostgresStorage(db)
+		if err != nil {
+			_ = db.Close()
+			return nil, nil, err
+		}
+		return storage, func() { _ = db.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf(
+			"invalid storage %q, expected one of %v", kind,
+			[]string{fileStorage, boltStorage, postgresStorage},
+		)
+	}
+}
+
+// brokerBlobs returns the blob store of the contracts, an s3://bucket/prefix URL or a directory.
+// The bucket is configured by the AWS_REGION, AWS_ENDPOINT_URL, AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables.
+func brokerBlobs(location string) (broker.BlobStore, error) {
+	if !strings.HasPrefix(location, "s3://") {
+		return broker.NewDirectoryBlobStore(location), nil
+	}
+
+	bucketURL, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bucket URL: %w", err)
+	}
+	prefix := strings.TrimPrefix(bucketURL.Path, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return broker.NewS3BlobStore(broker.S3Config{
+		Endpoint:        os.Getenv("AWS_ENDPOINT_URL"),
+		Region:          os.Getenv("AWS_REGION"),
+		Bucket:          bucketURL.Host,
+		Prefix:          prefix,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	})
+}
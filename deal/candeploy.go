@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/faunists/deal-go/brokerclient"
+)
+
+var canIDeployCommand = command{
+	name: "can-i-deploy",
+	usage: "can-i-deploy -broker <url> [-token <token>] -consumer <name> " +
+		"[-consumer-version <version>] -provider <name> [-provider-version <version>] " +
+		"[-to <environment>] [-format text|json]",
+	description: "Tells whether a consumer and a provider version are compatible, according to " +
+		"the verifications of the broker",
+}
+
+func init() {
+	canIDeployCommand.run = runCanIDeploy
+}
+
+// runCanIDeploy queries the matrix of the consumer and provider versions, a missing version
+// is the one deployed to the environment, the command fails unless every version pair verified
+// the contract
+func runCanIDeploy(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&canIDeployCommand, stderr)
+	brokerURL := flags.String("broker", "", "URL of the contract broker")
+	token := brokerTokenFlag(flags)
+	consumer := flags.String("consumer", "", "Name of the consumer")
+	consumerVersion := flags.String(
+		"consumer-version", "", "Version of the consumer, the one deployed to the environment by default",
+	)
+	provider := flags.String("provider", "", "Name of the provider")
+	providerVersion := flags.String(
+		"provider-version", "", "Version of the provider, the one deployed to the environment by default",
+	)
+	environment := flags.String("to", "", "Environment the version is deployed to, e.g. production")
+	format := flags.String("format", textFormat, "Output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format, textFormat, jsonFormat); err != nil {
+		return err
+	}
+	if *brokerURL == "" {
+		return errors.New("the -broker flag must be provided")
+	}
+	if *consumer == "" || *provider == "" {
+		return errors.New("the -consumer and -provider flags must be provided")
+	}
+	if *consumerVersion == "" && *providerVersion == "" {
+		return errors.New("the -consumer-version or -provider-version flag must be provided")
+	}
+	if (*consumerVersion == "" || *providerVersion == "") && *environment == "" {
+		return errors.New("the -to flag must be provided when a version is missing")
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	client, err := newBrokerClient(*brokerURL, *token)
+	if err != nil {
+		return err
+	}
+
+	deployment, err := client.CanIDeploy(context.Background(), brokerclient.MatrixQuery{
+		Consumer:        *consumer,
+		ConsumerVersion: *consumerVersion,
+		Provider:        *provider,
+		ProviderVersion: *providerVersion,
+		Environment:     *environment,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *format == jsonFormat {
+		err = writeJSON(stdout, deployment)
+	} else {
+		err = writeDeployment(stdout, deployment)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !deployment.Deployable {
+		return errIssuesFound
+	}
+
+	return nil
+}
+
+func writeDeployment(output io.Writer, answer brokerclient.Deployment) error {
+	if len(answer.Rows) > 0 {
+		table := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0) //nolint:revive // the column padding
+		fmt.Fprintln(table, "CONSUMER\tVERSION\tPROVIDER\tVERSION\tVERIFICATION")
+		for _, row := range answer.Rows {
+			verification := "missing"
+			if row.Success != nil && *row.Success {
+				verification = "success"
+			} else if row.Success != nil {
+				verification = "failure"
+			}
+
+			fmt.Fprintf(
+				table, "%s\t%s\t%s\t%s\t%s\n", row.Consumer, row.ConsumerVersion, row.Provider,
+				row.ProviderVersion, verification,
+			)
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(output)
+	}
+
+	verdict := "no"
+	if answer.Deployable {
+		verdict = "yes"
+	}
+	fmt.Fprintf(output, "can I deploy? %s, %s\n", verdict, answer.Reason)
+
+	return nil
+}
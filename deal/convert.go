@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var convertCommand = command{
+	name:        "convert",
+	usage:       "convert [-to json|yaml|cue] [-o file] <contract file>",
+	description: "Converts a contract file to another format, e.g. from JSON to YAML",
+}
+
+func init() {
+	convertCommand.run = runConvert
+}
+
+// runConvert writes the converted contract to the output file, or to the standard output
+// when no file is given. The format is the one of the -to flag or of the output file extension.
+func runConvert(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&convertCommand, stderr)
+	to := flags.String("to", "", "Output format, e.g. json, yaml or cue, the -o extension by default")
+	outputPath := flags.String("o", "", "Output file, the converted contract is printed otherwise")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	extension := filepath.Ext(*outputPath)
+	if *to != "" {
+		if *outputPath != "" && extension != "."+*to {
+			return fmt.Errorf("the -to format %s doesn't match the output file %s", *to, *outputPath)
+		}
+		extension = "." + *to
+	}
+	if extension == "" {
+		return errors.New("the -to flag or an output file with an extension must be provided")
+	}
+	if flags.NArg() != 1 {
+		return errors.New("a contract file must be provided")
+	}
+
+	converted, err := processors.ConvertContractFile(flags.Arg(0), extension)
+	if err != nil {
+		return err
+	}
+
+	if *outputPath == "" {
+		_, err = stdout.Write(converted)
+		return err
+	}
+
+	//nolint:gosec // the contracts aren't secret
+	return ioutil.WriteFile(*outputPath, converted, 0o644)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var coverageCommand = command{
+	name: "coverage",
+	usage: "coverage -descriptor-set <file> [-threshold percentage] [-format table|json] " +
+		"<contract file>",
+	description: "Reports the methods of the proto services covered by the cases of a contract",
+}
+
+func init() {
+	coverageCommand.run = runCoverage
+}
+
+// runCoverage reports the coverage of every method, the command fails when the coverage
+// is below the threshold
+func runCoverage(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&coverageCommand, stderr)
+	descriptorSetPath := flags.String(
+		"descriptor-set", "", "Descriptor set (protoc --descriptor_set_out) or buf image",
+	)
+	threshold := flags.Float64(
+		"threshold", 0, "Minimum percentage of methods with cases, the command fails below it",
+	)
+	format := flags.String("format", tableFormat, "Output format: table or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format, tableFormat, jsonFormat); err != nil {
+		return err
+	}
+	if *descriptorSetPath == "" {
+		return errors.New("the -descriptor-set flag must be provided")
+	}
+	if *threshold < 0 || *threshold > 100 {
+		return fmt.Errorf("invalid threshold %v, expected a percentage between 0 and 100", *threshold)
+	}
+	if flags.NArg() != 1 {
+		return errors.New("a contract file must be provided")
+	}
+
+	files, err := processors.ReadDescriptorSet(*descriptorSetPath)
+	if err != nil {
+		return err
+	}
+	contract, err := processors.ReadContractFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	coverage := processors.ComputeContractCoverage(contract, files)
+	if *format == jsonFormat {
+		err = writeJSON(stdout, coverage)
+	} else {
+		err = writeCoverageTable(stdout, coverage)
+	}
+	if err != nil {
+		return err
+	}
+
+	if coverage.Percentage < *threshold {
+		fmt.Fprintf(
+			stderr, "coverage %.1f%% is below the threshold of %.1f%%\n",
+			coverage.Percentage, *threshold,
+		)
+		return errIssuesFound
+	}
+
+	return nil
+}
+
+func writeCoverageTable(output io.Writer, coverage processors.ContractCoverage) error {
+	table := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0) //nolint:revive // the column padding
+	fmt.Fprintln(table, "SERVICE\tMETHOD\tSUCCESS\tAPPLICATION ERROR\tFAILURE\tSTATUS")
+	for _, method := range coverage.Methods {
+		fmt.Fprintf(
+			table, "%s\t%s\t%d\t%d\t%d\t%s\n",
+			method.Service, method.Method, method.SuccessCases,
+			method.ApplicationErrorCases, method.FailureCases, method.Status,
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(
+		output, "\ncoverage: %.1f%% (%d/%d methods)\n",
+		coverage.Percentage, coverage.CoveredMethods, coverage.TotalMethods,
+	)
+	return err
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var recordDeploymentCommand = command{
+	name: "record-deployment",
+	usage: "record-deployment -broker <url> [-token <token>] -pacticipant <name> " +
+		"-version <version> -environment <name>",
+	description: "Records that a consumer or provider version is deployed to an environment, " +
+		"for can-i-deploy to check the versions against it",
+}
+
+func init() {
+	recordDeploymentCommand.run = runRecordDeployment
+}
+
+// runRecordDeployment records the deployment once it succeeded, the version deployed to the
+// environment before is replaced
+func runRecordDeployment(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&recordDeploymentCommand, stderr)
+	brokerURL := flags.String("broker", "", "URL of the contract broker")
+	token := brokerTokenFlag(flags)
+	pacticipant := flags.String("pacticipant", "", "Name of the consumer or the provider")
+	version := flags.String("version", "", "Deployed version, e.g. a git SHA")
+	environment := flags.String("environment", "", "Environment of the deployment, e.g. production")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *brokerURL == "" {
+		return errors.New("the -broker flag must be provided")
+	}
+	if *pacticipant == "" || *version == "" || *environment == "" {
+		return errors.New("the -pacticipant, -version and -environment flags must be provided")
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	client, err := newBrokerClient(*brokerURL, *token)
+	if err != nil {
+		return err
+	}
+
+	deployed, err := client.RecordDeployment(
+		context.Background(), *pacticipant, *version, *environment,
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(
+		stdout, "recorded the deployment of %s %s to %s\n", deployed.Pacticipant, deployed.Version,
+		deployed.Environment,
+	)
+
+	return nil
+}
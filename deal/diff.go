@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var diffCommand = command{
+	name:  "diff",
+	usage: "diff [-format text|json] <base contract> <revision contract>",
+	description: "Reports the services, methods and cases added, removed or changed " +
+		"between two contracts",
+}
+
+func init() {
+	diffCommand.run = runDiff
+}
+
+func runDiff(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&diffCommand, stderr)
+	format := flags.String("format", textFormat, "Output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format, textFormat, jsonFormat); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 { //nolint:revive // the base and the revision
+		return errors.New("the base and the revision contract files must be provided")
+	}
+
+	changes, err := diffContractFiles(flags.Arg(0), flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	if *format == jsonFormat {
+		return writeJSON(stdout, struct {
+			Changes []processors.ContractChange `json:"changes"`
+		}{Changes: changes})
+	}
+
+	for _, change := range changes {
+		fmt.Fprintln(stdout, change)
+	}
+	fmt.Fprintln(stdout, changesSummary(changes))
+
+	return nil
+}
+
+// diffContractFiles reads both contract files and returns their changes
+func diffContractFiles(basePath, revisionPath string) ([]processors.ContractChange, error) {
+	base, err := processors.ReadContractFile(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := processors.ReadContractFile(revisionPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return processors.DiffContracts(base, revision)
+}
+
+// changesSummary counts the changes by type, e.g. "2 added, 1 removed, 0 changed"
+func changesSummary(changes []processors.ContractChange) string {
+	counts := make(map[processors.ChangeType]int)
+	for _, change := range changes {
+		counts[change.Type]++
+	}
+
+	return fmt.Sprintf(
+		"%d added, %d removed, %d changed",
+		counts[processors.ChangeAdded],
+		counts[processors.ChangeRemoved],
+		counts[processors.ChangeChanged],
+	)
+}
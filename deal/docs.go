@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var docsCommand = command{
+	name:        "docs",
+	usage:       "docs [-format markdown|html] [-o directory] <contract file>",
+	description: "Generates the documentation of the services of a contract, in Markdown or HTML",
+}
+
+func init() {
+	docsCommand.run = runDocs
+}
+
+// runDocs writes a page per service in the output directory, or every service to the standard
+// output when no directory is given. The HTML pages are written along with their index page.
+func runDocs(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&docsCommand, stderr)
+	format := flags.String("format", markdownFormat, "Output format: markdown or html")
+	outputDir := flags.String("o", "", "Directory of the pages, one per service")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format, markdownFormat, htmlFormat); err != nil {
+		return err
+	}
+	if *format == htmlFormat && *outputDir == "" {
+		return errors.New("the -o flag must be provided for the html format")
+	}
+	if flags.NArg() != 1 {
+		return errors.New("a contract file must be provided")
+	}
+
+	contractPath := flags.Arg(0)
+	contract, err := processors.ReadContractFile(contractPath)
+	if err != nil {
+		return err
+	}
+	services, err := processors.BuildContractDocs(contract)
+	if err != nil {
+		return err
+	}
+
+	if *outputDir != "" {
+		if err = os.MkdirAll(*outputDir, 0o755); err != nil { //nolint:gosec // a docs directory
+			return err
+		}
+	}
+
+	if *format == htmlFormat {
+		title := contract.Name
+		if title == "" {
+			title = strings.TrimSuffix(filepath.Base(contractPath), filepath.Ext(contractPath))
+		}
+		return writeHTMLDocs(*outputDir, title, services)
+	}
+
+	for index, service := range services {
+		markdown := bytes.NewBuffer(nil)
+		if err = processors.WriteMarkdownDocs(markdown, service); err != nil {
+			return err
+		}
+
+		if *outputDir == "" {
+			if index > 0 {
+				fmt.Fprintln(stdout)
+			}
+			if _, err = stdout.Write(markdown.Bytes()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = writeDocsFile(filepath.Join(*outputDir, service.Name+".md"), markdown); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeHTMLDocs writes the index page and the page of every service in the directory
+func writeHTMLDocs(outputDir, title string, services []processors.ServiceDocs) error {
+	index := bytes.NewBuffer(nil)
+	if err := processors.WriteHTMLIndex(index, title, services); err != nil {
+		return err
+	}
+	if err := writeDocsFile(filepath.Join(outputDir, "index.html"), index); err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		page := bytes.NewBuffer(nil)
+		if err := processors.WriteHTMLDocs(page, service); err != nil {
+			return err
+		}
+
+		pagePath := filepath.Join(outputDir, processors.HTMLDocsFilename(service.Name))
+		if err := writeDocsFile(pagePath, page); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeDocsFile(docsPath string, content *bytes.Buffer) error {
+	return ioutil.WriteFile(docsPath, content.Bytes(), 0o644) //nolint:gosec // the docs aren't secret
+}
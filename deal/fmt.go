@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var fmtCommand = command{
+	name:        "fmt",
+	usage:       "fmt [-l] [-w] <contract file>...",
+	description: "Rewrites contract files into their canonical form, keeping the diffs minimal",
+}
+
+func init() {
+	fmtCommand.run = runFmt
+}
+
+// runFmt prints the canonical form of the contract files, the -l flag fails when a file
+// isn't formatted so it can gate a CI pipeline
+func runFmt(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&fmtCommand, stderr)
+	list := flags.Bool(
+		"l", false, "List the files whose formatting differs, failing when there's any",
+	)
+	write := flags.Bool(
+		"w", false, "Write the canonical form to the files instead of printing it",
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("at least one contract file must be provided")
+	}
+
+	unformatted := false
+	for _, contractPath := range flags.Args() {
+		original, err := ioutil.ReadFile(contractPath)
+		if err != nil {
+			return err
+		}
+
+		formatted, err := processors.FormatContractFile(contractPath)
+		if err != nil {
+			return err
+		}
+
+		if !*list && !*write {
+			if _, err = stdout.Write(formatted); err != nil {
+				return err
+			}
+			continue
+		}
+		if bytes.Equal(original, formatted) {
+			continue
+		}
+
+		unformatted = true
+		if *list {
+			fmt.Fprintln(stdout, contractPath)
+		}
+		if *write {
+			info, err := os.Stat(contractPath)
+			if err != nil {
+				return err
+			}
+			if err = ioutil.WriteFile(contractPath, formatted, info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if *list && unformatted {
+		return errIssuesFound
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var generateCommand = command{
+	name:  "generate",
+	usage: "generate [-config <file>] [-dry-run]",
+	description: "Runs protoc or buf with the plugins and the options of a deal.yaml project " +
+		"file",
+}
+
+func init() {
+	generateCommand.run = runGenerate
+}
+
+// runGenerate runs the compiler of the project, its output is forwarded
+func runGenerate(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&generateCommand, stderr)
+	configPath := flags.String("config", "deal.yaml", "Project file")
+	dryRun := flags.Bool("dry-run", false, "Print the command instead of running it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	config, err := processors.ReadProjectConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	program, programArgs, err := config.GenerateCommand()
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Fprintln(stdout, shellCommand(program, programArgs))
+		return nil
+	}
+
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return fmt.Errorf("%s must be installed to generate the code: %w", program, err)
+	}
+
+	generator := exec.Command(path, programArgs...) //nolint:gosec // the project file is trusted
+	generator.Stdout, generator.Stderr = stdout, stderr
+	if err = generator.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", program, err)
+	}
+
+	return nil
+}
+
+// shellCommand returns the command as it'd be typed in a shell, the arguments are quoted
+// when needed
+func shellCommand(program string, args []string) string {
+	words := []string{program}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, " \t\n\"'{}[]$*?;&|<>()\\") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		} else if arg == "" {
+			arg = strconv.Quote(arg)
+		}
+		words = append(words, arg)
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/faunists/deal-go/brokerclient"
+)
+
+var graphCommand = command{
+	name: "graph",
+	usage: "graph -broker <url> [-token <token>] [-tag <tag>] [-branch <branch>] " +
+		"[-format text|json|dot]",
+	description: "Shows which consumers call which providers according to the latest contracts " +
+		"of a contract broker, the dot format is rendered by Graphviz",
+}
+
+func init() {
+	graphCommand.run = runGraph
+}
+
+// runGraph prints the dependencies of the consumers, one per consumer and provider pair
+func runGraph(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&graphCommand, stderr)
+	brokerURL := flags.String("broker", "", "URL of the contract broker")
+	token := brokerTokenFlag(flags)
+	tag := flags.String("tag", "", "Only use the consumer versions with the tag")
+	branch := flags.String("branch", "", "Only use the consumer versions on the branch")
+	format := flags.String("format", textFormat, "Output format: text, json or dot")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format, textFormat, jsonFormat, dotFormat); err != nil {
+		return err
+	}
+	if *brokerURL == "" {
+		return errors.New("the -broker flag must be provided")
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	client, err := newBrokerClient(*brokerURL, *token)
+	if err != nil {
+		return err
+	}
+
+	graph, err := client.Graph(
+		context.Background(), brokerclient.Selector{Tag: *tag, Branch: *branch},
+	)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case jsonFormat:
+		return writeJSON(stdout, graph)
+	case dotFormat:
+		writeDOTGraph(stdout, graph)
+	default:
+		writeGraph(stdout, graph)
+	}
+
+	return nil
+}
+
+func writeGraph(output io.Writer, graph brokerclient.Graph) {
+	if len(graph.Dependencies) == 0 {
+		fmt.Fprintln(output, "no contract found")
+		return
+	}
+
+	for _, dependency := range graph.Dependencies {
+		fmt.Fprintf(output, "%s -> %s", dependency.Consumer, dependency.Provider)
+		if dependency.Pending {
+			fmt.Fprint(output, " (pending)")
+		}
+		fmt.Fprintf(output, ": %s\n", strings.Join(dependency.Methods, ", "))
+	}
+}
+
+// writeDOTGraph writes the graph in the DOT language, the edges are labeled with the methods of
+// the contracts and the ones of the pending contracts are dashed
+func writeDOTGraph(output io.Writer, graph brokerclient.Graph) {
+	fmt.Fprintln(output, "digraph deal {")
+	fmt.Fprintln(output, "  rankdir=LR;")
+	fmt.Fprintln(output, "  node [shape=box];")
+	for _, pacticipant := range graph.Pacticipants {
+		fmt.Fprintf(output, "  %s;\n", dotQuote(pacticipant))
+	}
+	for _, dependency := range graph.Dependencies {
+		attributes := "label=" + dotQuote(strings.Join(dependency.Methods, "\n"))
+		if dependency.Pending {
+			attributes += ", style=dashed"
+		}
+		fmt.Fprintf(
+			output, "  %s -> %s [%s];\n",
+			dotQuote(dependency.Consumer), dotQuote(dependency.Provider), attributes,
+		)
+	}
+	fmt.Fprintln(output, "}")
+}
+
+// dotQuote returns the DOT string of the value, the newlines break the lines of the labels
+func dotQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+// pactSource is the only source the contracts are imported from
+const pactSource = "pact"
+
+var importCommand = command{
+	name:  "import",
+	usage: "import [-from pact] [-descriptor-set <file>] [-name <name>] [-o <file>] <pact file>",
+	description: "Converts the gRPC interactions of a Pact file into the cases of a contract, " +
+		"easing the migration from Pact",
+}
+
+func init() {
+	importCommand.run = runImport
+}
+
+// runImport writes the imported contract, the skipped interactions are reported to the
+// standard error
+func runImport(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&importCommand, stderr)
+	from := flags.String("from", pactSource, "Format of the imported file, only pact is supported")
+	descriptorSetPath := flags.String(
+		"descriptor-set", "",
+		"Descriptor set decoding the messages, the descriptors of the pact file by default",
+	)
+	name := flags.String("name", "", "Name of the contract, consumer-provider by default")
+	output := flags.String(
+		"o", "", "File receiving the imported contract, the standard output by default",
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *from != pactSource {
+		return fmt.Errorf("invalid source %q, expected %s", *from, pactSource)
+	}
+	if flags.NArg() != 1 {
+		return errors.New("a pact file must be provided")
+	}
+
+	var files *protoregistry.Files
+	if *descriptorSetPath != "" {
+		var err error
+		if files, err = processors.ReadDescriptorSet(*descriptorSetPath); err != nil {
+			return err
+		}
+	}
+
+	data, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	contract, issues, err := processors.ImportPactContract(data, files)
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(stderr, "warning: %s\n", issue)
+	}
+
+	if *name != "" {
+		contract.Name = *name
+	}
+
+	return writeContract(*output, stdout, contract)
+}
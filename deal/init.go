@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var initCommand = command{
+	name: "init",
+	usage: "init -descriptor-set <file> [-services <service>,...] [-name <name>] [-o <file>] " +
+		"[-force]",
+	description: "Generates a skeleton contract with a success and a failure case per method",
+}
+
+func init() {
+	initCommand.run = runInit
+}
+
+// runInit writes the skeleton contract, an existing contract file is only overwritten
+// with the -force flag
+func runInit(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&initCommand, stderr)
+	descriptorSetPath := flags.String(
+		"descriptor-set", "", "Descriptor set (protoc --descriptor_set_out) or buf image",
+	)
+	services := flags.String(
+		"services", "", "Comma separated services of the contract, every service by default",
+	)
+	name := flags.String("name", "", "Name of the contract")
+	output := flags.String(
+		"o", "", "File receiving the skeleton contract, the standard output by default",
+	)
+	force := flags.Bool("force", false, "Overwrite the output file when it exists")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *descriptorSetPath == "" {
+		return errors.New("the -descriptor-set flag must be provided")
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+	if *output != "" && !*force {
+		if _, err := os.Stat(*output); err == nil {
+			return fmt.Errorf("%s already exists, use -force to overwrite it", *output)
+		}
+	}
+
+	files, err := processors.ReadDescriptorSet(*descriptorSetPath)
+	if err != nil {
+		return err
+	}
+
+	serviceKeys := make([]string, 0)
+	for _, service := range strings.Split(*services, ",") {
+		if service = strings.TrimSpace(service); service != "" {
+			serviceKeys = append(serviceKeys, service)
+		}
+	}
+
+	contract, err := processors.ScaffoldContract(files, serviceKeys...)
+	if err != nil {
+		return err
+	}
+	contract.Name = *name
+
+	return writeContract(*output, stdout, contract)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var listCommand = command{
+	name:        "list",
+	usage:       "list [-format table|json] <contract file>",
+	description: "Prints the services and methods of a contract along with their number of cases",
+}
+
+var statsCommand = command{
+	name:        "stats",
+	usage:       "stats [-format table|json] <contract file>",
+	description: "Prints aggregate statistics of a contract, e.g. the cases per method",
+}
+
+func init() {
+	listCommand.run = runList
+	statsCommand.run = runStats
+}
+
+func runList(args []string, stdout, stderr io.Writer) error {
+	contractPath, format, err := parseInspectArgs(&listCommand, args, stderr)
+	if err != nil {
+		return err
+	}
+
+	contract, err := processors.ReadContractFile(contractPath)
+	if err != nil {
+		return err
+	}
+
+	methods := processors.CountMethodCases(contract)
+	if format == jsonFormat {
+		return writeJSON(stdout, struct {
+			Methods []processors.MethodCaseCounts `json:"methods"`
+		}{Methods: methods})
+	}
+
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0) //nolint:revive // the column padding
+	fmt.Fprintln(table, "SERVICE\tMETHOD\tSUCCESS\tAPPLICATION ERROR\tFAILURE\tTOTAL")
+	for _, method := range methods {
+		fmt.Fprintf(
+			table, "%s\t%s\t%d\t%d\t%d\t%d\n",
+			method.Service, method.Method, method.SuccessCases,
+			method.ApplicationErrorCases, method.FailureCases, method.Cases(),
+		)
+	}
+
+	return table.Flush()
+}
+
+func runStats(args []string, stdout, stderr io.Writer) error {
+	contractPath, format, err := parseInspectArgs(&statsCommand, args, stderr)
+	if err != nil {
+		return err
+	}
+
+	contract, err := processors.ReadContractFile(contractPath)
+	if err != nil {
+		return err
+	}
+
+	stats := processors.ComputeContractStats(contract)
+	if format == jsonFormat {
+		return writeJSON(stdout, stats)
+	}
+
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0) //nolint:revive // the column padding
+	for _, row := range []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "Services", value: stats.Services},
+		{name: "Methods", value: stats.Methods},
+		{name: "Cases", value: stats.Cases},
+		{name: "Success cases", value: stats.SuccessCases},
+		{name: "Application error cases", value: stats.ApplicationErrorCases},
+		{name: "Failure cases", value: stats.FailureCases},
+		{name: "Cases per method (min)", value: stats.MinCasesPerMethod},
+		{name: "Cases per method (max)", value: stats.MaxCasesPerMethod},
+		{name: "Cases per method (average)", value: fmt.Sprintf("%.2f", stats.AverageCasesPerMethod)},
+		{name: "Failures per success", value: fmt.Sprintf("%.2f", stats.FailureRatio)},
+	} {
+		fmt.Fprintf(table, "%s:\t%v\n", row.name, row.value)
+	}
+
+	return table.Flush()
+}
+
+// parseInspectArgs returns the contract file and the output format of the inspection commands
+func parseInspectArgs(command *command, args []string, stderr io.Writer) (string, string, error) {
+	flags := newFlagSet(command, stderr)
+	format := flags.String("format", tableFormat, "Output format: table or json")
+	if err := flags.Parse(args); err != nil {
+		return "", "", err
+	}
+
+	if err := validateFormat(*format, tableFormat, jsonFormat); err != nil {
+		return "", "", err
+	}
+	if flags.NArg() != 1 {
+		return "", "", errors.New("a contract file must be provided")
+	}
+
+	return flags.Arg(0), *format, nil
+}
@@ -0,0 +1,207 @@
+// Command deal works with the contract files without generating code, e.g. `deal validate`
+// checks a contract against the compiled proto files.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/faunists/deal-go/brokerclient"
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+// The output formats of the commands
+const (
+	textFormat     = "text"
+	tableFormat    = "table"
+	jsonFormat     = "json"
+	markdownFormat = "markdown"
+	htmlFormat     = "html"
+	dotFormat      = "dot"
+)
+
+// brokerTokenVariable holds the token of the broker commands run without the -token flag
+const brokerTokenVariable = "DEAL_BROKER_TOKEN"
+
+// errIssuesFound makes the command exit with a failure once its issues are reported
+var errIssuesFound = errors.New("issues found")
+
+// command is a subcommand of the CLI, it receives the arguments following its name
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(args []string, stdout, stderr io.Writer) error
+}
+
+// commands are listed in the usage in the same order
+var commands = []*command{
+	&validateCommand,
+	&coverageCommand,
+	&diffCommand,
+	&breakingCommand,
+	&mergeCommand,
+	&fmtCommand,
+	&convertCommand,
+	&importCommand,
+	&initCommand,
+	&generateCommand,
+	&listCommand,
+	&statsCommand,
+	&docsCommand,
+	&mockServerCommand,
+	&recordCommand,
+	&verifyCommand,
+	&publishCommand,
+	&tagCommand,
+	&canIDeployCommand,
+	&matrixCommand,
+	&graphCommand,
+	&recordDeploymentCommand,
+	&brokerCommand,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command of the arguments, returning the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2 //nolint:revive // the exit code of the usage errors
+	}
+	if isHelp(args[0]) || args[0] == "help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	for _, command := range commands {
+		if command.name != args[0] {
+			continue
+		}
+
+		err := command.run(args[1:], stdout, stderr)
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return 0
+		case !errors.Is(err, errIssuesFound):
+			fmt.Fprintf(stderr, "deal %s: %v\n", command.name, err)
+		}
+		return 1
+	}
+
+	fmt.Fprintf(stderr, "deal: unknown command %q\n\n", args[0])
+	printUsage(stderr)
+	return 2 //nolint:revive // the exit code of the usage errors
+}
+
+// isHelp tells if the argument asks for the usage, like the flag package does
+func isHelp(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+func printUsage(output io.Writer) {
+	fmt.Fprintln(output, "Usage: deal <command> [arguments]")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Commands:")
+	nameWidth := 0
+	for _, command := range commands {
+		if len(command.name) > nameWidth {
+			nameWidth = len(command.name)
+		}
+	}
+	for _, command := range commands {
+		fmt.Fprintf(output, "  %-*s %s\n", nameWidth, command.name, command.description)
+	}
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, `Run "deal <command> -h" for the arguments of a command.`)
+}
+
+// newFlagSet creates the flags of the command, the usage errors are printed to the standard error
+func newFlagSet(command *command, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(command.name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deal %s\n\n%s\n\n", command.usage, command.description)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// writeContract writes the contract to the file, encoded by the encoder of its extension,
+// or as JSON to the standard output when no file is given
+func writeContract(outputPath string, stdout io.Writer, contract entities.Contract) error {
+	data, err := processors.ContractEncoderFor(outputPath)(contract)
+	if err != nil {
+		return err
+	}
+
+	if outputPath == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+
+	return ioutil.WriteFile(outputPath, data, 0o644) //nolint:gosec // the contracts aren't secret
+}
+
+// validateFormat verifies the output format is one of the formats supported by the command
+func validateFormat(format string, formats ...string) error {
+	for _, supportedFormat := range formats {
+		if format == supportedFormat {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid format %q, expected one of %v", format, formats)
+}
+
+// writeJSON writes the value as indented JSON
+func writeJSON(output io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+// splitList returns the values of a comma separated flag, without the empty ones
+func splitList(value string) []string {
+	values := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+
+	return values
+}
+
+// brokerTokenFlag adds the -token flag of the commands talking to a broker, its default isn't
+// printed by the usage
+func brokerTokenFlag(flags *flag.FlagSet) *string {
+	return flags.String(
+		"token", "", "Token of the contract broker, $"+brokerTokenVariable+" by default",
+	)
+}
+
+// newBrokerClient returns a client of the broker, authenticated with the token when one is given
+func newBrokerClient(brokerURL, token string) (*brokerclient.Client, error) {
+	if token == "" {
+		token = os.Getenv(brokerTokenVariable)
+	}
+
+	opts := make([]brokerclient.ClientOption, 0)
+	if token != "" {
+		opts = append(opts, brokerclient.WithToken(token))
+	}
+
+	return brokerclient.NewClient(brokerURL, opts...)
+}
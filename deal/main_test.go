@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/faunists/deal-go/broker"
+)
+
+// The contract and the descriptor set of the code generation fixture
+var (
+	fixtureDescriptorSet = filepath.Join("..", "protoc-gen-go-deal", "testdata", "fixture.protoset")
+	fixtureContract      = filepath.Join("..", "protoc-gen-go-deal", "testdata", "contract.json")
+)
+
+// runTest is a run of the CLI along with what its outputs contain, an empty output isn't checked
+type runTest struct {
+	name           string
+	args           []string
+	expectedCode   int
+	expectedStdout string
+	expectedStderr string
+}
+
+func (test runTest) check(t *testing.T) {
+	t.Helper()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := run(test.args, stdout, stderr)
+
+	if code != test.expectedCode {
+		t.Errorf("Given: %d, expected: %d (stderr: %s)", code, test.expectedCode, stderr)
+	}
+	if !strings.Contains(stdout.String(), test.expectedStdout) {
+		t.Errorf("Given: %q, expected: %q in the output", stdout, test.expectedStdout)
+	}
+	if !strings.Contains(stderr.String(), test.expectedStderr) {
+		t.Errorf("Given: %q, expected: %q in the errors", stderr, test.expectedStderr)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	tests := []runTest{
+		{
+			name:           "no command",
+			args:           []string{},
+			expectedCode:   2,
+			expectedStderr: "Usage: deal <command> [arguments]",
+		},
+		{
+			name:           "help",
+			args:           []string{"help"},
+			expectedCode:   0,
+			expectedStdout: "Usage: deal <command> [arguments]",
+		},
+		{
+			name:           "help flag",
+			args:           []string{"-h"},
+			expectedCode:   0,
+			expectedStdout: "can-i-deploy",
+		},
+		{
+			name:           "unknown command",
+			args:           []string{"deploy"},
+			expectedCode:   2,
+			expectedStderr: `deal: unknown command "deploy"`,
+		},
+		{
+			name:           "unknown flag",
+			args:           []string{"validate", "-bogus"},
+			expectedCode:   1,
+			expectedStderr: "flag provided but not defined: -bogus",
+		},
+		{
+			name:         "valid contract",
+			args:         []string{"validate", "-descriptor-set", fixtureDescriptorSet, fixtureContract},
+			expectedCode: 0,
+		},
+		{
+			name:           "validate without descriptor set",
+			args:           []string{"validate", fixtureContract},
+			expectedCode:   1,
+			expectedStderr: "deal validate: the -descriptor-set flag must be provided",
+		},
+		{
+			name:           "coverage",
+			args:           []string{"coverage", "-descriptor-set", fixtureDescriptorSet, fixtureContract},
+			expectedCode:   0,
+			expectedStdout: "coverage: 66.7% (4/6 methods)",
+		},
+		{
+			name: "coverage below the threshold",
+			args: []string{
+				"coverage", "-descriptor-set", fixtureDescriptorSet, "-threshold", "90",
+				fixtureContract,
+			},
+			expectedCode:   1,
+			expectedStderr: "coverage 66.7% is below the threshold of 90.0%",
+		},
+		{
+			name:           "identical contracts diff",
+			args:           []string{"diff", fixtureContract, fixtureContract},
+			expectedCode:   0,
+			expectedStdout: "0 added, 0 removed, 0 changed",
+		},
+		{
+			name:           "diff without revision",
+			args:           []string{"diff", fixtureContract},
+			expectedCode:   1,
+			expectedStderr: "deal diff: the base and the revision contract files must be provided",
+		},
+		{
+			name:         "identical contracts aren't breaking",
+			args:         []string{"breaking", fixtureContract, fixtureContract},
+			expectedCode: 0,
+		},
+		{
+			name:           "merged contracts",
+			args:           []string{"merge", "-strategy", "first", fixtureContract, fixtureContract},
+			expectedCode:   0,
+			expectedStdout: `"name": "fixture"`,
+		},
+		{
+			name:           "unformatted contract",
+			args:           []string{"fmt", "-l", fixtureContract},
+			expectedCode:   1,
+			expectedStdout: fixtureContract,
+		},
+		{
+			name:           "converted contract",
+			args:           []string{"convert", "-to", "yaml", fixtureContract},
+			expectedCode:   0,
+			expectedStdout: "name: fixture",
+		},
+		{
+			name:           "conversion to an unknown format",
+			args:           []string{"convert", "-to", "xml", fixtureContract},
+			expectedCode:   1,
+			expectedStderr: "deal convert: no encoder of the .xml contracts",
+		},
+		{
+			name:           "import without pact file",
+			args:           []string{"import"},
+			expectedCode:   1,
+			expectedStderr: "deal import: a pact file must be provided",
+		},
+		{
+			name: "skeleton contract",
+			args: []string{
+				"init", "-descriptor-set", fixtureDescriptorSet, "-services",
+				"fixture.v1.StockService", "-name", "stock",
+			},
+			expectedCode:   0,
+			expectedStdout: `"fixture.v1.StockService"`,
+		},
+		{
+			name:           "skeleton contract written to a file",
+			args:           []string{"init", "-descriptor-set", fixtureDescriptorSet, "-o", fixtureContract},
+			expectedCode:   1,
+			expectedStderr: "already exists, use -force to overwrite it",
+		},
+		{
+			name:           "generate without project file",
+			args:           []string{"generate", "-config", filepath.Join(outputDir, "deal.yaml")},
+			expectedCode:   1,
+			expectedStderr: "deal generate: ",
+		},
+		{
+			name:           "listed methods",
+			args:           []string{"list", fixtureContract},
+			expectedCode:   0,
+			expectedStdout: "GetStock",
+		},
+		{
+			name:           "stats",
+			args:           []string{"stats", fixtureContract},
+			expectedCode:   0,
+			expectedStdout: "Services:",
+		},
+		{
+			name:           "docs",
+			args:           []string{"docs", fixtureContract},
+			expectedCode:   0,
+			expectedStdout: "# CatalogService",
+		},
+		{
+			name:           "docs in an unknown format",
+			args:           []string{"docs", "-format", "pdf", fixtureContract},
+			expectedCode:   1,
+			expectedStderr: `deal docs: invalid format "pdf"`,
+		},
+		{
+			name:           "mock server without descriptor set",
+			args:           []string{"mock-server", fixtureContract},
+			expectedCode:   1,
+			expectedStderr: "deal mock-server: the -descriptor-set flag must be provided",
+		},
+		{
+			name:           "record without descriptor set",
+			args:           []string{"record", "-target", "localhost:50051"},
+			expectedCode:   1,
+			expectedStderr: "deal record: the -descriptor-set flag must be provided",
+		},
+		{
+			name:           "verify without target",
+			args:           []string{"verify", fixtureContract},
+			expectedCode:   1,
+			expectedStderr: "deal verify: the -target flag must be provided",
+		},
+		{
+			name:           "publish without broker",
+			args:           []string{"publish", "-consumer", "web", "-version", "1", fixtureContract},
+			expectedCode:   1,
+			expectedStderr: "deal publish: the -broker flag must be provided",
+		},
+		{
+			name:           "broker without subcommand",
+			args:           []string{"broker"},
+			expectedCode:   1,
+			expectedStderr: "deal broker: missing subcommand, expected serve",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			test.check(t)
+		})
+	}
+}
+
+func TestRunPrintsTheUsageOfTheCommands(t *testing.T) {
+	t.Parallel()
+
+	for _, command := range commands {
+		test := runTest{
+			name:           command.name,
+			args:           []string{command.name, "-h"},
+			expectedCode:   0,
+			expectedStderr: "Usage: deal " + command.usage + "\n\n" + command.description,
+		}
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			test.check(t)
+		})
+	}
+}
+
+func TestRunBrokerCommands(t *testing.T) {
+	t.Parallel()
+
+	contractBroker, err := broker.NewBroker("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server := httptest.NewServer(broker.NewHTTPHandler(contractBroker))
+	t.Cleanup(server.Close)
+
+	// The commands depend on the versions published by the previous ones, they run in order
+	tests := []runTest{
+		{
+			name: "published contract",
+			args: []string{
+				"publish", "-broker", server.URL, "-consumer", "web", "-version", "1",
+				fixtureContract,
+			},
+			expectedCode:   0,
+			expectedStdout: "published the contract of web 1 with fixture",
+		},
+		{
+			name: "tagged version",
+			args: []string{
+				"tag", "-broker", server.URL, "-pacticipant", "web", "-version", "1", "main",
+			},
+			expectedCode:   0,
+			expectedStdout: "tagged web 1 with main",
+		},
+		{
+			name: "recorded deployment",
+			args: []string{
+				"record-deployment", "-broker", server.URL, "-pacticipant", "web", "-version", "1",
+				"-environment", "production",
+			},
+			expectedCode:   0,
+			expectedStdout: "recorded the deployment of web 1 to production",
+		},
+		{
+			name: "unverified contract can't be deployed",
+			args: []string{
+				"can-i-deploy", "-broker", server.URL, "-consumer", "web", "-consumer-version", "1",
+				"-provider", "fixture", "-to", "production",
+			},
+			expectedCode:   1,
+			expectedStdout: "can I deploy? no",
+		},
+		{
+			name:           "matrix",
+			args:           []string{"matrix", "-broker", server.URL, "-consumer", "web"},
+			expectedCode:   0,
+			expectedStdout: "web",
+		},
+		{
+			name:           "graph",
+			args:           []string{"graph", "-broker", server.URL},
+			expectedCode:   0,
+			expectedStdout: "web -> fixture",
+		},
+		{
+			name:           "graph in an unknown format",
+			args:           []string{"graph", "-broker", server.URL, "-format", "svg"},
+			expectedCode:   1,
+			expectedStderr: `deal graph: invalid format "svg"`,
+		},
+	}
+
+	for _, test := range tests {
+		if !t.Run(test.name, test.check) {
+			return
+		}
+	}
+}
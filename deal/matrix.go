@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/faunists/deal-go/brokerclient"
+)
+
+var matrixCommand = command{
+	name: "matrix",
+	usage: "matrix -broker <url> [-token <token>] [-consumer <name>] " +
+		"[-consumer-version <version>] [-provider <name>] [-provider-version <version>] " +
+		"[-to <environment>] [-limit <count>] [-format text|json]",
+	description: "Shows which consumer and provider versions verified their contracts, as a grid " +
+		"of consumer versions and provider versions",
+}
+
+func init() {
+	matrixCommand.run = runMatrix
+}
+
+// runMatrix prints a grid for each consumer and provider pair, the consumer versions are the
+// rows and the provider versions which verified their contracts are the columns
+func runMatrix(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&matrixCommand, stderr)
+	brokerURL := flags.String("broker", "", "URL of the contract broker")
+	token := brokerTokenFlag(flags)
+	consumer := flags.String("consumer", "", "Name of the consumer, every consumer by default")
+	consumerVersion := flags.String("consumer-version", "", "Version of the consumer")
+	provider := flags.String("provider", "", "Name of the provider, every provider by default")
+	providerVersion := flags.String("provider-version", "", "Version of the provider")
+	environment := flags.String(
+		"to", "", "Environment whose deployed versions replace the missing versions",
+	)
+	limit := flags.Int("limit", 0, "Number of the latest contracts shown, every contract when 0")
+	format := flags.String("format", textFormat, "Output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format, textFormat, jsonFormat); err != nil {
+		return err
+	}
+	if *brokerURL == "" {
+		return errors.New("the -broker flag must be provided")
+	}
+	if *limit < 0 {
+		return errors.New("the -limit flag can't be negative")
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	client, err := newBrokerClient(*brokerURL, *token)
+	if err != nil {
+		return err
+	}
+
+	rows, err := client.Matrix(context.Background(), brokerclient.MatrixQuery{
+		Consumer:        *consumer,
+		ConsumerVersion: *consumerVersion,
+		Provider:        *provider,
+		ProviderVersion: *providerVersion,
+		Environment:     *environment,
+		Limit:           *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *format == jsonFormat {
+		return writeJSON(stdout, struct {
+			Rows []brokerclient.MatrixRow `json:"rows"`
+		}{Rows: rows})
+	}
+
+	return writeMatrix(stdout, rows)
+}
+
+// matrixGrid is the matrix of a consumer and a provider, the versions are kept in the order of
+// the rows
+type matrixGrid struct {
+	consumer         string
+	provider         string
+	consumerVersions []string
+	providerVersions []string
+	verifications    map[[2]string]string
+}
+
+func writeMatrix(output io.Writer, rows []brokerclient.MatrixRow) error {
+	if len(rows) == 0 {
+		fmt.Fprintln(output, "no contract found")
+		return nil
+	}
+
+	grids := make([]*matrixGrid, 0)
+	gridsByPair := make(map[[2]string]*matrixGrid)
+	for _, row := range rows {
+		pair := [2]string{row.Consumer, row.Provider}
+		grid, exists := gridsByPair[pair]
+		if !exists {
+			grid = &matrixGrid{
+				consumer:      row.Consumer,
+				provider:      row.Provider,
+				verifications: make(map[[2]string]string),
+			}
+			gridsByPair[pair] = grid
+			grids = append(grids, grid)
+		}
+
+		grid.consumerVersions = appendMissing(grid.consumerVersions, row.ConsumerVersion)
+		if row.ProviderVersion == "" {
+			continue
+		}
+		grid.providerVersions = appendMissing(grid.providerVersions, row.ProviderVersion)
+
+		verification := "missing"
+		if row.Success != nil && *row.Success {
+			verification = "success"
+		} else if row.Success != nil {
+			verification = "failure"
+		}
+		grid.verifications[[2]string{row.ConsumerVersion, row.ProviderVersion}] = verification
+	}
+
+	for index, grid := range grids {
+		if index > 0 {
+			fmt.Fprintln(output)
+		}
+		if err := grid.write(output); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// write prints the consumer versions against the provider versions, the versions without
+// verification are marked with a dash
+func (g *matrixGrid) write(output io.Writer) error {
+	fmt.Fprintf(output, "CONSUMER %s, PROVIDER %s\n", g.consumer, g.provider)
+	if len(g.providerVersions) == 0 {
+		fmt.Fprintf(
+			output, "no version of %s verified the contracts of %s\n", g.provider,
+			strings.Join(g.consumerVersions, ", "),
+		)
+		return nil
+	}
+
+	table := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0) //nolint:revive // the column padding
+	fmt.Fprintf(table, "VERSION\t%s\n", strings.Join(g.providerVersions, "\t"))
+	for _, consumerVersion := range g.consumerVersions {
+		cells := make([]string, 0, len(g.providerVersions))
+		for _, providerVersion := range g.providerVersions {
+			verification, exists := g.verifications[[2]string{consumerVersion, providerVersion}]
+			if !exists {
+				verification = "-"
+			}
+			cells = append(cells, verification)
+		}
+		fmt.Fprintf(table, "%s\t%s\n", consumerVersion, strings.Join(cells, "\t"))
+	}
+
+	return table.Flush()
+}
+
+func appendMissing(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var mergeCommand = command{
+	name:  "merge",
+	usage: "merge [-strategy fail|first|last] [-name <name>] [-o <file>] <contract file>...",
+	description: "Combines contract fragments into a single contract, " +
+		"the cases of the same request conflict",
+}
+
+func init() {
+	mergeCommand.run = runMerge
+}
+
+// runMerge writes the merged contract, the conflicts resolved by the strategy are
+// reported to the standard error
+func runMerge(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&mergeCommand, stderr)
+	strategy := flags.String(
+		"strategy", string(processors.MergeFail), "Resolution of the conflicts: fail, first or last",
+	)
+	name := flags.String("name", "", "Name of the merged contract, the first fragment one by default")
+	output := flags.String(
+		"o", "", "File receiving the merged contract, the standard output by default",
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("at least one contract file must be provided")
+	}
+
+	fragments := make([]processors.ContractFragment, 0, flags.NArg())
+	for _, contractPath := range flags.Args() {
+		contract, err := processors.ReadContractFile(contractPath)
+		if err != nil {
+			return err
+		}
+		fragments = append(
+			fragments, processors.ContractFragment{Source: contractPath, Contract: contract},
+		)
+	}
+
+	merged, conflicts, err := processors.MergeContracts(
+		processors.MergeStrategy(*strategy), fragments...,
+	)
+	for _, conflict := range conflicts {
+		fmt.Fprintf(stderr, "conflict: %s\n", conflict)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *name != "" {
+		merged.Name = *name
+	}
+
+	return writeContract(*output, stdout, merged)
+}
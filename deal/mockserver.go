@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+var mockServerCommand = command{
+	name: "mock-server",
+	usage: "mock-server -descriptor-set <file> [-addr host:port] [-fake-seed seed] " +
+		"<contract file>",
+	description: "Serves the cases of a contract as a gRPC server, without generating any code",
+}
+
+func init() {
+	mockServerCommand.run = runMockServer
+}
+
+// runMockServer serves the contract until the process is interrupted, every call is logged
+// to the standard error with the case answering it
+func runMockServer(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&mockServerCommand, stderr)
+	descriptorSetPath := flags.String(
+		"descriptor-set", "", "Descriptor set (protoc --descriptor_set_out) or buf image",
+	)
+	address := flags.String("addr", ":50051", "Address the server listens on")
+	fakeSeed := flags.Int64("fake-seed", 0, "Seed used to generate the fake values")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *descriptorSetPath == "" {
+		return errors.New("the -descriptor-set flag must be provided")
+	}
+	if flags.NArg() != 1 {
+		return errors.New("a contract file must be provided")
+	}
+
+	files, err := processors.ReadDescriptorSet(*descriptorSetPath)
+	if err != nil {
+		return err
+	}
+	contract, err := processors.ReadContractFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	mock, err := processors.NewContractMock(contract, files, *fakeSeed)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		return err
+	}
+
+	server := grpc.NewServer()
+	registerMockServices(server, mock, &callLogger{output: stderr})
+	registerReflection(server, files)
+
+	for _, method := range mock.Methods() {
+		fmt.Fprintf(stdout, "serving %s\n", processors.MockFullMethod(method))
+	}
+	fmt.Fprintf(stdout, "listening on %s\n", listener.Addr())
+
+	return serveUntilStopped(server, listener)
+}
+
+// registerReflection serves the gRPC reflection, e.g. for grpcurl. The reflection reads the
+// descriptors of the global registry, so the files of the descriptor set are registered there,
+// except the ones declaring names already registered, e.g. the well-known types.
+func registerReflection(server *grpc.Server, files *protoregistry.Files) {
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		if !isRegisteredGlobally(file) {
+			// The names were checked, so the registration can't conflict
+			_ = protoregistry.GlobalFiles.RegisterFile(file)
+		}
+		return true
+	})
+
+	reflection.Register(server)
+}
+
+// isRegisteredGlobally tells if the file or any of its top-level declarations is already in the
+// global registry, which panics on conflicts
+func isRegisteredGlobally(file protoreflect.FileDescriptor) bool {
+	if _, err := protoregistry.GlobalFiles.FindFileByPath(file.Path()); err == nil {
+		return true
+	}
+
+	names := make([]protoreflect.FullName, 0)
+	for i := 0; i < file.Messages().Len(); i++ {
+		names = append(names, file.Messages().Get(i).FullName())
+	}
+	for i := 0; i < file.Enums().Len(); i++ {
+		names = append(names, file.Enums().Get(i).FullName())
+	}
+	for i := 0; i < file.Extensions().Len(); i++ {
+		names = append(names, file.Extensions().Get(i).FullName())
+	}
+	for i := 0; i < file.Services().Len(); i++ {
+		names = append(names, file.Services().Get(i).FullName())
+	}
+	for _, name := range names {
+		if _, err := protoregistry.GlobalFiles.FindDescriptorByName(name); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serveUntilStopped serves until the process is interrupted or terminated, the calls being
+// handled are completed before returning
+func serveUntilStopped(server *grpc.Server, listener net.Listener) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		server.GracefulStop()
+	}()
+
+	return server.Serve(listener)
+}
+
+// callLogger logs the calls, the handlers run concurrently
+type callLogger struct {
+	mu     sync.Mutex
+	output io.Writer
+}
+
+func (l *callLogger) log(fullMethod, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fmt.Fprintf(l.output, "%s %s: %s\n", time.Now().Format(time.RFC3339), fullMethod, message)
+}
+
+// registerMockServices registers a service descriptor for every service of the contract,
+// the requests are decoded as dynamic messages of the method input
+func registerMockServices(server *grpc.Server, mock *processors.ContractMock, logger *callLogger) {
+	services := make(map[protoreflect.FullName]*grpc.ServiceDesc)
+	order := make([]protoreflect.FullName, 0)
+	for _, method := range mock.Methods() {
+		serviceName := method.Parent().FullName()
+		if services[serviceName] == nil {
+			services[serviceName] = &grpc.ServiceDesc{
+				ServiceName: string(serviceName),
+				Metadata:    method.ParentFile().Path(),
+			}
+			order = append(order, serviceName)
+		}
+
+		services[serviceName].Methods = append(services[serviceName].Methods, grpc.MethodDesc{
+			MethodName: string(method.Name()),
+			Handler:    mockMethodHandler(mock, method, logger),
+		})
+	}
+
+	// Without a service implementation the handlers aren't checked against a service interface
+	for _, serviceName := range order {
+		server.RegisterService(services[serviceName], nil)
+	}
+}
+
+func mockMethodHandler(
+	mock *processors.ContractMock,
+	method protoreflect.MethodDescriptor,
+	logger *callLogger,
+) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (
+	interface{}, error,
+) {
+	fullMethod := processors.MockFullMethod(method)
+	handle := func(ctx context.Context, request interface{}) (interface{}, error) {
+		result, matched := mock.Respond(fullMethod, request.(*dynamicpb.Message))
+		if !matched {
+			logger.log(fullMethod, "no contract case matches the request")
+			return nil, status.Errorf(
+				codes.Unimplemented, "no contract case matches the %s request", method.Name(),
+			)
+		}
+		logger.log(fullMethod, result.Case)
+
+		if result.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, status.FromContextError(ctx.Err()).Err()
+			case <-time.After(result.Delay):
+			}
+		}
+
+		if len(result.Headers) > 0 {
+			if err := grpc.SetHeader(ctx, mockMetadata(result.Headers)); err != nil {
+				return nil, err
+			}
+		}
+		if len(result.Trailers) > 0 {
+			if err := grpc.SetTrailer(ctx, mockMetadata(result.Trailers)); err != nil {
+				return nil, err
+			}
+		}
+
+		if result.Error != nil {
+			return nil, mockError(*result.Error)
+		}
+
+		return result.Response, nil
+	}
+
+	return func(
+		_ interface{},
+		ctx context.Context,
+		decode func(interface{}) error,
+		interceptor grpc.UnaryServerInterceptor,
+	) (interface{}, error) {
+		request := dynamicpb.NewMessage(method.Input())
+		if err := decode(request); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return handle(ctx, request)
+		}
+
+		info := &grpc.UnaryServerInfo{FullMethod: fullMethod}
+		return interceptor(ctx, request, info, handle)
+	}
+}
+
+// mockMetadata returns the gRPC metadata of the contract metadata
+func mockMetadata(values entities.Metadata) metadata.MD {
+	md := make(metadata.MD, len(values))
+	for key, keyValues := range values {
+		md.Append(key, keyValues...)
+	}
+
+	return md
+}
+
+// mockError returns the status of the contract error, with its details
+func mockError(grpcError entities.GRPCError) error {
+	// The codes were verified when the contract was loaded
+	code, _ := processors.ErrorCodeNumber(string(grpcError.ErrorCode))
+	errorStatus := status.New(codes.Code(code), grpcError.Message)
+
+	details, err := statusDetails(grpcError.Details)
+	if err != nil {
+		return err
+	}
+	if len(details) == 0 {
+		return errorStatus.Err()
+	}
+
+	errorStatus, err = errorStatus.WithDetails(details...)
+	if err != nil {
+		return err
+	}
+
+	return errorStatus.Err()
+}
+
+// statusDetails returns the messages of the error details, in the same order as the
+// generated servers: BadRequest, PreconditionFailure, ErrorInfo, QuotaFailure and RetryInfo
+func statusDetails(details *entities.ErrorDetails) ([]protoiface.MessageV1, error) {
+	if details.IsEmpty() {
+		return nil, nil
+	}
+
+	messages := make([]protoiface.MessageV1, 0)
+	if len(details.BadRequest) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range details.BadRequest {
+			badRequest.FieldViolations = append(
+				badRequest.FieldViolations,
+				&errdetails.BadRequest_FieldViolation{
+					Field: violation.Field, Description: violation.Description,
+				},
+			)
+		}
+		messages = append(messages, badRequest)
+	}
+
+	if len(details.PreconditionFailure) > 0 {
+		preconditionFailure := &errdetails.PreconditionFailure{}
+		for _, violation := range details.PreconditionFailure {
+			preconditionFailure.Violations = append(
+				preconditionFailure.Violations,
+				&errdetails.PreconditionFailure_Violation{
+					Type: violation.Type, Subject: violation.Subject, Description: violation.Description,
+				},
+			)
+		}
+		messages = append(messages, preconditionFailure)
+	}
+
+	if details.ErrorInfo != nil {
+		messages = append(messages, &errdetails.ErrorInfo{
+			Reason:   details.ErrorInfo.Reason,
+			Domain:   details.ErrorInfo.Domain,
+			Metadata: details.ErrorInfo.Metadata,
+		})
+	}
+
+	if len(details.QuotaFailure) > 0 {
+		quotaFailure := &errdetails.QuotaFailure{}
+		for _, violation := range details.QuotaFailure {
+			quotaFailure.Violations = append(
+				quotaFailure.Violations,
+				&errdetails.QuotaFailure_Violation{
+					Subject: violation.Subject, Description: violation.Description,
+				},
+			)
+		}
+		messages = append(messages, quotaFailure)
+	}
+
+	if details.RetryDelay != "" {
+		retryDelay, err := processors.ParseDuration(details.RetryDelay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry delay: %w", err)
+		}
+		messages = append(messages, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)})
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/faunists/deal-go/brokerclient"
+	"github.com/faunists/deal-go/processors"
+)
+
+var publishCommand = command{
+	name: "publish",
+	usage: "publish -broker <url> [-token <token>] -consumer <name> -version <version> " +
+		"[-provider <name>] [-branch <branch>] [-commit <sha>] [-semver <version>] " +
+		"[-tags <tag>,...] <contract file>",
+	description: "Uploads a contract to a contract broker, along with the consumer version " +
+		"expecting it",
+}
+
+func init() {
+	publishCommand.run = runPublish
+}
+
+// runPublish sends the contract file to the broker, the provider is the name of the contract
+// unless the -provider flag is given
+func runPublish(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&publishCommand, stderr)
+	brokerURL := flags.String("broker", "", "URL of the contract broker")
+	token := brokerTokenFlag(flags)
+	consumer := flags.String("consumer", "", "Name of the consumer expecting the contract")
+	version := flags.String("version", "", "Version of the consumer, e.g. a git SHA")
+	provider := flags.String(
+		"provider", "", "Name of the provider of the contract, the contract name by default",
+	)
+	branch := flags.String("branch", "", "Branch of the consumer version")
+	commit := flags.String("commit", "", "Git commit of the consumer version")
+	semver := flags.String("semver", "", "Semantic version of the consumer version, e.g. 1.2.0")
+	tags := flags.String("tags", "", "Comma separated tags of the consumer version, e.g. main")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *brokerURL == "" {
+		return errors.New("the -broker flag must be provided")
+	}
+	if *consumer == "" || *version == "" {
+		return errors.New("the -consumer and -version flags must be provided")
+	}
+	if flags.NArg() != 1 {
+		return errors.New("a contract file must be provided")
+	}
+
+	contract, err := processors.ReadContractFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *provider == "" {
+		*provider = contract.Name
+	}
+	if *provider == "" {
+		return errors.New("the contract has no name, the -provider flag must be provided")
+	}
+
+	client, err := newBrokerClient(*brokerURL, *token)
+	if err != nil {
+		return err
+	}
+
+	published, err := client.PublishContract(context.Background(), brokerclient.PublishedContract{
+		Consumer: *consumer,
+		Provider: *provider,
+		Version:  *version,
+		Branch:   *branch,
+		Commit:   *commit,
+		Semver:   *semver,
+		Tags:     splitList(*tags),
+		Contract: contract,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(
+		stdout, "published the contract of %s %s with %s\n", published.Consumer, published.Version,
+		published.Provider,
+	)
+
+	return nil
+}
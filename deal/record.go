@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+var recordCommand = command{
+	name: "record",
+	usage: "record -descriptor-set <file> -target <host:port> [-addr host:port] [-name <name>] " +
+		"[-o <file>]",
+	description: "Proxies the calls of a consumer to a real provider, recording them as the cases " +
+		"of a contract",
+}
+
+func init() {
+	recordCommand.run = runRecord
+}
+
+// runRecord proxies the unary methods of every service of the descriptor set until the
+// process is interrupted, then writes the contract of the recorded calls
+func runRecord(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&recordCommand, stderr)
+	descriptorSetPath := flags.String(
+		"descriptor-set", "", "Descriptor set (protoc --descriptor_set_out) or buf image",
+	)
+	target := flags.String("target", "", "Address of the provider receiving the calls")
+	address := flags.String("addr", ":50051", "Address the proxy listens on")
+	name := flags.String("name", "", "Name of the recorded contract")
+	output := flags.String(
+		"o", "", "File receiving the recorded contract, the standard output by default",
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *descriptorSetPath == "" {
+		return errors.New("the -descriptor-set flag must be provided")
+	}
+	if *target == "" {
+		return errors.New("the -target flag must be provided")
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	files, err := processors.ReadDescriptorSet(*descriptorSetPath)
+	if err != nil {
+		return err
+	}
+
+	conn, err := grpc.Dial(*target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		return err
+	}
+
+	recorder := processors.NewContractRecorder(files)
+	server := grpc.NewServer()
+	registerRecordServices(server, files, conn, recorder, &callLogger{output: stderr})
+
+	fmt.Fprintf(stderr, "recording the calls to %s on %s\n", *target, listener.Addr())
+	if err = serveUntilStopped(server, listener); err != nil {
+		return err
+	}
+
+	contract := recorder.Contract()
+	contract.Name = *name
+	if len(contract.Services) == 0 {
+		return errors.New("no call recorded")
+	}
+
+	return writeContract(*output, stdout, contract)
+}
+
+// registerRecordServices registers a service descriptor forwarding the unary methods of every
+// service of the proto files, the streams aren't proxied
+func registerRecordServices(
+	server *grpc.Server,
+	files *protoregistry.Files,
+	conn *grpc.ClientConn,
+	recorder *processors.ContractRecorder,
+	logger *callLogger,
+) {
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for index := 0; index < file.Services().Len(); index++ {
+			service := file.Services().Get(index)
+			serviceDesc := &grpc.ServiceDesc{
+				ServiceName: string(service.FullName()),
+				Metadata:    file.Path(),
+			}
+
+			for methodIndex := 0; methodIndex < service.Methods().Len(); methodIndex++ {
+				method := service.Methods().Get(methodIndex)
+				if method.IsStreamingClient() || method.IsStreamingServer() {
+					continue
+				}
+
+				serviceDesc.Methods = append(serviceDesc.Methods, grpc.MethodDesc{
+					MethodName: string(method.Name()),
+					Handler:    recordMethodHandler(conn, recorder, method, logger),
+				})
+			}
+
+			if len(serviceDesc.Methods) > 0 {
+				server.RegisterService(serviceDesc, nil)
+			}
+		}
+		return true
+	})
+}
+
+func recordMethodHandler(
+	conn *grpc.ClientConn,
+	recorder *processors.ContractRecorder,
+	method protoreflect.MethodDescriptor,
+	logger *callLogger,
+) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (
+	interface{}, error,
+) {
+	fullMethod := processors.MockFullMethod(method)
+
+	return func(
+		_ interface{},
+		ctx context.Context,
+		decode func(interface{}) error,
+		_ grpc.UnaryServerInterceptor,
+	) (interface{}, error) {
+		request := dynamicpb.NewMessage(method.Input())
+		if err := decode(request); err != nil {
+			return nil, err
+		}
+
+		// The metadata of the consumer is forwarded, and the one of the provider returned
+		if md, exists := metadata.FromIncomingContext(ctx); exists {
+			ctx = metadata.NewOutgoingContext(ctx, md)
+		}
+
+		var header, trailer metadata.MD
+		response := dynamicpb.NewMessage(method.Output())
+		callErr := conn.Invoke(
+			ctx, fullMethod, request, response, grpc.Header(&header), grpc.Trailer(&trailer),
+		)
+		if err := grpc.SetHeader(ctx, header); err != nil {
+			return nil, err
+		}
+		if err := grpc.SetTrailer(ctx, trailer); err != nil {
+			return nil, err
+		}
+
+		// The calls given up by the consumer don't tell anything about the provider
+		if ctx.Err() != nil {
+			logger.log(fullMethod, fmt.Sprintf("not recorded: %v", ctx.Err()))
+			return nil, callErr
+		}
+
+		var added bool
+		var recordErr error
+		if callErr != nil {
+			added, recordErr = recorder.RecordError(method, request, recordedError(callErr))
+		} else {
+			added, recordErr = recorder.RecordResponse(method, request, response)
+		}
+
+		switch {
+		case recordErr != nil:
+			logger.log(fullMethod, fmt.Sprintf("not recorded: %v", recordErr))
+		case added && callErr != nil:
+			logger.log(fullMethod, fmt.Sprintf("recorded the failure %s", status.Code(callErr)))
+		case added:
+			logger.log(fullMethod, "recorded the response")
+		default:
+			logger.log(fullMethod, "already recorded")
+		}
+
+		if callErr != nil {
+			return nil, callErr
+		}
+		return response, nil
+	}
+}
+
+// recordedError returns the contract error of the status, with its details
+func recordedError(err error) entities.GRPCError {
+	errorStatus := status.Convert(err)
+	grpcError := entities.GRPCError{
+		ErrorCode: entities.ErrorCode(errorStatus.Code().String()),
+		Message:   errorStatus.Message(),
+	}
+
+	details := &entities.ErrorDetails{}
+	for _, detail := range errorStatus.Details() {
+		switch typedDetail := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, violation := range typedDetail.GetFieldViolations() {
+				details.BadRequest = append(details.BadRequest, entities.FieldViolation{
+					Field: violation.GetField(), Description: violation.GetDescription(),
+				})
+			}
+		case *errdetails.PreconditionFailure:
+			for _, violation := range typedDetail.GetViolations() {
+				details.PreconditionFailure = append(
+					details.PreconditionFailure,
+					entities.PreconditionViolation{
+						Type:        violation.GetType(),
+						Subject:     violation.GetSubject(),
+						Description: violation.GetDescription(),
+					},
+				)
+			}
+		case *errdetails.ErrorInfo:
+			details.ErrorInfo = &entities.ErrorInfo{
+				Reason:   typedDetail.GetReason(),
+				Domain:   typedDetail.GetDomain(),
+				Metadata: typedDetail.GetMetadata(),
+			}
+		case *errdetails.QuotaFailure:
+			for _, violation := range typedDetail.GetViolations() {
+				details.QuotaFailure = append(details.QuotaFailure, entities.QuotaViolation{
+					Subject: violation.GetSubject(), Description: violation.GetDescription(),
+				})
+			}
+		case *errdetails.RetryInfo:
+			details.RetryDelay = typedDetail.GetRetryDelay().AsDuration().String()
+		}
+	}
+	if !details.IsEmpty() {
+		grpcError.Details = details
+	}
+
+	return grpcError
+}
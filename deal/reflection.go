@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+// reflectionServicePrefix is the package of the reflection service itself, which isn't resolved
+const reflectionServicePrefix = "grpc.reflection."
+
+// reflectionFiles resolves the proto files of the services of the server, and their imports,
+// through the server reflection
+func reflectionFiles(ctx context.Context, conn grpc.ClientConnInterface) (
+	*protoregistry.Files, error,
+) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection unavailable: %w", err)
+	}
+	defer stream.CloseSend() //nolint:errcheck // the files are already resolved
+
+	exchange := func(request *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+		if err := stream.Send(request); err != nil {
+			return nil, fmt.Errorf("server reflection unavailable: %w", err)
+		}
+
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("server reflection unavailable: %w", err)
+		}
+		if errorResponse := response.GetErrorResponse(); errorResponse != nil {
+			return nil, fmt.Errorf("server reflection failed: %s", errorResponse.GetErrorMessage())
+		}
+
+		return response, nil
+	}
+
+	response, err := exchange(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]bool)
+	descriptorSet := &descriptorpb.FileDescriptorSet{}
+	addFiles := func(response *rpb.ServerReflectionResponse) ([]string, error) {
+		dependencies := make([]string, 0)
+		for _, data := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(data, file); err != nil {
+				return nil, fmt.Errorf("invalid file descriptor: %w", err)
+			}
+			if resolved[file.GetName()] {
+				continue
+			}
+
+			resolved[file.GetName()] = true
+			descriptorSet.File = append(descriptorSet.File, file)
+			dependencies = append(dependencies, file.GetDependency()...)
+		}
+
+		return dependencies, nil
+	}
+
+	// The servers usually send the imports along with the file, the others are requested
+	missing := make([]string, 0)
+	for _, service := range response.GetListServicesResponse().GetService() {
+		if strings.HasPrefix(service.GetName(), reflectionServicePrefix) {
+			continue
+		}
+
+		fileResponse, err := exchange(&rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{
+				FileContainingSymbol: service.GetName(),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		dependencies, err := addFiles(fileResponse)
+		if err != nil {
+			return nil, err
+		}
+		missing = append(missing, dependencies...)
+	}
+
+	for len(missing) > 0 {
+		fileName := missing[0]
+		missing = missing[1:]
+		if resolved[fileName] {
+			continue
+		}
+
+		// An unknown import may still be a well-known type, resolved when the files are parsed
+		fileResponse, err := exchange(&rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: fileName},
+		})
+		if err != nil {
+			resolved[fileName] = true
+			continue
+		}
+		dependencies, err := addFiles(fileResponse)
+		if err != nil {
+			return nil, err
+		}
+		missing = append(missing, dependencies...)
+	}
+
+	data, err := proto.Marshal(descriptorSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return processors.ParseDescriptorSet(data, "of the server reflection")
+}
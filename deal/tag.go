@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/faunists/deal-go/brokerclient"
+)
+
+var tagCommand = command{
+	name:  "tag",
+	usage: "tag -broker <url> [-token <token>] -pacticipant <name> -version <version> <tag>...",
+	description: "Tags a consumer or provider version known by a contract broker, e.g. with the " +
+		"environment it's deployed to",
+}
+
+func init() {
+	tagCommand.run = runTag
+}
+
+// runTag adds the tags to the version one by one, the broker creates the version when it
+// doesn't know it yet
+func runTag(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&tagCommand, stderr)
+	brokerURL := flags.String("broker", "", "URL of the contract broker")
+	token := brokerTokenFlag(flags)
+	pacticipant := flags.String("pacticipant", "", "Name of the consumer or the provider")
+	number := flags.String("version", "", "Version to tag, e.g. a git SHA")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *brokerURL == "" {
+		return errors.New("the -broker flag must be provided")
+	}
+	if *pacticipant == "" || *number == "" {
+		return errors.New("the -pacticipant and -version flags must be provided")
+	}
+	if flags.NArg() == 0 {
+		return errors.New("at least one tag must be provided")
+	}
+
+	client, err := newBrokerClient(*brokerURL, *token)
+	if err != nil {
+		return err
+	}
+
+	version := brokerclient.Version{}
+	for _, tag := range flags.Args() {
+		version, err = client.TagVersion(context.Background(), *pacticipant, *number, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(
+		stdout, "tagged %s %s with %s\n", version.Pacticipant, version.Number,
+		strings.Join(version.Tags, ", "),
+	)
+
+	return nil
+}
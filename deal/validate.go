@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+var validateCommand = command{
+	name:        "validate",
+	usage:       "validate -descriptor-set <file> <contract file>...",
+	description: "Checks contract files against the proto files compiled into a descriptor set",
+}
+
+func init() {
+	validateCommand.run = runValidate
+}
+
+// runValidate reports the issues of every contract file, the command fails when there's any
+func runValidate(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&validateCommand, stderr)
+	descriptorSetPath := flags.String(
+		"descriptor-set", "", "Descriptor set (protoc --descriptor_set_out) or buf image",
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *descriptorSetPath == "" {
+		return errors.New("the -descriptor-set flag must be provided")
+	}
+	if flags.NArg() == 0 {
+		return errors.New("at least one contract file must be provided")
+	}
+
+	files, err := processors.ReadDescriptorSet(*descriptorSetPath)
+	if err != nil {
+		return err
+	}
+
+	issuesFound := false
+	for _, contractPath := range flags.Args() {
+		contract, err := processors.ReadContractFile(contractPath)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range processors.ValidateContract(contract, files) {
+			fmt.Fprintf(stdout, "%s: %s\n", contractPath, issue)
+			issuesFound = true
+		}
+	}
+
+	if issuesFound {
+		return errIssuesFound
+	}
+
+	return nil
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/faunists/deal-go/brokerclient"
+	"github.com/faunists/deal-go/dealtest"
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+var verifyCommand = command{
+	name: "verify",
+	usage: "verify -target <host:port> [-descriptor-set <file>] [-tls] [-timeout duration] " +
+		"[-format text|json] (<contract file> | -broker <url> [-token <token>] " +
+		"-provider <name> [-consumer <name>] [-tag <tag>] [-branch <branch>] " +
+		"[-provider-version <version>] [-enable-pending])",
+	description: "Verifies a running provider, written in any language, against the cases of " +
+		"a contract, or of the latest contracts of its consumers in a contract broker",
+}
+
+func init() {
+	verifyCommand.run = runVerify
+}
+
+// verificationResult is the outcome of a single case, the consumer fields are set when the
+// contract comes from a broker
+type verificationResult struct {
+	Consumer        string `json:"consumer,omitempty"`
+	ConsumerVersion string `json:"consumerVersion,omitempty"`
+	Pending         bool   `json:"pending,omitempty"`
+	Path            string `json:"path"`
+	Description     string `json:"description,omitempty"`
+	Passed          bool   `json:"passed"`
+	Failure         string `json:"failure,omitempty"`
+	// duration is published to the broker along with the outcome
+	duration time.Duration
+}
+
+// verifiedContract is a contract verified by the command, published is nil for a contract file
+type verifiedContract struct {
+	published *brokerclient.PublishedContract
+	contract  entities.Contract
+}
+
+// runVerify calls the provider with the request of every case, the command fails when any
+// result differs from the contract. With -enable-pending, the failures of the pending contracts
+// of the broker are reported without failing the command.
+func runVerify(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet(&verifyCommand, stderr)
+	target := flags.String("target", "", "Address of the provider")
+	descriptorSetPath := flags.String(
+		"descriptor-set", "",
+		"Descriptor set (protoc --descriptor_set_out) or buf image, the server reflection by default",
+	)
+	useTLS := flags.Bool("tls", false, "Dial the provider with TLS, trusting the system roots")
+	timeout := flags.Duration(
+		"timeout", 10*time.Second, //nolint:revive // the default timeout of the calls
+		"Timeout of the calls of the cases without their own timeout",
+	)
+	format := flags.String("format", textFormat, "Output format: text or json")
+	brokerURL := flags.String("broker", "", "URL of the contract broker of the contracts")
+	token := brokerTokenFlag(flags)
+	provider := flags.String("provider", "", "Name of the provider of the broker contracts")
+	consumer := flags.String("consumer", "", "Only verify the contract of the consumer")
+	tag := flags.String("tag", "", "Only verify the consumer versions with the tag")
+	branch := flags.String("branch", "", "Only verify the consumer versions on the branch")
+	providerVersion := flags.String(
+		"provider-version", "", "Publish the results to the broker as this provider version",
+	)
+	enablePending := flags.Bool(
+		"enable-pending", false,
+		"Don't fail on the contracts that no provider version verified successfully yet",
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := validateFormat(*format, textFormat, jsonFormat); err != nil {
+		return err
+	}
+	if *target == "" {
+		return errors.New("the -target flag must be provided")
+	}
+
+	ctx := context.Background()
+	var client *brokerclient.Client
+	var contracts []verifiedContract
+	var err error
+	if *brokerURL == "" {
+		if *providerVersion != "" || *enablePending {
+			return errors.New(
+				"the -provider-version and -enable-pending flags require the -broker flag",
+			)
+		}
+		if flags.NArg() != 1 {
+			return errors.New("a contract file must be provided")
+		}
+		var contract entities.Contract
+		if contract, err = processors.ReadContractFile(flags.Arg(0)); err != nil {
+			return err
+		}
+		contracts = []verifiedContract{{contract: contract}}
+	} else {
+		if *provider == "" {
+			return errors.New("the -provider flag must be provided")
+		}
+		if flags.NArg() != 0 {
+			return errors.New("a contract file can't be provided along with the -broker flag")
+		}
+		client, err = newBrokerClient(*brokerURL, *token)
+		if err != nil {
+			return err
+		}
+		contracts, err = brokerContracts(
+			ctx, client, *provider, *consumer, brokerclient.Selector{Tag: *tag, Branch: *branch},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	transportCredentials := insecure.NewCredentials()
+	if *useTLS {
+		transportCredentials = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.Dial(*target, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var files *protoregistry.Files
+	if *descriptorSetPath != "" {
+		files, err = processors.ReadDescriptorSet(*descriptorSetPath)
+	} else {
+		files, err = reflectionFiles(ctx, conn)
+	}
+	if err != nil {
+		return err
+	}
+
+	results := make([]verificationResult, 0)
+	failed, ignored := 0, 0
+	for _, verified := range contracts {
+		contractResults, err := verifyContract(ctx, conn, files, verified, *timeout)
+		if err != nil {
+			return err
+		}
+		for _, result := range contractResults {
+			switch {
+			case result.Passed:
+			case result.Pending && *enablePending:
+				ignored++
+			default:
+				failed++
+			}
+		}
+		results = append(results, contractResults...)
+
+		if *providerVersion != "" {
+			err = publishVerification(ctx, client, verified, *providerVersion, contractResults)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if *format == jsonFormat {
+		if err = writeJSON(stdout, results); err != nil {
+			return err
+		}
+	} else {
+		writeVerificationResults(stdout, results, failed, ignored)
+	}
+
+	if failed > 0 {
+		return errIssuesFound
+	}
+
+	return nil
+}
+
+// brokerContracts fetches the latest contracts of the consumers of the provider
+func brokerContracts(
+	ctx context.Context,
+	client *brokerclient.Client,
+	provider, consumer string,
+	selector brokerclient.Selector,
+) ([]verifiedContract, error) {
+	latest, err := client.FetchLatestContracts(ctx, provider, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := make([]verifiedContract, 0, len(latest))
+	for i := range latest {
+		if consumer != "" && latest[i].Consumer != consumer {
+			continue
+		}
+		contracts = append(contracts, verifiedContract{
+			published: &latest[i],
+			contract:  latest[i].Contract,
+		})
+	}
+	if len(contracts) == 0 {
+		return nil, fmt.Errorf("the broker has no contract of %s matching the flags", provider)
+	}
+
+	return contracts, nil
+}
+
+// verifyContract verifies the provider against every case of the contract
+func verifyContract(
+	ctx context.Context,
+	conn grpc.ClientConnInterface,
+	files *protoregistry.Files,
+	verified verifiedContract,
+	timeout time.Duration,
+) ([]verificationResult, error) {
+	cases, err := processors.VerificationCases(verified.contract, files)
+	if err != nil {
+		if verified.published != nil {
+			return nil, fmt.Errorf(
+				"the contract of %s %s: %w",
+				verified.published.Consumer, verified.published.Version, err,
+			)
+		}
+		return nil, err
+	}
+
+	results := make([]verificationResult, 0, len(cases))
+	for _, verificationCase := range cases {
+		result := verificationResult{
+			Path:        verificationCase.Path,
+			Description: verificationCase.Description,
+			Passed:      true,
+		}
+		if verified.published != nil {
+			result.Consumer = verified.published.Consumer
+			result.ConsumerVersion = verified.published.Version
+			result.Pending = verified.published.Pending
+		}
+
+		start := time.Now()
+		if failure := verifyCase(ctx, conn, verificationCase, timeout); failure != "" {
+			result.Passed, result.Failure = false, failure
+		}
+		result.duration = time.Since(start)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// publishVerification publishes the results of the cases of the broker contract
+func publishVerification(
+	ctx context.Context,
+	client *brokerclient.Client,
+	verified verifiedContract,
+	providerVersion string,
+	results []verificationResult,
+) error {
+	verification := brokerclient.VerificationResult{
+		Consumer:        verified.published.Consumer,
+		ConsumerVersion: verified.published.Version,
+		Provider:        verified.published.Provider,
+		ProviderVersion: providerVersion,
+		Success:         true,
+		Cases:           make([]brokerclient.CaseResult, 0, len(results)),
+	}
+	for _, result := range results {
+		verification.Success = verification.Success && result.Passed
+		verification.Cases = append(verification.Cases, brokerclient.CaseResult{
+			Name:     result.Path,
+			Success:  result.Passed,
+			Duration: result.duration,
+		})
+	}
+
+	_, err := client.PublishVerificationResult(ctx, verification)
+
+	return err
+}
+
+// writeVerificationResults writes the results, grouped by the contracts of the broker
+func writeVerificationResults(
+	output io.Writer,
+	results []verificationResult,
+	failed, ignored int,
+) {
+	contract := ""
+	for _, result := range results {
+		if result.Consumer != "" && result.Consumer+" "+result.ConsumerVersion != contract {
+			contract = result.Consumer + " " + result.ConsumerVersion
+			header := "the contract of " + contract
+			if result.Pending {
+				header += " (pending)"
+			}
+			fmt.Fprintf(output, "%s\n", header)
+		}
+		writeVerificationResult(output, result)
+	}
+
+	fmt.Fprintf(
+		output, "\n%d cases: %d passed, %d failed",
+		len(results), len(results)-failed-ignored, failed+ignored,
+	)
+	if ignored > 0 {
+		fmt.Fprintf(output, " (%d in pending contracts, ignored)", ignored)
+	}
+	fmt.Fprintln(output)
+}
+
+func writeVerificationResult(output io.Writer, result verificationResult) {
+	outcome := "PASS"
+	if !result.Passed {
+		outcome = "FAIL"
+	}
+
+	fmt.Fprintf(output, "%s %s", outcome, result.Path)
+	if result.Description != "" {
+		fmt.Fprintf(output, " %q", result.Description)
+	}
+	if result.Failure != "" {
+		fmt.Fprintf(output, ": %s", result.Failure)
+	}
+	fmt.Fprintln(output)
+}
+
+// caseFailure implements dealtest.TestingT, it keeps the first failure of the case
+type caseFailure struct {
+	message string
+}
+
+func (f *caseFailure) Helper() {}
+
+func (f *caseFailure) Fatalf(format string, args ...interface{}) {
+	if f.message == "" {
+		f.message = fmt.Sprintf(format, args...)
+	}
+}
+
+// verifyCase makes the calls of the case, the failure is returned, or an empty string
+func verifyCase(
+	ctx context.Context,
+	conn grpc.ClientConnInterface,
+	verificationCase processors.VerificationCase,
+	defaultTimeout time.Duration,
+) string {
+	timeout := verificationCase.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	fullMethod := processors.MockFullMethod(verificationCase.Method)
+
+	failure := &caseFailure{}
+	for call, step := range verificationCase.Steps {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		var header, trailer metadata.MD
+		response := dynamicpb.NewMessage(verificationCase.Method.Output())
+		err := conn.Invoke(
+			callCtx, fullMethod, verificationCase.Request, response,
+			grpc.Header(&header), grpc.Trailer(&trailer),
+		)
+		cancel()
+
+		verifyStep(failure, step, response, err)
+		if len(verificationCase.Steps) == 1 {
+			dealtest.AssertMetadata(failure, "header", mockMetadata(verificationCase.Headers), header)
+			dealtest.AssertMetadata(
+				failure, "trailer", mockMetadata(verificationCase.Trailers), trailer,
+			)
+		}
+
+		if failure.message != "" {
+			if len(verificationCase.Steps) > 1 {
+				return fmt.Sprintf("call %d: %s", call, failure.message)
+			}
+			return failure.message
+		}
+	}
+
+	return ""
+}
+
+// verifyStep verifies the result of a call like the generated contract tests do
+func verifyStep(
+	failure *caseFailure,
+	step processors.VerificationStep,
+	response proto.Message,
+	err error,
+) {
+	if step.Error == nil {
+		if err != nil {
+			failure.Fatalf("unexpected error happened: %v", err)
+			return
+		}
+
+		expected := proto.Clone(step.Response)
+		switch {
+		case step.ErrorField != "":
+			dealtest.KeepFields(response, step.ErrorField)
+			dealtest.KeepFields(expected, step.ErrorField)
+		case len(step.IgnoredFields) > 0:
+			dealtest.ClearFields(response, step.IgnoredFields...)
+			dealtest.ClearFields(expected, step.IgnoredFields...)
+		}
+		dealtest.AssertResponse(failure, expected, response)
+		return
+	}
+
+	if err == nil {
+		failure.Fatalf("an error was expected but no one was returned")
+		return
+	}
+	errorStatus, isStatus := status.FromError(err)
+	if !isStatus {
+		failure.Fatalf("a gRPC status error was expected, given error: %v", err)
+		return
+	}
+
+	codeAccepted := false
+	for _, errorCode := range step.Error.Codes() {
+		code, _ := processors.ErrorCodeNumber(string(errorCode))
+		codeAccepted = codeAccepted || uint32(errorStatus.Code()) == code
+	}
+	if !codeAccepted {
+		expectedCode := string(step.Error.ErrorCode)
+		if len(step.Error.AlternativeCodes) > 0 {
+			expectedCode = fmt.Sprintf("%s (or one of %v)", expectedCode, step.Error.AlternativeCodes)
+		}
+
+		failure.Fatalf("expected code: %s, given code: %s", expectedCode, errorStatus.Code())
+		return
+	}
+
+	dealtest.AssertMessage(failure, step.MessageMatch, step.ExpectedMessage, errorStatus.Message())
+
+	details, detailsErr := statusDetails(step.Error.Details)
+	if detailsErr != nil {
+		failure.Fatalf("%v", detailsErr)
+		return
+	}
+	expectedDetails := make([]proto.Message, 0, len(details))
+	for _, detail := range details {
+		expectedDetails = append(expectedDetails, protoadapt.MessageV2Of(detail))
+	}
+	dealtest.AssertDetails(failure, expectedDetails, errorStatus.Details())
+}
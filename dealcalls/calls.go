@@ -0,0 +1,72 @@
+// Package dealcalls records the calls received by the generated contract clients, it only
+// depends on protobuf so the clients can be used by production code.
+package dealcalls
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Call represents a request received by a generated contract client
+type Call struct {
+	Method   string
+	Request  proto.Message
+	Metadata map[string][]string
+	Time     time.Time
+}
+
+// Recorder records the calls received by a generated contract client,
+// it's safe for concurrent use and its zero value is ready to use.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Record stores a copy of the given request
+func (r *Recorder) Record(method string, request proto.Message, metadata map[string][]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, Call{
+		Method:   method,
+		Request:  proto.Clone(request),
+		Metadata: metadata,
+		Time:     time.Now(),
+	})
+}
+
+// Calls returns the recorded calls in the order they were received
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]Call, len(r.calls))
+	copy(calls, r.calls)
+
+	return calls
+}
+
+// CallCount returns how many calls the given method received
+func (r *Recorder) CallCount(method string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, call := range r.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Reset forgets every recorded call
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
@@ -0,0 +1,61 @@
+package dealcalls_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/faunists/deal-go/dealcalls"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	recorder := dealcalls.Recorder{}
+	request := &descriptorpb.FileDescriptorProto{Name: proto.String("file.proto")}
+	metadata := map[string][]string{"x-request-id": {"abc"}}
+
+	recorder.Record("GetFile", request, metadata)
+	recorder.Record("ListFiles", &descriptorpb.FileDescriptorProto{}, nil)
+	recorder.Record("GetFile", request, nil)
+
+	// Changes made after the call must not affect the recorded request
+	request.Name = proto.String("changed.proto")
+
+	calls := recorder.Calls()
+	if len(calls) != 3 { //nolint:revive // number of recorded calls
+		t.Fatalf("Wrong number of calls, given: %d, expected: 3", len(calls))
+	}
+
+	firstCall := calls[0]
+	expectedRequest := &descriptorpb.FileDescriptorProto{Name: proto.String("file.proto")}
+	if firstCall.Method != "GetFile" || !proto.Equal(firstCall.Request, expectedRequest) {
+		t.Errorf("Wrong first call, given: %v", firstCall)
+	}
+	if firstCall.Metadata["x-request-id"][0] != "abc" || firstCall.Time.IsZero() {
+		t.Errorf("Wrong first call metadata or time, given: %v", firstCall)
+	}
+
+	tests := []struct {
+		method        string
+		expectedCount int
+	}{
+		{method: "GetFile", expectedCount: 2},
+		{method: "ListFiles", expectedCount: 1},
+		{method: "DeleteFile", expectedCount: 0},
+	}
+	for _, test := range tests {
+		if count := recorder.CallCount(test.method); count != test.expectedCount {
+			t.Errorf(
+				"Wrong count for %s, given: %d, expected: %d",
+				test.method, count, test.expectedCount,
+			)
+		}
+	}
+
+	recorder.Reset()
+	if calls := recorder.Calls(); len(calls) != 0 {
+		t.Errorf("Calls were expected to be reset, given: %v", calls)
+	}
+}
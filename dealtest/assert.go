@@ -0,0 +1,88 @@
+package dealtest
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// TestingT is the part of testing.TB used by the assertions, it keeps the testing package
+// out of the builds importing dealtest
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// AssertResponse fails the test when the response isn't equal to the expected one,
+// the comparison options (e.g. protocmp.IgnoreFields) are applied through ResponseDiff
+func AssertResponse(t TestingT, expected, actual proto.Message, opts ...cmp.Option) {
+	t.Helper()
+
+	if len(opts) > 0 {
+		if diff := ResponseDiff(expected, actual, opts...); diff != "" {
+			t.Fatalf("unexpected response (-expected +given):\n%s", diff)
+		}
+		return
+	}
+
+	if !proto.Equal(actual, expected) {
+		t.Fatalf("expected response: %v, given response: %v", expected, actual)
+	}
+}
+
+// ResponseDiff returns the differences between the responses, an empty string when they're
+// equal. The responses are compared through protocmp.Transform and the given options.
+func ResponseDiff(expected, actual proto.Message, opts ...cmp.Option) string {
+	return cmp.Diff(expected, actual, append([]cmp.Option{protocmp.Transform()}, opts...)...)
+}
+
+// AssertMessage fails the test when the error message doesn't satisfy the expected one
+// following the given mode, see MatchMessage
+func AssertMessage(t TestingT, mode, expected, actual string) {
+	t.Helper()
+
+	matched, err := MatchMessage(mode, expected, actual)
+	if err != nil {
+		t.Fatalf("invalid message match: %v", err)
+		return
+	}
+	if !matched {
+		t.Fatalf("expected message: %s (%s match), given message: %s", expected, mode, actual)
+	}
+}
+
+// AssertDetails fails the test when the error details (e.g. status.Details()) aren't
+// equal to the expected ones, in the same order
+func AssertDetails(t TestingT, expected []proto.Message, actual []interface{}) {
+	t.Helper()
+
+	if len(actual) != len(expected) {
+		t.Fatalf("expected details: %v, given details: %v", expected, actual)
+		return
+	}
+	for index, detail := range actual {
+		message, isMessage := detail.(proto.Message)
+		if !isMessage || !proto.Equal(message, expected[index]) {
+			t.Fatalf("expected detail: %v, given detail: %v", expected[index], detail)
+			return
+		}
+	}
+}
+
+// AssertMetadata fails the test when the metadata (e.g. metadata.MD) doesn't hold the
+// expected values, the kind names the metadata in the failure message (header or trailer)
+func AssertMetadata(t TestingT, kind string, expected, actual map[string][]string) {
+	t.Helper()
+
+	for key, values := range expected {
+		// The metadata keys are always lowercase
+		actualValues := actual[strings.ToLower(key)]
+		if !reflect.DeepEqual(actualValues, values) {
+			t.Fatalf("expected %s %s: %v, given: %v", kind, key, values, actualValues)
+			return
+		}
+	}
+}
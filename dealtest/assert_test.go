@@ -0,0 +1,216 @@
+package dealtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/faunists/deal-go/dealtest"
+)
+
+// fakeT records the failures instead of stopping the test
+type fakeT struct {
+	failures []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		expected       proto.Message
+		actual         proto.Message
+		opts           []cmp.Option
+		expectedFailed bool
+	}{
+		{
+			name:           "should accept an equal response",
+			expected:       wrapperspb.String("value"),
+			actual:         wrapperspb.String("value"),
+			expectedFailed: false,
+		},
+		{
+			name:           "should fail when the response differs",
+			expected:       wrapperspb.String("value"),
+			actual:         wrapperspb.String("other"),
+			expectedFailed: true,
+		},
+		{
+			name:     "should accept a response differing only in the ignored fields",
+			expected: wrapperspb.String("value"),
+			actual:   wrapperspb.String("other"),
+			opts: []cmp.Option{
+				protocmp.IgnoreFields(&wrapperspb.StringValue{}, "value"),
+			},
+			expectedFailed: false,
+		},
+		{
+			name:           "should fail when the response differs with comparison options",
+			expected:       wrapperspb.String("value"),
+			actual:         wrapperspb.String("other"),
+			opts:           []cmp.Option{protocmp.IgnoreUnknown()},
+			expectedFailed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeT{}
+			dealtest.AssertResponse(fake, test.expected, test.actual, test.opts...)
+			if (len(fake.failures) > 0) != test.expectedFailed {
+				t.Errorf("Unexpected failures: %v", fake.failures)
+			}
+		})
+	}
+}
+
+func TestAssertMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		mode           string
+		expected       string
+		actual         string
+		expectedFailed bool
+	}{
+		{
+			name:           "should accept a matching message",
+			mode:           "contains",
+			expected:       "not found",
+			actual:         "user not found",
+			expectedFailed: false,
+		},
+		{
+			name:           "should fail when the message doesn't match",
+			mode:           "exact",
+			expected:       "not found",
+			actual:         "user not found",
+			expectedFailed: true,
+		},
+		{
+			name:           "should fail when the mode is invalid",
+			mode:           "unknown",
+			expected:       "not found",
+			actual:         "not found",
+			expectedFailed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeT{}
+			dealtest.AssertMessage(fake, test.mode, test.expected, test.actual)
+			if (len(fake.failures) > 0) != test.expectedFailed {
+				t.Errorf("Unexpected failures: %v", fake.failures)
+			}
+		})
+	}
+}
+
+func TestAssertDetails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		expected       []proto.Message
+		actual         []interface{}
+		expectedFailed bool
+	}{
+		{
+			name:           "should accept the same details",
+			expected:       []proto.Message{wrapperspb.String("a"), wrapperspb.Int32(1)},
+			actual:         []interface{}{wrapperspb.String("a"), wrapperspb.Int32(1)},
+			expectedFailed: false,
+		},
+		{
+			name:           "should fail when a detail is missing",
+			expected:       []proto.Message{wrapperspb.String("a"), wrapperspb.Int32(1)},
+			actual:         []interface{}{wrapperspb.String("a")},
+			expectedFailed: true,
+		},
+		{
+			name:           "should fail when the details are in another order",
+			expected:       []proto.Message{wrapperspb.String("a"), wrapperspb.Int32(1)},
+			actual:         []interface{}{wrapperspb.Int32(1), wrapperspb.String("a")},
+			expectedFailed: true,
+		},
+		{
+			name:           "should fail when a detail isn't a message",
+			expected:       []proto.Message{wrapperspb.String("a")},
+			actual:         []interface{}{fmt.Errorf("any: %s", "a")},
+			expectedFailed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeT{}
+			dealtest.AssertDetails(fake, test.expected, test.actual)
+			if (len(fake.failures) > 0) != test.expectedFailed {
+				t.Errorf("Unexpected failures: %v", fake.failures)
+			}
+		})
+	}
+}
+
+func TestAssertMetadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		expected       map[string][]string
+		actual         map[string][]string
+		expectedFailed bool
+	}{
+		{
+			name:           "should accept metadata holding the expected values",
+			expected:       map[string][]string{"X-Request-Id": {"abc"}},
+			actual:         map[string][]string{"x-request-id": {"abc"}, "other": {"value"}},
+			expectedFailed: false,
+		},
+		{
+			name:           "should fail when a value differs",
+			expected:       map[string][]string{"x-cost": {"1", "2"}},
+			actual:         map[string][]string{"x-cost": {"1"}},
+			expectedFailed: true,
+		},
+		{
+			name:           "should fail when a key is missing",
+			expected:       map[string][]string{"x-cost": {"1"}},
+			actual:         map[string][]string{},
+			expectedFailed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeT{}
+			dealtest.AssertMetadata(fake, "header", test.expected, test.actual)
+			if (len(fake.failures) > 0) != test.expectedFailed {
+				t.Errorf("Unexpected failures: %v", fake.failures)
+			}
+		})
+	}
+}
+
+func TestResponseDiff(t *testing.T) {
+	t.Parallel()
+
+	if diff := dealtest.ResponseDiff(wrapperspb.Int64(1), wrapperspb.Int64(1)); diff != "" {
+		t.Errorf("Expected no differences, given: %s", diff)
+	}
+	if diff := dealtest.ResponseDiff(wrapperspb.Int64(1), wrapperspb.Int64(2)); diff == "" {
+		t.Errorf("Expected the differences of the responses")
+	}
+}
@@ -0,0 +1,13 @@
+package dealtest
+
+import "github.com/faunists/deal-go/dealcalls"
+
+// Call represents a request received by a generated contract client.
+//
+// Deprecated: use dealcalls.Call instead.
+type Call = dealcalls.Call
+
+// CallRecorder records the calls received by a generated contract client.
+//
+// Deprecated: use dealcalls.Recorder instead.
+type CallRecorder = dealcalls.Recorder
@@ -0,0 +1,138 @@
+// Package dealtest holds the helpers used by the code generated by protoc-gen-go-deal
+package dealtest
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ClearFields clears the fields referenced by the given paths. A path is a dot separated list
+// of field names (proto or JSON names), when a path goes through a list or a map the rest of
+// the path is applied to every element. Paths that don't exist in the message are ignored.
+func ClearFields(message proto.Message, paths ...string) {
+	if message == nil {
+		return
+	}
+
+	reflectMessage := message.ProtoReflect()
+	if !reflectMessage.IsValid() {
+		return
+	}
+
+	for _, path := range paths {
+		clearField(reflectMessage, strings.Split(path, "."))
+	}
+}
+
+func clearField(message protoreflect.Message, path []string) {
+	field := findField(message.Descriptor(), path[0])
+	if field == nil || !message.Has(field) {
+		return
+	}
+
+	if len(path) == 1 {
+		message.Clear(field)
+		return
+	}
+
+	if field.Message() == nil {
+		return
+	}
+
+	switch {
+	case field.IsList():
+		list := message.Get(field).List()
+		for i := 0; i < list.Len(); i++ {
+			clearField(list.Get(i).Message(), path[1:])
+		}
+	case field.IsMap():
+		if field.MapValue().Message() == nil {
+			return
+		}
+		message.Get(field).Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+			clearField(value.Message(), path[1:])
+			return true
+		})
+	default:
+		clearField(message.Mutable(field).Message(), path[1:])
+	}
+}
+
+func findField(message protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if field := message.Fields().ByName(protoreflect.Name(name)); field != nil {
+		return field
+	}
+
+	return message.Fields().ByJSONName(name)
+}
+
+// KeepFields clears every field not referenced by the given paths, the paths work the same way
+// as in ClearFields. When a path goes through a message, its fields not referenced are cleared.
+func KeepFields(message proto.Message, paths ...string) {
+	if message == nil {
+		return
+	}
+
+	reflectMessage := message.ProtoReflect()
+	if !reflectMessage.IsValid() {
+		return
+	}
+
+	splitPaths := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		splitPaths = append(splitPaths, strings.Split(path, "."))
+	}
+	keepFields(reflectMessage, splitPaths)
+}
+
+func keepFields(message protoreflect.Message, paths [][]string) {
+	// Every kept field points to the rest of its paths, nil means the whole field is kept
+	keptFields := make(map[protoreflect.FieldNumber][][]string)
+	for _, path := range paths {
+		field := findField(message.Descriptor(), path[0])
+		if field == nil {
+			continue
+		}
+
+		subPaths, alreadyKept := keptFields[field.Number()]
+		switch {
+		case len(path) == 1 || field.Message() == nil:
+			keptFields[field.Number()] = nil
+		case !alreadyKept || subPaths != nil:
+			keptFields[field.Number()] = append(subPaths, path[1:])
+		}
+	}
+
+	clearedFields := make([]protoreflect.FieldDescriptor, 0)
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		subPaths, kept := keptFields[field.Number()]
+		switch {
+		case !kept:
+			clearedFields = append(clearedFields, field)
+		case subPaths == nil:
+		case field.IsList():
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				keepFields(list.Get(i).Message(), subPaths)
+			}
+		case field.IsMap():
+			if field.MapValue().Message() == nil {
+				return true
+			}
+			value.Map().Range(func(_ protoreflect.MapKey, mapValue protoreflect.Value) bool {
+				keepFields(mapValue.Message(), subPaths)
+				return true
+			})
+		default:
+			keepFields(value.Message(), subPaths)
+		}
+
+		return true
+	})
+
+	for _, field := range clearedFields {
+		message.Clear(field)
+	}
+}
@@ -0,0 +1,161 @@
+package dealtest_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/faunists/deal-go/dealtest"
+)
+
+func TestClearFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		message         proto.Message
+		paths           []string
+		expectedMessage proto.Message
+	}{
+		{
+			name:            "should clear a top level field",
+			message:         &descriptorpb.FileDescriptorProto{Name: proto.String("file.proto")},
+			paths:           []string{"name"},
+			expectedMessage: &descriptorpb.FileDescriptorProto{},
+		},
+		{
+			name: "should clear a field using its JSON name",
+			message: &descriptorpb.FileDescriptorProto{
+				Dependency: []string{"other.proto"},
+				Syntax:     proto.String("proto3"),
+			},
+			paths:           []string{"dependency"},
+			expectedMessage: &descriptorpb.FileDescriptorProto{Syntax: proto.String("proto3")},
+		},
+		{
+			name: "should clear a nested field",
+			message: &descriptorpb.FileDescriptorProto{
+				Options: &descriptorpb.FileOptions{
+					GoPackage:   proto.String("example"),
+					JavaPackage: proto.String("example"),
+				},
+			},
+			paths: []string{"options.goPackage"},
+			expectedMessage: &descriptorpb.FileDescriptorProto{
+				Options: &descriptorpb.FileOptions{JavaPackage: proto.String("example")},
+			},
+		},
+		{
+			name: "should clear the field of every list element",
+			message: &descriptorpb.FileDescriptorProto{
+				MessageType: []*descriptorpb.DescriptorProto{
+					{Name: proto.String("First")},
+					{Name: proto.String("Second")},
+				},
+			},
+			paths: []string{"message_type.name"},
+			expectedMessage: &descriptorpb.FileDescriptorProto{
+				MessageType: []*descriptorpb.DescriptorProto{{}, {}},
+			},
+		},
+		{
+			name:            "should ignore unknown and unpopulated paths",
+			message:         &descriptorpb.FileDescriptorProto{Name: proto.String("file.proto")},
+			paths:           []string{"unknown", "options.go_package"},
+			expectedMessage: &descriptorpb.FileDescriptorProto{Name: proto.String("file.proto")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dealtest.ClearFields(test.message, test.paths...)
+			if !proto.Equal(test.message, test.expectedMessage) {
+				t.Errorf("Wrong message, given: %v, expected: %v", test.message, test.expectedMessage)
+			}
+		})
+	}
+}
+
+func TestKeepFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		message         proto.Message
+		paths           []string
+		expectedMessage proto.Message
+	}{
+		{
+			name: "should keep only the top level field",
+			message: &descriptorpb.FileDescriptorProto{
+				Name:    proto.String("file.proto"),
+				Package: proto.String("example"),
+			},
+			paths:           []string{"package"},
+			expectedMessage: &descriptorpb.FileDescriptorProto{Package: proto.String("example")},
+		},
+		{
+			name: "should keep only the nested field",
+			message: &descriptorpb.FileDescriptorProto{
+				Name: proto.String("file.proto"),
+				Options: &descriptorpb.FileOptions{
+					GoPackage:   proto.String("example"),
+					JavaPackage: proto.String("example"),
+				},
+			},
+			paths: []string{"options.goPackage"},
+			expectedMessage: &descriptorpb.FileDescriptorProto{
+				Options: &descriptorpb.FileOptions{GoPackage: proto.String("example")},
+			},
+		},
+		{
+			name: "should keep the whole message when it's referenced",
+			message: &descriptorpb.FileDescriptorProto{
+				Name: proto.String("file.proto"),
+				Options: &descriptorpb.FileOptions{
+					GoPackage:   proto.String("example"),
+					JavaPackage: proto.String("example"),
+				},
+			},
+			paths: []string{"options.go_package", "options"},
+			expectedMessage: &descriptorpb.FileDescriptorProto{
+				Options: &descriptorpb.FileOptions{
+					GoPackage:   proto.String("example"),
+					JavaPackage: proto.String("example"),
+				},
+			},
+		},
+		{
+			name: "should keep the field of every list element",
+			message: &descriptorpb.FileDescriptorProto{
+				MessageType: []*descriptorpb.DescriptorProto{
+					{Name: proto.String("First"), Field: []*descriptorpb.FieldDescriptorProto{{}}},
+					{Name: proto.String("Second")},
+				},
+			},
+			paths: []string{"message_type.name"},
+			expectedMessage: &descriptorpb.FileDescriptorProto{
+				MessageType: []*descriptorpb.DescriptorProto{
+					{Name: proto.String("First")},
+					{Name: proto.String("Second")},
+				},
+			},
+		},
+		{
+			name:            "should clear everything when no path exists",
+			message:         &descriptorpb.FileDescriptorProto{Name: proto.String("file.proto")},
+			paths:           []string{"unknown"},
+			expectedMessage: &descriptorpb.FileDescriptorProto{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dealtest.KeepFields(test.message, test.paths...)
+			if !proto.Equal(test.message, test.expectedMessage) {
+				t.Errorf("Wrong message, given: %v, expected: %v", test.message, test.expectedMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package dealtest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// MatchMessage tells if the message satisfies the expected one following the given mode,
+// an empty mode means an exact match. See the entities.MessageMatch values.
+func MatchMessage(mode, expected, message string) (bool, error) {
+	switch mode {
+	case "", entities.MessageMatchExact:
+		return message == expected, nil
+	case entities.MessageMatchContains:
+		return strings.Contains(message, expected), nil
+	case entities.MessageMatchRegex:
+		pattern, err := regexp.Compile(expected)
+		if err != nil {
+			return false, fmt.Errorf("invalid message pattern: %w", err)
+		}
+
+		return pattern.MatchString(message), nil
+	case entities.MessageMatchIgnore:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown message match: %s", mode)
+	}
+}
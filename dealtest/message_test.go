@@ -0,0 +1,87 @@
+package dealtest_test
+
+import (
+	"testing"
+
+	"github.com/faunists/deal-go/dealtest"
+	"github.com/faunists/deal-go/entities"
+)
+
+func TestMatchMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		mode          string
+		expected      string
+		message       string
+		expectedMatch bool
+		expectedError bool
+	}{
+		{
+			name:          "should match the same message by default",
+			expected:      "user not found",
+			message:       "user not found",
+			expectedMatch: true,
+		},
+		{
+			name:          "should not match a different message exactly",
+			mode:          entities.MessageMatchExact,
+			expected:      "user not found",
+			message:       "user 42 not found",
+			expectedMatch: false,
+		},
+		{
+			name:          "should match a message containing the expected one",
+			mode:          entities.MessageMatchContains,
+			expected:      "not found",
+			message:       "user 42 not found",
+			expectedMatch: true,
+		},
+		{
+			name:          "should match a message satisfying the pattern",
+			mode:          entities.MessageMatchRegex,
+			expected:      `^user \d+ not found$`,
+			message:       "user 42 not found",
+			expectedMatch: true,
+		},
+		{
+			name:          "should not match a message not satisfying the pattern",
+			mode:          entities.MessageMatchRegex,
+			expected:      `^user \d+ not found$`,
+			message:       "user john not found",
+			expectedMatch: false,
+		},
+		{
+			name:          "should match any message when it's ignored",
+			mode:          entities.MessageMatchIgnore,
+			expected:      "user not found",
+			message:       "something else",
+			expectedMatch: true,
+		},
+		{
+			name:          "should fail when the pattern is invalid",
+			mode:          entities.MessageMatchRegex,
+			expected:      `(`,
+			expectedError: true,
+		},
+		{
+			name:          "should fail when the mode is unknown",
+			mode:          "prefix",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matched, err := dealtest.MatchMessage(test.mode, test.expected, test.message)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("Unexpected error result, given: %v", err)
+			}
+
+			if matched != test.expectedMatch {
+				t.Errorf("Given: %v, expected: %v", matched, test.expectedMatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package dealtest
+
+import (
+	"context"
+	"math"
+	"net"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+const (
+	// DefaultGracefulStopTimeout is how long the contract tests wait for the server
+	// to stop gracefully before stopping it
+	DefaultGracefulStopTimeout = 5 * time.Second
+	// DefaultBufferSize is the size of the in-memory connection used by the contract tests
+	DefaultBufferSize = 1024 * 1024
+	// DefaultMaxRecvMsgSize and DefaultMaxSendMsgSize are the message size limits of the
+	// contract tests client, they're the same as the grpc-go defaults
+	DefaultMaxRecvMsgSize = 4 * 1024 * 1024
+	DefaultMaxSendMsgSize = math.MaxInt32
+)
+
+// ErrorComparer verifies the error returned by the server against the contract one,
+// the test fails when a non nil error is returned
+type ErrorComparer func(expected entities.GRPCError, actual error) error
+
+// LeakChecker returns an error when goroutines were leaked, e.g. a function calling goleak.Find.
+// It's called once the server of the contract tests is stopped.
+type LeakChecker func() error
+
+// ContractTestConfig holds the settings of a generated contract test
+type ContractTestConfig struct {
+	ErrorComparer       ErrorComparer
+	GracefulStopTimeout time.Duration
+	BufferSize          int
+	MaxRecvMsgSize      int
+	MaxSendMsgSize      int
+	LeakChecker         LeakChecker
+	// CompareOptions are applied while comparing the responses, see ResponseDiff
+	CompareOptions []cmp.Option
+	// CallTimeout bounds the calls of the cases without their own timeout
+	CallTimeout time.Duration
+	// Listener replaces the in-memory listener of the server when provided
+	Listener net.Listener
+	// DialOptions are added to the options of the contract tests client
+	DialOptions []grpc.DialOption
+	// ServerOptions are the options of the server created by the generated
+	// ContractTestWithService functions
+	ServerOptions []grpc.ServerOption
+
+	// verification is set by WithVerificationPublisher
+	verification *verification
+}
+
+// ContractTestOption configures a generated contract test
+type ContractTestOption func(*ContractTestConfig)
+
+// NewContractTestConfig returns the config resulting of the given options
+func NewContractTestConfig(opts ...ContractTestOption) ContractTestConfig {
+	config := ContractTestConfig{
+		GracefulStopTimeout: DefaultGracefulStopTimeout,
+		BufferSize:          DefaultBufferSize,
+		MaxRecvMsgSize:      DefaultMaxRecvMsgSize,
+		MaxSendMsgSize:      DefaultMaxSendMsgSize,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
+// WithErrorComparer replaces the verification of the errors returned by the server
+// in the failure cases, it's useful when the errors carry a custom envelope
+func WithErrorComparer(comparer ErrorComparer) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.ErrorComparer = comparer
+	}
+}
+
+// WithGracefulStopTimeout changes how long the contract tests wait for the in-flight calls
+// before stopping the server, a zero timeout stops it right away
+func WithGracefulStopTimeout(timeout time.Duration) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.GracefulStopTimeout = timeout
+	}
+}
+
+// WithBufferSize changes the size of the in-memory connection between the client
+// and the server of the contract tests
+func WithBufferSize(size int) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.BufferSize = size
+	}
+}
+
+// WithMaxMessageSize changes the size limits of the messages received and sent by the
+// contract tests client, the server limits are set through the options of your server
+func WithMaxMessageSize(recv, send int) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.MaxRecvMsgSize = recv
+		config.MaxSendMsgSize = send
+	}
+}
+
+// WithLeakChecker verifies the server doesn't leak goroutines, the checker
+// is called after the server of the contract tests is stopped
+func WithLeakChecker(checker LeakChecker) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.LeakChecker = checker
+	}
+}
+
+// WithCompareOptions adds options to the comparison of the responses, e.g. protocmp.IgnoreFields
+// or protocmp.SortRepeatedFields, without changing the contract file
+func WithCompareOptions(opts ...cmp.Option) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.CompareOptions = append(config.CompareOptions, opts...)
+	}
+}
+
+// WithCallTimeout bounds each call of the cases without their own timeout,
+// so a hung server fails the case instead of blocking the tests
+func WithCallTimeout(timeout time.Duration) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.CallTimeout = timeout
+	}
+}
+
+// WithListener serves the server through the given listener, e.g. a TCP or a unix socket one,
+// instead of the in-memory connection. The listener is closed once the server is stopped.
+func WithListener(listener net.Listener) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.Listener = listener
+	}
+}
+
+// WithDialOptions adds options to the ones used by the contract tests client, e.g. interceptors
+// or per-RPC credentials. They're applied after the default ones.
+func WithDialOptions(opts ...grpc.DialOption) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.DialOptions = append(config.DialOptions, opts...)
+	}
+}
+
+// WithServerOptions adds options to the server created by the generated ContractTestWithService
+// functions, e.g. message size limits or interceptors. The servers given to the ContractTest
+// functions are already created, so these options are rejected by them.
+func WithServerOptions(opts ...grpc.ServerOption) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.ServerOptions = append(config.ServerOptions, opts...)
+	}
+}
+
+// CallContext returns the context of a single call, bounded by the case timeout or
+// by the CallTimeout when the case has none. Zero timeouts leave the context unbounded.
+func (c ContractTestConfig) CallContext(
+	ctx context.Context,
+	timeout time.Duration,
+) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = c.CallTimeout
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,209 @@
+package dealtest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/faunists/deal-go/dealtest"
+)
+
+func TestNewContractTestConfigGracefulStopTimeout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		opts            []dealtest.ContractTestOption
+		expectedTimeout time.Duration
+	}{
+		{
+			name:            "should use the default timeout",
+			expectedTimeout: dealtest.DefaultGracefulStopTimeout,
+		},
+		{
+			name: "should use the provided timeout",
+			opts: []dealtest.ContractTestOption{
+				dealtest.WithGracefulStopTimeout(time.Second),
+			},
+			expectedTimeout: time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := dealtest.NewContractTestConfig(test.opts...)
+			if config.GracefulStopTimeout != test.expectedTimeout {
+				t.Errorf(
+					"Expected timeout: %v, given: %v",
+					test.expectedTimeout, config.GracefulStopTimeout,
+				)
+			}
+		})
+	}
+}
+
+func TestNewContractTestConfigConnectionSizes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                   string
+		opts                   []dealtest.ContractTestOption
+		expectedBufferSize     int
+		expectedMaxRecvMsgSize int
+		expectedMaxSendMsgSize int
+	}{
+		{
+			name:                   "should use the default sizes",
+			expectedBufferSize:     dealtest.DefaultBufferSize,
+			expectedMaxRecvMsgSize: dealtest.DefaultMaxRecvMsgSize,
+			expectedMaxSendMsgSize: dealtest.DefaultMaxSendMsgSize,
+		},
+		{
+			name: "should use the provided sizes",
+			opts: []dealtest.ContractTestOption{
+				dealtest.WithBufferSize(2048),          //nolint:revive // random size
+				dealtest.WithMaxMessageSize(4096, 512), //nolint:revive // random sizes
+			},
+			expectedBufferSize:     2048, //nolint:revive // random size
+			expectedMaxRecvMsgSize: 4096, //nolint:revive // random size
+			expectedMaxSendMsgSize: 512,  //nolint:revive // random size
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := dealtest.NewContractTestConfig(test.opts...)
+			if config.BufferSize != test.expectedBufferSize {
+				t.Errorf(
+					"Expected buffer size: %d, given: %d",
+					test.expectedBufferSize, config.BufferSize,
+				)
+			}
+			if config.MaxRecvMsgSize != test.expectedMaxRecvMsgSize ||
+				config.MaxSendMsgSize != test.expectedMaxSendMsgSize {
+				t.Errorf(
+					"Expected message sizes: %d/%d, given: %d/%d",
+					test.expectedMaxRecvMsgSize, test.expectedMaxSendMsgSize,
+					config.MaxRecvMsgSize, config.MaxSendMsgSize,
+				)
+			}
+		})
+	}
+}
+
+func TestWithLeakChecker(t *testing.T) {
+	t.Parallel()
+
+	errLeak := errors.New("leaked goroutines")
+	config := dealtest.NewContractTestConfig(
+		dealtest.WithLeakChecker(func() error { return errLeak }),
+	)
+
+	if config.LeakChecker == nil {
+		t.Fatalf("Expected a leak checker")
+	}
+	if err := config.LeakChecker(); !errors.Is(err, errLeak) {
+		t.Errorf("Expected the checker error, given: %v", err)
+	}
+}
+
+func TestWithDialOptions(t *testing.T) {
+	t.Parallel()
+
+	first := grpc.WithUserAgent("first")
+	second := grpc.WithAuthority("second")
+	third := grpc.WithBlock()
+	config := dealtest.NewContractTestConfig(
+		dealtest.WithDialOptions(first),
+		dealtest.WithDialOptions(second, third),
+	)
+
+	expected := []grpc.DialOption{first, second, third}
+	if len(config.DialOptions) != len(expected) {
+		t.Fatalf("Expected dial options: %v, given: %v", expected, config.DialOptions)
+	}
+	for i, option := range expected {
+		if config.DialOptions[i] != option {
+			t.Errorf("Expected dial option %d: %v, given: %v", i, option, config.DialOptions[i])
+		}
+	}
+}
+
+func TestWithServerOptions(t *testing.T) {
+	t.Parallel()
+
+	first := grpc.MaxRecvMsgSize(1)
+	second := grpc.MaxSendMsgSize(2)
+	config := dealtest.NewContractTestConfig(
+		dealtest.WithServerOptions(first),
+		dealtest.WithServerOptions(second),
+	)
+
+	expected := []grpc.ServerOption{first, second}
+	if len(config.ServerOptions) != len(expected) {
+		t.Fatalf("Expected server options: %v, given: %v", expected, config.ServerOptions)
+	}
+	for i, option := range expected {
+		if config.ServerOptions[i] != option {
+			t.Errorf("Expected server option %d: %v, given: %v", i, option, config.ServerOptions[i])
+		}
+	}
+}
+
+func TestContractTestConfigCallContext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		opts             []dealtest.ContractTestOption
+		timeout          time.Duration
+		expectedDeadline bool
+		expectedTimeout  time.Duration
+	}{
+		{
+			name:             "should not bound the call without timeouts",
+			expectedDeadline: false,
+		},
+		{
+			name:             "should use the case timeout",
+			opts:             []dealtest.ContractTestOption{dealtest.WithCallTimeout(time.Hour)},
+			timeout:          time.Minute,
+			expectedDeadline: true,
+			expectedTimeout:  time.Minute,
+		},
+		{
+			name:             "should use the call timeout when the case has none",
+			opts:             []dealtest.ContractTestOption{dealtest.WithCallTimeout(time.Hour)},
+			expectedDeadline: true,
+			expectedTimeout:  time.Hour,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := dealtest.NewContractTestConfig(test.opts...)
+			ctx, cancel := config.CallContext(context.Background(), test.timeout)
+			defer cancel()
+
+			deadline, hasDeadline := ctx.Deadline()
+			if hasDeadline != test.expectedDeadline {
+				t.Fatalf("Expected a deadline: %t, given: %t", test.expectedDeadline, hasDeadline)
+			}
+			if !hasDeadline {
+				return
+			}
+
+			// The deadline is computed from the current time, so it's compared with a margin
+			remaining := time.Until(deadline)
+			if remaining > test.expectedTimeout || remaining < test.expectedTimeout-time.Second {
+				t.Errorf("Expected a timeout of %s, given: %s", test.expectedTimeout, remaining)
+			}
+		})
+	}
+}
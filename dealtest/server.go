@@ -0,0 +1,42 @@
+package dealtest
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// StopGracefully calls gracefulStop, waiting for the in-flight calls to finish, and calls stop
+// when they don't finish within the timeout. It's used to stop the server of the contract tests.
+func StopGracefully(gracefulStop, stop func(), timeout time.Duration) {
+	if timeout <= 0 {
+		stop()
+		return
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		gracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+	case <-timer.C:
+		stop()
+	}
+}
+
+// ListenerDialer returns a dialer connecting to the address of the given listener,
+// the contract tests use it to reach the server through a listener given by the caller
+func ListenerDialer(listener net.Listener) func(context.Context, string) (net.Conn, error) {
+	return func(ctx context.Context, _ string) (net.Conn, error) {
+		address := listener.Addr()
+		dialer := net.Dialer{}
+
+		return dialer.DialContext(ctx, address.Network(), address.String())
+	}
+}
@@ -0,0 +1,104 @@
+package dealtest_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/faunists/deal-go/dealtest"
+)
+
+func TestStopGracefully(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                 string
+		gracefulStopDuration time.Duration
+		timeout              time.Duration
+		expectedStopCalled   bool
+	}{
+		{
+			name:                 "should not stop when the graceful stop finishes in time",
+			gracefulStopDuration: 0,
+			timeout:              time.Second,
+			expectedStopCalled:   false,
+		},
+		{
+			name:                 "should stop when the graceful stop takes too long",
+			gracefulStopDuration: time.Second,
+			timeout:              time.Millisecond,
+			expectedStopCalled:   true,
+		},
+		{
+			name:                 "should stop right away without a timeout",
+			gracefulStopDuration: time.Second,
+			timeout:              0,
+			expectedStopCalled:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			release, duration := make(chan struct{}), test.gracefulStopDuration
+			gracefulStop := func() {
+				select {
+				case <-release:
+				case <-time.After(duration):
+				}
+			}
+
+			var stopCalled int32
+			stop := func() {
+				atomic.StoreInt32(&stopCalled, 1)
+				close(release)
+			}
+
+			dealtest.StopGracefully(gracefulStop, stop, test.timeout)
+
+			if (atomic.LoadInt32(&stopCalled) == 1) != test.expectedStopCalled {
+				t.Errorf("Expected stop called: %v", test.expectedStopCalled)
+			}
+		})
+	}
+}
+
+func TestListenerDialer(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+		close(accepted)
+	}()
+
+	dial := dealtest.ListenerDialer(listener)
+	conn, err := dial(context.Background(), "ignored")
+	if err != nil {
+		t.Fatalf("Unexpected error happened: %v", err)
+	}
+	defer conn.Close()
+
+	serverConn, ok := <-accepted
+	if !ok {
+		t.Fatalf("Expected the listener to accept the connection")
+	}
+	defer serverConn.Close()
+
+	if serverConn.RemoteAddr().String() != conn.LocalAddr().String() {
+		t.Errorf(
+			"Expected the connection from: %s, given: %s",
+			conn.LocalAddr(), serverConn.RemoteAddr(),
+		)
+	}
+}
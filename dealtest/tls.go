@@ -0,0 +1,66 @@
+package dealtest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSOption configures the TLS settings used to verify a remote server
+type TLSOption func(*tls.Config) error
+
+// NewTLSConfig returns the TLS settings resulting of the given options, they're given to the
+// generated verify functions through grpc.WithTransportCredentials(credentials.NewTLS(config))
+func NewTLSConfig(opts ...TLSOption) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	for _, opt := range opts {
+		if err := opt(config); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// WithCABundle trusts the certificates of the given PEM file instead of the system ones
+func WithCABundle(path string) TLSOption {
+	return func(config *tls.Config) error {
+		bundle, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read the CA bundle: %w", err)
+		}
+
+		if config.RootCAs == nil {
+			config.RootCAs = x509.NewCertPool()
+		}
+		if !config.RootCAs.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("no certificates found in the CA bundle %s", path)
+		}
+
+		return nil
+	}
+}
+
+// WithClientCertificate presents the given certificate to the server, as required by mTLS,
+// both files must be PEM encoded
+func WithClientCertificate(certFile, keyFile string) TLSOption {
+	return func(config *tls.Config) error {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+
+		config.Certificates = append(config.Certificates, certificate)
+		return nil
+	}
+}
+
+// WithServerName overrides the name used to verify the server certificate,
+// it's useful when the target is an IP address or a load balancer
+func WithServerName(name string) TLSOption {
+	return func(config *tls.Config) error {
+		config.ServerName = name
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+package dealtest_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/faunists/deal-go/dealtest"
+)
+
+// writeCertificate writes a self-signed certificate and its key as PEM files
+func writeCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate the key: %v", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "deal"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create the certificate: %v", err)
+	}
+
+	privateKey, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal the key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	files := map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: certificate},
+		keyFile:  {Type: "PRIVATE KEY", Bytes: privateKey},
+	}
+	for path, block := range files {
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := writeCertificate(t)
+	config, err := dealtest.NewTLSConfig(
+		dealtest.WithCABundle(certFile),
+		dealtest.WithClientCertificate(certFile, keyFile),
+		dealtest.WithServerName("deal.example.com"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error happened: %v", err)
+	}
+
+	if config.RootCAs == nil {
+		t.Errorf("Expected the CA bundle to be trusted")
+	}
+	if len(config.Certificates) != 1 {
+		t.Errorf("Expected one client certificate, given: %d", len(config.Certificates))
+	}
+	if config.ServerName != "deal.example.com" {
+		t.Errorf("Expected the server name: deal.example.com, given: %s", config.ServerName)
+	}
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := writeCertificate(t)
+	missingFile := filepath.Join(t.TempDir(), "missing.pem")
+
+	tests := []struct {
+		name   string
+		option dealtest.TLSOption
+	}{
+		{
+			name:   "should fail when the CA bundle doesn't exist",
+			option: dealtest.WithCABundle(missingFile),
+		},
+		{
+			name:   "should fail when the CA bundle has no certificates",
+			option: dealtest.WithCABundle(keyFile),
+		},
+		{
+			name:   "should fail when the client key doesn't match the certificate",
+			option: dealtest.WithClientCertificate(certFile, certFile),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := dealtest.NewTLSConfig(test.option); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package dealtest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/faunists/deal-go/brokerclient"
+)
+
+// verificationPublishTimeout bounds the publication of the verification result
+const verificationPublishTimeout = 10 * time.Second
+
+// VerificationT is the part of testing.TB used to record the results of the cases
+type VerificationT interface {
+	Name() string
+	Failed() bool
+	Cleanup(func())
+	Errorf(format string, args ...interface{})
+}
+
+// VerificationPublisher publishes the verification results, e.g. a *brokerclient.Client
+type VerificationPublisher interface {
+	PublishVerificationResult(
+		ctx context.Context,
+		result brokerclient.VerificationResult,
+	) (brokerclient.VerificationResult, error)
+}
+
+// verification collects the results of the cases of a contract test run
+type verification struct {
+	publisher VerificationPublisher
+	result    brokerclient.VerificationResult
+	rootName  string
+
+	mutex sync.Mutex
+	cases []brokerclient.CaseResult
+}
+
+// WithVerificationPublisher publishes the result of each case once the contract test ran, the
+// result names the consumer and the provider versions, e.g. the git SHA of the provider built
+// by the CI. The success of the result and its cases are filled by the contract test.
+func WithVerificationPublisher(
+	publisher VerificationPublisher,
+	result brokerclient.VerificationResult,
+) ContractTestOption {
+	return func(config *ContractTestConfig) {
+		config.verification = &verification{publisher: publisher, result: result}
+	}
+}
+
+// StartVerification publishes the verification result once the test and its cases are done,
+// it does nothing without a VerificationPublisher. The publication keeps the values of ctx but
+// not its cancellation, which usually happened by then, and is bounded by its own timeout.
+func (c ContractTestConfig) StartVerification(ctx context.Context, t VerificationT) {
+	if c.verification == nil {
+		return
+	}
+
+	c.verification.rootName = t.Name()
+	t.Cleanup(func() {
+		result := c.verification.result
+		result.Success = !t.Failed()
+		result.Cases = c.verification.caseResults()
+
+		publishCtx, cancel := context.WithTimeout(
+			detachedContext{parent: ctx}, verificationPublishTimeout,
+		)
+		defer cancel()
+
+		_, err := c.verification.publisher.PublishVerificationResult(publishCtx, result)
+		if err != nil {
+			t.Errorf("Failed to publish the verification result: %v", err)
+		}
+	})
+}
+
+// RecordCase records the outcome and the duration of the case once it's done, it does nothing
+// without a VerificationPublisher
+func (c ContractTestConfig) RecordCase(t VerificationT) {
+	if c.verification == nil {
+		return
+	}
+
+	start := time.Now()
+	t.Cleanup(func() {
+		c.verification.addCase(brokerclient.CaseResult{
+			Name:     strings.TrimPrefix(t.Name(), c.verification.rootName+"/"),
+			Success:  !t.Failed(),
+			Duration: time.Since(start),
+		})
+	})
+}
+
+func (v *verification) addCase(result brokerclient.CaseResult) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.cases = append(v.cases, result)
+}
+
+func (v *verification) caseResults() []brokerclient.CaseResult {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return append([]brokerclient.CaseResult{}, v.cases...)
+}
+
+// detachedContext keeps the values of its parent but neither its deadline nor its cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (d detachedContext) Value(key interface{}) interface{} { return d.parent.Value(key) }
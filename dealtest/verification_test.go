@@ -0,0 +1,152 @@
+package dealtest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/faunists/deal-go/brokerclient"
+	"github.com/faunists/deal-go/dealtest"
+)
+
+// fakeVerificationT runs its cleanups when the test is done, in the reverse order
+type fakeVerificationT struct {
+	name     string
+	failed   bool
+	cleanups []func()
+	failures []string
+}
+
+func (f *fakeVerificationT) Name() string { return f.name }
+
+func (f *fakeVerificationT) Failed() bool { return f.failed }
+
+func (f *fakeVerificationT) Cleanup(cleanup func()) {
+	f.cleanups = append(f.cleanups, cleanup)
+}
+
+func (f *fakeVerificationT) Errorf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeVerificationT) done() {
+	for index := len(f.cleanups) - 1; index >= 0; index-- {
+		f.cleanups[index]()
+	}
+}
+
+// fakePublisher keeps the published results
+type fakePublisher struct {
+	results []brokerclient.VerificationResult
+	err     error
+	ctxErrs []error
+}
+
+func (f *fakePublisher) PublishVerificationResult(
+	ctx context.Context,
+	result brokerclient.VerificationResult,
+) (brokerclient.VerificationResult, error) {
+	f.ctxErrs = append(f.ctxErrs, ctx.Err())
+	f.results = append(f.results, result)
+	return result, f.err
+}
+
+func TestContractTestConfigPublishesTheVerification(t *testing.T) {
+	t.Parallel()
+
+	publisher := &fakePublisher{}
+	config := dealtest.NewContractTestConfig(dealtest.WithVerificationPublisher(
+		publisher,
+		brokerclient.VerificationResult{
+			Consumer: "web", ConsumerVersion: "1", Provider: "users", ProviderVersion: "a",
+		},
+	))
+
+	root := &fakeVerificationT{name: "TestUsers"}
+	config.StartVerification(context.Background(), root)
+	for _, test := range []struct {
+		name   string
+		failed bool
+	}{
+		{name: "TestUsers/Success_Cases/found_0", failed: false},
+		{name: "TestUsers/Failure_Cases/missing_0", failed: true},
+	} {
+		caseT := &fakeVerificationT{name: test.name, failed: test.failed}
+		config.RecordCase(caseT)
+		caseT.done()
+	}
+	root.failed = true
+	root.done()
+
+	if len(publisher.results) != 1 {
+		t.Fatalf("Given: %d results, expected: 1", len(publisher.results))
+	}
+	result := publisher.results[0]
+	if result.Success || result.ProviderVersion != "a" {
+		t.Errorf("Given: %+v, expected: the failed verification by a", result)
+	}
+
+	expectedCases := []brokerclient.CaseResult{
+		{Name: "Success_Cases/found_0", Success: true},
+		{Name: "Failure_Cases/missing_0", Success: false},
+	}
+	if len(result.Cases) != len(expectedCases) {
+		t.Fatalf("Given: %+v, expected: %+v", result.Cases, expectedCases)
+	}
+	for index, caseResult := range result.Cases {
+		caseResult.Duration = 0
+		if caseResult != expectedCases[index] {
+			t.Errorf("Given: %+v, expected: %+v", caseResult, expectedCases[index])
+		}
+	}
+}
+
+func TestContractTestConfigReportsThePublicationFailures(t *testing.T) {
+	t.Parallel()
+
+	publisher := &fakePublisher{err: errors.New("broker unavailable")}
+	config := dealtest.NewContractTestConfig(
+		dealtest.WithVerificationPublisher(publisher, brokerclient.VerificationResult{}),
+	)
+
+	root := &fakeVerificationT{name: "TestUsers"}
+	config.StartVerification(context.Background(), root)
+	root.done()
+
+	if len(root.failures) != 1 {
+		t.Errorf("Given: %v, expected: the publication failure", root.failures)
+	}
+}
+
+func TestContractTestConfigPublishesAfterTheCancellation(t *testing.T) {
+	t.Parallel()
+
+	publisher := &fakePublisher{}
+	config := dealtest.NewContractTestConfig(
+		dealtest.WithVerificationPublisher(publisher, brokerclient.VerificationResult{}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	root := &fakeVerificationT{name: "TestUsers"}
+	config.StartVerification(ctx, root)
+	cancel()
+	root.done()
+
+	if len(publisher.ctxErrs) != 1 || publisher.ctxErrs[0] != nil {
+		t.Errorf("Given: %v, expected: a single publication with a live context", publisher.ctxErrs)
+	}
+}
+
+func TestContractTestConfigWithoutPublisher(t *testing.T) {
+	t.Parallel()
+
+	config := dealtest.NewContractTestConfig()
+	root := &fakeVerificationT{name: "TestUsers"}
+	config.StartVerification(context.Background(), root)
+	config.RecordCase(root)
+
+	if len(root.cleanups) != 0 {
+		t.Errorf("Given: %d cleanups, expected: none", len(root.cleanups))
+	}
+}
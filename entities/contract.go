@@ -1,10 +1,14 @@
 package entities
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
 
 // Contract represents the root of everything that will be generated
 type Contract struct {
-	Name     string             `json:"name"`
+	Name     string             `json:"name,omitempty"`
 	Services map[string]Service `json:"services"`
 }
 
@@ -12,35 +16,275 @@ type Contract struct {
 // The key represents the service name
 type Service map[string]Method
 
-// Method handles the two possibles cases to be generated:
+// Method handles the possibles cases to be generated:
 //   - Success
+//   - Application error
 //   - Failure
 type Method struct {
-	SuccessCases []SuccessCase `json:"successCases"`
-	FailureCases []FailureCase `json:"failureCases"`
+	SuccessCases          []SuccessCase          `json:"successCases,omitempty"`
+	ApplicationErrorCases []ApplicationErrorCase `json:"applicationErrorCases,omitempty"`
+	FailureCases          []FailureCase          `json:"failureCases,omitempty"`
 }
 
-// SuccessCase handles the information about the request and response of a method
+// SuccessCase handles the information about the request and response of a method.
+// Cases with a higher Priority are matched first, ties keep the declaration order.
+// Oneofs maps a oneof name to the variant that must be set in the request, when the request
+// doesn't provide a value for the variant any value is accepted.
+// Responses replaces Response when consecutive calls should return different results.
+// Delay is a duration (e.g. "150ms") waited by the generated client before returning.
+// FailFirst makes the generated client fail before returning the response(s), since it
+// simulates transient failures it isn't verified against the server, nor used by the
+// generated contract server.
+// Headers and Trailers are the response metadata sent along with the case result.
+// Timeout is a duration (e.g. "2s") bounding each call of the server tests.
 type SuccessCase struct {
-	Description string      `json:"description"`
-	Request     interface{} `json:"request"`
-	Response    interface{} `json:"response"`
+	Description string            `json:"description,omitempty"`
+	Priority    int               `json:"priority,omitempty"`
+	Delay       string            `json:"delay,omitempty"`
+	Timeout     string            `json:"timeout,omitempty"`
+	Request     interface{}       `json:"request,omitempty"`
+	Oneofs      map[string]string `json:"oneofs,omitempty"`
+	Response    interface{}       `json:"response,omitempty"`
+	Responses   []SequenceStep    `json:"responses,omitempty"`
+	FailFirst   *FailFirst        `json:"failFirst,omitempty"`
+	Headers     Metadata          `json:"headers,omitempty"`
+	Trailers    Metadata          `json:"trailers,omitempty"`
+}
+
+// Sequence returns the steps returned by the generated client for a sequenced case,
+// the failures declared by FailFirst come before the responses.
+// When the case isn't sequenced nil is returned.
+func (c SuccessCase) Sequence() []SequenceStep {
+	if c.FailFirst == nil && len(c.Responses) == 0 {
+		return nil
+	}
+
+	steps := make([]SequenceStep, 0)
+	if c.FailFirst != nil {
+		failure := c.FailFirst.Failure()
+		for i := 0; i < c.FailFirst.Times; i++ {
+			steps = append(steps, SequenceStep{Error: &failure})
+		}
+	}
+
+	if len(c.Responses) > 0 {
+		return append(steps, c.Responses...)
+	}
+
+	return append(steps, SequenceStep{Response: c.Response})
+}
+
+// DefaultFailFirstError is the error returned by FailFirst when no error is provided
+var DefaultFailFirstError = GRPCError{ErrorCode: "Unavailable", Message: "service unavailable"}
+
+// FailFirst handles the number of times a case fails, and the error returned,
+// before succeeding. It's useful to test retry policies.
+type FailFirst struct {
+	Times int        `json:"times,omitempty"`
+	Error *GRPCError `json:"error,omitempty"`
+}
+
+// Failure returns the error of each failed call, DefaultFailFirstError when Error isn't provided
+func (f FailFirst) Failure() GRPCError {
+	if f.Error == nil {
+		return DefaultFailFirstError
+	}
+
+	return *f.Error
+}
+
+// SequenceStep handles the result of a single call of a sequence, the n-th call of a case
+// returns its n-th step and once the steps are over the last one keeps being returned.
+// A step returns the Error when it's provided, otherwise the Response.
+type SequenceStep struct {
+	Response interface{} `json:"response,omitempty"`
+	Error    *GRPCError  `json:"error,omitempty"`
+}
+
+// ApplicationErrorCase handles a response carrying an application error, e.g. a status field,
+// instead of a GRPC error. ErrorField is the dot separated path of the response field holding
+// the error payload, the server tests verify only this field.
+// Priority, Delay, Timeout, Oneofs, Headers and Trailers work the same way as in SuccessCase.
+type ApplicationErrorCase struct {
+	Description string            `json:"description,omitempty"`
+	Priority    int               `json:"priority,omitempty"`
+	Delay       string            `json:"delay,omitempty"`
+	Timeout     string            `json:"timeout,omitempty"`
+	Request     interface{}       `json:"request,omitempty"`
+	Oneofs      map[string]string `json:"oneofs,omitempty"`
+	Response    interface{}       `json:"response,omitempty"`
+	ErrorField  string            `json:"errorField,omitempty"`
+	Headers     Metadata          `json:"headers,omitempty"`
+	Trailers    Metadata          `json:"trailers,omitempty"`
 }
 
 // FailureCase handles the information about the request and the error that should be returned
-// for a given request
+// for a given request. Priority, Delay, Timeout, Oneofs, Headers and Trailers work the same way
+// as in SuccessCase.
+// MessageMatch tells how the server tests verify the error message, see the MessageMatch values.
 type FailureCase struct {
-	Description string      `json:"description"`
-	Request     interface{} `json:"request"`
-	Error       GRPCError   `json:"error"`
+	Description  string            `json:"description,omitempty"`
+	Priority     int               `json:"priority,omitempty"`
+	Delay        string            `json:"delay,omitempty"`
+	Timeout      string            `json:"timeout,omitempty"`
+	Request      interface{}       `json:"request,omitempty"`
+	Oneofs       map[string]string `json:"oneofs,omitempty"`
+	Error        GRPCError         `json:"error"`
+	MessageMatch string            `json:"messageMatch,omitempty"`
+	Headers      Metadata          `json:"headers,omitempty"`
+	Trailers     Metadata          `json:"trailers,omitempty"`
+}
+
+// The ways an error message can be matched, an empty MessageMatch means MessageMatchExact
+const (
+	// MessageMatchExact expects the same message
+	MessageMatchExact = "exact"
+	// MessageMatchContains expects a message containing the contract one
+	MessageMatchContains = "contains"
+	// MessageMatchRegex expects a message matching the contract one as a regular expression
+	MessageMatchRegex = "regex"
+	// MessageMatchIgnore doesn't verify the message, only the error code
+	MessageMatchIgnore = "ignore"
+)
+
+// Metadata handles GRPC metadata, the key represents the metadata key
+type Metadata map[string]MetadataValues
+
+// MetadataValues handles the values of a metadata key,
+// it can be written either as a single string or a list of strings
+type MetadataValues []string
+
+// UnmarshalJSON accepts both a single string and a list of strings
+func (v *MetadataValues) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*v = MetadataValues{value}
+		return nil
+	}
+
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("metadata values must be a string or a list of strings: %w", err)
+	}
+
+	*v = values
+	return nil
 }
 
-// GRPCError handles the information about the error code and the string message of a GRPC error
+// GRPCError handles the information about the error code and the string message of a GRPC error,
+// Details are optional and sent along with the error status.
+// AlternativeCodes are also accepted from the server, they're provided by writing the errorCode
+// as a list, e.g. ["NotFound", "FailedPrecondition"], where the first code is the ErrorCode.
+// When MessageArgs are provided the Message is a format (e.g. "user %s not found") and the
+// arguments are the paths of the request fields filling its placeholders. The formatted message
+// is matched exactly, so the contains and regex message matches can't be used along with them.
 type GRPCError struct {
-	ErrorCode string `json:"errorCode"`
-	Message   string `json:"message"`
+	ErrorCode        ErrorCode     `json:"errorCode,omitempty"`
+	AlternativeCodes []ErrorCode   `json:"alternativeCodes,omitempty"`
+	Message          string        `json:"message,omitempty"`
+	MessageArgs      []string      `json:"messageArgs,omitempty"`
+	Details          *ErrorDetails `json:"details,omitempty"`
+}
+
+// UnmarshalJSON accepts the errorCode written either as a single code or a list of codes
+func (e *GRPCError) UnmarshalJSON(data []byte) error {
+	type grpcError GRPCError
+	rawError := struct {
+		*grpcError
+		ErrorCode json.RawMessage `json:"errorCode"`
+	}{grpcError: (*grpcError)(e)}
+	if err := json.Unmarshal(data, &rawError); err != nil {
+		return err
+	}
+
+	if len(rawError.ErrorCode) == 0 {
+		return nil
+	}
+
+	var errorCodes []ErrorCode
+	if err := json.Unmarshal(rawError.ErrorCode, &errorCodes); err == nil {
+		if len(errorCodes) == 0 {
+			return fmt.Errorf("at least one error code must be provided")
+		}
+
+		e.ErrorCode, e.AlternativeCodes = errorCodes[0], errorCodes[1:]
+		return nil
+	}
+
+	return json.Unmarshal(rawError.ErrorCode, &e.ErrorCode)
+}
+
+// Codes returns the error code followed by the alternative codes
+func (e GRPCError) Codes() []ErrorCode {
+	return append([]ErrorCode{e.ErrorCode}, e.AlternativeCodes...)
 }
 
 func (e GRPCError) String() string {
 	return fmt.Sprintf("rpc error: code = %s desc = %s", e.ErrorCode, e.Message)
 }
+
+// ErrorCode handles a GRPC error code, it can be written either as a string or a number
+type ErrorCode string
+
+// UnmarshalJSON accepts both a string and a number
+func (c *ErrorCode) UnmarshalJSON(data []byte) error {
+	var code string
+	if err := json.Unmarshal(data, &code); err == nil {
+		*c = ErrorCode(code)
+		return nil
+	}
+
+	var number int
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("error code must be a string or a number: %w", err)
+	}
+
+	*c = ErrorCode(strconv.Itoa(number))
+	return nil
+}
+
+// ErrorDetails handles the google.rpc error details of a GRPC error.
+// RetryDelay is a duration (e.g. "30s") telling the client when to retry, see google.rpc.RetryInfo.
+type ErrorDetails struct {
+	BadRequest          []FieldViolation        `json:"badRequest,omitempty"`
+	PreconditionFailure []PreconditionViolation `json:"preconditionFailure,omitempty"`
+	ErrorInfo           *ErrorInfo              `json:"errorInfo,omitempty"`
+	QuotaFailure        []QuotaViolation        `json:"quotaFailure,omitempty"`
+	RetryDelay          string                  `json:"retryDelay,omitempty"`
+}
+
+// IsEmpty tells if no detail is provided
+func (d *ErrorDetails) IsEmpty() bool {
+	return d == nil ||
+		(len(d.BadRequest) == 0 &&
+			len(d.PreconditionFailure) == 0 &&
+			d.ErrorInfo == nil &&
+			len(d.QuotaFailure) == 0 &&
+			d.RetryDelay == "")
+}
+
+// FieldViolation represents a field of a bad request, see google.rpc.BadRequest
+type FieldViolation struct {
+	Field       string `json:"field,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// PreconditionViolation represents a failed precondition, see google.rpc.PreconditionFailure
+type PreconditionViolation struct {
+	Type        string `json:"type,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ErrorInfo describes the cause of the error, see google.rpc.ErrorInfo
+type ErrorInfo struct {
+	Reason   string            `json:"reason,omitempty"`
+	Domain   string            `json:"domain,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// QuotaViolation represents an exceeded quota, see google.rpc.QuotaFailure
+type QuotaViolation struct {
+	Subject     string `json:"subject,omitempty"`
+	Description string `json:"description,omitempty"`
+}
@@ -0,0 +1,253 @@
+package entities_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+func TestSuccessCaseSequence(t *testing.T) {
+	t.Parallel()
+
+	notFound := entities.GRPCError{ErrorCode: "NotFound", Message: "not found"}
+
+	tests := []struct {
+		name          string
+		successCase   entities.SuccessCase
+		expectedSteps []entities.SequenceStep
+	}{
+		{
+			name:          "should return nil when the case isn't sequenced",
+			successCase:   entities.SuccessCase{Response: "response"},
+			expectedSteps: nil,
+		},
+		{
+			name: "should return the responses",
+			successCase: entities.SuccessCase{
+				Responses: []entities.SequenceStep{{Response: "first"}, {Error: &notFound}},
+			},
+			expectedSteps: []entities.SequenceStep{{Response: "first"}, {Error: &notFound}},
+		},
+		{
+			name: "should fail with the default error before the response",
+			successCase: entities.SuccessCase{
+				Response:  "response",
+				FailFirst: &entities.FailFirst{Times: 2},
+			},
+			expectedSteps: []entities.SequenceStep{
+				{Error: &entities.DefaultFailFirstError},
+				{Error: &entities.DefaultFailFirstError},
+				{Response: "response"},
+			},
+		},
+		{
+			name: "should fail with the provided error before the responses",
+			successCase: entities.SuccessCase{
+				Responses: []entities.SequenceStep{{Response: "first"}, {Response: "second"}},
+				FailFirst: &entities.FailFirst{Times: 1, Error: &notFound},
+			},
+			expectedSteps: []entities.SequenceStep{
+				{Error: &notFound},
+				{Response: "first"},
+				{Response: "second"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualSteps := test.successCase.Sequence()
+			if !reflect.DeepEqual(actualSteps, test.expectedSteps) {
+				t.Errorf("Wrong steps, given: %v, expected: %v", actualSteps, test.expectedSteps)
+			}
+		})
+	}
+}
+
+func TestMetadataValuesUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		data           string
+		expectedValues entities.MetadataValues
+		expectedError  bool
+	}{
+		{
+			name:           "should accept a single string",
+			data:           `"value"`,
+			expectedValues: entities.MetadataValues{"value"},
+		},
+		{
+			name:           "should accept a list of strings",
+			data:           `["first", "second"]`,
+			expectedValues: entities.MetadataValues{"first", "second"},
+		},
+		{
+			name:          "should fail when the value isn't a string",
+			data:          `42`,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actualValues entities.MetadataValues
+			err := json.Unmarshal([]byte(test.data), &actualValues)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("Unexpected error result, given: %v", err)
+			}
+
+			if !reflect.DeepEqual(actualValues, test.expectedValues) {
+				t.Errorf("Wrong values, given: %v, expected: %v", actualValues, test.expectedValues)
+			}
+		})
+	}
+}
+
+func TestErrorDetailsIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		details       *entities.ErrorDetails
+		expectedEmpty bool
+	}{
+		{
+			name:          "should be empty when the details aren't provided",
+			details:       nil,
+			expectedEmpty: true,
+		},
+		{
+			name:          "should be empty when no detail is provided",
+			details:       &entities.ErrorDetails{},
+			expectedEmpty: true,
+		},
+		{
+			name: "should not be empty when a detail is provided",
+			details: &entities.ErrorDetails{
+				ErrorInfo: &entities.ErrorInfo{Reason: "USER_BLOCKED"},
+			},
+			expectedEmpty: false,
+		},
+		{
+			name:          "should not be empty when only the retry delay is provided",
+			details:       &entities.ErrorDetails{RetryDelay: "30s"},
+			expectedEmpty: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if isEmpty := test.details.IsEmpty(); isEmpty != test.expectedEmpty {
+				t.Errorf("Wrong result, given: %v, expected: %v", isEmpty, test.expectedEmpty)
+			}
+		})
+	}
+}
+
+func TestErrorCodeUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		data          string
+		expectedCode  entities.ErrorCode
+		expectedError bool
+	}{
+		{
+			name:         "should accept a string",
+			data:         `"NOT_FOUND"`,
+			expectedCode: "NOT_FOUND",
+		},
+		{
+			name:         "should accept a number",
+			data:         `5`,
+			expectedCode: "5",
+		},
+		{
+			name:          "should fail when the value isn't a string or a number",
+			data:          `true`,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actualCode entities.ErrorCode
+			err := json.Unmarshal([]byte(test.data), &actualCode)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("Unexpected error result, given: %v", err)
+			}
+
+			if actualCode != test.expectedCode {
+				t.Errorf("Wrong code, given: %v, expected: %v", actualCode, test.expectedCode)
+			}
+		})
+	}
+}
+
+func TestGRPCErrorUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		data          string
+		expectedError entities.GRPCError
+		expectedFail  bool
+	}{
+		{
+			name: "should accept a single error code",
+			data: `{"errorCode": "NotFound", "message": "not found"}`,
+			expectedError: entities.GRPCError{
+				ErrorCode: "NotFound",
+				Message:   "not found",
+			},
+		},
+		{
+			name: "should accept a numeric error code",
+			data: `{"errorCode": 5}`,
+			expectedError: entities.GRPCError{
+				ErrorCode: "5",
+			},
+		},
+		{
+			name: "should accept a list of error codes",
+			data: `{"errorCode": ["NotFound", "FailedPrecondition", 14], "message": "not found"}`,
+			expectedError: entities.GRPCError{
+				ErrorCode:        "NotFound",
+				AlternativeCodes: []entities.ErrorCode{"FailedPrecondition", "14"},
+				Message:          "not found",
+			},
+		},
+		{
+			name:         "should fail when the list of error codes is empty",
+			data:         `{"errorCode": []}`,
+			expectedFail: true,
+		},
+		{
+			name:         "should fail when the error code is invalid",
+			data:         `{"errorCode": true}`,
+			expectedFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actualError entities.GRPCError
+			err := json.Unmarshal([]byte(test.data), &actualError)
+			if (err != nil) != test.expectedFail {
+				t.Fatalf("Unexpected error result, given: %v", err)
+			}
+			if test.expectedFail {
+				return
+			}
+
+			if !reflect.DeepEqual(actualError, test.expectedError) {
+				t.Errorf("Wrong error, given: %v, expected: %v", actualError, test.expectedError)
+			}
+		})
+	}
+}
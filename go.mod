@@ -3,6 +3,15 @@ module github.com/faunists/deal-go
 go 1.16
 
 require (
+	github.com/golang/protobuf v1.5.4 // indirect
+	github.com/google/go-cmp v0.5.8
+	github.com/lib/pq v1.10.9
+	go.etcd.io/bbolt v1.3.6
+	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
 	google.golang.org/genproto v0.0.0-20210708141623-e76da96a951f
-	google.golang.org/protobuf v1.27.1
+	google.golang.org/grpc v1.43.0
+	google.golang.org/protobuf v1.34.1
+	gopkg.in/yaml.v3 v3.0.1
 )
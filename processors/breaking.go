@@ -0,0 +1,234 @@
+package processors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// requestCases indexes the cases of a method by their request, a request being
+// its JSON representation along with the oneof variants it selects
+type requestCases struct {
+	successCases          map[string]entities.SuccessCase
+	applicationErrorCases map[string]entities.ApplicationErrorCase
+	failureCases          map[string]entities.FailureCase
+}
+
+// BreakingChanges returns the changes from the base contract to the revision weakening the
+// guarantees given to the existing consumers: removed services, methods or success cases,
+// changed responses (or application errors) for the same request and changed error codes.
+// The cases are matched by their request, the issues are located in the base contract.
+func BreakingChanges(base, revision entities.Contract) ([]ContractIssue, error) {
+	issues := make([]ContractIssue, 0)
+	for _, serviceKey := range sortedKeys(base.Services) {
+		revisionService, exists := revision.Services[serviceKey]
+		if !exists {
+			issues = append(issues, ContractIssue{
+				Path: ContractServicePath(serviceKey), Message: "service removed",
+			})
+			continue
+		}
+
+		for _, methodName := range sortedKeys(base.Services[serviceKey]) {
+			change := ContractChange{Service: serviceKey, Method: methodName}
+			revisionMethod, exists := revisionService[methodName]
+			if !exists {
+				issues = append(issues, ContractIssue{Path: change.Path(), Message: "method removed"})
+				continue
+			}
+
+			methodIssues, err := methodBreakingChanges(
+				change, base.Services[serviceKey][methodName], revisionMethod,
+			)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, methodIssues...)
+		}
+	}
+
+	return issues, nil
+}
+
+func methodBreakingChanges(
+	change ContractChange,
+	base, revision entities.Method,
+) ([]ContractIssue, error) {
+	revisionCases, err := indexCasesByRequest(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]ContractIssue, 0)
+	report := func(casesKey string, index int, message string, args ...interface{}) {
+		caseChange := change
+		caseChange.Cases, caseChange.Index = casesKey, index
+		cases := MethodCases(base, casesKey)
+		if identified := identifyCases(cases); index < len(identified) {
+			caseChange.Description = identified[index].description
+		}
+		issues = append(issues, ContractIssue{
+			Path: caseChange.Path(), Message: fmt.Sprintf(message, args...),
+		})
+	}
+
+	for index, successCase := range base.SuccessCases {
+		key, err := requestKey(successCase.Request, successCase.Oneofs)
+		if err != nil {
+			return nil, err
+		}
+
+		if revisionCase, exists := revisionCases.successCases[key]; exists {
+			changed, err := isChanged(successCaseResult(successCase), successCaseResult(revisionCase))
+			if err != nil {
+				return nil, err
+			}
+			if changed {
+				report(SuccessCasesKey, index, "response changed for the same request")
+			}
+			continue
+		}
+
+		if failureCase, exists := revisionCases.failureCases[key]; exists {
+			report(
+				SuccessCasesKey, index, "the request now fails with %s", errorCodes(failureCase.Error),
+			)
+			continue
+		}
+
+		if _, exists := revisionCases.applicationErrorCases[key]; exists {
+			report(SuccessCasesKey, index, "the request now returns an application error")
+			continue
+		}
+
+		report(SuccessCasesKey, index, "success case removed")
+	}
+
+	for index, applicationErrorCase := range base.ApplicationErrorCases {
+		key, err := requestKey(applicationErrorCase.Request, applicationErrorCase.Oneofs)
+		if err != nil {
+			return nil, err
+		}
+
+		revisionCase, exists := revisionCases.applicationErrorCases[key]
+		if !exists {
+			continue
+		}
+		changed, err := isChanged(
+			[]interface{}{applicationErrorCase.Response, applicationErrorCase.ErrorField},
+			[]interface{}{revisionCase.Response, revisionCase.ErrorField},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			report(ApplicationErrorCasesKey, index, "application error changed for the same request")
+		}
+	}
+
+	for index, failureCase := range base.FailureCases {
+		key, err := requestKey(failureCase.Request, failureCase.Oneofs)
+		if err != nil {
+			return nil, err
+		}
+
+		revisionCase, exists := revisionCases.failureCases[key]
+		if !exists {
+			continue
+		}
+		codes, revisionCodes := errorCodes(failureCase.Error), errorCodes(revisionCase.Error)
+		if codes != revisionCodes {
+			report(FailureCasesKey, index, "error code changed from %s to %s", codes, revisionCodes)
+		}
+	}
+
+	return issues, nil
+}
+
+// indexCasesByRequest indexes the cases of the method by request, the first case of a
+// request is kept since it's the one matched by the generated code
+func indexCasesByRequest(method entities.Method) (requestCases, error) {
+	cases := requestCases{
+		successCases:          make(map[string]entities.SuccessCase),
+		applicationErrorCases: make(map[string]entities.ApplicationErrorCase),
+		failureCases:          make(map[string]entities.FailureCase),
+	}
+
+	for _, successCase := range method.SuccessCases {
+		key, err := requestKey(successCase.Request, successCase.Oneofs)
+		if err != nil {
+			return requestCases{}, err
+		}
+		if _, exists := cases.successCases[key]; !exists {
+			cases.successCases[key] = successCase
+		}
+	}
+	for _, applicationErrorCase := range method.ApplicationErrorCases {
+		key, err := requestKey(applicationErrorCase.Request, applicationErrorCase.Oneofs)
+		if err != nil {
+			return requestCases{}, err
+		}
+		if _, exists := cases.applicationErrorCases[key]; !exists {
+			cases.applicationErrorCases[key] = applicationErrorCase
+		}
+	}
+	for _, failureCase := range method.FailureCases {
+		key, err := requestKey(failureCase.Request, failureCase.Oneofs)
+		if err != nil {
+			return requestCases{}, err
+		}
+		if _, exists := cases.failureCases[key]; !exists {
+			cases.failureCases[key] = failureCase
+		}
+	}
+
+	return cases, nil
+}
+
+// requestKey returns the canonical JSON representation of the request and its oneof variants,
+// so the same request always has the same key however its numbers are written
+func requestKey(request interface{}, oneofs map[string]string) (string, error) {
+	key, err := canonicalJSON([]interface{}{request, oneofs})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the request: %w", err)
+	}
+
+	return key, nil
+}
+
+// successCaseResult returns what the consumers receive from a success case
+func successCaseResult(successCase entities.SuccessCase) interface{} {
+	return []interface{}{successCase.Response, successCase.Responses}
+}
+
+// isChanged compares the canonical JSON representations of the values, the numbers are compared
+// by value
+func isChanged(before, after interface{}) (bool, error) {
+	beforeJSON, err := canonicalJSON(before)
+	if err != nil {
+		return false, err
+	}
+	afterJSON, err := canonicalJSON(after)
+	if err != nil {
+		return false, err
+	}
+
+	return beforeJSON != afterJSON, nil
+}
+
+// errorCodes returns the accepted codes of the error, sorted and normalized when they're valid
+func errorCodes(grpcError entities.GRPCError) string {
+	codes := make([]string, 0, len(grpcError.AlternativeCodes)+1)
+	for _, errorCode := range grpcError.Codes() {
+		normalizedCode, err := NormalizeErrorCode(string(errorCode))
+		if err != nil {
+			normalizedCode = string(errorCode)
+		}
+		codes = append(codes, normalizedCode)
+	}
+	sort.Strings(codes)
+
+	return strings.Join(codes, "|")
+}
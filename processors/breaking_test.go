@@ -0,0 +1,148 @@
+package processors_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestBreakingChanges(t *testing.T) {
+	t.Parallel()
+
+	request := func(id string) map[string]interface{} { return map[string]interface{}{"id": id} }
+	base := entities.Contract{Services: map[string]entities.Service{
+		"RemovedService": {"Get": {}},
+		"UserService": {
+			"RemovedMethod": {},
+			"GetUser": {
+				SuccessCases: []entities.SuccessCase{
+					{Description: "kept", Request: request("1"), Response: request("1")},
+					{Description: "changed", Request: request("2"), Response: request("2")},
+					{Description: "removed", Request: request("3")},
+					{Description: "now failing", Request: request("4")},
+					{Description: "renamed", Request: request("5"), Response: request("5")},
+				},
+				ApplicationErrorCases: []entities.ApplicationErrorCase{
+					{Request: request("6"), Response: request("6"), ErrorField: "id"},
+				},
+				FailureCases: []entities.FailureCase{
+					{Request: request("7"), Error: entities.GRPCError{ErrorCode: "NotFound"}},
+					{Request: request("8"), Error: entities.GRPCError{ErrorCode: "Internal"}},
+					{Request: request("9"), Error: entities.GRPCError{ErrorCode: "Unavailable"}},
+				},
+			},
+		},
+	}}
+	revision := entities.Contract{Services: map[string]entities.Service{
+		"AddedService": {"Get": {}},
+		"UserService": {
+			"AddedMethod": {},
+			"GetUser": {
+				SuccessCases: []entities.SuccessCase{
+					{Description: "kept", Request: request("1"), Response: request("1")},
+					{Description: "changed", Request: request("2"), Response: request("changed")},
+					{Description: "new name", Request: request("5"), Response: request("5")},
+					{Description: "added", Request: request("10")},
+				},
+				ApplicationErrorCases: []entities.ApplicationErrorCase{
+					{Request: request("6"), Response: request("changed"), ErrorField: "id"},
+				},
+				FailureCases: []entities.FailureCase{
+					{Request: request("4"), Error: entities.GRPCError{ErrorCode: "NotFound"}},
+					{Request: request("7"), Error: entities.GRPCError{ErrorCode: "NOT_FOUND"}},
+					{Request: request("8"), Error: entities.GRPCError{ErrorCode: "Unknown"}},
+				},
+			},
+		},
+	}}
+
+	issues, err := processors.BreakingChanges(base, revision)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedIssues := []processors.ContractIssue{
+		{Path: "services.RemovedService", Message: "service removed"},
+		{
+			Path:    `services.UserService.GetUser.successCases["changed"]`,
+			Message: "response changed for the same request",
+		},
+		{
+			Path:    `services.UserService.GetUser.successCases["removed"]`,
+			Message: "success case removed",
+		},
+		{
+			Path:    `services.UserService.GetUser.successCases["now failing"]`,
+			Message: "the request now fails with NotFound",
+		},
+		{
+			Path:    "services.UserService.GetUser.applicationErrorCases[0]",
+			Message: "application error changed for the same request",
+		},
+		{
+			Path:    "services.UserService.GetUser.failureCases[1]",
+			Message: "error code changed from Internal to Unknown",
+		},
+		{Path: "services.UserService.RemovedMethod", Message: "method removed"},
+	}
+	if !reflect.DeepEqual(issues, expectedIssues) {
+		t.Errorf("Given: %v, expected: %v", issues, expectedIssues)
+	}
+}
+
+func TestBreakingChangesComparesNumbersByValue(t *testing.T) {
+	t.Parallel()
+
+	decode := func(request, response string) entities.Contract {
+		contract := entities.Contract{}
+		err := processors.DecodeJSONContract([]byte(
+			`{"services": {"UserService": {"GetUser": {"successCases": [`+
+				`{"request": `+request+`, "response": `+response+`}]}}}}`,
+		), &contract)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		return contract
+	}
+
+	tests := []struct {
+		name           string
+		base           entities.Contract
+		revision       entities.Contract
+		expectedIssues []processors.ContractIssue
+	}{
+		{
+			name:           "should match the requests whose numbers are written differently",
+			base:           decode(`{"id": 1.0}`, `{"score": 1e300}`),
+			revision:       decode(`{"id": 1}`, `{"score": 1e+300}`),
+			expectedIssues: []processors.ContractIssue{},
+		},
+		{
+			name:     "should tell the 64-bit integers apart",
+			base:     decode(`{"id": 9007199254740993}`, `{}`),
+			revision: decode(`{"id": 9007199254740992}`, `{}`),
+			expectedIssues: []processors.ContractIssue{{
+				Path:    "services.UserService.GetUser.successCases[0]",
+				Message: "success case removed",
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			issues, err := processors.BreakingChanges(test.base, test.revision)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(issues, test.expectedIssues) {
+				t.Errorf("Given: %v, expected: %v", issues, test.expectedIssues)
+			}
+		})
+	}
+}
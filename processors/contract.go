@@ -1,23 +1,84 @@
 package processors
 
 import (
-	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"path/filepath"
 
 	"github.com/faunists/deal-go/entities"
 )
 
-// ReadContractFile reads a JSON File and try to parse it to a entities.Contract object
+// ReadContractFile reads a contract file and try to parse it to a entities.Contract object,
+// the file is decoded by the decoder registered for its extension (JSON by default)
 func ReadContractFile(filePath string) (entities.Contract, error) {
-	jsonData, err := ioutil.ReadFile(filePath)
+	data, err := ioutil.ReadFile(filePath)
 	if err != nil {
 		return entities.Contract{}, err
 	}
 
+	return decodeContract(filePath, data)
+}
+
+// decodeContract decodes the content of the contract file, the error codes are normalized.
+// The files of a format that's only written, e.g. CUE, can't be decoded without a decoder.
+func decodeContract(filePath string, data []byte) (entities.Contract, error) {
+	extension := filepath.Ext(filePath)
+	_, hasDecoder := contractDecoder(extension)
+	if _, hasEncoder := contractEncoder(extension); hasEncoder && !hasDecoder {
+		return entities.Contract{}, fmt.Errorf(
+			"%s can't be read, no decoder is registered for the %s contracts (the readable ones are %v), "+
+				"convert it to one of them first",
+			filePath, extension, ContractExtensions(),
+		)
+	}
+
 	rawContract := entities.Contract{}
-	if err = json.Unmarshal(jsonData, &rawContract); err != nil {
-		return entities.Contract{}, err
+	if err := ContractDecoderFor(filePath)(data, &rawContract); err != nil {
+		return entities.Contract{}, fmt.Errorf("failed to decode %s: %w", filePath, err)
 	}
 
+	normalizeErrorCodes(rawContract)
+
 	return rawContract, nil
 }
+
+// normalizeErrorCodes replaces the valid error codes of the contract by their identifiers in the
+// GRPC Codes package, the invalid ones are kept as they are to be reported during generation
+func normalizeErrorCodes(contract entities.Contract) {
+	for _, service := range contract.Services {
+		for _, method := range service {
+			for _, successCase := range method.SuccessCases {
+				for i := range successCase.Responses {
+					normalizeErrorCode(successCase.Responses[i].Error)
+				}
+				if successCase.FailFirst != nil {
+					normalizeErrorCode(successCase.FailFirst.Error)
+				}
+			}
+
+			for i := range method.FailureCases {
+				normalizeErrorCode(&method.FailureCases[i].Error)
+			}
+		}
+	}
+}
+
+func normalizeErrorCode(grpcError *entities.GRPCError) {
+	if grpcError == nil {
+		return
+	}
+
+	grpcError.ErrorCode = normalizeContractErrorCode(grpcError.ErrorCode)
+	for i, alternativeCode := range grpcError.AlternativeCodes {
+		grpcError.AlternativeCodes[i] = normalizeContractErrorCode(alternativeCode)
+	}
+}
+
+func normalizeContractErrorCode(errorCode entities.ErrorCode) entities.ErrorCode {
+	normalizedCode, err := NormalizeErrorCode(string(errorCode))
+	if err != nil {
+		return errorCode
+	}
+
+	return entities.ErrorCode(normalizedCode)
+}
@@ -0,0 +1,93 @@
+package processors_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestReadContractFileNormalizesErrorCodes(t *testing.T) {
+	t.Parallel()
+
+	contractFilePath := filepath.Join(t.TempDir(), "contract.json")
+	err := ioutil.WriteFile(contractFilePath, []byte(`{
+		"services": {
+			"MyService": {
+				"MyMethod": {
+					"successCases": [{
+						"responses": [{"error": {"errorCode": "UNAVAILABLE"}}],
+						"failFirst": {"times": 1, "error": {"errorCode": 8}}
+					}],
+					"failureCases": [
+						{"error": {"errorCode": ["not_found", 9]}},
+						{"error": {"errorCode": "MyTest"}}
+					]
+				}
+			}
+		}
+	}`), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write the contract file: %v", err)
+	}
+
+	contract, err := processors.ReadContractFile(contractFilePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	method := contract.Services["MyService"]["MyMethod"]
+	actualCodes := []string{
+		string(method.SuccessCases[0].Responses[0].Error.ErrorCode),
+		string(method.SuccessCases[0].FailFirst.Error.ErrorCode),
+		string(method.FailureCases[0].Error.ErrorCode),
+		string(method.FailureCases[0].Error.AlternativeCodes[0]),
+		string(method.FailureCases[1].Error.ErrorCode),
+	}
+	expectedCodes := []string{
+		"Unavailable", "ResourceExhausted", "NotFound", "FailedPrecondition", "MyTest",
+	}
+	for i, actualCode := range actualCodes {
+		if actualCode != expectedCodes[i] {
+			t.Errorf("Given: %v, expected: %v", actualCode, expectedCodes[i])
+		}
+	}
+}
+
+func TestReadContractFileKeepsLargeIntegers(t *testing.T) {
+	t.Parallel()
+
+	contractFilePath := filepath.Join(t.TempDir(), "contract.json")
+	err := ioutil.WriteFile(contractFilePath, []byte(`{
+		"services": {
+			"MyService": {
+				"MyMethod": {
+					"successCases": [{
+						"request": {"id": 9007199254740993, "ref": "18446744073709551615"}
+					}]
+				}
+			}
+		}
+	}`), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write the contract file: %v", err)
+	}
+
+	contract, err := processors.ReadContractFile(contractFilePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := contract.Services["MyService"]["MyMethod"].SuccessCases[0].Request
+	marshaledRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Failed to marshal the request: %v", err)
+	}
+
+	expectedRequest := `{"id":9007199254740993,"ref":"18446744073709551615"}`
+	if string(marshaledRequest) != expectedRequest {
+		t.Errorf("Given: %s, expected: %s", marshaledRequest, expectedRequest)
+	}
+}
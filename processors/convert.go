@@ -0,0 +1,84 @@
+package processors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+)
+
+// ConvertContractFile returns the contract file encoded by the encoder of the extension, e.g.
+// ".yaml". The conversion fails when a field of the file isn't part of the contract, since it
+// would be lost, or when the converted contract doesn't decode back to the same contract. The
+// round trip is only verified when the extension has a decoder, e.g. not for ".cue".
+func ConvertContractFile(filePath, extension string) ([]byte, error) {
+	encoder, exists := contractEncoder(extension)
+	if !exists {
+		return nil, fmt.Errorf(
+			"no encoder of the %s contracts, expected one of %v", extension, ContractEncoderExtensions(),
+		)
+	}
+
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := decodeContract(filePath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonContract, err := EncodeJSONContract(contract)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", filePath, err)
+	}
+	if lostFields := lostFields(data, jsonContract); len(lostFields) > 0 {
+		return nil, fmt.Errorf(
+			"unknown fields in %s would be lost by the conversion: %s",
+			filePath, strings.Join(lostFields, ", "),
+		)
+	}
+
+	converted, err := encoder(contract)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", filePath, err)
+	}
+
+	if _, hasDecoder := contractDecoder(extension); hasDecoder {
+		convertedContract, err := decodeContract("converted"+extension, converted)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = verifySameContract(jsonContract, convertedContract); err != nil {
+			return nil, fmt.Errorf("the conversion of %s to %s: %w", filePath, extension, err)
+		}
+	}
+
+	return converted, nil
+}
+
+// verifySameContract compares the contracts as JSON documents, so the numbers are compared by
+// value, e.g. `1.0` and `1` are the same
+func verifySameContract(jsonContract []byte, contract interface{}) error {
+	convertedData, err := json.Marshal(contract)
+	if err != nil {
+		return err
+	}
+
+	var original, converted interface{}
+	if err = json.Unmarshal(jsonContract, &original); err != nil {
+		return err
+	}
+	if err = json.Unmarshal(convertedData, &converted); err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(original, converted) {
+		return fmt.Errorf("the converted contract differs from the original one")
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package processors_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestConvertContractFile(t *testing.T) {
+	t.Parallel()
+
+	jsonContent := `{"name": "users", "services": {"example.v1.UserService": {"GetUser": {
+		"successCases": [{"request": {"id": 9007199254740993, "ratio": 1.0}, "response": {}}],
+		"failureCases": [{"error": {"errorCode": "NOT_FOUND", "message": "user \\(id) not found"}}]
+	}}}}`
+	tests := []struct {
+		name              string
+		fileName          string
+		content           string
+		extension         string
+		expectedConverted string
+		expectedError     string
+	}{
+		{
+			name:      "JSON to YAML",
+			fileName:  "contract.json",
+			content:   jsonContent,
+			extension: ".yaml",
+			expectedConverted: `name: users
+services:
+  example.v1.UserService:
+    GetUser:
+      failureCases:
+        - error:
+            errorCode: NotFound
+            message: user \(id) not found
+      successCases:
+        - request:
+            id: 9007199254740993
+            ratio: 1
+          response: {}
+`,
+		},
+		{
+			name:     "YAML to JSON",
+			fileName: "contract.yml",
+			content: "services:\n  UserService:\n    GetUser:\n" +
+				"      successCases:\n        - request: {id: 1}\n",
+			extension: ".json",
+			expectedConverted: `{
+  "services": {
+    "UserService": {
+      "GetUser": {
+        "successCases": [
+          {
+            "request": {
+              "id": 1
+            }
+          }
+        ]
+      }
+    }
+  }
+}
+`,
+		},
+		{
+			name:      "JSON to CUE",
+			fileName:  "contract.json",
+			content:   jsonContent,
+			extension: ".cue",
+			expectedConverted: `name: "users"
+services: {
+	"example.v1.UserService": {
+		GetUser: {
+			successCases: [{
+				request: {
+					id: 9007199254740993
+					ratio: 1.0
+				}
+				response: {}
+			}]
+			failureCases: [{
+				error: {
+					errorCode: "NotFound"
+					message: "user \\(id) not found"
+				}
+			}]
+		}
+	}
+}
+`,
+		},
+		{
+			name:          "Unknown fields",
+			fileName:      "contract.json",
+			content:       `{"services": {"UserService": {"GetUser": {"sucessCases": [{}]}}}}`,
+			extension:     ".yaml",
+			expectedError: "would be lost by the conversion: services.UserService.GetUser.sucessCases",
+		},
+		{
+			name:          "CUE input",
+			fileName:      "contract.cue",
+			content:       `name: "users"`,
+			extension:     ".json",
+			expectedError: "contract.cue can't be read, no decoder is registered for the .cue contracts",
+		},
+		{
+			name:          "Unknown extension",
+			fileName:      "contract.json",
+			content:       jsonContent,
+			extension:     ".unknown",
+			expectedError: "no encoder of the .unknown contracts",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			contractFilePath := filepath.Join(t.TempDir(), test.fileName)
+			if err := ioutil.WriteFile(contractFilePath, []byte(test.content), 0o600); err != nil {
+				t.Fatalf("Failed to write the contract file: %v", err)
+			}
+
+			converted, err := processors.ConvertContractFile(contractFilePath, test.extension)
+			if test.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+					t.Fatalf("Given error: %v, expected: %s", err, test.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(converted) != test.expectedConverted {
+				t.Errorf("Given:\n%s\nexpected:\n%s", converted, test.expectedConverted)
+			}
+		})
+	}
+}
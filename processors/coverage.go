@@ -0,0 +1,108 @@
+package processors
+
+import (
+	"sort"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// CoverageStatus tells which kinds of cases a method has
+type CoverageStatus string
+
+// The coverage statuses of a method
+const (
+	// Uncovered methods have no case
+	Uncovered CoverageStatus = "uncovered"
+	// SuccessOnly methods have success cases only
+	SuccessOnly CoverageStatus = "success-only"
+	// FailureOnly methods have failure or application error cases only
+	FailureOnly CoverageStatus = "failure-only"
+	// Covered methods have both success and failure (or application error) cases
+	Covered CoverageStatus = "covered"
+)
+
+// MethodCoverage is the coverage of a method by the contract
+type MethodCoverage struct {
+	MethodCaseCounts
+	Status CoverageStatus `json:"status"`
+}
+
+// ContractCoverage is the coverage of the proto services by the contract, a method
+// is counted as covered as soon as it has a case
+type ContractCoverage struct {
+	Methods        []MethodCoverage `json:"methods"`
+	TotalMethods   int              `json:"totalMethods"`
+	CoveredMethods int              `json:"coveredMethods"`
+	Percentage     float64          `json:"percentage"`
+}
+
+// ComputeContractCoverage returns the coverage of every method of the services of the proto
+// files by the contract, sorted by service and method. The services are found in the contract
+// by their fully-qualified name first, then by their name.
+func ComputeContractCoverage(
+	contract entities.Contract,
+	files *protoregistry.Files,
+) ContractCoverage {
+	coverage := ContractCoverage{Methods: make([]MethodCoverage, 0)}
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for serviceIndex := 0; serviceIndex < file.Services().Len(); serviceIndex++ {
+			service := file.Services().Get(serviceIndex)
+
+			serviceContract, hasContract := contract.Services[string(service.FullName())]
+			if !hasContract {
+				serviceContract = contract.Services[string(service.Name())]
+			}
+
+			for methodIndex := 0; methodIndex < service.Methods().Len(); methodIndex++ {
+				methodName := string(service.Methods().Get(methodIndex).Name())
+				methodContract := serviceContract[methodName]
+				coverage.Methods = append(coverage.Methods, newMethodCoverage(MethodCaseCounts{
+					Service:               string(service.FullName()),
+					Method:                methodName,
+					SuccessCases:          len(methodContract.SuccessCases),
+					ApplicationErrorCases: len(methodContract.ApplicationErrorCases),
+					FailureCases:          len(methodContract.FailureCases),
+				}))
+			}
+		}
+		return true
+	})
+
+	sort.Slice(coverage.Methods, func(i, j int) bool {
+		if coverage.Methods[i].Service != coverage.Methods[j].Service {
+			return coverage.Methods[i].Service < coverage.Methods[j].Service
+		}
+		return coverage.Methods[i].Method < coverage.Methods[j].Method
+	})
+
+	coverage.TotalMethods = len(coverage.Methods)
+	for _, method := range coverage.Methods {
+		if method.Status != Uncovered {
+			coverage.CoveredMethods++
+		}
+	}
+	if coverage.TotalMethods > 0 {
+		coverage.Percentage = 100 * float64(coverage.CoveredMethods) / float64(coverage.TotalMethods)
+	}
+
+	return coverage
+}
+
+func newMethodCoverage(counts MethodCaseCounts) MethodCoverage {
+	hasSuccess := counts.SuccessCases > 0
+	hasFailure := counts.FailureCases+counts.ApplicationErrorCases > 0
+
+	switch {
+	case hasSuccess && hasFailure:
+		return MethodCoverage{MethodCaseCounts: counts, Status: Covered}
+	case hasSuccess:
+		return MethodCoverage{MethodCaseCounts: counts, Status: SuccessOnly}
+	case hasFailure:
+		return MethodCoverage{MethodCaseCounts: counts, Status: FailureOnly}
+	default:
+		return MethodCoverage{MethodCaseCounts: counts, Status: Uncovered}
+	}
+}
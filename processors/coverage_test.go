@@ -0,0 +1,89 @@
+package processors_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestComputeContractCoverage(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	tests := []struct {
+		name             string
+		contract         entities.Contract
+		expectedCoverage processors.ContractCoverage
+	}{
+		{
+			name: "Partially covered",
+			contract: entities.Contract{Services: map[string]entities.Service{
+				"UserService": {"GetUser": {
+					SuccessCases: make([]entities.SuccessCase, 2),
+					FailureCases: make([]entities.FailureCase, 1),
+				}},
+			}},
+			expectedCoverage: processors.ContractCoverage{
+				Methods: []processors.MethodCoverage{
+					{
+						MethodCaseCounts: processors.MethodCaseCounts{
+							Service: "test.v1.OtherService", Method: "GetUser",
+						},
+						Status: processors.Uncovered,
+					},
+					{
+						MethodCaseCounts: processors.MethodCaseCounts{
+							Service: "test.v1.UserService", Method: "GetUser", SuccessCases: 2, FailureCases: 1,
+						},
+						Status: processors.Covered,
+					},
+				},
+				TotalMethods:   2, //nolint:revive // the methods of the descriptor set
+				CoveredMethods: 1,
+				Percentage:     50, //nolint:revive // a method out of two
+			},
+		},
+		{
+			name: "Fully-qualified names",
+			contract: entities.Contract{Services: map[string]entities.Service{
+				"test.v1.OtherService": {"GetUser": {
+					ApplicationErrorCases: make([]entities.ApplicationErrorCase, 1),
+				}},
+				"test.v1.UserService": {"GetUser": {SuccessCases: make([]entities.SuccessCase, 1)}},
+			}},
+			expectedCoverage: processors.ContractCoverage{
+				Methods: []processors.MethodCoverage{
+					{
+						MethodCaseCounts: processors.MethodCaseCounts{
+							Service: "test.v1.OtherService", Method: "GetUser", ApplicationErrorCases: 1,
+						},
+						Status: processors.FailureOnly,
+					},
+					{
+						MethodCaseCounts: processors.MethodCaseCounts{
+							Service: "test.v1.UserService", Method: "GetUser", SuccessCases: 1,
+						},
+						Status: processors.SuccessOnly,
+					},
+				},
+				TotalMethods:   2,   //nolint:revive // the methods of the descriptor set
+				CoveredMethods: 2,   //nolint:revive // every method
+				Percentage:     100, //nolint:revive // every method
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			coverage := processors.ComputeContractCoverage(test.contract, files)
+			if !reflect.DeepEqual(coverage, test.expectedCoverage) {
+				t.Errorf("Given: %+v, expected: %+v", coverage, test.expectedCoverage)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package processors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// ContractDecoder decodes the content of a contract file into the contract
+type ContractDecoder func(data []byte, contract *entities.Contract) error
+
+var (
+	decodersMutex    sync.RWMutex
+	contractDecoders = map[string]ContractDecoder{
+		".json": DecodeJSONContract,
+		".yaml": DecodeYAMLContract,
+		".yml":  DecodeYAMLContract,
+	}
+)
+
+// RegisterContractDecoder registers the decoder of the contract files with the given extension
+// (e.g. ".cue"), replacing the previous one. The extensions are case-insensitive.
+func RegisterContractDecoder(extension string, decoder ContractDecoder) {
+	decodersMutex.Lock()
+	defer decodersMutex.Unlock()
+
+	contractDecoders[strings.ToLower(extension)] = decoder
+}
+
+// ContractDecoderFor returns the decoder of the contract file, it's chosen by the file extension.
+// The files of an unknown extension are decoded as JSON.
+func ContractDecoderFor(filePath string) ContractDecoder {
+	if decoder, exists := contractDecoder(filepath.Ext(filePath)); exists {
+		return decoder
+	}
+
+	return DecodeJSONContract
+}
+
+// contractDecoder returns the decoder registered for the extension
+func contractDecoder(extension string) (ContractDecoder, bool) {
+	decodersMutex.RLock()
+	defer decodersMutex.RUnlock()
+
+	decoder, exists := contractDecoders[strings.ToLower(extension)]
+	return decoder, exists
+}
+
+// ContractExtensions returns the extensions with a registered decoder, sorted
+func ContractExtensions() []string {
+	decodersMutex.RLock()
+	defer decodersMutex.RUnlock()
+
+	extensions := make([]string, 0, len(contractDecoders))
+	for extension := range contractDecoders {
+		extensions = append(extensions, extension)
+	}
+	sort.Strings(extensions)
+
+	return extensions
+}
+
+// DecodeJSONContract decodes a JSON contract, the numbers are kept as written
+// since a float64 can't represent every 64-bit integer
+func DecodeJSONContract(data []byte, contract *entities.Contract) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(contract)
+}
+
+// DecodeYAMLContract decodes a YAML contract, it's converted to JSON
+// so both formats follow the same rules
+func DecodeYAMLContract(data []byte, contract *entities.Contract) error {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(stringifyYAMLKeys(document))
+	if err != nil {
+		return fmt.Errorf("failed to convert the YAML contract: %w", err)
+	}
+
+	return DecodeJSONContract(jsonData, contract)
+}
+
+// stringifyYAMLKeys converts the keys of the YAML mappings to strings, as required by JSON,
+// e.g. the integer keys of a map field
+func stringifyYAMLKeys(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, element := range typedValue {
+			typedValue[key] = stringifyYAMLKeys(element)
+		}
+		return typedValue
+	case map[interface{}]interface{}:
+		mapping := make(map[string]interface{}, len(typedValue))
+		for key, element := range typedValue {
+			mapping[fmt.Sprint(key)] = stringifyYAMLKeys(element)
+		}
+		return mapping
+	case []interface{}:
+		for index, element := range typedValue {
+			typedValue[index] = stringifyYAMLKeys(element)
+		}
+		return typedValue
+	default:
+		return value
+	}
+}
@@ -0,0 +1,163 @@
+package processors_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestReadContractFileDecodesByExtension(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		fileName        string
+		content         string
+		expectedRequest string
+	}{
+		{
+			name:     "JSON",
+			fileName: "contract.json",
+			content: `{"services": {"MyService": {"MyMethod": {
+				"successCases": [{"request": {"id": 9007199254740993}}]
+			}}}}`,
+			expectedRequest: `{"id":9007199254740993}`,
+		},
+		{
+			name:     "YAML",
+			fileName: "contract.yaml",
+			content: `
+services:
+  MyService:
+    MyMethod:
+      successCases:
+        - request:
+            id: 9007199254740993
+            labels: {1: one}
+`,
+			expectedRequest: `{"id":9007199254740993,"labels":{"1":"one"}}`,
+		},
+		{
+			name:     "YML with an uppercase extension",
+			fileName: "contract.YML",
+			content: `
+services:
+  MyService:
+    MyMethod:
+      successCases:
+        - request: {name: john}
+`,
+			expectedRequest: `{"name":"john"}`,
+		},
+		{
+			name:     "Unknown extension decoded as JSON",
+			fileName: "contract.txt",
+			content: `{"services": {"MyService": {"MyMethod": {
+				"successCases": [{"request": {"name": "john"}}]
+			}}}}`,
+			expectedRequest: `{"name":"john"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			contractFilePath := filepath.Join(t.TempDir(), test.fileName)
+			if err := ioutil.WriteFile(contractFilePath, []byte(test.content), 0o600); err != nil {
+				t.Fatalf("Failed to write the contract file: %v", err)
+			}
+
+			contract, err := processors.ReadContractFile(contractFilePath)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			request := contract.Services["MyService"]["MyMethod"].SuccessCases[0].Request
+			marshaledRequest, err := json.Marshal(request)
+			if err != nil {
+				t.Fatalf("Failed to marshal the request: %v", err)
+			}
+			if string(marshaledRequest) != test.expectedRequest {
+				t.Errorf("Given: %s, expected: %s", marshaledRequest, test.expectedRequest)
+			}
+		})
+	}
+}
+
+func TestReadContractFileNormalizesYAMLErrorCodes(t *testing.T) {
+	t.Parallel()
+
+	contractFilePath := filepath.Join(t.TempDir(), "contract.yaml")
+	err := ioutil.WriteFile(contractFilePath, []byte(`
+services:
+  MyService:
+    MyMethod:
+      failureCases:
+        - request: {}
+          error: {errorCode: NOT_FOUND, message: not found}
+`), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write the contract file: %v", err)
+	}
+
+	contract, err := processors.ReadContractFile(contractFilePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	errorCode := contract.Services["MyService"]["MyMethod"].FailureCases[0].Error.ErrorCode
+	if errorCode != "NotFound" {
+		t.Errorf("Given: %s, expected: NotFound", errorCode)
+	}
+}
+
+func TestRegisterContractDecoder(t *testing.T) {
+	t.Parallel()
+
+	decoderErr := errors.New("decoder error")
+	customDecoder := func(data []byte, contract *entities.Contract) error {
+		if string(data) != "custom" {
+			return decoderErr
+		}
+		contract.Services = map[string]entities.Service{"MyService": {}}
+		return nil
+	}
+	processors.RegisterContractDecoder(".Custom", customDecoder)
+
+	directory := t.TempDir()
+	contractFilePath := filepath.Join(directory, "contract.custom")
+	if err := ioutil.WriteFile(contractFilePath, []byte("custom"), 0o600); err != nil {
+		t.Fatalf("Failed to write the contract file: %v", err)
+	}
+
+	contract, err := processors.ReadContractFile(contractFilePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, exists := contract.Services["MyService"]; !exists {
+		t.Errorf("Expected the contract decoded by the custom decoder, given: %v", contract)
+	}
+
+	invalidFilePath := filepath.Join(directory, "invalid.custom")
+	if err = ioutil.WriteFile(invalidFilePath, []byte("invalid"), 0o600); err != nil {
+		t.Fatalf("Failed to write the contract file: %v", err)
+	}
+	if _, err = processors.ReadContractFile(invalidFilePath); !errors.Is(err, decoderErr) {
+		t.Errorf("Given: %v, expected: %v", err, decoderErr)
+	}
+
+	expectedExtensions := []string{".custom", ".json", ".yaml", ".yml"}
+	if extensions := processors.ContractExtensions(); !reflect.DeepEqual(
+		extensions, expectedExtensions,
+	) {
+		t.Errorf("Given: %v, expected: %v", extensions, expectedExtensions)
+	}
+}
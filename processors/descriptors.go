@@ -0,0 +1,125 @@
+package processors
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	// The well-known types are registered, so the descriptor sets built without
+	// their imports can still refer to them
+	_ "google.golang.org/protobuf/types/known/anypb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+	_ "google.golang.org/protobuf/types/known/fieldmaskpb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ReadDescriptorSet reads the proto files compiled into a descriptor set, e.g. by
+// `protoc --descriptor_set_out` or `buf build`, whose images are wire compatible.
+// The imports missing from the set are resolved against the well-known types.
+func ReadDescriptorSet(filePath string) (*protoregistry.Files, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseDescriptorSet(data, filePath)
+}
+
+// ParseDescriptorSet parses the proto files of an encoded descriptor set, e.g. embedded in
+// another file, the same way as ReadDescriptorSet. The source names the set in the errors.
+func ParseDescriptorSet(data []byte, source string) (*protoregistry.Files, error) {
+	descriptorSet := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, descriptorSet); err != nil {
+		return nil, fmt.Errorf("failed to decode the descriptor set %s: %w", source, err)
+	}
+
+	files, err := protodesc.NewFiles(withMissingImports(descriptorSet))
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set %s: %w", source, err)
+	}
+
+	return files, nil
+}
+
+// withMissingImports adds the imported files missing from the set, when they're known
+func withMissingImports(
+	descriptorSet *descriptorpb.FileDescriptorSet,
+) *descriptorpb.FileDescriptorSet {
+	included := make(map[string]bool, len(descriptorSet.GetFile()))
+	for _, file := range descriptorSet.GetFile() {
+		included[file.GetName()] = true
+	}
+
+	missingImports := make([]*descriptorpb.FileDescriptorProto, 0)
+	var addImports func(dependencies []string)
+	addImports = func(dependencies []string) {
+		for _, dependency := range dependencies {
+			if included[dependency] {
+				continue
+			}
+
+			file, err := protoregistry.GlobalFiles.FindFileByPath(dependency)
+			if err != nil {
+				continue
+			}
+			included[dependency] = true
+
+			fileProto := protodesc.ToFileDescriptorProto(file)
+			addImports(fileProto.GetDependency())
+			missingImports = append(missingImports, fileProto)
+		}
+	}
+	for _, file := range descriptorSet.GetFile() {
+		addImports(file.GetDependency())
+	}
+
+	return &descriptorpb.FileDescriptorSet{
+		File: append(missingImports, descriptorSet.GetFile()...),
+	}
+}
+
+// FindService returns the service of the contract service key, given either by its
+// fully-qualified name or by its name when no other service has the same name
+func FindService(files *protoregistry.Files, key string) (protoreflect.ServiceDescriptor, error) {
+	if descriptor, err := files.FindDescriptorByName(protoreflect.FullName(key)); err == nil {
+		if service, isService := descriptor.(protoreflect.ServiceDescriptor); isService {
+			return service, nil
+		}
+	}
+
+	var found protoreflect.ServiceDescriptor
+	var err error
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for index := 0; index < file.Services().Len(); index++ {
+			service := file.Services().Get(index)
+			if string(service.Name()) != key {
+				continue
+			}
+			if found != nil {
+				err = fmt.Errorf(
+					"service %s is ambiguous, it matches %s and %s",
+					key, found.FullName(), service.FullName(),
+				)
+				return false
+			}
+			found = service
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("service %s not found", key)
+	}
+
+	return found, nil
+}
@@ -0,0 +1,193 @@
+package processors_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+// newDescriptorSetFile writes a descriptor set of the test.v1 package, its services are
+// UserService (GetUser) and OtherService (GetUser), the Timestamp import isn't included
+func newDescriptorSetFile(t *testing.T) string {
+	t.Helper()
+
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	stringType := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	oneofIndex := proto.Int32(0)
+
+	descriptorSet := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:       proto.String("test/v1/user.proto"),
+			Package:    proto.String("test.v1"),
+			Syntax:     proto.String("proto3"),
+			Dependency: []string{"google/protobuf/timestamp.proto"},
+			EnumType: []*descriptorpb.EnumDescriptorProto{{
+				Name: proto.String("Status"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("STATUS_UNKNOWN"), Number: proto.Int32(0)},
+					{Name: proto.String("STATUS_ACTIVE"), Number: proto.Int32(1)},
+				},
+			}},
+			MessageType: []*descriptorpb.DescriptorProto{
+				{
+					Name: proto.String("Request"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						{Name: proto.String("id"), Number: proto.Int32(1), Label: optional, Type: stringType},
+						{
+							Name:       proto.String("email"),
+							Number:     proto.Int32(2), //nolint:revive // the field number
+							Label:      optional,
+							Type:       stringType,
+							OneofIndex: oneofIndex,
+						},
+						{
+							Name:       proto.String("phone_number"),
+							JsonName:   proto.String("phoneNumber"),
+							Number:     proto.Int32(3), //nolint:revive // the field number
+							Label:      optional,
+							Type:       stringType,
+							OneofIndex: oneofIndex,
+						},
+					},
+					OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("contact")}},
+				},
+				{
+					Name: proto.String("Response"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						{Name: proto.String("name"), Number: proto.Int32(1), Label: optional, Type: stringType},
+						{
+							Name:     proto.String("status"),
+							Number:   proto.Int32(2), //nolint:revive // the field number
+							Label:    optional,
+							Type:     descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum(),
+							TypeName: proto.String(".test.v1.Status"),
+						},
+						{
+							Name:     proto.String("created_at"),
+							JsonName: proto.String("createdAt"),
+							Number:   proto.Int32(3), //nolint:revive // the field number
+							Label:    optional,
+							Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+							TypeName: proto.String(".google.protobuf.Timestamp"),
+						},
+					},
+				},
+			},
+			Service: []*descriptorpb.ServiceDescriptorProto{
+				{
+					Name: proto.String("UserService"),
+					Method: []*descriptorpb.MethodDescriptorProto{{
+						Name:       proto.String("GetUser"),
+						InputType:  proto.String(".test.v1.Request"),
+						OutputType: proto.String(".test.v1.Response"),
+					}},
+				},
+				{
+					Name: proto.String("OtherService"),
+					Method: []*descriptorpb.MethodDescriptorProto{{
+						Name:       proto.String("GetUser"),
+						InputType:  proto.String(".test.v1.Request"),
+						OutputType: proto.String(".test.v1.Response"),
+					}},
+				},
+			},
+		}},
+	}
+
+	data, err := proto.Marshal(descriptorSet)
+	if err != nil {
+		t.Fatalf("Failed to encode the descriptor set: %v", err)
+	}
+
+	descriptorSetPath := filepath.Join(t.TempDir(), "descriptors.pb")
+	if err = ioutil.WriteFile(descriptorSetPath, data, 0o600); err != nil {
+		t.Fatalf("Failed to write the descriptor set: %v", err)
+	}
+
+	return descriptorSetPath
+}
+
+func readTestDescriptorSet(t *testing.T) *protoregistry.Files {
+	t.Helper()
+
+	files, err := processors.ReadDescriptorSet(newDescriptorSetFile(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return files
+}
+
+func TestReadDescriptorSetResolvesWellKnownImports(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	if _, err := files.FindFileByPath("google/protobuf/timestamp.proto"); err != nil {
+		t.Errorf("Expected the Timestamp import to be resolved: %v", err)
+	}
+}
+
+func TestReadDescriptorSetRejectsInvalidFiles(t *testing.T) {
+	t.Parallel()
+
+	descriptorSetPath := filepath.Join(t.TempDir(), "descriptors.pb")
+	if err := ioutil.WriteFile(descriptorSetPath, []byte("invalid"), 0o600); err != nil {
+		t.Fatalf("Failed to write the descriptor set: %v", err)
+	}
+
+	if _, err := processors.ReadDescriptorSet(descriptorSetPath); err == nil {
+		t.Error("Expected an error, given nil")
+	}
+}
+
+func TestFindService(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	tests := []struct {
+		name          string
+		key           string
+		expectedName  string
+		expectedError string
+	}{
+		{name: "Fully-qualified name", key: "test.v1.UserService", expectedName: "test.v1.UserService"},
+		{name: "Name", key: "UserService", expectedName: "test.v1.UserService"},
+		{
+			name:          "Unknown service",
+			key:           "MissingService",
+			expectedError: "service MissingService not found",
+		},
+		{
+			name:          "Message name",
+			key:           "test.v1.Request",
+			expectedError: "service test.v1.Request not found",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, err := processors.FindService(files, test.key)
+			if test.expectedError != "" {
+				if err == nil || err.Error() != test.expectedError {
+					t.Fatalf("Given error: %v, expected: %s", err, test.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(service.FullName()) != test.expectedName {
+				t.Errorf("Given: %s, expected: %s", service.FullName(), test.expectedName)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package processors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// ChangeType tells how a contract entry changed between two versions of the contract
+type ChangeType string
+
+// The types of the contract changes
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// The keys of the case lists in the contract file, in the order they're compared
+const (
+	SuccessCasesKey          = "successCases"
+	ApplicationErrorCasesKey = "applicationErrorCases"
+	FailureCasesKey          = "failureCases"
+)
+
+// ContractChange is a difference between two versions of a contract. A change of a whole
+// service or method has no Cases, the cases are identified by their description, or by
+// their index when the description is empty or shared by other cases of the list.
+// Fields are the changed fields of a changed case, Before and After are the case versions.
+type ContractChange struct {
+	Type        ChangeType  `json:"type"`
+	Service     string      `json:"service"`
+	Method      string      `json:"method,omitempty"`
+	Cases       string      `json:"cases,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Index       int         `json:"index"`
+	Fields      []string    `json:"fields,omitempty"`
+	Before      interface{} `json:"before,omitempty"`
+	After       interface{} `json:"after,omitempty"`
+}
+
+// Path returns the location of the changed entry in the contract file,
+// e.g. `services.MyService.MyMethod.successCases["should find the user"]`
+func (c ContractChange) Path() string {
+	path := ContractServicePath(c.Service)
+	if c.Method != "" {
+		path += "." + c.Method
+	}
+	if c.Cases == "" {
+		return path
+	}
+
+	if c.Description != "" {
+		return fmt.Sprintf("%s.%s[%q]", path, c.Cases, c.Description)
+	}
+
+	return fmt.Sprintf("%s.%s[%d]", path, c.Cases, c.Index)
+}
+
+func (c ContractChange) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return "+ " + c.Path()
+	case ChangeRemoved:
+		return "- " + c.Path()
+	default:
+		return fmt.Sprintf("~ %s: %v changed", c.Path(), c.Fields)
+	}
+}
+
+// contractCase is a case of any list, with its identity in the list
+type contractCase struct {
+	key         string
+	description string
+	index       int
+	value       interface{}
+}
+
+// DiffContracts returns the services, methods and cases added, removed or changed from the
+// base contract to the revision, sorted by service, method and case list
+func DiffContracts(base, revision entities.Contract) ([]ContractChange, error) {
+	changes := make([]ContractChange, 0)
+	for _, serviceKey := range sortedKeys(base.Services, revision.Services) {
+		baseService, inBase := base.Services[serviceKey]
+		revisionService, inRevision := revision.Services[serviceKey]
+		if !inBase || !inRevision {
+			changes = append(changes, entryChange(inBase, ContractChange{Service: serviceKey}))
+			continue
+		}
+
+		for _, methodName := range sortedKeys(baseService, revisionService) {
+			baseMethod, inBase := baseService[methodName]
+			revisionMethod, inRevision := revisionService[methodName]
+			if !inBase || !inRevision {
+				changes = append(
+					changes,
+					entryChange(inBase, ContractChange{Service: serviceKey, Method: methodName}),
+				)
+				continue
+			}
+
+			methodChanges, err := diffMethods(serviceKey, methodName, baseMethod, revisionMethod)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, methodChanges...)
+		}
+	}
+
+	return changes, nil
+}
+
+// entryChange returns the change of an entry found in only one of the contracts
+func entryChange(inBase bool, change ContractChange) ContractChange {
+	change.Type = ChangeAdded
+	if inBase {
+		change.Type = ChangeRemoved
+	}
+
+	return change
+}
+
+func diffMethods(
+	serviceKey, methodName string,
+	base, revision entities.Method,
+) ([]ContractChange, error) {
+	changes := make([]ContractChange, 0)
+	for _, casesKey := range []string{SuccessCasesKey, ApplicationErrorCasesKey, FailureCasesKey} {
+		baseCases, revisionCases := MethodCases(base, casesKey), MethodCases(revision, casesKey)
+		baseByKey := make(map[string]contractCase, len(baseCases))
+		for _, baseCase := range identifyCases(baseCases) {
+			baseByKey[baseCase.key] = baseCase
+		}
+
+		revisionKeys := make(map[string]bool, len(revisionCases))
+		for _, revisionCase := range identifyCases(revisionCases) {
+			revisionKeys[revisionCase.key] = true
+			change := ContractChange{
+				Service:     serviceKey,
+				Method:      methodName,
+				Cases:       casesKey,
+				Description: revisionCase.description,
+				Index:       revisionCase.index,
+			}
+
+			baseCase, inBase := baseByKey[revisionCase.key]
+			if !inBase {
+				change.Type, change.After = ChangeAdded, revisionCase.value
+				changes = append(changes, change)
+				continue
+			}
+
+			fields, err := ChangedFields(baseCase.value, revisionCase.value)
+			if err != nil {
+				return nil, err
+			}
+			if len(fields) > 0 {
+				change.Type, change.Fields = ChangeChanged, fields
+				change.Before, change.After = baseCase.value, revisionCase.value
+				changes = append(changes, change)
+			}
+		}
+
+		for _, baseCase := range identifyCases(baseCases) {
+			if !revisionKeys[baseCase.key] {
+				changes = append(changes, ContractChange{
+					Type:        ChangeRemoved,
+					Service:     serviceKey,
+					Method:      methodName,
+					Cases:       casesKey,
+					Description: baseCase.description,
+					Index:       baseCase.index,
+					Before:      baseCase.value,
+				})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// MethodCases returns the cases of the list of the method, by the key of the list
+func MethodCases(method entities.Method, casesKey string) []interface{} {
+	cases := make([]interface{}, 0)
+	switch casesKey {
+	case SuccessCasesKey:
+		for _, successCase := range method.SuccessCases {
+			cases = append(cases, successCase)
+		}
+	case ApplicationErrorCasesKey:
+		for _, applicationErrorCase := range method.ApplicationErrorCases {
+			cases = append(cases, applicationErrorCase)
+		}
+	case FailureCasesKey:
+		for _, failureCase := range method.FailureCases {
+			cases = append(cases, failureCase)
+		}
+	}
+
+	return cases
+}
+
+// identifyCases keys the cases by their description, the cases without a description
+// or sharing it with others are keyed by their index
+func identifyCases(cases []interface{}) []contractCase {
+	descriptions := make(map[string]int, len(cases))
+	for _, value := range cases {
+		descriptions[caseDescription(value)]++
+	}
+
+	identifiedCases := make([]contractCase, 0, len(cases))
+	for index, value := range cases {
+		identifiedCase := contractCase{key: fmt.Sprintf("#%d", index), index: index, value: value}
+		if description := caseDescription(value); description != "" && descriptions[description] == 1 {
+			identifiedCase.key, identifiedCase.description = "description:"+description, description
+		}
+		identifiedCases = append(identifiedCases, identifiedCase)
+	}
+
+	return identifiedCases
+}
+
+func caseDescription(value interface{}) string {
+	return reflect.ValueOf(value).FieldByName("Description").String()
+}
+
+// ChangedFields returns the sorted JSON keys whose values differ between the two values, the
+// numbers are compared by value, e.g. 1.0 and 1 are the same number
+func ChangedFields(before, after interface{}) ([]string, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0)
+	for _, field := range sortedKeys(beforeFields, afterFields) {
+		beforeField, inBefore := beforeFields[field]
+		afterField, inAfter := afterFields[field]
+		if inBefore != inAfter {
+			fields = append(fields, field)
+			continue
+		}
+
+		changed, err := isChanged(beforeField, afterField)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields, nil
+}
+
+// canonicalJSON returns the JSON representation of the value whose object keys are sorted and
+// whose numbers are written the same way when they're equal, e.g. 1.0 and 1, or 1e300 and 1e+300
+func canonicalJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err = decoder.Decode(&decoded); err != nil {
+		return "", err
+	}
+
+	canonicalData, err := json.Marshal(canonicalNumbers(decoded))
+	if err != nil {
+		return "", err
+	}
+
+	return string(canonicalData), nil
+}
+
+// canonicalNumbers rewrites the numbers of the decoded JSON value in their shortest form, the
+// 256-bit mantissa keeps the 64-bit integers exact
+func canonicalNumbers(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, element := range typedValue {
+			typedValue[key] = canonicalNumbers(element)
+		}
+	case []interface{}:
+		for index, element := range typedValue {
+			typedValue[index] = canonicalNumbers(element)
+		}
+	case json.Number:
+		number, _, err := big.ParseFloat(
+			typedValue.String(), 10, 256, big.ToNearestEven, //nolint:revive // the mantissa bits
+		)
+		if err == nil {
+			return json.Number(number.Text('g', -1))
+		}
+	}
+
+	return value
+}
+
+func jsonFields(value interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// sortedKeys returns the keys of every map, sorted
+func sortedKeys(maps ...interface{}) []string {
+	keys := make(map[string]bool)
+	for _, value := range maps {
+		for _, key := range reflect.ValueOf(value).MapKeys() {
+			keys[key.String()] = true
+		}
+	}
+
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	return sortedKeys
+}
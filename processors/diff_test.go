@@ -0,0 +1,209 @@
+package processors_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestDiffContracts(t *testing.T) {
+	t.Parallel()
+
+	base := entities.Contract{Services: map[string]entities.Service{
+		"RemovedService": {"Get": {}},
+		"UserService": {
+			"RemovedMethod": {},
+			"GetUser": {
+				SuccessCases: []entities.SuccessCase{
+					{Description: "found", Request: map[string]interface{}{"id": "1"}},
+					{Description: "kept", Request: map[string]interface{}{"id": "2"}},
+					{Description: "removed", Request: map[string]interface{}{"id": "3"}},
+				},
+				FailureCases: []entities.FailureCase{
+					{Error: entities.GRPCError{ErrorCode: "NotFound"}},
+				},
+			},
+		},
+	}}
+	revision := entities.Contract{Services: map[string]entities.Service{
+		"AddedService": {"Get": {}},
+		"UserService": {
+			"AddedMethod": {},
+			"GetUser": {
+				SuccessCases: []entities.SuccessCase{
+					{Description: "kept", Request: map[string]interface{}{"id": "2"}},
+					{
+						Description: "found",
+						Request:     map[string]interface{}{"id": "1"},
+						Response:    map[string]interface{}{"name": "john"},
+						Timeout:     "1s",
+					},
+					{Description: "added", Request: map[string]interface{}{"id": "4"}},
+				},
+				FailureCases: []entities.FailureCase{
+					{Error: entities.GRPCError{ErrorCode: "Internal"}},
+					{Error: entities.GRPCError{ErrorCode: "NotFound"}},
+				},
+			},
+		},
+	}}
+
+	changes, err := processors.DiffContracts(base, revision)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedChanges := []string{
+		"+ services.AddedService",
+		"- services.RemovedService",
+		"+ services.UserService.AddedMethod",
+		`~ services.UserService.GetUser.successCases["found"]: [response timeout] changed`,
+		`+ services.UserService.GetUser.successCases["added"]`,
+		`- services.UserService.GetUser.successCases["removed"]`,
+		"~ services.UserService.GetUser.failureCases[0]: [error] changed",
+		"+ services.UserService.GetUser.failureCases[1]",
+		"- services.UserService.RemovedMethod",
+	}
+	givenChanges := make([]string, 0, len(changes))
+	for _, change := range changes {
+		givenChanges = append(givenChanges, change.String())
+	}
+	if !reflect.DeepEqual(givenChanges, expectedChanges) {
+		t.Errorf("Given: %q, expected: %q", givenChanges, expectedChanges)
+	}
+}
+
+func TestDiffContractsWithoutChanges(t *testing.T) {
+	t.Parallel()
+
+	contract := entities.Contract{Services: map[string]entities.Service{
+		"example.v1.UserService": {"GetUser": {
+			SuccessCases: []entities.SuccessCase{{Request: map[string]interface{}{"id": "1"}}},
+		}},
+	}}
+
+	changes, err := processors.DiffContracts(contract, contract)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, given: %v", changes)
+	}
+}
+
+func TestContractChangePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		change       processors.ContractChange
+		expectedPath string
+	}{
+		{
+			name:         "Service",
+			change:       processors.ContractChange{Service: "UserService"},
+			expectedPath: "services.UserService",
+		},
+		{
+			name:         "Fully-qualified service",
+			change:       processors.ContractChange{Service: "example.v1.UserService", Method: "GetUser"},
+			expectedPath: `services["example.v1.UserService"].GetUser`,
+		},
+		{
+			name: "Case by index",
+			change: processors.ContractChange{
+				Service: "UserService", Method: "GetUser", Cases: "failureCases", Index: 2,
+			},
+			expectedPath: "services.UserService.GetUser.failureCases[2]",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if path := test.change.Path(); path != test.expectedPath {
+				t.Errorf("Given: %s, expected: %s", path, test.expectedPath)
+			}
+		})
+	}
+}
+
+// decodeTestJSON decodes the JSON data the way the contracts are decoded, the numbers are kept
+// as written
+func decodeTestJSON(t *testing.T, data string) interface{} {
+	t.Helper()
+
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return value
+}
+
+func TestChangedFieldsComparesNumbersByValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		before         string
+		after          string
+		expectedFields []string
+	}{
+		{
+			name:           "should ignore a decimal point",
+			before:         `{"a": 1.0}`,
+			after:          `{"a": 1}`,
+			expectedFields: []string{},
+		},
+		{
+			name:           "should ignore the sign of an exponent",
+			before:         `{"a": {"b": [1e300]}}`,
+			after:          `{"a": {"b": [1e+300]}}`,
+			expectedFields: []string{},
+		},
+		{
+			name:           "should compare the 64-bit integers exactly",
+			before:         `{"a": 9007199254740993}`,
+			after:          `{"a": 9007199254740992}`,
+			expectedFields: []string{"a"},
+		},
+		{
+			name:           "should tell a number from a string",
+			before:         `{"a": 1}`,
+			after:          `{"a": "1"}`,
+			expectedFields: []string{"a"},
+		},
+		{
+			name:           "should tell a missing field from a null one",
+			before:         `{"a": null}`,
+			after:          `{}`,
+			expectedFields: []string{"a"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fields, err := processors.ChangedFields(
+				decodeTestJSON(t, test.before), decodeTestJSON(t, test.after),
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(fields, test.expectedFields) {
+				t.Errorf("Given: %v, expected: %v", fields, test.expectedFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package processors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// ServiceDocs documents a service of the contract for its consumers, the methods are sorted
+type ServiceDocs struct {
+	Name    string       `json:"name"`
+	Methods []MethodDocs `json:"methods"`
+}
+
+// MethodDocs documents the cases of a method, the lists keep the contract order
+type MethodDocs struct {
+	Name                  string     `json:"name"`
+	SuccessCases          []CaseDocs `json:"successCases"`
+	ApplicationErrorCases []CaseDocs `json:"applicationErrorCases"`
+	FailureCases          []CaseDocs `json:"failureCases"`
+}
+
+// CaseDocs documents a case, the request and the response are rendered as indented JSON.
+// Responses is set instead of Response for the sequenced cases, Error for the failure cases.
+type CaseDocs struct {
+	Description string            `json:"description"`
+	Request     string            `json:"request"`
+	Oneofs      map[string]string `json:"oneofs,omitempty"`
+	Response    string            `json:"response,omitempty"`
+	Responses   string            `json:"responses,omitempty"`
+	ErrorField  string            `json:"errorField,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// BuildContractDocs documents every service of the contract, sorted by name
+func BuildContractDocs(contract entities.Contract) ([]ServiceDocs, error) {
+	services := make([]ServiceDocs, 0, len(contract.Services))
+	for _, serviceName := range sortedKeys(contract.Services) {
+		service, err := BuildServiceDocs(serviceName, contract.Services[serviceName])
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, service)
+	}
+
+	return services, nil
+}
+
+// BuildServiceDocs documents the methods of the service, the cases without a description
+// are named after their position in the list, e.g. `Case 2`
+func BuildServiceDocs(name string, service entities.Service) (ServiceDocs, error) {
+	docs := ServiceDocs{Name: name, Methods: make([]MethodDocs, 0, len(service))}
+	for _, methodName := range sortedKeys(service) {
+		method := service[methodName]
+		methodDocs := MethodDocs{
+			Name:                  methodName,
+			SuccessCases:          make([]CaseDocs, 0, len(method.SuccessCases)),
+			ApplicationErrorCases: make([]CaseDocs, 0, len(method.ApplicationErrorCases)),
+			FailureCases:          make([]CaseDocs, 0, len(method.FailureCases)),
+		}
+
+		for index, successCase := range method.SuccessCases {
+			caseDocs, err := newCaseDocs(index, successCase.Description, successCase.Request)
+			if err != nil {
+				return ServiceDocs{}, fmt.Errorf("%s.%s: %w", name, methodName, err)
+			}
+			caseDocs.Oneofs = successCase.Oneofs
+			if sequence := successCase.Sequence(); sequence != nil {
+				caseDocs.Responses, err = indentedJSON(sequence)
+			} else {
+				caseDocs.Response, err = indentedJSON(successCase.Response)
+			}
+			if err != nil {
+				return ServiceDocs{}, fmt.Errorf("%s.%s: %w", name, methodName, err)
+			}
+			methodDocs.SuccessCases = append(methodDocs.SuccessCases, caseDocs)
+		}
+
+		for index, applicationErrorCase := range method.ApplicationErrorCases {
+			caseDocs, err := newCaseDocs(
+				index, applicationErrorCase.Description, applicationErrorCase.Request,
+			)
+			if err != nil {
+				return ServiceDocs{}, fmt.Errorf("%s.%s: %w", name, methodName, err)
+			}
+			caseDocs.Oneofs = applicationErrorCase.Oneofs
+			caseDocs.ErrorField = applicationErrorCase.ErrorField
+			if caseDocs.Response, err = indentedJSON(applicationErrorCase.Response); err != nil {
+				return ServiceDocs{}, fmt.Errorf("%s.%s: %w", name, methodName, err)
+			}
+			methodDocs.ApplicationErrorCases = append(methodDocs.ApplicationErrorCases, caseDocs)
+		}
+
+		for index, failureCase := range method.FailureCases {
+			caseDocs, err := newCaseDocs(index, failureCase.Description, failureCase.Request)
+			if err != nil {
+				return ServiceDocs{}, fmt.Errorf("%s.%s: %w", name, methodName, err)
+			}
+			caseDocs.Oneofs = failureCase.Oneofs
+			caseDocs.Error = describeError(failureCase.Error)
+			methodDocs.FailureCases = append(methodDocs.FailureCases, caseDocs)
+		}
+
+		docs.Methods = append(docs.Methods, methodDocs)
+	}
+
+	return docs, nil
+}
+
+func newCaseDocs(index int, description string, request interface{}) (CaseDocs, error) {
+	if description == "" {
+		description = fmt.Sprintf("Case %d", index+1)
+	}
+
+	renderedRequest, err := indentedJSON(request)
+	if err != nil {
+		return CaseDocs{}, fmt.Errorf("%s: %w", description, err)
+	}
+
+	return CaseDocs{Description: description, Request: renderedRequest}, nil
+}
+
+// describeError returns the codes and the message of the error, e.g. `NotFound: user not found`
+func describeError(grpcError entities.GRPCError) string {
+	codes := make([]string, 0, len(grpcError.AlternativeCodes)+1)
+	for _, code := range grpcError.Codes() {
+		codes = append(codes, string(code))
+	}
+
+	description := strings.Join(codes, " or ")
+	if grpcError.Message != "" {
+		description += ": " + grpcError.Message
+	}
+
+	return description
+}
+
+// indentedJSON renders the value as JSON indented by two spaces, an absent value is rendered
+// as an empty object since it's the default message
+func indentedJSON(value interface{}) (string, error) {
+	if value == nil {
+		return "{}", nil
+	}
+
+	output := bytes.NewBuffer(nil)
+	encoder := json.NewEncoder(output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(output.String(), "\n"), nil
+}
+
+// WriteMarkdownDocs writes the documentation of the service as Markdown, a section per method
+// listing its success, application error and failure cases
+func WriteMarkdownDocs(output io.Writer, docs ServiceDocs) error {
+	markdown := bytes.NewBuffer(nil)
+	fmt.Fprintf(markdown, "# %s\n\n", docs.Name)
+	for _, method := range docs.Methods {
+		fmt.Fprintf(markdown, "- [%s](#%s)\n", method.Name, strings.ToLower(method.Name))
+	}
+
+	for _, method := range docs.Methods {
+		fmt.Fprintf(markdown, "\n## %s\n", method.Name)
+		if len(method.SuccessCases)+len(method.ApplicationErrorCases)+len(method.FailureCases) == 0 {
+			markdown.WriteString("\nThe method has no case.\n")
+		}
+
+		for _, cases := range []struct {
+			title string
+			cases []CaseDocs
+		}{
+			{title: "Success cases", cases: method.SuccessCases},
+			{title: "Application error cases", cases: method.ApplicationErrorCases},
+			{title: "Failure cases", cases: method.FailureCases},
+		} {
+			if len(cases.cases) == 0 {
+				continue
+			}
+
+			fmt.Fprintf(markdown, "\n### %s\n", cases.title)
+			for _, caseDocs := range cases.cases {
+				writeMarkdownCase(markdown, caseDocs)
+			}
+		}
+	}
+
+	_, err := output.Write(markdown.Bytes())
+	return err
+}
+
+func writeMarkdownCase(markdown *bytes.Buffer, caseDocs CaseDocs) {
+	fmt.Fprintf(
+		markdown, "\n#### %s\n\nRequest:\n\n```json\n%s\n```\n", caseDocs.Description, caseDocs.Request,
+	)
+	for _, oneof := range sortedKeys(caseDocs.Oneofs) {
+		fmt.Fprintf(markdown, "\nThe `%s` oneof must be set to `%s`.\n", oneof, caseDocs.Oneofs[oneof])
+	}
+
+	switch {
+	case caseDocs.Error != "":
+		fmt.Fprintf(markdown, "\nError: `%s`\n", caseDocs.Error)
+	case caseDocs.Responses != "":
+		fmt.Fprintf(
+			markdown, "\nResponses, one per call:\n\n```json\n%s\n```\n", caseDocs.Responses,
+		)
+	default:
+		fmt.Fprintf(markdown, "\nResponse:\n\n```json\n%s\n```\n", caseDocs.Response)
+		if caseDocs.ErrorField != "" {
+			fmt.Fprintf(
+				markdown, "\nThe application error is held by the `%s` field.\n", caseDocs.ErrorField,
+			)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package processors_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestWriteMarkdownDocs(t *testing.T) {
+	t.Parallel()
+
+	service := entities.Service{
+		"GetUser": {
+			SuccessCases: []entities.SuccessCase{
+				{
+					Description: "Should return the user",
+					Request:     map[string]interface{}{"id": "1"},
+					Response:    map[string]interface{}{"name": "john"},
+				},
+				{
+					Request: map[string]interface{}{"id": "2"},
+					Responses: []entities.SequenceStep{
+						{Error: &entities.GRPCError{ErrorCode: "Unavailable"}},
+						{Response: map[string]interface{}{"name": "mary"}},
+					},
+				},
+			},
+			ApplicationErrorCases: []entities.ApplicationErrorCase{
+				{
+					Description: "Should return a disabled status",
+					Request:     map[string]interface{}{"id": "3"},
+					Response:    map[string]interface{}{"status": "DISABLED"},
+					ErrorField:  "status",
+				},
+			},
+			FailureCases: []entities.FailureCase{
+				{
+					Description: "Should fail for an unknown user",
+					Oneofs:      map[string]string{"contact": "email"},
+					Error: entities.GRPCError{
+						ErrorCode:        "NotFound",
+						AlternativeCodes: []entities.ErrorCode{"FailedPrecondition"},
+						Message:          "user not found",
+					},
+				},
+			},
+		},
+		"DeleteUser": {},
+	}
+
+	expectedMarkdown := "# UserService\n\n" +
+		"- [DeleteUser](#deleteuser)\n" +
+		"- [GetUser](#getuser)\n" +
+		"\n## DeleteUser\n\nThe method has no case.\n" +
+		"\n## GetUser\n" +
+		"\n### Success cases\n" +
+		"\n#### Should return the user\n\nRequest:\n\n```json\n{\n  \"id\": \"1\"\n}\n```\n" +
+		"\nResponse:\n\n```json\n{\n  \"name\": \"john\"\n}\n```\n" +
+		"\n#### Case 2\n\nRequest:\n\n```json\n{\n  \"id\": \"2\"\n}\n```\n" +
+		"\nResponses, one per call:\n\n```json\n[\n" +
+		"  {\n    \"error\": {\n      \"errorCode\": \"Unavailable\"\n    }\n  },\n" +
+		"  {\n    \"response\": {\n      \"name\": \"mary\"\n    }\n  }\n]\n```\n" +
+		"\n### Application error cases\n" +
+		"\n#### Should return a disabled status\n\nRequest:\n\n```json\n{\n  \"id\": \"3\"\n}\n```\n" +
+		"\nResponse:\n\n```json\n{\n  \"status\": \"DISABLED\"\n}\n```\n" +
+		"\nThe application error is held by the `status` field.\n" +
+		"\n### Failure cases\n" +
+		"\n#### Should fail for an unknown user\n\nRequest:\n\n```json\n{}\n```\n" +
+		"\nThe `contact` oneof must be set to `email`.\n" +
+		"\nError: `NotFound or FailedPrecondition: user not found`\n"
+
+	docs, err := processors.BuildServiceDocs("UserService", service)
+	if err != nil {
+		t.Fatalf("unexpected error happened: %v", err)
+	}
+
+	markdown := bytes.NewBuffer(nil)
+	if err = processors.WriteMarkdownDocs(markdown, docs); err != nil {
+		t.Fatalf("unexpected error happened: %v", err)
+	}
+
+	if markdown.String() != expectedMarkdown {
+		t.Errorf("Given:\n%s\nexpected:\n%s", markdown, expectedMarkdown)
+	}
+}
+
+func TestBuildContractDocs(t *testing.T) {
+	t.Parallel()
+
+	contract := entities.Contract{Services: map[string]entities.Service{
+		"UserService":  {"GetUser": {}},
+		"OrderService": {"GetOrder": {}},
+	}}
+
+	docs, err := processors.BuildContractDocs(contract)
+	if err != nil {
+		t.Fatalf("unexpected error happened: %v", err)
+	}
+
+	if len(docs) != 2 || docs[0].Name != "OrderService" || docs[1].Name != "UserService" {
+		t.Errorf("unexpected services: %+v", docs)
+	}
+}
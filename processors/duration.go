@@ -0,0 +1,50 @@
+package processors
+
+import (
+	"fmt"
+	"time"
+)
+
+var durationUnits = []struct {
+	name  string
+	value time.Duration
+}{
+	{name: "Hour", value: time.Hour},
+	{name: "Minute", value: time.Minute},
+	{name: "Second", value: time.Second},
+	{name: "Millisecond", value: time.Millisecond},
+	{name: "Microsecond", value: time.Microsecond},
+	{name: "Nanosecond", value: time.Nanosecond},
+}
+
+// ParseDuration parses a duration provided through the contract (e.g. "150ms"),
+// an empty string means no duration at all.
+func ParseDuration(duration string) (time.Duration, error) {
+	if duration == "" {
+		return 0, nil
+	}
+
+	parsedDuration, err := time.ParseDuration(duration)
+	if err != nil {
+		return 0, err
+	}
+
+	if parsedDuration < 0 {
+		return 0, fmt.Errorf("duration %q must not be negative", duration)
+	}
+
+	return parsedDuration, nil
+}
+
+// DurationUnit splits a duration in an amount of the largest time unit that represents it
+// exactly, returning the amount and the unit identifier in the time package.
+// E.g. 150ms -> (150, "Millisecond") which can be written as 150 * time.Millisecond
+func DurationUnit(duration time.Duration) (int64, string) {
+	for _, unit := range durationUnits {
+		if duration%unit.value == 0 {
+			return int64(duration / unit.value), unit.name
+		}
+	}
+
+	return int64(duration), "Nanosecond"
+}
@@ -0,0 +1,104 @@
+package processors_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		duration         string
+		expectedDuration time.Duration
+		expectedError    bool
+	}{
+		{
+			name:             "should return zero when the duration is empty",
+			duration:         "",
+			expectedDuration: 0,
+		},
+		{
+			name:             "should parse a valid duration",
+			duration:         "150ms",
+			expectedDuration: 150 * time.Millisecond, //nolint:revive // random duration
+		},
+		{
+			name:          "should fail when the duration is invalid",
+			duration:      "150",
+			expectedError: true,
+		},
+		{
+			name:          "should fail when the duration is negative",
+			duration:      "-1s",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualDuration, err := processors.ParseDuration(test.duration)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("Unexpected error result, given: %v", err)
+			}
+
+			if actualDuration != test.expectedDuration {
+				t.Errorf(
+					"Wrong duration, given: %v, expected: %v",
+					actualDuration, test.expectedDuration,
+				)
+			}
+		})
+	}
+}
+
+func TestDurationUnit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		duration       time.Duration
+		expectedAmount int64
+		expectedUnit   string
+	}{
+		{
+			name:           "should use hours when possible",
+			duration:       2 * time.Hour,
+			expectedAmount: 2,
+			expectedUnit:   "Hour",
+		},
+		{
+			name:           "should use milliseconds when seconds aren't exact",
+			duration:       1500 * time.Millisecond, //nolint:revive // random duration
+			expectedAmount: 1500,                    //nolint:revive // random duration
+			expectedUnit:   "Millisecond",
+		},
+		{
+			name:           "should fallback to nanoseconds",
+			duration:       time.Duration(1001),
+			expectedAmount: 1001, //nolint:revive // random duration
+			expectedUnit:   "Nanosecond",
+		},
+		{
+			name:           "should work with zero",
+			duration:       0,
+			expectedAmount: 0,
+			expectedUnit:   "Hour",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualAmount, actualUnit := processors.DurationUnit(test.duration)
+			if actualAmount != test.expectedAmount || actualUnit != test.expectedUnit {
+				t.Errorf(
+					"Wrong duration unit, given: %d %s, expected: %d %s",
+					actualAmount, actualUnit, test.expectedAmount, test.expectedUnit,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package processors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// ContractEncoder encodes the contract into the content of a contract file
+type ContractEncoder func(contract entities.Contract) ([]byte, error)
+
+var (
+	encodersMutex    sync.RWMutex
+	contractEncoders = map[string]ContractEncoder{
+		".json": EncodeJSONContract,
+		".yaml": EncodeYAMLContract,
+		".yml":  EncodeYAMLContract,
+		".cue":  EncodeCUEContract,
+	}
+)
+
+// RegisterContractEncoder registers the encoder of the contract files with the given extension
+// (e.g. ".toml"), replacing the previous one. The extensions are case-insensitive.
+func RegisterContractEncoder(extension string, encoder ContractEncoder) {
+	encodersMutex.Lock()
+	defer encodersMutex.Unlock()
+
+	contractEncoders[strings.ToLower(extension)] = encoder
+}
+
+// ContractEncoderFor returns the encoder of the contract file, it's chosen by the file extension.
+// The files of an unknown extension are encoded as JSON.
+func ContractEncoderFor(filePath string) ContractEncoder {
+	if encoder, exists := contractEncoder(filepath.Ext(filePath)); exists {
+		return encoder
+	}
+
+	return EncodeJSONContract
+}
+
+// contractEncoder returns the encoder registered for the extension
+func contractEncoder(extension string) (ContractEncoder, bool) {
+	encodersMutex.RLock()
+	defer encodersMutex.RUnlock()
+
+	encoder, exists := contractEncoders[strings.ToLower(extension)]
+	return encoder, exists
+}
+
+// ContractEncoderExtensions returns the extensions with a registered encoder, sorted
+func ContractEncoderExtensions() []string {
+	encodersMutex.RLock()
+	defer encodersMutex.RUnlock()
+
+	extensions := make([]string, 0, len(contractEncoders))
+	for extension := range contractEncoders {
+		extensions = append(extensions, extension)
+	}
+	sort.Strings(extensions)
+
+	return extensions
+}
+
+// EncodeJSONContract encodes the contract as JSON indented by two spaces, the fields of the
+// contract keep their declaration order while the keys of the messages are sorted
+func EncodeJSONContract(contract entities.Contract) ([]byte, error) {
+	output := bytes.NewBuffer(nil)
+	encoder := json.NewEncoder(output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(contract); err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+// EncodeYAMLContract encodes the contract as YAML indented by two spaces, every key is sorted
+func EncodeYAMLContract(contract entities.Contract) ([]byte, error) {
+	jsonData, err := json.Marshal(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	// The numbers are kept as written, a float64 can't represent every 64-bit integer
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err = decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	output := bytes.NewBuffer(nil)
+	encoder := yaml.NewEncoder(output)
+	encoder.SetIndent(2) //nolint:revive // the indentation of the contract files
+
+	if err = encoder.Encode(yamlNumbers(document)); err != nil {
+		return nil, err
+	}
+	if err = encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+// yamlNumbers converts the JSON numbers to Go numbers, they'd be encoded as strings otherwise
+func yamlNumbers(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, element := range typedValue {
+			typedValue[key] = yamlNumbers(element)
+		}
+		return typedValue
+	case []interface{}:
+		for index, element := range typedValue {
+			typedValue[index] = yamlNumbers(element)
+		}
+		return typedValue
+	case json.Number:
+		if number, err := typedValue.Int64(); err == nil {
+			return number
+		}
+		if number, err := strconv.ParseUint(typedValue.String(), 10, 64); err == nil {
+			return number
+		}
+		if number, err := typedValue.Float64(); err == nil {
+			return number
+		}
+		return typedValue.String()
+	default:
+		return value
+	}
+}
+
+// cueIdentifier matches the labels written without quotes, the other ones
+// (e.g. "example.v1.UserService" or "_hidden") are quoted
+var cueIdentifier = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// cueKeywords can't be written as labels without quotes
+var cueKeywords = map[string]bool{
+	"true": true, "false": true, "null": true, "package": true, "import": true,
+	"for": true, "in": true, "if": true, "let": true,
+	"div": true, "mod": true, "quo": true, "rem": true,
+}
+
+// EncodeCUEContract encodes the contract as CUE data indented by tabs, as formatted by `cue fmt`.
+// The fields keep the order of the JSON encoding, the contract fields being the top-level ones.
+func EncodeCUEContract(contract entities.Contract) ([]byte, error) {
+	jsonData, err := json.Marshal(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	// The JSON tokens are streamed, a decoded map would lose the order of the fields
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	if _, err = decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	output := bytes.NewBuffer(nil)
+	if err = writeCUEFields(decoder, output, 0); err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+// writeCUEFields writes the remaining fields of the JSON object, one per line
+func writeCUEFields(decoder *json.Decoder, output *bytes.Buffer, depth int) error {
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		label, _ := token.(string)
+		if !cueIdentifier.MatchString(label) || cueKeywords[label] {
+			label, err = cueString(label)
+			if err != nil {
+				return err
+			}
+		}
+
+		output.WriteString(strings.Repeat("\t", depth) + label + ": ")
+		if err = writeCUEValue(decoder, output, depth); err != nil {
+			return err
+		}
+		output.WriteString("\n")
+	}
+
+	// The end of the object
+	_, err := decoder.Token()
+	return err
+}
+
+// writeCUEValue writes the next JSON value, the elements of a list are written on the
+// same line, e.g. `[{` and `}, {`, so only the fields of the structs are indented
+func writeCUEValue(decoder *json.Decoder, output *bytes.Buffer, depth int) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if !decoder.More() {
+			output.WriteString(string(value))
+			endToken, err := decoder.Token()
+			output.WriteString(fmt.Sprint(endToken))
+			return err
+		}
+
+		if value == '{' {
+			output.WriteString("{\n")
+			if err = writeCUEFields(decoder, output, depth+1); err != nil {
+				return err
+			}
+			output.WriteString(strings.Repeat("\t", depth) + "}")
+			return nil
+		}
+
+		output.WriteString("[")
+		for index := 0; decoder.More(); index++ {
+			if index > 0 {
+				output.WriteString(", ")
+			}
+			if err = writeCUEValue(decoder, output, depth); err != nil {
+				return err
+			}
+		}
+		output.WriteString("]")
+		_, err = decoder.Token()
+		return err
+	case string:
+		quoted, err := cueString(value)
+		output.WriteString(quoted)
+		return err
+	case json.Number:
+		output.WriteString(value.String())
+	case bool:
+		output.WriteString(strconv.FormatBool(value))
+	case nil:
+		output.WriteString("null")
+	default:
+		return fmt.Errorf("unexpected JSON token %v", token)
+	}
+
+	return nil
+}
+
+// cueString quotes the string, the JSON escapes are valid in CUE and escaping the backslashes
+// prevents the string interpolations, e.g. `\(name)`
+func cueString(value string) (string, error) {
+	output := bytes.NewBuffer(nil)
+	encoder := json.NewEncoder(output)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(output.String(), "\n"), nil
+}
@@ -0,0 +1,45 @@
+package processors_test
+
+import (
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestContractEncoderFor(t *testing.T) {
+	t.Parallel()
+
+	processors.RegisterContractEncoder(".Text", func(contract entities.Contract) ([]byte, error) {
+		return []byte(contract.Name), nil
+	})
+
+	contract := entities.Contract{Name: "users"}
+	jsonContent := "{\n  \"name\": \"users\",\n  \"services\": null\n}\n"
+	tests := []struct {
+		name            string
+		filePath        string
+		expectedContent string
+	}{
+		{name: "JSON", filePath: "contract.json", expectedContent: jsonContent},
+		{name: "YAML", filePath: "contract.YML", expectedContent: "name: users\nservices: null\n"},
+		{name: "CUE", filePath: "contract.cue", expectedContent: "name: \"users\"\nservices: null\n"},
+		{name: "Registered encoder", filePath: "contract.text", expectedContent: "users"},
+		{name: "Unknown extension", filePath: "contract", expectedContent: jsonContent},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := processors.ContractEncoderFor(test.filePath)(contract)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(content) != test.expectedContent {
+				t.Errorf("Given: %q, expected: %q", content, test.expectedContent)
+			}
+		})
+	}
+}
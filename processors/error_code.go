@@ -1,5 +1,12 @@
 package processors
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The codes are declared following their numeric values
 var allowedErrorCodeNames = []string{
 	"OK",
 	"Canceled", // It's not a typo here, this is the actual identifier in grpc codes
@@ -20,12 +27,58 @@ var allowedErrorCodeNames = []string{
 	"Unauthenticated",
 }
 
-// IsErrorCodeValid returns true when a error code exists in the GRPC Codes package
+const (
+	canceledCode = "Canceled"
+	// canceledAlias is the lowercase canonical name of the Canceled code (CANCELLED)
+	canceledAlias = "cancelled"
+)
+
+// IsErrorCodeValid returns true when a error code exists in the GRPC Codes package,
+// every form accepted by NormalizeErrorCode is valid
 func IsErrorCodeValid(errorCode string) bool {
+	_, err := NormalizeErrorCode(errorCode)
+	return err == nil
+}
+
+// NormalizeErrorCode returns the identifier of the given error code in the GRPC Codes package.
+// Besides the identifier itself (NotFound) the canonical name (NOT_FOUND), case-insensitive
+// names (not_found, notfound) and numeric codes (5) are accepted.
+func NormalizeErrorCode(errorCode string) (string, error) {
+	if number, err := strconv.Atoi(errorCode); err == nil {
+		if number < 0 || number >= len(allowedErrorCodeNames) {
+			return "", fmt.Errorf("invalid error code: %s", errorCode)
+		}
+
+		return allowedErrorCodeNames[number], nil
+	}
+
+	name := strings.ToLower(strings.ReplaceAll(errorCode, "_", ""))
+	if name == canceledAlias {
+		return canceledCode, nil
+	}
+
 	for _, allowedCode := range allowedErrorCodeNames {
-		if errorCode == allowedCode {
-			return true
+		if name == strings.ToLower(allowedCode) {
+			return allowedCode, nil
 		}
 	}
-	return false
+
+	return "", fmt.Errorf("invalid error code: %s", errorCode)
+}
+
+// ErrorCodeNumber returns the numeric value of the given error code, any form accepted by
+// NormalizeErrorCode is accepted
+func ErrorCodeNumber(errorCode string) (uint32, error) {
+	name, err := NormalizeErrorCode(errorCode)
+	if err != nil {
+		return 0, err
+	}
+
+	for number, allowedCode := range allowedErrorCodeNames {
+		if name == allowedCode {
+			return uint32(number), nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid error code: %s", errorCode)
 }
@@ -118,3 +118,108 @@ func TestIsErrorCodeValid(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeErrorCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		errorCode     string
+		expectedCode  string
+		expectedError bool
+	}{
+		{
+			name:         "should keep the identifier",
+			errorCode:    "NotFound",
+			expectedCode: "NotFound",
+		},
+		{
+			name:         "should accept the canonical name",
+			errorCode:    "FAILED_PRECONDITION",
+			expectedCode: "FailedPrecondition",
+		},
+		{
+			name:         "should accept the canonical name of Canceled",
+			errorCode:    "CANCELLED",
+			expectedCode: "Canceled",
+		},
+		{
+			name:         "should accept lowercase names",
+			errorCode:    "permission_denied",
+			expectedCode: "PermissionDenied",
+		},
+		{
+			name:         "should accept lowercase identifiers",
+			errorCode:    "unauthenticated",
+			expectedCode: "Unauthenticated",
+		},
+		{
+			name:         "should accept numeric codes",
+			errorCode:    "14",
+			expectedCode: "Unavailable",
+		},
+		{
+			name:          "should fail when the numeric code doesn't exist",
+			errorCode:     "17",
+			expectedError: true,
+		},
+		{
+			name:          "should fail when the code doesn't exist",
+			errorCode:     "MY_TEST",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualCode, err := processors.NormalizeErrorCode(test.errorCode)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("Unexpected error result, given: %v", err)
+			}
+
+			if actualCode != test.expectedCode {
+				t.Errorf("Given: %v, expected: %v", actualCode, test.expectedCode)
+			}
+		})
+	}
+}
+
+func TestErrorCodeNumber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		errorCode      string
+		expectedNumber uint32
+		expectedError  bool
+	}{
+		{
+			name:           "should return the number of the identifier",
+			errorCode:      "NotFound",
+			expectedNumber: 5,
+		},
+		{
+			name:           "should return the number of the canonical name",
+			errorCode:      "UNAUTHENTICATED",
+			expectedNumber: 16,
+		},
+		{
+			name:          "should fail when the code doesn't exist",
+			errorCode:     "MY_TEST",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualNumber, err := processors.ErrorCodeNumber(test.errorCode)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("Unexpected error result, given: %v", err)
+			}
+
+			if actualNumber != test.expectedNumber {
+				t.Errorf("Given: %v, expected: %v", actualNumber, test.expectedNumber)
+			}
+		})
+	}
+}
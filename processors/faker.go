@@ -0,0 +1,216 @@
+package processors
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FakePlaceholderKey is the key used to declare a fake value, e.g. {"$fake": "email"}
+const FakePlaceholderKey = "$fake"
+
+var (
+	fakeFirstNames = []string{
+		"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda",
+		"David", "Elizabeth", "William", "Barbara", "Richard", "Susan", "Joseph", "Jessica",
+	}
+	fakeLastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+		"Rodriguez", "Martinez", "Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas",
+	}
+	fakeWords = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit",
+		"sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna",
+	}
+	fakeCities = []string{
+		"London", "Paris", "Tokyo", "New York", "Berlin", "Sydney", "Toronto", "Madrid",
+		"Rome", "Lisbon", "Amsterdam", "Dublin", "Seoul", "Mumbai", "Cairo", "Lima",
+	}
+	fakeCountries = []string{
+		"United Kingdom", "France", "Japan", "United States", "Germany", "Australia", "Canada",
+		"Spain", "Italy", "Portugal", "Netherlands", "Ireland", "South Korea", "India", "Egypt",
+	}
+	fakeCompanySuffixes = []string{"Inc", "LLC", "Group", "Ltd", "Corp"}
+	fakeDomains         = []string{"example.com", "example.org", "example.net"}
+)
+
+// fakeGenerators maps a fake kind to the function that generates its values
+var fakeGenerators = map[string]func(r *rand.Rand) interface{}{
+	"uuid": func(r *rand.Rand) interface{} {
+		// Version 4 UUID with the RFC 4122 variant
+		return fmt.Sprintf(
+			"%08x-%04x-4%03x-%04x-%012x",
+			r.Uint32(), r.Intn(0x10000), r.Intn(0x1000), 0x8000|r.Intn(0x4000), r.Int63n(1<<48),
+		)
+	},
+	"firstName": func(r *rand.Rand) interface{} { return pick(r, fakeFirstNames) },
+	"lastName":  func(r *rand.Rand) interface{} { return pick(r, fakeLastNames) },
+	"name": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("%s %s", pick(r, fakeFirstNames), pick(r, fakeLastNames))
+	},
+	"username": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("%s%d", strings.ToLower(pick(r, fakeFirstNames)), r.Intn(1000))
+	},
+	"email": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf(
+			"%s.%s@%s",
+			strings.ToLower(pick(r, fakeFirstNames)),
+			strings.ToLower(pick(r, fakeLastNames)),
+			pick(r, fakeDomains),
+		)
+	},
+	"phone": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("+1-%03d-555-%04d", r.Intn(800)+200, r.Intn(10000))
+	},
+	"url": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("https://%s/%s", pick(r, fakeDomains), pick(r, fakeWords))
+	},
+	"ipv4": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("10.%d.%d.%d", r.Intn(256), r.Intn(256), r.Intn(256))
+	},
+	"word": func(r *rand.Rand) interface{} { return pick(r, fakeWords) },
+	"sentence": func(r *rand.Rand) interface{} {
+		words := make([]string, 0)
+		for i := 0; i < 4+r.Intn(5); i++ {
+			words = append(words, pick(r, fakeWords))
+		}
+		sentence := strings.Join(words, " ")
+		return fmt.Sprintf("%s%s.", strings.ToUpper(sentence[:1]), sentence[1:])
+	},
+	"city":    func(r *rand.Rand) interface{} { return pick(r, fakeCities) },
+	"country": func(r *rand.Rand) interface{} { return pick(r, fakeCountries) },
+	"company": func(r *rand.Rand) interface{} {
+		return fmt.Sprintf("%s %s", pick(r, fakeLastNames), pick(r, fakeCompanySuffixes))
+	},
+	"int":   func(r *rand.Rand) interface{} { return r.Intn(10000) },
+	"float": func(r *rand.Rand) interface{} { return float64(r.Intn(1000000)) / 100 },
+	"bool":  func(r *rand.Rand) interface{} { return r.Intn(2) == 1 },
+	"timestamp": func(r *rand.Rand) interface{} {
+		// Any second between 2000-01-01 and 2030-01-01
+		return time.Unix(946684800+r.Int63n(946771200), 0).UTC().Format(time.RFC3339)
+	},
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
+
+// FakeKinds returns the sorted list of supported fake kinds
+func FakeKinds() []string {
+	kinds := make([]string, 0, len(fakeGenerators))
+	for kind := range fakeGenerators {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	return kinds
+}
+
+// ResolveFakeValues replaces every fake placeholder (e.g. {"$fake": "email"}) found in the
+// given JSON value by a generated value, returning the new value and the paths of the
+// replaced fields. A path is the dot separated list of the keys leading to the placeholder,
+// list indexes aren't part of it.
+//
+// The values are reproducible: the same seed and value always generate the same fake values.
+func ResolveFakeValues(value interface{}, seed int64) (interface{}, []string, error) {
+	template, err := json.Marshal(value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolver := fakeResolver{seed: seed, template: string(template)}
+	resolvedValue, err := resolver.resolve(value, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resolvedValue, resolver.paths, nil
+}
+
+type fakeResolver struct {
+	seed     int64
+	template string
+	paths    []string
+	count    int
+}
+
+func (f *fakeResolver) resolve(value interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if kind, isPlaceholder := v[FakePlaceholderKey]; isPlaceholder {
+			return f.fake(kind, path)
+		}
+
+		// Keys are sorted to keep the generation order, and so the values, reproducible
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		resolved := make(map[string]interface{}, len(v))
+		for _, key := range keys {
+			item := v[key]
+			resolvedItem, err := f.resolve(item, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = resolvedItem
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			resolvedItem, err := f.resolve(item, path)
+			if err != nil {
+				return nil, err
+			}
+			resolved = append(resolved, resolvedItem)
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
+
+func (f *fakeResolver) fake(kind interface{}, path string) (interface{}, error) {
+	kindName, isString := kind.(string)
+	generator, exists := fakeGenerators[kindName]
+	if !isString || !exists {
+		return nil, fmt.Errorf(
+			"unknown fake kind %v at %q, expected one of %v", kind, path, FakeKinds(),
+		)
+	}
+
+	// Every placeholder has its own source, derived from the seed, the value
+	// being resolved and the placeholder position inside of it
+	hash := fnv.New64a()
+	hash.Write([]byte(fmt.Sprintf("%s|%s|%d", f.template, path, f.count)))
+	f.count++
+
+	f.paths = appendUnique(f.paths, path)
+
+	return generator(rand.New(rand.NewSource(f.seed ^ int64(hash.Sum64())))), nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return fmt.Sprintf("%s.%s", path, key)
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
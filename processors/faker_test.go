@@ -0,0 +1,126 @@
+package processors_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestResolveFakeValues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		value         interface{}
+		expectedPaths []string
+	}{
+		{
+			name:          "should keep the value when there are no placeholders",
+			value:         map[string]interface{}{"name": "John"},
+			expectedPaths: nil,
+		},
+		{
+			name: "should replace the placeholders of nested values",
+			value: map[string]interface{}{
+				"id":      map[string]interface{}{"$fake": "uuid"},
+				"profile": map[string]interface{}{"email": map[string]interface{}{"$fake": "email"}},
+			},
+			expectedPaths: []string{"id", "profile.email"},
+		},
+		{
+			name: "should report list paths without indexes",
+			value: map[string]interface{}{
+				"users": []interface{}{
+					map[string]interface{}{"name": map[string]interface{}{"$fake": "name"}},
+					map[string]interface{}{"name": map[string]interface{}{"$fake": "name"}},
+				},
+			},
+			expectedPaths: []string{"users.name"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, actualPaths, err := processors.ResolveFakeValues(test.value, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actualPaths, test.expectedPaths) {
+				t.Errorf("Wrong paths, given: %v, expected: %v", actualPaths, test.expectedPaths)
+			}
+		})
+	}
+}
+
+func TestResolveFakeValuesIsReproducible(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]interface{}{
+		"id":    map[string]interface{}{"$fake": "uuid"},
+		"email": map[string]interface{}{"$fake": "email"},
+	}
+
+	first, _, err := processors.ResolveFakeValues(value, 42) //nolint:revive // random seed
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, _, err := processors.ResolveFakeValues(value, 42) //nolint:revive // random seed
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Values should be equal for the same seed, given: %v and %v", first, second)
+	}
+
+	third, _, err := processors.ResolveFakeValues(value, 7) //nolint:revive // random seed
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if reflect.DeepEqual(first, third) {
+		t.Errorf("Values should be different for different seeds, given: %v", first)
+	}
+}
+
+func TestResolveFakeValuesKinds(t *testing.T) {
+	t.Parallel()
+
+	formats := map[string]*regexp.Regexp{
+		"uuid":      regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		"email":     regexp.MustCompile(`^[a-z]+\.[a-z]+@example\.(com|org|net)$`),
+		"timestamp": regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`),
+	}
+
+	for _, kind := range processors.FakeKinds() {
+		kind := kind
+		t.Run(kind, func(t *testing.T) {
+			value, _, err := processors.ResolveFakeValues(
+				map[string]interface{}{"field": map[string]interface{}{"$fake": kind}}, 0,
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			fakeValue := value.(map[string]interface{})["field"]
+			if format, exists := formats[kind]; exists && !format.MatchString(fakeValue.(string)) {
+				t.Errorf("Wrong %s format, given: %v", kind, fakeValue)
+			}
+		})
+	}
+}
+
+func TestResolveFakeValuesUnknownKind(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := processors.ResolveFakeValues(
+		map[string]interface{}{"field": map[string]interface{}{"$fake": "unknown"}}, 0,
+	)
+	if err == nil {
+		t.Errorf("an error was expected for an unknown kind")
+	}
+}
@@ -2,6 +2,10 @@ package processors
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
 
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
@@ -13,7 +17,9 @@ import (
 // double quote is the way we use to instantiate/create a string.
 // Some other examples:
 //   - int64: 64 -> 64 (to instantiate/create a int we just need the number)
-//   - []bytes: []byte("abcd") -> []byte{0x61, 0x62, 0x63, 0x64}
+//   - float64: 0.1 -> 0.1 (the shortest representation parsed back to the same value)
+//   - []bytes: []byte("abcd") -> []byte("abcd")
+//   - []bytes: []byte{0x89, 0x00} -> []byte{0x89, 0x0} (when it isn't printable text)
 func FormatFieldValue(value protoreflect.Value) string {
 	// TODO: Make sure this works for proto enum, message, list and map
 	// References:
@@ -21,12 +27,17 @@ func FormatFieldValue(value protoreflect.Value) string {
 	//   - protoreflect.List
 	//   - protoreflect.Map
 	//   - protoreflect.EnumNumber
-	switch v := value.Interface(); v.(type) {
-	case float32, float64:
-		return fmt.Sprintf("%f", v)
+	switch v := value.Interface().(type) {
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
 	case string:
 		return fmt.Sprintf("%q", v)
 	case []byte:
+		if isPrintable(value.Bytes()) {
+			return fmt.Sprintf("[]byte(%q)", v)
+		}
 		return fmt.Sprintf("%#v", v)
 	case protoreflect.Message, protoreflect.List, protoreflect.Map, protoreflect.EnumNumber:
 		return fmt.Sprintf(`"Unsupported type: %T"`, v)
@@ -34,3 +45,43 @@ func FormatFieldValue(value protoreflect.Value) string {
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+// isPrintable reports whether the bytes are a text made of printable characters and spaces
+func isPrintable(value []byte) bool {
+	if !utf8.Valid(value) {
+		return false
+	}
+
+	for _, character := range string(value) {
+		if !unicode.IsPrint(character) && !unicode.IsSpace(character) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SortedMapKeys returns the keys of the map in ascending order, the map doesn't follow
+// any order when ranging over it but the generated code must be reproducible
+func SortedMapKeys(value protoreflect.Map) []protoreflect.MapKey {
+	keys := make([]protoreflect.MapKey, 0, value.Len())
+	value.Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, key)
+		return true
+	})
+
+	sort.Slice(keys, func(i, j int) bool {
+		switch key := keys[i].Interface().(type) {
+		case bool:
+			return !key && keys[j].Bool()
+		case int32, int64:
+			return keys[i].Int() < keys[j].Int()
+		case uint32, uint64:
+			return keys[i].Uint() < keys[j].Uint()
+		default:
+			return keys[i].String() < keys[j].String()
+		}
+	})
+
+	return keys
+}
@@ -1,9 +1,14 @@
 package processors_test
 
 import (
+	"reflect"
 	"testing"
 
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
 
 	"github.com/faunists/deal-go/processors"
 )
@@ -44,12 +49,27 @@ func TestFormatFieldValue(t *testing.T) {
 		{
 			name:           "should format correctly when value is float32",
 			value:          protoreflect.ValueOfFloat32(32.0), //nolint:revive // random number
-			expectedFormat: "32.000000",
+			expectedFormat: "32",
 		},
 		{
 			name:           "should format correctly when value is float64",
-			value:          protoreflect.ValueOfFloat32(64.0), //nolint:revive // random number
-			expectedFormat: "64.000000",
+			value:          protoreflect.ValueOfFloat64(64.0), //nolint:revive // random number
+			expectedFormat: "64",
+		},
+		{
+			name:           "should keep the precision of float32 values",
+			value:          protoreflect.ValueOfFloat32(0.1), //nolint:revive // random number
+			expectedFormat: "0.1",
+		},
+		{
+			name:           "should keep the precision of float64 values",
+			value:          protoreflect.ValueOfFloat64(0.0000001234), //nolint:revive // random number
+			expectedFormat: "1.234e-07",
+		},
+		{
+			name:           "should keep the precision of large float64 values",
+			value:          protoreflect.ValueOfFloat64(123456789.125), //nolint:revive // random number
+			expectedFormat: "1.23456789125e+08",
 		},
 		{
 			name:           "should format correctly when value is string",
@@ -59,7 +79,17 @@ func TestFormatFieldValue(t *testing.T) {
 		{
 			name:           "should format correctly when value is bytes",
 			value:          protoreflect.ValueOfBytes([]byte("abcd")),
-			expectedFormat: "[]byte{0x61, 0x62, 0x63, 0x64}",
+			expectedFormat: `[]byte("abcd")`,
+		},
+		{
+			name:           "should format correctly when value is bytes with line breaks",
+			value:          protoreflect.ValueOfBytes([]byte("ab\ncd")),
+			expectedFormat: `[]byte("ab\ncd")`,
+		},
+		{
+			name:           "should format correctly when value is binary bytes",
+			value:          protoreflect.ValueOfBytes([]byte{0x89, 0x00, 0xff}),
+			expectedFormat: "[]byte{0x89, 0x0, 0xff}",
 		},
 	}
 
@@ -75,3 +105,119 @@ func TestFormatFieldValue(t *testing.T) {
 		})
 	}
 }
+
+// newMapValue creates a map populated with the given entries,
+// the map key type is described by the given kind
+func newMapValue(
+	t *testing.T,
+	keyKind descriptorpb.FieldDescriptorProto_Type,
+	entries map[interface{}]string,
+) protoreflect.Map {
+	t.Helper()
+
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("map.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Message"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("values"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(".test.Message.ValuesEntry"),
+			}},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("ValuesEntry"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("key"), Number: proto.Int32(1), Label: &optional, Type: &keyKind},
+					{
+						Name:   proto.String("value"),
+						Number: proto.Int32(2), //nolint:revive // the map value field number
+						Label:  &optional,
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+				},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create the file descriptor: %v", err)
+	}
+
+	message := dynamicpb.NewMessage(file.Messages().Get(0))
+	field := message.Descriptor().Fields().Get(0)
+	values := message.Mutable(field).Map()
+	for key, value := range entries {
+		values.Set(protoreflect.ValueOf(key).MapKey(), protoreflect.ValueOfString(value))
+	}
+
+	return values
+}
+
+func TestSortedMapKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		keyKind      descriptorpb.FieldDescriptorProto_Type
+		entries      map[interface{}]string
+		expectedKeys []interface{}
+	}{
+		{
+			name:         "should return no keys when the map is empty",
+			keyKind:      descriptorpb.FieldDescriptorProto_TYPE_STRING,
+			entries:      map[interface{}]string{},
+			expectedKeys: []interface{}{},
+		},
+		{
+			name:         "should sort the keys when they're strings",
+			keyKind:      descriptorpb.FieldDescriptorProto_TYPE_STRING,
+			entries:      map[interface{}]string{"b": "2", "c": "3", "a": "1"},
+			expectedKeys: []interface{}{"a", "b", "c"},
+		},
+		{
+			name:    "should sort the keys numerically when they're integers",
+			keyKind: descriptorpb.FieldDescriptorProto_TYPE_INT64,
+			entries: map[interface{}]string{
+				int64(10): "10", int64(-1): "-1", int64(9): "9", //nolint:revive // random numbers
+			},
+			expectedKeys: []interface{}{int64(-1), int64(9), int64(10)}, //nolint:revive // random numbers
+		},
+		{
+			name:    "should sort the keys numerically when they're unsigned integers",
+			keyKind: descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+			entries: map[interface{}]string{
+				uint32(10): "10", uint32(9): "9", //nolint:revive // random numbers
+			},
+			expectedKeys: []interface{}{uint32(9), uint32(10)}, //nolint:revive // random numbers
+		},
+		{
+			name:         "should sort false before true when the keys are booleans",
+			keyKind:      descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+			entries:      map[interface{}]string{true: "yes", false: "no"},
+			expectedKeys: []interface{}{false, true},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			keys := processors.SortedMapKeys(newMapValue(t, test.keyKind, test.entries))
+
+			actualKeys := make([]interface{}, 0, len(keys))
+			for _, key := range keys {
+				actualKeys = append(actualKeys, key.Interface())
+			}
+
+			if !reflect.DeepEqual(actualKeys, test.expectedKeys) {
+				t.Errorf("Wrong keys, given: %v expected %v", actualKeys, test.expectedKeys)
+			}
+		})
+	}
+}
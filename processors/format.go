@@ -0,0 +1,111 @@
+package processors
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormatContractFile returns the canonical form of the contract file, encoded by the encoder of
+// its extension: the services and methods are sorted, the error codes are normalized, the empty
+// fields are omitted and the indentation is consistent. The formatting fails when a field of the
+// file isn't part of the contract, e.g. a misspelled key, since it would be lost.
+func FormatContractFile(filePath string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := decodeContract(filePath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	formatted, err := ContractEncoderFor(filePath)(contract)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", filePath, err)
+	}
+
+	if lostFields := lostFields(data, formatted); len(lostFields) > 0 {
+		return nil, fmt.Errorf(
+			"unknown fields in %s would be lost by the formatting: %s",
+			filePath, strings.Join(lostFields, ", "),
+		)
+	}
+
+	return formatted, nil
+}
+
+// lostFields returns the paths of the non-empty fields of the original document missing from
+// the formatted one. YAML being a superset of JSON both are compared as YAML, the documents of
+// other formats aren't compared.
+func lostFields(original, formatted []byte) []string {
+	var originalDocument, formattedDocument interface{}
+	if yaml.Unmarshal(original, &originalDocument) != nil ||
+		yaml.Unmarshal(formatted, &formattedDocument) != nil {
+		return nil
+	}
+
+	lostFields := make([]string, 0)
+	collectLostFields(
+		stringifyYAMLKeys(originalDocument), stringifyYAMLKeys(formattedDocument), "", &lostFields,
+	)
+	sort.Strings(lostFields)
+
+	return lostFields
+}
+
+func collectLostFields(original, formatted interface{}, path string, lostFields *[]string) {
+	switch originalValue := original.(type) {
+	case map[string]interface{}:
+		formattedValue, isMap := formatted.(map[string]interface{})
+		if !isMap {
+			return
+		}
+
+		for key, element := range originalValue {
+			formattedElement, exists := formattedValue[key]
+			if !exists && !isEmptyValue(element) {
+				*lostFields = append(*lostFields, strings.TrimPrefix(path+"."+key, "."))
+				continue
+			}
+			collectLostFields(element, formattedElement, path+"."+key, lostFields)
+		}
+	case []interface{}:
+		formattedValue, isList := formatted.([]interface{})
+		if !isList || len(formattedValue) != len(originalValue) {
+			return
+		}
+
+		for index, element := range originalValue {
+			collectLostFields(
+				element, formattedValue[index], fmt.Sprintf("%s[%d]", path, index), lostFields,
+			)
+		}
+	}
+}
+
+// isEmptyValue reports whether the value is omitted by the encoding of the contract
+func isEmptyValue(value interface{}) bool {
+	switch typedValue := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typedValue == ""
+	case bool:
+		return !typedValue
+	case int:
+		return typedValue == 0
+	case float64:
+		return typedValue == 0
+	case []interface{}:
+		return len(typedValue) == 0
+	case map[string]interface{}:
+		return len(typedValue) == 0
+	default:
+		return false
+	}
+}
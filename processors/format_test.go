@@ -0,0 +1,123 @@
+package processors_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestFormatContractFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		fileName          string
+		content           string
+		expectedFormatted string
+		expectedError     string
+	}{
+		{
+			name:     "JSON",
+			fileName: "contract.json",
+			content: `{"services": {"UserService": {"GetUser": {"failureCases": [{
+				"request": {"name": "<john>", "id": 9007199254740993},
+				"error": {"errorCode": "NOT_FOUND", "message": ""},
+				"delay": ""
+			}]}}, "AService": {}}, "name": "users"}`,
+			expectedFormatted: `{
+  "name": "users",
+  "services": {
+    "AService": {},
+    "UserService": {
+      "GetUser": {
+        "failureCases": [
+          {
+            "request": {
+              "id": 9007199254740993,
+              "name": "<john>"
+            },
+            "error": {
+              "errorCode": "NotFound"
+            }
+          }
+        ]
+      }
+    }
+  }
+}
+`,
+		},
+		{
+			name:     "YAML",
+			fileName: "contract.yaml",
+			content: `
+services:
+    UserService:
+        GetUser:
+            successCases:
+                - request: {id: 9007199254740993}
+                  description: found
+`,
+			expectedFormatted: `services:
+  UserService:
+    GetUser:
+      successCases:
+        - description: found
+          request:
+            id: 9007199254740993
+`,
+		},
+		{
+			name:     "Unknown fields",
+			fileName: "contract.json",
+			content: `{"services": {"UserService": {"GetUser": {
+				"sucessCases": [{"request": {}}],
+				"failureCases": [{"request": {}, "error": {"errorCode": "Internal"}, "retries": 3}]
+			}}}}`,
+			expectedError: "contract.json would be lost by the formatting: " +
+				"services.UserService.GetUser.failureCases[0].retries, " +
+				"services.UserService.GetUser.sucessCases",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			contractFilePath := filepath.Join(t.TempDir(), test.fileName)
+			if err := ioutil.WriteFile(contractFilePath, []byte(test.content), 0o600); err != nil {
+				t.Fatalf("Failed to write the contract file: %v", err)
+			}
+
+			formatted, err := processors.FormatContractFile(contractFilePath)
+			if test.expectedError != "" {
+				if err == nil || !strings.HasSuffix(err.Error(), test.expectedError) {
+					t.Fatalf("Given error: %v, expected: %s", err, test.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(formatted) != test.expectedFormatted {
+				t.Fatalf("Given:\n%s\nexpected:\n%s", formatted, test.expectedFormatted)
+			}
+
+			// The canonical form is kept by the formatting
+			if err = ioutil.WriteFile(contractFilePath, formatted, 0o600); err != nil {
+				t.Fatalf("Failed to write the contract file: %v", err)
+			}
+			reformatted, err := processors.FormatContractFile(contractFilePath)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(reformatted) != string(formatted) {
+				t.Errorf("Given:\n%s\nexpected:\n%s", reformatted, formatted)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package processors
+
+import (
+	"html/template"
+	"io"
+	"strings"
+)
+
+// The style and the script are inlined, the pages are self-contained and work offline
+const htmlLayout = `{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0 auto;
+  max-width: 960px; padding: 0 1rem 2rem; color: #1f2328; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+input[type=search] { box-sizing: border-box; width: 100%; padding: .5rem; font-size: 1rem; }
+details { border: 1px solid #d0d7de; border-radius: 6px; margin: .5rem 0; padding: .5rem; }
+summary { cursor: pointer; font-weight: 600; }
+pre { background: #f6f8fa; border-radius: 6px; overflow: auto; padding: .5rem; }
+.kind { border-radius: 1rem; font-size: .75rem; margin-right: .5rem; padding: .1rem .5rem; }
+.success { background: #dafbe1; }
+.application-error { background: #fff8c5; }
+.failure { background: #ffebe9; }
+.hidden { display: none; }
+</style>
+</head>
+<body>
+{{end}}
+{{define "search"}}<input type="search" placeholder="Search" autofocus
+  oninput="var q = this.value.toLowerCase();
+    document.querySelectorAll('[data-search]').forEach(function (e) {
+      e.classList.toggle('hidden', e.dataset.search.indexOf(q) === -1);
+    });">
+{{end}}
+{{define "foot"}}</body>
+</html>
+{{end}}`
+
+const htmlIndex = `{{template "head" .Title}}<h1>{{.Title}}</h1>
+{{template "search"}}
+<ul>
+{{- range .Services}}
+<li data-search="{{search .Name .Methods}}"><a href="{{page .Name}}">{{.Name}}</a>
+<ul>
+{{- $page := page .Name}}
+{{- range .Methods}}
+<li><a href="{{$page}}#{{.Name}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+</li>
+{{- end}}
+</ul>
+{{template "foot"}}`
+
+const htmlService = `{{template "head" .Name}}<p><a href="index.html">All services</a></p>
+<h1>{{.Name}}</h1>
+{{template "search"}}
+{{- range .Methods}}
+<section id="{{.Name}}">
+<h2>{{.Name}}</h2>
+{{- if not (or .SuccessCases .ApplicationErrorCases .FailureCases)}}
+<p>The method has no case.</p>
+{{- end}}
+{{- range .SuccessCases}}{{template "case" kind "success" "Success" .}}{{end}}
+{{- range .ApplicationErrorCases}}
+{{- template "case" kind "application-error" "Application error" .}}
+{{- end}}
+{{- range .FailureCases}}{{template "case" kind "failure" "Failure" .}}{{end}}
+</section>
+{{- end}}
+{{template "foot"}}
+{{- define "case"}}
+<details data-search="{{lower .Case.Description}}">
+<summary><span class="kind {{.Class}}">{{.Label}}</span>{{.Case.Description}}</summary>
+<p>Request:</p>
+<pre>{{.Case.Request}}</pre>
+{{- range $oneof, $variant := .Case.Oneofs}}
+<p>The <code>{{$oneof}}</code> oneof must be set to <code>{{$variant}}</code>.</p>
+{{- end}}
+{{- if .Case.Error}}
+<p>Error: <code>{{.Case.Error}}</code></p>
+{{- else if .Case.Responses}}
+<p>Responses, one per call:</p>
+<pre>{{.Case.Responses}}</pre>
+{{- else}}
+<p>Response:</p>
+<pre>{{.Case.Response}}</pre>
+{{- if .Case.ErrorField}}
+<p>The application error is held by the <code>{{.Case.ErrorField}}</code> field.</p>
+{{- end}}
+{{- end}}
+</details>
+{{- end}}`
+
+// htmlCase is a case given to the case template along with its kind
+type htmlCase struct {
+	Class string
+	Label string
+	Case  CaseDocs
+}
+
+var htmlTemplates = template.Must(
+	template.New("layout").Funcs(template.FuncMap{
+		"page":  HTMLDocsFilename,
+		"lower": strings.ToLower,
+		"kind": func(class, label string, caseDocs CaseDocs) htmlCase {
+			return htmlCase{Class: class, Label: label, Case: caseDocs}
+		},
+		"search": func(service string, methods []MethodDocs) string {
+			terms := []string{service}
+			for _, method := range methods {
+				terms = append(terms, method.Name)
+			}
+			return strings.ToLower(strings.Join(terms, " "))
+		},
+	}).Parse(htmlLayout),
+)
+
+var (
+	htmlIndexTemplate   = template.Must(template.Must(htmlTemplates.Clone()).Parse(htmlIndex))
+	htmlServiceTemplate = template.Must(template.Must(htmlTemplates.Clone()).Parse(htmlService))
+)
+
+// HTMLDocsFilename returns the name of the HTML page of the service
+func HTMLDocsFilename(service string) string {
+	return service + ".html"
+}
+
+// WriteHTMLIndex writes the index page of the HTML documentation, it links the page of every
+// service and filters the services by the searched name (or method name)
+func WriteHTMLIndex(output io.Writer, title string, services []ServiceDocs) error {
+	return htmlIndexTemplate.Execute(output, struct {
+		Title    string
+		Services []ServiceDocs
+	}{Title: title, Services: services})
+}
+
+// WriteHTMLDocs writes the HTML page of the service, named after HTMLDocsFilename. The cases
+// are collapsible and filtered by the searched description.
+func WriteHTMLDocs(output io.Writer, docs ServiceDocs) error {
+	return htmlServiceTemplate.Execute(output, docs)
+}
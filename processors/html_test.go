@@ -0,0 +1,81 @@
+package processors_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestWriteHTMLDocs(t *testing.T) {
+	t.Parallel()
+
+	docs, err := processors.BuildServiceDocs("UserService", entities.Service{
+		"GetUser": {
+			SuccessCases: []entities.SuccessCase{
+				{
+					Description: "Should return <the> user",
+					Request:     map[string]interface{}{"id": "1"},
+					Response:    map[string]interface{}{"name": "john"},
+				},
+			},
+			FailureCases: []entities.FailureCase{
+				{Error: entities.GRPCError{ErrorCode: "NotFound", Message: "user not found"}},
+			},
+		},
+		"DeleteUser": {},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error happened: %v", err)
+	}
+
+	page := bytes.NewBuffer(nil)
+	if err = processors.WriteHTMLDocs(page, docs); err != nil {
+		t.Fatalf("unexpected error happened: %v", err)
+	}
+
+	for _, fragment := range []string{
+		"<title>UserService</title>",
+		`<input type="search"`,
+		"<section id=\"DeleteUser\">\n<h2>DeleteUser</h2>\n<p>The method has no case.</p>",
+		`<details data-search="should return &lt;the&gt; user">`,
+		`<summary><span class="kind success">Success</span>Should return &lt;the&gt; user</summary>`,
+		"<pre>{\n  &#34;id&#34;: &#34;1&#34;\n}</pre>",
+		"<pre>{\n  &#34;name&#34;: &#34;john&#34;\n}</pre>",
+		`<summary><span class="kind failure">Failure</span>Case 1</summary>`,
+		"<p>Error: <code>NotFound: user not found</code></p>",
+	} {
+		if !strings.Contains(page.String(), fragment) {
+			t.Errorf("%q not found in the page:\n%s", fragment, page)
+		}
+	}
+}
+
+func TestWriteHTMLIndex(t *testing.T) {
+	t.Parallel()
+
+	docs, err := processors.BuildContractDocs(entities.Contract{Services: map[string]entities.Service{
+		"UserService": {"GetUser": {}, "DeleteUser": {}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error happened: %v", err)
+	}
+
+	index := bytes.NewBuffer(nil)
+	if err = processors.WriteHTMLIndex(index, "My contract", docs); err != nil {
+		t.Fatalf("unexpected error happened: %v", err)
+	}
+
+	for _, fragment := range []string{
+		"<title>My contract</title>",
+		`<li data-search="userservice deleteuser getuser"><a href="UserService.html">UserService</a>`,
+		`<li><a href="UserService.html#DeleteUser">DeleteUser</a></li>`,
+		`<li><a href="UserService.html#GetUser">GetUser</a></li>`,
+	} {
+		if !strings.Contains(index.String(), fragment) {
+			t.Errorf("%q not found in the index:\n%s", fragment, index)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package processors
+
+import (
+	"fmt"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// MergeStrategy tells how the conflicting cases of the merged contracts are resolved
+type MergeStrategy string
+
+// The strategies resolving the merge conflicts
+const (
+	// MergeFail fails the merge when there's any conflict
+	MergeFail MergeStrategy = "fail"
+	// MergeFirst keeps the case of the first contract declaring the request
+	MergeFirst MergeStrategy = "first"
+	// MergeLast keeps the case of the last contract declaring the request
+	MergeLast MergeStrategy = "last"
+)
+
+// ContractFragment is a contract to be merged, the source (e.g. the file path)
+// tells where the conflicting cases come from
+type ContractFragment struct {
+	Source   string
+	Contract entities.Contract
+}
+
+// mergedCase is a case of the merged method along with the list and fragment it comes from
+type mergedCase struct {
+	casesKey string
+	value    interface{}
+	source   string
+	removed  bool
+}
+
+// mergedMethod keeps the cases of a merged method in order, indexed by request
+type mergedMethod struct {
+	cases     []*mergedCase
+	byRequest map[string]*mergedCase
+}
+
+// ValidateMergeStrategy verifies the strategy is one of the known ones
+func ValidateMergeStrategy(strategy MergeStrategy) error {
+	switch strategy {
+	case MergeFail, MergeFirst, MergeLast:
+		return nil
+	default:
+		return fmt.Errorf(
+			"invalid merge strategy %q, expected %s, %s or %s",
+			strategy, MergeFail, MergeFirst, MergeLast,
+		)
+	}
+}
+
+// MergeContracts combines the contract fragments into a single contract, the name of the first
+// fragment with one is kept. The cases are matched by their request: the identical cases are
+// merged and the different cases of the same request conflict, even in different lists.
+// The conflicts are returned along with the merged contract, resolved by the strategy, and
+// the MergeFail strategy returns an error when there's any.
+func MergeContracts(
+	strategy MergeStrategy,
+	fragments ...ContractFragment,
+) (entities.Contract, []ContractIssue, error) {
+	if err := ValidateMergeStrategy(strategy); err != nil {
+		return entities.Contract{}, nil, err
+	}
+
+	merged := entities.Contract{Services: make(map[string]entities.Service)}
+	methods := make(map[string]map[string]*mergedMethod)
+	conflicts := make([]ContractIssue, 0)
+
+	for _, fragment := range fragments {
+		if merged.Name == "" {
+			merged.Name = fragment.Contract.Name
+		}
+
+		for _, serviceKey := range sortedKeys(fragment.Contract.Services) {
+			if methods[serviceKey] == nil {
+				methods[serviceKey] = make(map[string]*mergedMethod)
+			}
+
+			serviceContract := fragment.Contract.Services[serviceKey]
+			for _, methodName := range sortedKeys(serviceContract) {
+				method := methods[serviceKey][methodName]
+				if method == nil {
+					method = &mergedMethod{byRequest: make(map[string]*mergedCase)}
+					methods[serviceKey][methodName] = method
+				}
+
+				methodConflicts, err := method.merge(
+					ContractChange{Service: serviceKey, Method: methodName},
+					fragment.Source,
+					serviceContract[methodName],
+					strategy,
+				)
+				if err != nil {
+					return entities.Contract{}, nil, err
+				}
+				conflicts = append(conflicts, methodConflicts...)
+			}
+		}
+	}
+
+	for serviceKey, serviceMethods := range methods {
+		merged.Services[serviceKey] = make(entities.Service, len(serviceMethods))
+		for methodName, method := range serviceMethods {
+			merged.Services[serviceKey][methodName] = method.build()
+		}
+	}
+
+	if strategy == MergeFail && len(conflicts) > 0 {
+		return merged, conflicts, fmt.Errorf("%d conflicting cases", len(conflicts))
+	}
+
+	return merged, conflicts, nil
+}
+
+// merge adds the cases of the fragment method, returning the conflicts with the cases
+// of the previous fragments
+func (m *mergedMethod) merge(
+	change ContractChange,
+	source string,
+	method entities.Method,
+	strategy MergeStrategy,
+) ([]ContractIssue, error) {
+	conflicts := make([]ContractIssue, 0)
+	for _, casesKey := range []string{SuccessCasesKey, ApplicationErrorCasesKey, FailureCasesKey} {
+		for _, identifiedCase := range identifyCases(MethodCases(method, casesKey)) {
+			request, oneofs := caseRequest(identifiedCase.value)
+			key, err := requestKey(request, oneofs)
+			if err != nil {
+				return nil, err
+			}
+
+			newCase := &mergedCase{casesKey: casesKey, value: identifiedCase.value, source: source}
+			existingCase, exists := m.byRequest[key]
+			if !exists {
+				m.cases = append(m.cases, newCase)
+				m.byRequest[key] = newCase
+				continue
+			}
+
+			changed, err := isChanged(existingCase.value, identifiedCase.value)
+			if err != nil {
+				return nil, err
+			}
+			if !changed && existingCase.casesKey == casesKey {
+				continue
+			}
+
+			caseChange := change
+			caseChange.Cases = casesKey
+			caseChange.Description, caseChange.Index = identifiedCase.description, identifiedCase.index
+			conflicts = append(conflicts, ContractIssue{
+				Path: caseChange.Path(),
+				Message: fmt.Sprintf(
+					"the case of %s conflicts with the %s case of the same request in %s",
+					source, existingCase.casesKey, existingCase.source,
+				),
+			})
+
+			if strategy == MergeLast {
+				existingCase.removed = true
+				m.cases = append(m.cases, newCase)
+				m.byRequest[key] = newCase
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+// build returns the merged method, its cases are in the order they were merged
+func (m *mergedMethod) build() entities.Method {
+	method := entities.Method{}
+	for _, mergedCase := range m.cases {
+		if mergedCase.removed {
+			continue
+		}
+
+		switch value := mergedCase.value.(type) {
+		case entities.SuccessCase:
+			method.SuccessCases = append(method.SuccessCases, value)
+		case entities.ApplicationErrorCase:
+			method.ApplicationErrorCases = append(method.ApplicationErrorCases, value)
+		case entities.FailureCase:
+			method.FailureCases = append(method.FailureCases, value)
+		}
+	}
+
+	return method
+}
+
+// caseRequest returns the request of a case of any list and the oneof variants it selects
+func caseRequest(value interface{}) (interface{}, map[string]string) {
+	switch contractCase := value.(type) {
+	case entities.SuccessCase:
+		return contractCase.Request, contractCase.Oneofs
+	case entities.ApplicationErrorCase:
+		return contractCase.Request, contractCase.Oneofs
+	case entities.FailureCase:
+		return contractCase.Request, contractCase.Oneofs
+	default:
+		return nil, nil
+	}
+}
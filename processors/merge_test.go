@@ -0,0 +1,138 @@
+package processors_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestMergeContracts(t *testing.T) {
+	t.Parallel()
+
+	request := func(id string) map[string]interface{} { return map[string]interface{}{"id": id} }
+	fragments := []processors.ContractFragment{
+		{
+			Source: "team-a.json",
+			Contract: entities.Contract{Services: map[string]entities.Service{
+				"UserService": {"GetUser": {
+					SuccessCases: []entities.SuccessCase{
+						{Description: "found", Request: request("1"), Response: request("1")},
+						{Description: "conflict", Request: request("2"), Response: request("a")},
+					},
+				}},
+			}},
+		},
+		{
+			Source: "team-b.json",
+			Contract: entities.Contract{
+				Name: "users",
+				Services: map[string]entities.Service{
+					"OrderService": {"GetOrder": {
+						SuccessCases: []entities.SuccessCase{{Request: request("1")}},
+					}},
+					"UserService": {"GetUser": {
+						SuccessCases: []entities.SuccessCase{
+							{Description: "found", Request: request("1"), Response: request("1")},
+							{Description: "conflict", Request: request("2"), Response: request("b")},
+						},
+						FailureCases: []entities.FailureCase{
+							{Request: request("3"), Error: entities.GRPCError{ErrorCode: "NotFound"}},
+						},
+					}},
+				},
+			},
+		},
+	}
+	expectedConflicts := []processors.ContractIssue{{
+		Path: `services.UserService.GetUser.successCases["conflict"]`,
+		Message: "the case of team-b.json conflicts with the successCases case " +
+			"of the same request in team-a.json",
+	}}
+
+	tests := []struct {
+		name             string
+		strategy         processors.MergeStrategy
+		expectedResponse interface{}
+		expectedError    bool
+	}{
+		{
+			name:             "Fail",
+			strategy:         processors.MergeFail,
+			expectedResponse: request("a"),
+			expectedError:    true,
+		},
+		{name: "First", strategy: processors.MergeFirst, expectedResponse: request("a")},
+		{name: "Last", strategy: processors.MergeLast, expectedResponse: request("b")},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			merged, conflicts, err := processors.MergeContracts(test.strategy, fragments...)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(conflicts, expectedConflicts) {
+				t.Errorf("Given conflicts: %v, expected: %v", conflicts, expectedConflicts)
+			}
+
+			if merged.Name != "users" {
+				t.Errorf("Given name: %s, expected: users", merged.Name)
+			}
+			if _, exists := merged.Services["OrderService"]["GetOrder"]; !exists {
+				t.Errorf("Expected the OrderService to be merged, given: %v", merged.Services)
+			}
+
+			method := merged.Services["UserService"]["GetUser"]
+			if len(method.SuccessCases) != 2 || len(method.FailureCases) != 1 {
+				t.Fatalf("Unexpected merged cases: %+v", method)
+			}
+			response := method.SuccessCases[len(method.SuccessCases)-1].Response
+			if !reflect.DeepEqual(response, test.expectedResponse) {
+				t.Errorf("Given response: %v, expected: %v", response, test.expectedResponse)
+			}
+		})
+	}
+}
+
+func TestMergeContractsInvalidStrategy(t *testing.T) {
+	t.Parallel()
+
+	if _, _, err := processors.MergeContracts("newest"); err == nil {
+		t.Error("Expected an error, given nil")
+	}
+}
+
+func TestMergeContractsComparesNumbersByValue(t *testing.T) {
+	t.Parallel()
+
+	fragments := make([]processors.ContractFragment, 0)
+	for _, number := range []string{"1.0", "1"} {
+		contract := entities.Contract{}
+		err := processors.DecodeJSONContract([]byte(
+			`{"services": {"UserService": {"GetUser": {"successCases": [`+
+				`{"request": {"id": `+number+`}, "response": {"score": `+number+`}}]}}}}`,
+		), &contract)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		fragments = append(fragments, processors.ContractFragment{
+			Source: number + ".json", Contract: contract,
+		})
+	}
+
+	merged, conflicts, err := processors.MergeContracts(processors.MergeFail, fragments...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, given: %v", conflicts)
+	}
+	if cases := merged.Services["UserService"]["GetUser"].SuccessCases; len(cases) != 1 {
+		t.Errorf("Given: %+v, expected: a single case", cases)
+	}
+}
@@ -0,0 +1,54 @@
+package processors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// placeholderRegex matches the fmt verbs of a message, including the escaped percent sign (%%)
+var placeholderRegex = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+// MessagePattern derives a regular expression matching the messages formatted from the given
+// message, every placeholder (e.g. %s, %d) matches any text. The number of placeholders is
+// returned as well.
+func MessagePattern(message string) (string, int) {
+	pattern := strings.Builder{}
+	pattern.WriteString("^")
+
+	placeholders, lastIndex := 0, 0
+	for _, location := range placeholderRegex.FindAllStringIndex(message, -1) {
+		pattern.WriteString(regexp.QuoteMeta(message[lastIndex:location[0]]))
+		lastIndex = location[1]
+
+		if message[location[0]:location[1]] == "%%" {
+			pattern.WriteString("%")
+			continue
+		}
+
+		placeholders++
+		pattern.WriteString("(.*)")
+	}
+	pattern.WriteString(regexp.QuoteMeta(message[lastIndex:]))
+	pattern.WriteString("$")
+
+	return pattern.String(), placeholders
+}
+
+// ValidateMessageArgsMatch verifies the message match of a message with arguments, the formatted
+// messages are matched through the pattern derived from the message so they can only be matched
+// exactly or ignored
+func ValidateMessageArgsMatch(messageMatch string, messageArgs []string) error {
+	if len(messageArgs) == 0 {
+		return nil
+	}
+
+	switch messageMatch {
+	case "", entities.MessageMatchExact, entities.MessageMatchIgnore:
+		return nil
+	default:
+		return fmt.Errorf("the message match %q can't be used with message arguments", messageMatch)
+	}
+}
@@ -0,0 +1,110 @@
+package processors_test
+
+import (
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestMessagePattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                 string
+		message              string
+		expectedPattern      string
+		expectedPlaceholders int
+	}{
+		{
+			name:                 "should quote a message without placeholders",
+			message:              "user (admin) not found.",
+			expectedPattern:      `^user \(admin\) not found\.$`,
+			expectedPlaceholders: 0,
+		},
+		{
+			name:                 "should replace the placeholders",
+			message:              "user %s not found in %d attempts",
+			expectedPattern:      `^user (.*) not found in (.*) attempts$`,
+			expectedPlaceholders: 2, //nolint:revive // number of placeholders
+		},
+		{
+			name:                 "should replace placeholders with flags and precision",
+			message:              "balance: %-8.2f",
+			expectedPattern:      `^balance: (.*)$`,
+			expectedPlaceholders: 1,
+		},
+		{
+			name:                 "should keep the escaped percent sign",
+			message:              "%d%% off",
+			expectedPattern:      `^(.*)% off$`,
+			expectedPlaceholders: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pattern, placeholders := processors.MessagePattern(test.message)
+			if pattern != test.expectedPattern || placeholders != test.expectedPlaceholders {
+				t.Errorf(
+					"Given: %s (%d), expected: %s (%d)",
+					pattern, placeholders, test.expectedPattern, test.expectedPlaceholders,
+				)
+			}
+		})
+	}
+}
+
+func TestValidateMessageArgsMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		messageMatch  string
+		messageArgs   []string
+		expectedError bool
+	}{
+		{
+			name:         "should accept any match without arguments",
+			messageMatch: entities.MessageMatchContains,
+		},
+		{
+			name:        "should accept the default match with arguments",
+			messageArgs: []string{"id"},
+		},
+		{
+			name:         "should accept the exact match with arguments",
+			messageMatch: entities.MessageMatchExact,
+			messageArgs:  []string{"id"},
+		},
+		{
+			name:         "should accept the ignored message with arguments",
+			messageMatch: entities.MessageMatchIgnore,
+			messageArgs:  []string{"id"},
+		},
+		{
+			name:          "should reject the contains match with arguments",
+			messageMatch:  entities.MessageMatchContains,
+			messageArgs:   []string{"id"},
+			expectedError: true,
+		},
+		{
+			name:          "should reject the regex match with arguments",
+			messageMatch:  entities.MessageMatchRegex,
+			messageArgs:   []string{"id"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := processors.ValidateMessageArgsMatch(test.messageMatch, test.messageArgs)
+			if (err != nil) != test.expectedError {
+				t.Errorf("Given: %v, expected an error: %+v", err, test.expectedError)
+			}
+		})
+	}
+}
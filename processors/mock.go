@@ -0,0 +1,491 @@
+package processors
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// MockResult is the answer of the contract case matching a request, either the response
+// or the error is set
+type MockResult struct {
+	// Case describes the matched case, e.g. `successCases[0]: returns the user`
+	Case     string
+	Response proto.Message
+	// Error has its message already formatted with the message arguments
+	Error    *entities.GRPCError
+	Delay    time.Duration
+	Headers  entities.Metadata
+	Trailers entities.Metadata
+}
+
+// ContractMock answers the requests of the contract methods following their cases, like the
+// generated contract servers do but without any generated code: the messages are dynamic ones
+// built from the proto files. It's safe for concurrent use.
+type ContractMock struct {
+	methods map[string]*mockMethod
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// mockMethod holds the cases of a method sorted by priority
+type mockMethod struct {
+	descriptor protoreflect.MethodDescriptor
+	cases      []mockCase
+}
+
+// mockCase is a contract case whose messages were parsed
+type mockCase struct {
+	key         string
+	description string
+	priority    int
+	request     proto.Message
+	variants    []protoreflect.FieldDescriptor
+	ignored     []protoreflect.OneofDescriptor
+	delay       time.Duration
+	headers     entities.Metadata
+	trailers    entities.Metadata
+	steps       []mockStep
+}
+
+// mockStep is a single result of a case, the sequenced cases have more than one
+type mockStep struct {
+	response proto.Message
+	err      *entities.GRPCError
+}
+
+// NewContractMock parses the cases of the contract against the proto files, the fake
+// placeholders of the responses are resolved once with the given seed. The cases are
+// evaluated by descending priority, the success, application error and failure cases
+// keeping their declaration order for equal priorities.
+func NewContractMock(
+	contract entities.Contract,
+	files *protoregistry.Files,
+	fakeSeed int64,
+) (*ContractMock, error) {
+	parser := mockParser{types: dynamicpb.NewTypes(files), fakeSeed: fakeSeed}
+	mock := &ContractMock{
+		methods: make(map[string]*mockMethod),
+		calls:   make(map[string]int),
+	}
+
+	for serviceKey, serviceContract := range contract.Services {
+		service, err := FindService(files, serviceKey)
+		if err != nil {
+			return nil, err
+		}
+
+		for methodName, methodContract := range serviceContract {
+			method := service.Methods().ByName(protoreflect.Name(methodName))
+			if method == nil {
+				return nil, fmt.Errorf(
+					"method %s not found in service %s", methodName, service.FullName(),
+				)
+			}
+			if method.IsStreamingClient() || method.IsStreamingServer() {
+				return nil, fmt.Errorf("method %s is a stream, which isn't supported", method.FullName())
+			}
+
+			cases, err := parser.parseMethod(method, methodContract)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", method.FullName(), err)
+			}
+
+			mock.methods[MockFullMethod(method)] = &mockMethod{descriptor: method, cases: cases}
+		}
+	}
+
+	return mock, nil
+}
+
+// MockFullMethod returns the gRPC name of the method, e.g. `/acme.v1.UserService/GetUser`
+func MockFullMethod(method protoreflect.MethodDescriptor) string {
+	return fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+}
+
+// Methods returns the methods having a contract, sorted by their gRPC name
+func (m *ContractMock) Methods() []protoreflect.MethodDescriptor {
+	fullMethods := make([]string, 0, len(m.methods))
+	for fullMethod := range m.methods {
+		fullMethods = append(fullMethods, fullMethod)
+	}
+	sort.Strings(fullMethods)
+
+	methods := make([]protoreflect.MethodDescriptor, 0, len(fullMethods))
+	for _, fullMethod := range fullMethods {
+		methods = append(methods, m.methods[fullMethod].descriptor)
+	}
+
+	return methods
+}
+
+// Respond returns the result of the first case matching the request of the method, given by
+// its gRPC name. False is returned when no case matches.
+func (m *ContractMock) Respond(fullMethod string, request proto.Message) (MockResult, bool) {
+	method, exists := m.methods[fullMethod]
+	if !exists {
+		return MockResult{}, false
+	}
+
+	for _, mockCase := range method.cases {
+		if !mockCase.matches(request) {
+			continue
+		}
+
+		step := mockCase.steps[0]
+		if len(mockCase.steps) > 1 {
+			call := m.nextCall(mockCase.key)
+			if call >= len(mockCase.steps) {
+				call = len(mockCase.steps) - 1
+			}
+			step = mockCase.steps[call]
+		}
+
+		result := MockResult{
+			Case:     mockCase.description,
+			Response: step.response,
+			Delay:    mockCase.delay,
+			Headers:  mockCase.headers,
+			Trailers: mockCase.trailers,
+		}
+		if step.err != nil {
+			grpcError := *step.err
+			grpcError.Message = formatErrorMessage(grpcError, request)
+			grpcError.MessageArgs = nil
+			result.Error = &grpcError
+		}
+
+		return result, true
+	}
+
+	return MockResult{}, false
+}
+
+// ResetCalls restarts the sequences of the cases from their first step
+func (m *ContractMock) ResetCalls() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = make(map[string]int)
+}
+
+func (m *ContractMock) nextCall(caseKey string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	call := m.calls[caseKey]
+	m.calls[caseKey]++
+
+	return call
+}
+
+// matches verifies the request selects the expected oneof variants and equals the contract
+// request, the oneofs whose variant has no value in the contract accept any value
+func (c mockCase) matches(request proto.Message) bool {
+	reflectRequest := request.ProtoReflect()
+	for _, variant := range c.variants {
+		populated := reflectRequest.WhichOneof(variant.ContainingOneof())
+		if populated == nil || populated.Number() != variant.Number() {
+			return false
+		}
+	}
+
+	if len(c.ignored) == 0 {
+		return proto.Equal(request, c.request)
+	}
+
+	compared := proto.Clone(request).ProtoReflect()
+	for _, oneof := range c.ignored {
+		if populated := compared.WhichOneof(oneof); populated != nil {
+			compared.Clear(populated)
+		}
+	}
+
+	return proto.Equal(compared.Interface(), c.request)
+}
+
+// formatErrorMessage formats the message of the error with the request fields referenced by
+// its message arguments, the fields missing from the request give their default value
+func formatErrorMessage(grpcError entities.GRPCError, request proto.Message) string {
+	if len(grpcError.MessageArgs) == 0 {
+		return grpcError.Message
+	}
+
+	args := make([]interface{}, 0, len(grpcError.MessageArgs))
+	for _, path := range grpcError.MessageArgs {
+		fields, err := FindFieldPath(request.ProtoReflect().Descriptor(), path)
+		if err != nil {
+			args = append(args, nil)
+			continue
+		}
+
+		message := request.ProtoReflect()
+		var value protoreflect.Value
+		for _, field := range fields {
+			value = message.Get(field)
+			if field.Message() != nil && !field.IsList() && !field.IsMap() {
+				message = value.Message()
+			}
+		}
+
+		last := fields[len(fields)-1]
+		switch {
+		case last.Kind() == protoreflect.EnumKind && !last.IsList() && !last.IsMap():
+			args = append(args, enumArgument{enum: last.Enum(), number: value.Enum()})
+		case last.Message() != nil && !last.IsList() && !last.IsMap():
+			args = append(args, value.Message().Interface())
+		default:
+			args = append(args, value.Interface())
+		}
+	}
+
+	return fmt.Sprintf(grpcError.Message, args...)
+}
+
+// enumArgument formats an enum like the generated enums: its name, or its number with %d
+type enumArgument struct {
+	enum   protoreflect.EnumDescriptor
+	number protoreflect.EnumNumber
+}
+
+func (a enumArgument) Format(state fmt.State, verb rune) {
+	if verb == 'd' {
+		fmt.Fprintf(state, "%d", a.number)
+		return
+	}
+
+	if value := a.enum.Values().ByNumber(a.number); value != nil {
+		fmt.Fprint(state, value.Name())
+		return
+	}
+	fmt.Fprint(state, a.number)
+}
+
+// mockParser parses the cases of the methods into dynamic messages
+type mockParser struct {
+	types    *dynamicpb.Types
+	fakeSeed int64
+}
+
+func (p mockParser) parseMethod(
+	method protoreflect.MethodDescriptor,
+	methodContract entities.Method,
+) ([]mockCase, error) {
+	cases := make([]mockCase, 0)
+	for index, successCase := range methodContract.SuccessCases {
+		parsedCase, err := p.parseCase(
+			method, fmt.Sprintf("successCases[%d]", index), successCase.Description,
+			successCase.Request, successCase.Oneofs, successCase.Delay,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		steps := successCase.Sequence()
+		if len(steps) == 0 {
+			steps = []entities.SequenceStep{{Response: successCase.Response}}
+		}
+		for _, step := range steps {
+			parsedStep, err := p.parseStep(method, step)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", parsedCase.key, err)
+			}
+			parsedCase.steps = append(parsedCase.steps, parsedStep)
+		}
+
+		parsedCase.priority = successCase.Priority
+		parsedCase.headers, parsedCase.trailers = successCase.Headers, successCase.Trailers
+		cases = append(cases, parsedCase)
+	}
+
+	for index, applicationErrorCase := range methodContract.ApplicationErrorCases {
+		parsedCase, err := p.parseCase(
+			method, fmt.Sprintf("applicationErrorCases[%d]", index),
+			applicationErrorCase.Description, applicationErrorCase.Request,
+			applicationErrorCase.Oneofs, applicationErrorCase.Delay,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		step, err := p.parseStep(
+			method, entities.SequenceStep{Response: applicationErrorCase.Response},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", parsedCase.key, err)
+		}
+
+		parsedCase.steps = []mockStep{step}
+		parsedCase.priority = applicationErrorCase.Priority
+		parsedCase.headers = applicationErrorCase.Headers
+		parsedCase.trailers = applicationErrorCase.Trailers
+		cases = append(cases, parsedCase)
+	}
+
+	for index, failureCase := range methodContract.FailureCases {
+		parsedCase, err := p.parseCase(
+			method, fmt.Sprintf("failureCases[%d]", index), failureCase.Description,
+			failureCase.Request, failureCase.Oneofs, failureCase.Delay,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		failure := failureCase.Error
+		step, err := p.parseStep(method, entities.SequenceStep{Error: &failure})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", parsedCase.key, err)
+		}
+
+		parsedCase.steps = []mockStep{step}
+		parsedCase.priority = failureCase.Priority
+		parsedCase.headers, parsedCase.trailers = failureCase.Headers, failureCase.Trailers
+		cases = append(cases, parsedCase)
+	}
+
+	sort.SliceStable(cases, func(i, j int) bool {
+		return cases[i].priority > cases[j].priority
+	})
+
+	return cases, nil
+}
+
+// parseCase parses the request matched by the case and its delay
+func (p mockParser) parseCase(
+	method protoreflect.MethodDescriptor,
+	casesKey, description string,
+	request interface{},
+	oneofs map[string]string,
+	delay string,
+) (mockCase, error) {
+	parsedCase := mockCase{key: casesKey, description: casesKey}
+	if description != "" {
+		parsedCase.description = fmt.Sprintf("%s: %s", casesKey, description)
+	}
+
+	parsedRequest, err := p.parseMessage(method.Input(), request)
+	if err != nil {
+		return mockCase{}, fmt.Errorf("%s: invalid request: %w", casesKey, err)
+	}
+	parsedCase.request = parsedRequest
+
+	oneofNames := make([]string, 0, len(oneofs))
+	for oneofName := range oneofs {
+		oneofNames = append(oneofNames, oneofName)
+	}
+	sort.Strings(oneofNames)
+
+	for _, oneofName := range oneofNames {
+		oneof := method.Input().Oneofs().ByName(protoreflect.Name(oneofName))
+		if oneof == nil || oneof.IsSynthetic() {
+			return mockCase{}, fmt.Errorf(
+				"%s: oneof %s not found in message %s",
+				casesKey, oneofName, method.Input().FullName(),
+			)
+		}
+
+		variant := findOneofField(oneof, oneofs[oneofName])
+		if variant == nil {
+			return mockCase{}, fmt.Errorf(
+				"%s: variant %s not found in oneof %s", casesKey, oneofs[oneofName], oneof.FullName(),
+			)
+		}
+
+		switch populated := parsedRequest.ProtoReflect().WhichOneof(oneof); {
+		case populated == nil:
+			parsedCase.ignored = append(parsedCase.ignored, oneof)
+		case populated.Number() != variant.Number():
+			return mockCase{}, fmt.Errorf(
+				"%s: the request sets the variant %s but the oneof %s expects %s",
+				casesKey, populated.Name(), oneof.Name(), variant.Name(),
+			)
+		}
+		parsedCase.variants = append(parsedCase.variants, variant)
+	}
+
+	if parsedCase.delay, err = ParseDuration(delay); err != nil {
+		return mockCase{}, fmt.Errorf("%s: invalid delay: %w", casesKey, err)
+	}
+
+	return parsedCase, nil
+}
+
+// parseStep parses the response of the step, or verifies its error
+func (p mockParser) parseStep(
+	method protoreflect.MethodDescriptor,
+	step entities.SequenceStep,
+) (mockStep, error) {
+	if step.Error != nil {
+		for _, errorCode := range step.Error.Codes() {
+			if !IsErrorCodeValid(string(errorCode)) {
+				return mockStep{}, fmt.Errorf("invalid error code: %s", errorCode)
+			}
+		}
+		if step.Error.Details != nil {
+			if _, err := ParseDuration(step.Error.Details.RetryDelay); err != nil {
+				return mockStep{}, fmt.Errorf("invalid retry delay: %w", err)
+			}
+		}
+
+		_, placeholders := MessagePattern(step.Error.Message)
+		if len(step.Error.MessageArgs) > 0 && placeholders != len(step.Error.MessageArgs) {
+			return mockStep{}, fmt.Errorf(
+				"the message %q has %d placeholders but %d arguments were provided",
+				step.Error.Message, placeholders, len(step.Error.MessageArgs),
+			)
+		}
+		for _, messageArg := range step.Error.MessageArgs {
+			if _, err := FindFieldPath(method.Input(), messageArg); err != nil {
+				return mockStep{}, fmt.Errorf("invalid message argument: %w", err)
+			}
+		}
+
+		return mockStep{err: step.Error}, nil
+	}
+
+	resolvedResponse, _, err := ResolveFakeValues(step.Response, p.fakeSeed)
+	if err != nil {
+		return mockStep{}, err
+	}
+
+	response, err := p.parseMessage(method.Output(), resolvedResponse)
+	if err != nil {
+		return mockStep{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return mockStep{response: response}, nil
+}
+
+// parseMessage returns the dynamic message of the value written in the contract
+func (p mockParser) parseMessage(
+	message protoreflect.MessageDescriptor,
+	value interface{},
+) (proto.Message, error) {
+	parsedMessage := dynamicpb.NewMessage(message)
+	if value == nil {
+		return parsedMessage, nil
+	}
+
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	unmarshalOptions := protojson.UnmarshalOptions{Resolver: p.types}
+	if err = unmarshalOptions.Unmarshal(jsonData, parsedMessage); err != nil {
+		return nil, err
+	}
+
+	return parsedMessage, nil
+}
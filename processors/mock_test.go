@@ -0,0 +1,242 @@
+package processors_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+const getUserMethod = "/test.v1.UserService/GetUser"
+
+// newTestMessage returns the test.v1 message written as JSON
+func newTestMessage(
+	t *testing.T,
+	files *protoregistry.Files,
+	name protoreflect.FullName,
+	jsonMessage string,
+) proto.Message {
+	t.Helper()
+
+	descriptor, err := files.FindDescriptorByName(name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	message := dynamicpb.NewMessage(descriptor.(protoreflect.MessageDescriptor))
+	if err = protojson.Unmarshal([]byte(jsonMessage), message); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return message
+}
+
+func TestContractMockRespond(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	contract := entities.Contract{Services: map[string]entities.Service{"UserService": {
+		"GetUser": {
+			SuccessCases: []entities.SuccessCase{
+				{
+					Description: "returns the user",
+					Request:     map[string]interface{}{"id": "1"},
+					Response:    map[string]interface{}{"name": "john", "status": "STATUS_ACTIVE"},
+					Delay:       "10ms",
+					Headers:     entities.Metadata{"x-trace": {"t1"}},
+				},
+				{
+					Description: "any email",
+					Request:     map[string]interface{}{"id": "2"},
+					Oneofs:      map[string]string{"contact": "email"},
+					Response:    map[string]interface{}{"name": "mary"},
+				},
+			},
+			FailureCases: []entities.FailureCase{
+				{
+					Description: "shadowed by the success case",
+					Request:     map[string]interface{}{"id": "1"},
+					Error:       entities.GRPCError{ErrorCode: "NOT_FOUND"},
+				},
+				{
+					Description: "prioritized",
+					Priority:    1,
+					Request:     map[string]interface{}{"id": "4"},
+					Error:       entities.GRPCError{ErrorCode: "Internal"},
+				},
+			},
+		},
+	}}}
+
+	mock, err := processors.NewContractMock(contract, files, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		request          string
+		expectedMatch    bool
+		expectedCase     string
+		expectedResponse string
+		expectedError    *entities.GRPCError
+	}{
+		{
+			name:             "should return the response of the first matching case",
+			request:          `{"id": "1"}`,
+			expectedMatch:    true,
+			expectedCase:     "successCases[0]: returns the user",
+			expectedResponse: `{"name": "john", "status": "STATUS_ACTIVE"}`,
+		},
+		{
+			name:             "should accept any value of the variant without a contract value",
+			request:          `{"id": "2", "email": "mary@example.com"}`,
+			expectedMatch:    true,
+			expectedCase:     "successCases[1]: any email",
+			expectedResponse: `{"name": "mary"}`,
+		},
+		{
+			name:    "should reject another variant",
+			request: `{"id": "2", "phoneNumber": "555"}`,
+		},
+		{
+			name:          "should prefer the cases with a higher priority",
+			request:       `{"id": "4"}`,
+			expectedMatch: true,
+			expectedCase:  "failureCases[1]: prioritized",
+			expectedError: &entities.GRPCError{ErrorCode: "Internal"},
+		},
+		{
+			name:    "should not match an unknown request",
+			request: `{"id": "5"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			request := newTestMessage(t, files, "test.v1.Request", test.request)
+			result, matched := mock.Respond(getUserMethod, request)
+			if matched != test.expectedMatch {
+				t.Fatalf("Given: %v, expected: %v", matched, test.expectedMatch)
+			}
+			if result.Case != test.expectedCase {
+				t.Errorf("Given: %v, expected: %v", result.Case, test.expectedCase)
+			}
+			if !reflect.DeepEqual(result.Error, test.expectedError) {
+				t.Errorf("Given: %+v, expected: %+v", result.Error, test.expectedError)
+			}
+
+			if test.expectedResponse != "" {
+				expectedResponse := newTestMessage(t, files, "test.v1.Response", test.expectedResponse)
+				if !proto.Equal(result.Response, expectedResponse) {
+					t.Errorf("Given: %v, expected: %v", result.Response, expectedResponse)
+				}
+			}
+		})
+	}
+
+	request := newTestMessage(t, files, "test.v1.Request", `{"id": "1"}`)
+	result, _ := mock.Respond(getUserMethod, request)
+	if result.Delay != 10*time.Millisecond {
+		t.Errorf("Given: %v, expected: %v", result.Delay, 10*time.Millisecond)
+	}
+	if !reflect.DeepEqual(result.Headers, entities.Metadata{"x-trace": {"t1"}}) {
+		t.Errorf("Given: %v, expected: %v", result.Headers, entities.Metadata{"x-trace": {"t1"}})
+	}
+}
+
+func TestContractMockRespondSequence(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	notFound := entities.GRPCError{ErrorCode: "NotFound", Message: "user %s not found"}
+	notFound.MessageArgs = []string{"id"}
+	contract := entities.Contract{Services: map[string]entities.Service{"test.v1.UserService": {
+		"GetUser": {SuccessCases: []entities.SuccessCase{{
+			Request:   map[string]interface{}{"id": "1"},
+			Response:  map[string]interface{}{"name": "john"},
+			FailFirst: &entities.FailFirst{Times: 1, Error: &notFound},
+		}}},
+	}}}
+
+	mock, err := processors.NewContractMock(contract, files, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := newTestMessage(t, files, "test.v1.Request", `{"id": "1"}`)
+	expectedError := &entities.GRPCError{ErrorCode: "NotFound", Message: "user 1 not found"}
+	expectedResponse := newTestMessage(t, files, "test.v1.Response", `{"name": "john"}`)
+
+	for call := 0; call < 2; call++ {
+		mock.ResetCalls()
+
+		result, _ := mock.Respond(getUserMethod, request)
+		if !reflect.DeepEqual(result.Error, expectedError) {
+			t.Errorf("Given: %+v, expected: %+v", result.Error, expectedError)
+		}
+
+		for step := 0; step < 2; step++ {
+			result, _ = mock.Respond(getUserMethod, request)
+			if result.Error != nil || !proto.Equal(result.Response, expectedResponse) {
+				t.Errorf("Given: %v, expected: %v", result.Response, expectedResponse)
+			}
+		}
+	}
+}
+
+func TestNewContractMockRejectsInvalidContracts(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	tests := []struct {
+		name   string
+		method entities.Method
+	}{
+		{
+			name: "should fail when a request field doesn't exist",
+			method: entities.Method{SuccessCases: []entities.SuccessCase{
+				{Request: map[string]interface{}{"missing": "1"}},
+			}},
+		},
+		{
+			name: "should fail when the error code doesn't exist",
+			method: entities.Method{FailureCases: []entities.FailureCase{
+				{Error: entities.GRPCError{ErrorCode: "MY_TEST"}},
+			}},
+		},
+		{
+			name: "should fail when a message argument doesn't exist",
+			method: entities.Method{FailureCases: []entities.FailureCase{
+				{Error: entities.GRPCError{
+					ErrorCode: "NotFound", Message: "%s", MessageArgs: []string{"missing"},
+				}},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			contract := entities.Contract{Services: map[string]entities.Service{
+				"test.v1.UserService": {"GetUser": test.method},
+			}}
+			if _, err := processors.NewContractMock(contract, files, 0); err == nil {
+				t.Error("Expected an error, given nil")
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package processors
 import (
 	"fmt"
 	"strings"
+	"unicode"
 )
 
 // MakeExportedName transforms any string in a Go's exported name.
@@ -18,3 +19,36 @@ func MakeExportedName(name string) string {
 		name[1:],
 	)
 }
+
+// MakeUnexportedName transforms any string in a Go's unexported name,
+// it's the opposite of MakeExportedName.
+func MakeUnexportedName(name string) string {
+	if len(name) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"%s%s",
+		strings.ToLower(name[:1]),
+		name[1:],
+	)
+}
+
+// MakeSnakeCaseName transforms a Go's name in its snake case form, e.g. UserService -> user_service,
+// acronyms are kept together: HTTPServer -> http_server.
+func MakeSnakeCaseName(name string) string {
+	runes := []rune(name)
+	snakeCase := strings.Builder{}
+	for index, r := range runes {
+		if index > 0 && unicode.IsUpper(r) {
+			previous := runes[index-1]
+			nextIsLower := index+1 < len(runes) && unicode.IsLower(runes[index+1])
+			if !unicode.IsUpper(previous) || nextIsLower {
+				snakeCase.WriteRune('_')
+			}
+		}
+		snakeCase.WriteRune(unicode.ToLower(r))
+	}
+
+	return snakeCase.String()
+}
@@ -0,0 +1,325 @@
+package processors
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// pactSynchronousMessages is the type of the interactions of the gRPC pacts
+const pactSynchronousMessages = "Synchronous/Messages"
+
+// The response metadata of the gRPC pacts carrying the status of the call
+const (
+	pactStatusKey      = "grpc-status"
+	pactMessageKey     = "grpc-message"
+	pactContentTypeKey = "contentType"
+)
+
+// pactFile is a V4 pact file written by the Pact protobuf plugin, only the fields describing
+// the gRPC interactions are decoded
+type pactFile struct {
+	Consumer     pactParticipant   `json:"consumer"`
+	Provider     pactParticipant   `json:"provider"`
+	Interactions []pactInteraction `json:"interactions"`
+	Metadata     struct {
+		Plugins []struct {
+			Configuration map[string]struct {
+				ProtoDescriptors string `json:"protoDescriptors"`
+			} `json:"configuration"`
+		} `json:"plugins"`
+	} `json:"metadata"`
+}
+
+type pactParticipant struct {
+	Name string `json:"name"`
+}
+
+type pactInteraction struct {
+	Type           string `json:"type"`
+	Description    string `json:"description"`
+	ProviderStates []struct {
+		Name string `json:"name"`
+	} `json:"providerStates"`
+	PluginConfiguration struct {
+		Protobuf struct {
+			DescriptorKey string `json:"descriptorKey"`
+			Service       string `json:"service"`
+		} `json:"protobuf"`
+	} `json:"pluginConfiguration"`
+	Request  pactMessage   `json:"request"`
+	Response []pactMessage `json:"response"`
+}
+
+type pactMessage struct {
+	Contents struct {
+		Content json.RawMessage `json:"content"`
+		Encoded interface{}     `json:"encoded"`
+	} `json:"contents"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// ImportPactContract converts the gRPC interactions of a pact file into the cases of a contract,
+// the interactions failing with a gRPC status become failure cases. The messages are decoded by
+// the given proto files, or by the descriptors embedded in the pact file when they're nil.
+// The interactions that can't be imported, e.g. HTTP ones, are skipped and reported as issues.
+// The matching rules aren't imported, the cases keep the example values of the pact.
+func ImportPactContract(
+	data []byte,
+	files *protoregistry.Files,
+) (entities.Contract, []ContractIssue, error) {
+	pact := pactFile{}
+	if err := json.Unmarshal(data, &pact); err != nil {
+		return entities.Contract{}, nil, fmt.Errorf("failed to decode the pact file: %w", err)
+	}
+
+	importer := pactImporter{
+		pact:          pact,
+		files:         files,
+		embeddedFiles: make(map[string]*protoregistry.Files),
+	}
+	contract := entities.Contract{
+		Name:     strings.Trim(pact.Consumer.Name+"-"+pact.Provider.Name, "-"),
+		Services: make(map[string]entities.Service),
+	}
+
+	issues := make([]ContractIssue, 0)
+	for index, interaction := range pact.Interactions {
+		path := fmt.Sprintf("interactions[%d]", index)
+		if err := importer.importInteraction(contract, interaction); err != nil {
+			issues = append(issues, ContractIssue{Path: path, Message: err.Error()})
+			continue
+		}
+		if len(interaction.Response) > 1 {
+			issues = append(issues, ContractIssue{
+				Path:    path,
+				Message: "only the first of the responses is imported, the streams aren't supported",
+			})
+		}
+	}
+
+	return contract, issues, nil
+}
+
+// pactImporter imports the interactions, the embedded descriptors are parsed once
+type pactImporter struct {
+	pact          pactFile
+	files         *protoregistry.Files
+	embeddedFiles map[string]*protoregistry.Files
+}
+
+func (i *pactImporter) importInteraction(
+	contract entities.Contract,
+	interaction pactInteraction,
+) error {
+	pluginConfiguration := interaction.PluginConfiguration.Protobuf
+	if interaction.Type != pactSynchronousMessages || pluginConfiguration.Service == "" {
+		return fmt.Errorf("skipped %q, it isn't a gRPC interaction", interaction.Description)
+	}
+
+	fullMethod := pluginConfiguration.Service
+	separator := strings.LastIndex(fullMethod, "/")
+	if separator < 0 {
+		return fmt.Errorf("invalid service %q, expected Service/Method", fullMethod)
+	}
+	serviceName, methodName := fullMethod[:separator], fullMethod[separator+1:]
+
+	files, err := i.protoFiles(pluginConfiguration.DescriptorKey)
+	if err != nil {
+		return err
+	}
+	service, err := FindService(files, serviceName)
+	if err != nil {
+		return err
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return fmt.Errorf("method %s not found in the service %s", methodName, service.FullName())
+	}
+
+	types := dynamicpb.NewTypes(files)
+	request, err := decodePactContents(interaction.Request, method.Input(), types)
+	if err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	description := interaction.Description
+	if len(interaction.ProviderStates) > 0 {
+		states := make([]string, 0, len(interaction.ProviderStates))
+		for _, state := range interaction.ProviderStates {
+			states = append(states, state.Name)
+		}
+		description = fmt.Sprintf("%s (given %s)", description, strings.Join(states, ", "))
+	}
+
+	response := pactMessage{}
+	if len(interaction.Response) > 0 {
+		response = interaction.Response[0]
+	}
+
+	// The services are keyed by their fully-qualified name, whichever name the pact uses
+	serviceKey := string(service.FullName())
+	methodContract := contract.Services[serviceKey][methodName]
+	status := pactMetadataValue(response.Metadata[pactStatusKey])
+	if status != "" && status != "OK" && status != "0" {
+		errorCode, err := NormalizeErrorCode(status)
+		if err != nil {
+			return err
+		}
+
+		methodContract.FailureCases = append(methodContract.FailureCases, entities.FailureCase{
+			Description: description,
+			Request:     request,
+			Error: entities.GRPCError{
+				ErrorCode: entities.ErrorCode(errorCode),
+				Message:   pactMetadataValue(response.Metadata[pactMessageKey]),
+			},
+			Headers: pactHeaders(response.Metadata),
+		})
+	} else {
+		responseContent, err := decodePactContents(response, method.Output(), types)
+		if err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+
+		methodContract.SuccessCases = append(methodContract.SuccessCases, entities.SuccessCase{
+			Description: description,
+			Request:     request,
+			Response:    responseContent,
+			Headers:     pactHeaders(response.Metadata),
+		})
+	}
+
+	if contract.Services[serviceKey] == nil {
+		contract.Services[serviceKey] = make(entities.Service)
+	}
+	contract.Services[serviceKey][methodName] = methodContract
+
+	return nil
+}
+
+// protoFiles returns the given proto files, or the ones embedded in the pact file under the key
+func (i *pactImporter) protoFiles(descriptorKey string) (*protoregistry.Files, error) {
+	if i.files != nil {
+		return i.files, nil
+	}
+	if files, exists := i.embeddedFiles[descriptorKey]; exists {
+		return files, nil
+	}
+
+	for _, plugin := range i.pact.Metadata.Plugins {
+		configuration, exists := plugin.Configuration[descriptorKey]
+		if !exists || configuration.ProtoDescriptors == "" {
+			continue
+		}
+
+		data, err := base64.StdEncoding.DecodeString(configuration.ProtoDescriptors)
+		if err != nil {
+			return nil, fmt.Errorf("invalid descriptors %s: %w", descriptorKey, err)
+		}
+		files, err := ParseDescriptorSet(data, descriptorKey)
+		if err != nil {
+			return nil, err
+		}
+
+		i.embeddedFiles[descriptorKey] = files
+		return files, nil
+	}
+
+	return nil, fmt.Errorf(
+		"the descriptors %q aren't in the pact file, a descriptor set must be provided",
+		descriptorKey,
+	)
+}
+
+// decodePactContents returns the content of the message as it's written in the contracts,
+// the binary contents are decoded as the proto message. An empty message is returned as nil.
+func decodePactContents(
+	message pactMessage,
+	descriptor protoreflect.MessageDescriptor,
+	types *dynamicpb.Types,
+) (interface{}, error) {
+	content := message.Contents.Content
+	if len(content) == 0 || string(content) == "null" {
+		return nil, nil
+	}
+
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		encoded := message.Contents.Encoded
+		if encoded != "base64" && encoded != true {
+			content = []byte(text)
+		} else {
+			binary, err := base64.StdEncoding.DecodeString(text)
+			if err != nil {
+				return nil, err
+			}
+
+			protoMessage := dynamicpb.NewMessage(descriptor)
+			if err = (proto.UnmarshalOptions{Resolver: types}).Unmarshal(binary, protoMessage); err != nil {
+				return nil, err
+			}
+			if content, err = (protojson.MarshalOptions{Resolver: types}).Marshal(protoMessage); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if fields, isObject := value.(map[string]interface{}); isObject && len(fields) == 0 {
+		return nil, nil
+	}
+
+	return value, nil
+}
+
+// pactHeaders returns the response metadata of the interaction, except the gRPC status
+func pactHeaders(metadata map[string]interface{}) entities.Metadata {
+	headers := make(entities.Metadata)
+	for key, value := range metadata {
+		if key == pactStatusKey || key == pactMessageKey || key == pactContentTypeKey {
+			continue
+		}
+
+		switch typedValue := value.(type) {
+		case []interface{}:
+			values := make(entities.MetadataValues, 0, len(typedValue))
+			for _, element := range typedValue {
+				values = append(values, pactMetadataValue(element))
+			}
+			headers[key] = values
+		default:
+			headers[key] = entities.MetadataValues{pactMetadataValue(value)}
+		}
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+
+	return headers
+}
+
+// pactMetadataValue returns the value of a metadata entry as a string
+func pactMetadataValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
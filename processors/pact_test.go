@@ -0,0 +1,161 @@
+package processors_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+// encodeTestMessage returns the base64 wire encoding of the test.v1 message written as JSON
+func encodeTestMessage(
+	t *testing.T,
+	files *protoregistry.Files,
+	name protoreflect.FullName,
+	jsonMessage string,
+) string {
+	t.Helper()
+
+	descriptor, err := files.FindDescriptorByName(name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	message := dynamicpb.NewMessage(descriptor.(protoreflect.MessageDescriptor))
+	if err = protojson.Unmarshal([]byte(jsonMessage), message); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestImportPactContract(t *testing.T) {
+	t.Parallel()
+
+	descriptorSet, err := ioutil.ReadFile(newDescriptorSetFile(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	files := readTestDescriptorSet(t)
+
+	pact := fmt.Sprintf(`{
+		"consumer": {"name": "web"},
+		"provider": {"name": "users"},
+		"interactions": [
+			{
+				"type": "Synchronous/Messages",
+				"description": "a request for the user 1",
+				"providerStates": [{"name": "the user 1 exists"}],
+				"pluginConfiguration": {"protobuf": {"descriptorKey": "abc", "service": "UserService/GetUser"}},
+				"request": {"contents": {"content": %q, "encoded": "base64"}},
+				"response": [{
+					"contents": {"content": %q, "encoded": "base64"},
+					"metadata": {"contentType": "application/protobuf;message=Response", "x-trace": "t1"}
+				}]
+			},
+			{
+				"type": "Synchronous/Messages",
+				"description": "a request for an unknown user",
+				"pluginConfiguration": {"protobuf": {
+					"descriptorKey": "abc",
+					"service": "test.v1.UserService/GetUser"
+				}},
+				"request": {"contents": {"content": {"id": "2"}}},
+				"response": [{"metadata": {"grpc-status": "NOT_FOUND", "grpc-message": "user not found"}}]
+			},
+			{
+				"type": "Synchronous/HTTP",
+				"description": "a HTTP request",
+				"request": {"method": "GET", "path": "/users/1"}
+			},
+			{
+				"type": "Synchronous/Messages",
+				"description": "a missing method",
+				"pluginConfiguration": {"protobuf": {"descriptorKey": "abc", "service": "UserService/Missing"}}
+			}
+		],
+		"metadata": {"plugins": [{
+			"name": "protobuf",
+			"configuration": {"abc": {"protoDescriptors": %q}}
+		}]}
+	}`,
+		encodeTestMessage(t, files, "test.v1.Request", `{"id": "1"}`),
+		encodeTestMessage(t, files, "test.v1.Response", `{"name": "john", "status": "STATUS_ACTIVE"}`),
+		base64.StdEncoding.EncodeToString(descriptorSet),
+	)
+
+	expectedContract := entities.Contract{
+		Name: "web-users",
+		Services: map[string]entities.Service{"test.v1.UserService": {"GetUser": {
+			SuccessCases: []entities.SuccessCase{{
+				Description: "a request for the user 1 (given the user 1 exists)",
+				Request:     map[string]interface{}{"id": "1"},
+				Response:    map[string]interface{}{"name": "john", "status": "STATUS_ACTIVE"},
+				Headers:     entities.Metadata{"x-trace": {"t1"}},
+			}},
+			FailureCases: []entities.FailureCase{{
+				Description: "a request for an unknown user",
+				Request:     map[string]interface{}{"id": "2"},
+				Error:       entities.GRPCError{ErrorCode: "NotFound", Message: "user not found"},
+			}},
+		}}},
+	}
+	expectedIssues := []processors.ContractIssue{
+		{Path: "interactions[2]", Message: `skipped "a HTTP request", it isn't a gRPC interaction`},
+		{Path: "interactions[3]", Message: "method Missing not found in the service test.v1.UserService"},
+	}
+
+	for _, providedFiles := range []*protoregistry.Files{nil, files} {
+		contract, issues, err := processors.ImportPactContract([]byte(pact), providedFiles)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(contract, expectedContract) {
+			given, _ := json.Marshal(contract)
+			expected, _ := json.Marshal(expectedContract)
+			t.Errorf("Given: %s, expected: %s", given, expected)
+		}
+		if !reflect.DeepEqual(issues, expectedIssues) {
+			t.Errorf("Given: %+v, expected: %+v", issues, expectedIssues)
+		}
+	}
+}
+
+func TestImportPactContractWithoutDescriptors(t *testing.T) {
+	t.Parallel()
+
+	pact := `{"interactions": [{
+		"type": "Synchronous/Messages",
+		"description": "a request",
+		"pluginConfiguration": {"protobuf": {"descriptorKey": "abc", "service": "UserService/GetUser"}}
+	}]}`
+
+	_, issues, err := processors.ImportPactContract([]byte(pact), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedIssues := []processors.ContractIssue{{
+		Path:    "interactions[0]",
+		Message: `the descriptors "abc" aren't in the pact file, a descriptor set must be provided`,
+	}}
+	if !reflect.DeepEqual(issues, expectedIssues) {
+		t.Errorf("Given: %+v, expected: %+v", issues, expectedIssues)
+	}
+}
@@ -0,0 +1,205 @@
+package processors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The compilers running the plugins of a project
+const (
+	ProtocGenerator = "protoc"
+	BufGenerator    = "buf"
+)
+
+// dealPlugin is the name of the deal plugin for the compilers, protoc-gen-go-deal
+const dealPlugin = "go-deal"
+
+// defaultPlugins are the plugins generating the code the contract code depends on
+var defaultPlugins = []string{"go", "go-grpc"}
+
+// ProjectConfig is the deal.yaml file describing how the code of a project is generated,
+// the plugins share the output directory and its layout
+type ProjectConfig struct {
+	// Generator is the compiler running the plugins, protoc by default
+	Generator string `yaml:"generator"`
+	// ProtoPaths are the include paths of protoc, or the single input of buf
+	ProtoPaths []string `yaml:"protoPaths"`
+	// Protos are the compiled proto files, every file of the proto paths by default
+	Protos []string `yaml:"protos"`
+	Out    string   `yaml:"out"`
+	// Paths and Module are the layout options of the output, e.g. source_relative
+	Paths  string `yaml:"paths"`
+	Module string `yaml:"module"`
+	// Plugins run along with the deal plugin, go and go-grpc by default
+	Plugins         []string `yaml:"plugins"`
+	ContractFile    string   `yaml:"contractFile"`
+	ContractDir     string   `yaml:"contractDir"`
+	ContractPattern string   `yaml:"contractPattern"`
+	// Options are the other options of the deal plugin, e.g. test-files=true
+	Options []string `yaml:"options"`
+}
+
+// ReadProjectConfig reads the project file, the unknown fields are rejected
+func ReadProjectConfig(path string) (ProjectConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ProjectConfig{}, err
+	}
+
+	config := ProjectConfig{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return ProjectConfig{}, fmt.Errorf("invalid project file %s: %w", path, err)
+	}
+
+	if config.Generator == "" {
+		config.Generator = ProtocGenerator
+	}
+	if config.Generator != ProtocGenerator && config.Generator != BufGenerator {
+		return ProjectConfig{}, fmt.Errorf(
+			"invalid project file %s: unknown generator %q, expected %s or %s",
+			path, config.Generator, ProtocGenerator, BufGenerator,
+		)
+	}
+	if config.Out == "" {
+		config.Out = "."
+	}
+	if config.Plugins == nil {
+		config.Plugins = defaultPlugins
+	}
+
+	return config, nil
+}
+
+// GenerateCommand returns the program and the arguments generating the code of the project
+func (c ProjectConfig) GenerateCommand() (string, []string, error) {
+	if c.Generator == BufGenerator {
+		return c.bufCommand()
+	}
+
+	protos, err := c.protoFiles()
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := make([]string, 0)
+	for _, protoPath := range c.ProtoPaths {
+		args = append(args, "-I", protoPath)
+	}
+	for _, plugin := range c.plugins() {
+		args = append(args, fmt.Sprintf("--%s_out=%s", plugin, c.Out))
+		if options := c.pluginOptions(plugin); len(options) > 0 {
+			args = append(args, fmt.Sprintf("--%s_opt=%s", plugin, strings.Join(options, ",")))
+		}
+	}
+
+	return ProtocGenerator, append(args, protos...), nil
+}
+
+// bufPlugin is a plugin of the buf generation template
+type bufPlugin struct {
+	Plugin string   `json:"plugin"`
+	Out    string   `json:"out"`
+	Opt    []string `json:"opt,omitempty"`
+}
+
+// bufCommand runs buf generate with an inline template, the protos restrict the generated files
+func (c ProjectConfig) bufCommand() (string, []string, error) {
+	if len(c.ProtoPaths) > 1 {
+		return "", nil, errors.New("buf generates a single input, at most one proto path is expected")
+	}
+
+	template := struct {
+		Version string      `json:"version"`
+		Plugins []bufPlugin `json:"plugins"`
+	}{Version: "v1"}
+	for _, plugin := range c.plugins() {
+		template.Plugins = append(
+			template.Plugins, bufPlugin{Plugin: plugin, Out: c.Out, Opt: c.pluginOptions(plugin)},
+		)
+	}
+
+	data, err := json.Marshal(template)
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := []string{"generate", "--template", string(data)}
+	for _, protoFile := range c.Protos {
+		args = append(args, "--path", protoFile)
+	}
+
+	return BufGenerator, append(args, c.ProtoPaths...), nil
+}
+
+// plugins returns the plugins run by the compiler, the deal plugin comes last
+func (c ProjectConfig) plugins() []string {
+	return append(append([]string{}, c.Plugins...), dealPlugin)
+}
+
+// pluginOptions returns the layout options of the plugin, along with the contract options
+// for the deal plugin
+func (c ProjectConfig) pluginOptions(plugin string) []string {
+	options := make([]string, 0)
+	if c.Paths != "" {
+		options = append(options, "paths="+c.Paths)
+	}
+	if c.Module != "" {
+		options = append(options, "module="+c.Module)
+	}
+	if plugin != dealPlugin {
+		return options
+	}
+
+	for _, option := range []struct{ name, value string }{
+		{name: "contract-file", value: c.ContractFile},
+		{name: "contract-dir", value: c.ContractDir},
+		{name: "contract-pattern", value: c.ContractPattern},
+	} {
+		if option.value != "" {
+			options = append(options, option.name+"="+option.value)
+		}
+	}
+
+	return append(options, c.Options...)
+}
+
+// protoFiles returns the compiled proto files, the files of the proto paths are sorted
+func (c ProjectConfig) protoFiles() ([]string, error) {
+	if len(c.Protos) > 0 {
+		return c.Protos, nil
+	}
+
+	protos := make([]string, 0)
+	for _, protoPath := range c.ProtoPaths {
+		err := filepath.Walk(protoPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && filepath.Ext(path) == ".proto" {
+				protos = append(protos, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(protos) == 0 {
+		return nil, errors.New("no proto file found in the proto paths")
+	}
+	sort.Strings(protos)
+
+	return protos, nil
+}
@@ -0,0 +1,162 @@
+package processors_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+// writeProjectConfig writes the deal.yaml file into a temporary directory
+func writeProjectConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "deal.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return path
+}
+
+func TestProjectConfigGenerateCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		config          string
+		expectedProgram string
+		expectedArgs    []string
+	}{
+		{
+			name: "should run protoc with the go, go-grpc and go-deal plugins",
+			config: `
+protoPaths: [proto]
+protos: [proto/user.proto]
+out: gen
+paths: source_relative
+contractFile: contract.json
+options: [test-files=true]
+`,
+			expectedProgram: "protoc",
+			expectedArgs: []string{
+				"-I", "proto",
+				"--go_out=gen", "--go_opt=paths=source_relative",
+				"--go-grpc_out=gen", "--go-grpc_opt=paths=source_relative",
+				"--go-deal_out=gen",
+				"--go-deal_opt=paths=source_relative,contract-file=contract.json,test-files=true",
+				"proto/user.proto",
+			},
+		},
+		{
+			name: "should only run the given plugins",
+			config: `
+protos: [user.proto]
+plugins: []
+contractDir: contracts
+`,
+			expectedProgram: "protoc",
+			expectedArgs: []string{
+				"--go-deal_out=.", "--go-deal_opt=contract-dir=contracts", "user.proto",
+			},
+		},
+		{
+			name: "should run buf with an inline template",
+			config: `
+generator: buf
+protoPaths: [proto]
+protos: [proto/user.proto]
+out: gen
+plugins: [go]
+module: example.com/gen
+`,
+			expectedProgram: "buf",
+			expectedArgs: []string{
+				"generate", "--template",
+				`{"version":"v1","plugins":[` +
+					`{"plugin":"go","out":"gen","opt":["module=example.com/gen"]},` +
+					`{"plugin":"go-deal","out":"gen","opt":["module=example.com/gen"]}]}`,
+				"--path", "proto/user.proto", "proto",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := processors.ReadProjectConfig(writeProjectConfig(t, test.config))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			program, args, err := config.GenerateCommand()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if program != test.expectedProgram {
+				t.Errorf("Given: %s, expected: %s", program, test.expectedProgram)
+			}
+			if !reflect.DeepEqual(args, test.expectedArgs) {
+				t.Errorf("Given: %q, expected: %q", args, test.expectedArgs)
+			}
+		})
+	}
+}
+
+func TestProjectConfigGenerateCommandFindsTheProtos(t *testing.T) {
+	t.Parallel()
+
+	protoPath := t.TempDir()
+	for _, name := range []string{"b.proto", "a.proto", "README.md"} {
+		if err := ioutil.WriteFile(filepath.Join(protoPath, name), nil, 0o600); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	config, err := processors.ReadProjectConfig(
+		writeProjectConfig(t, "protoPaths: ["+protoPath+"]\nplugins: []\n"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, args, err := config.GenerateCommand()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedArgs := []string{
+		"-I", protoPath, "--go-deal_out=.",
+		filepath.Join(protoPath, "a.proto"), filepath.Join(protoPath, "b.proto"),
+	}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Given: %q, expected: %q", args, expectedArgs)
+	}
+}
+
+func TestReadProjectConfigRejectsInvalidFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{name: "should fail when a field is unknown", config: "contract: contract.json\n"},
+		{name: "should fail when the generator is unknown", config: "generator: bazel\n"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := processors.ReadProjectConfig(writeProjectConfig(t, test.config)); err == nil {
+				t.Error("Expected an error, given nil")
+			}
+		})
+	}
+}
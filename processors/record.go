@@ -0,0 +1,155 @@
+package processors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// ContractRecorder collects real calls as the cases of a contract, the calls ending with an
+// error become failure cases. The identical calls are recorded once. It's safe for
+// concurrent use.
+type ContractRecorder struct {
+	types *dynamicpb.Types
+
+	mu       sync.Mutex
+	contract entities.Contract
+	recorded map[string]bool
+}
+
+// NewContractRecorder creates a recorder of the calls of the services of the proto files
+func NewContractRecorder(files *protoregistry.Files) *ContractRecorder {
+	return &ContractRecorder{
+		types:    dynamicpb.NewTypes(files),
+		contract: entities.Contract{Services: make(map[string]entities.Service)},
+		recorded: make(map[string]bool),
+	}
+}
+
+// RecordResponse records a call answered with the response, false is returned when the
+// same call was already recorded
+func (r *ContractRecorder) RecordResponse(
+	method protoreflect.MethodDescriptor,
+	request, response proto.Message,
+) (bool, error) {
+	requestValue, err := r.messageValue(request)
+	if err != nil {
+		return false, fmt.Errorf("invalid request: %w", err)
+	}
+	responseValue, err := r.messageValue(response)
+	if err != nil {
+		return false, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return r.record(method, requestValue, responseValue, func(methodContract *entities.Method) {
+		methodContract.SuccessCases = append(methodContract.SuccessCases, entities.SuccessCase{
+			Description: recordedDescription(*methodContract),
+			Request:     requestValue,
+			Response:    responseValue,
+		})
+	})
+}
+
+// RecordError records a call failing with the error, false is returned when the same call
+// was already recorded
+func (r *ContractRecorder) RecordError(
+	method protoreflect.MethodDescriptor,
+	request proto.Message,
+	grpcError entities.GRPCError,
+) (bool, error) {
+	requestValue, err := r.messageValue(request)
+	if err != nil {
+		return false, fmt.Errorf("invalid request: %w", err)
+	}
+
+	return r.record(method, requestValue, grpcError, func(methodContract *entities.Method) {
+		methodContract.FailureCases = append(methodContract.FailureCases, entities.FailureCase{
+			Description: recordedDescription(*methodContract),
+			Request:     requestValue,
+			Error:       grpcError,
+		})
+	})
+}
+
+// Contract returns the contract of the calls recorded so far, the services are keyed by their
+// fully-qualified name
+func (r *ContractRecorder) Contract() entities.Contract {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	contract := entities.Contract{Services: make(map[string]entities.Service)}
+	for serviceKey, serviceContract := range r.contract.Services {
+		contract.Services[serviceKey] = make(entities.Service, len(serviceContract))
+		for methodName, methodContract := range serviceContract {
+			contract.Services[serviceKey][methodName] = entities.Method{
+				SuccessCases: append([]entities.SuccessCase(nil), methodContract.SuccessCases...),
+				FailureCases: append([]entities.FailureCase(nil), methodContract.FailureCases...),
+			}
+		}
+	}
+
+	return contract
+}
+
+// record adds the case to the method unless the same request and result were already recorded
+func (r *ContractRecorder) record(
+	method protoreflect.MethodDescriptor,
+	request, result interface{},
+	addCase func(methodContract *entities.Method),
+) (bool, error) {
+	key, err := json.Marshal([]interface{}{MockFullMethod(method), request, result})
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.recorded[string(key)] {
+		return false, nil
+	}
+	r.recorded[string(key)] = true
+
+	serviceKey, methodName := string(method.Parent().FullName()), string(method.Name())
+	if r.contract.Services[serviceKey] == nil {
+		r.contract.Services[serviceKey] = make(entities.Service)
+	}
+	methodContract := r.contract.Services[serviceKey][methodName]
+	addCase(&methodContract)
+	r.contract.Services[serviceKey][methodName] = methodContract
+
+	return true, nil
+}
+
+// messageValue returns the message as it's written in the contracts
+func (r *ContractRecorder) messageValue(message proto.Message) (interface{}, error) {
+	content, err := (protojson.MarshalOptions{Resolver: r.types}).Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var value map[string]interface{}
+	if err = decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// recordedDescription describes the next case of the method, e.g. `recorded call 3`
+func recordedDescription(methodContract entities.Method) string {
+	cases := len(methodContract.SuccessCases) + len(methodContract.FailureCases)
+	return fmt.Sprintf("recorded call %d", cases+1)
+}
@@ -0,0 +1,88 @@
+package processors_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestContractRecorder(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	descriptor, err := files.FindDescriptorByName("test.v1.UserService.GetUser")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	method := descriptor.(protoreflect.MethodDescriptor)
+
+	recorder := processors.NewContractRecorder(files)
+	response := newTestMessage(t, files, "test.v1.Response", `{"name": "john", "status": 1}`)
+	notFound := entities.GRPCError{ErrorCode: "NotFound", Message: "user not found"}
+
+	calls := []struct {
+		request       string
+		error         *entities.GRPCError
+		expectedAdded bool
+	}{
+		{request: `{"id": "1"}`, expectedAdded: true},
+		{request: `{"id": "1"}`, expectedAdded: false},
+		{request: `{"id": "2"}`, error: &notFound, expectedAdded: true},
+		{request: `{"id": "2"}`, error: &notFound, expectedAdded: false},
+		{request: `{}`, expectedAdded: true},
+	}
+	for _, call := range calls {
+		request := newTestMessage(t, files, "test.v1.Request", call.request)
+
+		var added bool
+		if call.error != nil {
+			added, err = recorder.RecordError(method, request, *call.error)
+		} else {
+			added, err = recorder.RecordResponse(method, request, response)
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if added != call.expectedAdded {
+			t.Errorf("Given: %v, expected: %v", added, call.expectedAdded)
+		}
+	}
+
+	expectedResponse := map[string]interface{}{"name": "john", "status": "STATUS_ACTIVE"}
+	expectedContract := entities.Contract{Services: map[string]entities.Service{
+		"test.v1.UserService": {"GetUser": {
+			SuccessCases: []entities.SuccessCase{
+				{
+					Description: "recorded call 1",
+					Request:     map[string]interface{}{"id": "1"},
+					Response:    expectedResponse,
+				},
+				{
+					Description: "recorded call 3",
+					Request:     map[string]interface{}{},
+					Response:    expectedResponse,
+				},
+			},
+			FailureCases: []entities.FailureCase{{
+				Description: "recorded call 2",
+				Request:     map[string]interface{}{"id": "2"},
+				Error:       notFound,
+			}},
+		}},
+	}}
+
+	contract := recorder.Contract()
+	if !reflect.DeepEqual(contract, expectedContract) {
+		given, _ := json.Marshal(contract)
+		expected, _ := json.Marshal(expectedContract)
+		t.Errorf("Given: %s, expected: %s", given, expected)
+	}
+	if issues := processors.ValidateContract(contract, files); len(issues) > 0 {
+		t.Errorf("Unexpected issues: %v", issues)
+	}
+}
@@ -0,0 +1,175 @@
+package processors
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// The placeholders of the scaffolded cases, they're meant to be replaced
+const (
+	scaffoldSuccessDescription = "TODO: describe the successful call"
+	scaffoldFailureDescription = "TODO: describe the failing call"
+	scaffoldErrorCode          = "Unknown"
+)
+
+// ScaffoldContract returns a skeleton contract with a success and a failure case for every
+// method of the services of the proto files, or of the given services only. The requests and
+// the responses stub every field with its zero value, except the google.protobuf.Any and
+// google.protobuf.Value fields which need a type to be chosen. The services are keyed by their
+// fully-qualified name.
+func ScaffoldContract(
+	files *protoregistry.Files,
+	serviceKeys ...string,
+) (entities.Contract, error) {
+	services := make([]protoreflect.ServiceDescriptor, 0)
+	if len(serviceKeys) == 0 {
+		files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+			for index := 0; index < file.Services().Len(); index++ {
+				services = append(services, file.Services().Get(index))
+			}
+			return true
+		})
+	}
+	for _, serviceKey := range serviceKeys {
+		service, err := FindService(files, serviceKey)
+		if err != nil {
+			return entities.Contract{}, err
+		}
+		services = append(services, service)
+	}
+
+	contract := entities.Contract{Services: make(map[string]entities.Service, len(services))}
+	for _, service := range services {
+		serviceContract := make(entities.Service, service.Methods().Len())
+		for index := 0; index < service.Methods().Len(); index++ {
+			method := service.Methods().Get(index)
+			if method.IsStreamingClient() || method.IsStreamingServer() {
+				continue
+			}
+
+			serviceContract[string(method.Name())] = entities.Method{
+				SuccessCases: []entities.SuccessCase{{
+					Description: scaffoldSuccessDescription,
+					Request:     stubMessage(method.Input(), nil),
+					Response:    stubMessage(method.Output(), nil),
+				}},
+				FailureCases: []entities.FailureCase{{
+					Description: scaffoldFailureDescription,
+					Request:     stubMessage(method.Input(), nil),
+					Error:       entities.GRPCError{ErrorCode: scaffoldErrorCode},
+				}},
+			}
+		}
+
+		if len(serviceContract) > 0 {
+			contract.Services[string(service.FullName())] = serviceContract
+		}
+	}
+	if len(contract.Services) == 0 {
+		return entities.Contract{}, fmt.Errorf("no service with unary methods found")
+	}
+
+	return contract, nil
+}
+
+// stubMessage returns the message with every field set to its zero value, only the first
+// variant of a oneof is set. The messages already being stubbed are left empty, since
+// a recursive message would never end.
+func stubMessage(
+	message protoreflect.MessageDescriptor,
+	stubbing map[protoreflect.FullName]bool,
+) map[string]interface{} {
+	fields := make(map[string]interface{}, message.Fields().Len())
+	if stubbing[message.FullName()] {
+		return fields
+	}
+
+	nestedStubbing := make(map[protoreflect.FullName]bool, len(stubbing)+1)
+	for name := range stubbing {
+		nestedStubbing[name] = true
+	}
+	nestedStubbing[message.FullName()] = true
+
+	for index := 0; index < message.Fields().Len(); index++ {
+		field := message.Fields().Get(index)
+		if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() &&
+			oneof.Fields().Get(0) != field {
+			continue
+		}
+
+		switch {
+		case field.IsMap():
+			fields[field.JSONName()] = map[string]interface{}{}
+		case field.IsList():
+			if value, isStubbed := stubValue(field, nestedStubbing); isStubbed {
+				fields[field.JSONName()] = []interface{}{value}
+			}
+		default:
+			if value, isStubbed := stubValue(field, nestedStubbing); isStubbed {
+				fields[field.JSONName()] = value
+			}
+		}
+	}
+
+	return fields
+}
+
+// stubValue returns the zero value of a single value of the field as written in the contracts,
+// false is returned when the field isn't stubbed
+func stubValue(
+	field protoreflect.FieldDescriptor,
+	stubbing map[protoreflect.FullName]bool,
+) (interface{}, bool) {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return false, true
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return "", true
+	case protoreflect.EnumKind:
+		return string(field.Enum().Values().Get(0).Name()), true
+	case protoreflect.MessageKind:
+		return stubWellKnownOrMessage(field.Message(), stubbing)
+	case protoreflect.GroupKind:
+		return nil, false
+	default:
+		return 0, true
+	}
+}
+
+func stubWellKnownOrMessage(
+	message protoreflect.MessageDescriptor,
+	stubbing map[protoreflect.FullName]bool,
+) (interface{}, bool) {
+	if message.FullName().Parent() != "google.protobuf" {
+		return stubMessage(message, stubbing), true
+	}
+
+	switch message.Name() {
+	case "Timestamp":
+		return "1970-01-01T00:00:00Z", true
+	case "Duration":
+		return "0s", true
+	case "FieldMask":
+		return "", true
+	case "Any", "Value":
+		return nil, false
+	case "ListValue":
+		return []interface{}{}, true
+	case "Empty", "Struct":
+		return map[string]interface{}{}, true
+	}
+
+	// The wrappers are written as the value they wrap
+	if strings.HasSuffix(string(message.Name()), "Value") {
+		if value := message.Fields().ByName("value"); value != nil {
+			return stubValue(value, stubbing)
+		}
+	}
+
+	return stubMessage(message, stubbing), true
+}
@@ -0,0 +1,85 @@
+package processors_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestScaffoldContract(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	method := entities.Method{
+		SuccessCases: []entities.SuccessCase{{
+			Description: "TODO: describe the successful call",
+			Request:     map[string]interface{}{"id": "", "email": ""},
+			Response: map[string]interface{}{
+				"name":      "",
+				"status":    "STATUS_UNKNOWN",
+				"createdAt": "1970-01-01T00:00:00Z",
+			},
+		}},
+		FailureCases: []entities.FailureCase{{
+			Description: "TODO: describe the failing call",
+			Request:     map[string]interface{}{"id": "", "email": ""},
+			Error:       entities.GRPCError{ErrorCode: "Unknown"},
+		}},
+	}
+
+	tests := []struct {
+		name             string
+		serviceKeys      []string
+		expectedContract entities.Contract
+		expectedError    string
+	}{
+		{
+			name: "Every service",
+			expectedContract: entities.Contract{Services: map[string]entities.Service{
+				"test.v1.UserService":  {"GetUser": method},
+				"test.v1.OtherService": {"GetUser": method},
+			}},
+		},
+		{
+			name:        "Given services",
+			serviceKeys: []string{"UserService"},
+			expectedContract: entities.Contract{Services: map[string]entities.Service{
+				"test.v1.UserService": {"GetUser": method},
+			}},
+		},
+		{
+			name:          "Unknown service",
+			serviceKeys:   []string{"MissingService"},
+			expectedError: "service MissingService not found",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			contract, err := processors.ScaffoldContract(files, test.serviceKeys...)
+			if test.expectedError != "" {
+				if err == nil || err.Error() != test.expectedError {
+					t.Fatalf("Given error: %v, expected: %s", err, test.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(contract, test.expectedContract) {
+				t.Errorf("Given: %+v, expected: %+v", contract, test.expectedContract)
+			}
+
+			// The skeleton is a valid contract
+			if issues := processors.ValidateContract(contract, files); len(issues) > 0 {
+				t.Errorf("Unexpected issues: %v", issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package processors
+
+import (
+	"github.com/faunists/deal-go/entities"
+)
+
+// MethodCaseCounts counts the cases of a method of the contract, by list
+type MethodCaseCounts struct {
+	Service               string `json:"service"`
+	Method                string `json:"method"`
+	SuccessCases          int    `json:"successCases"`
+	ApplicationErrorCases int    `json:"applicationErrorCases"`
+	FailureCases          int    `json:"failureCases"`
+}
+
+// Cases returns the number of cases of the method
+func (c MethodCaseCounts) Cases() int {
+	return c.SuccessCases + c.ApplicationErrorCases + c.FailureCases
+}
+
+// ContractStats aggregates the cases of a contract. The failure ratio is the number of failure
+// and application error cases for each success case, zero when there's no success case.
+type ContractStats struct {
+	Services              int     `json:"services"`
+	Methods               int     `json:"methods"`
+	Cases                 int     `json:"cases"`
+	SuccessCases          int     `json:"successCases"`
+	ApplicationErrorCases int     `json:"applicationErrorCases"`
+	FailureCases          int     `json:"failureCases"`
+	MinCasesPerMethod     int     `json:"minCasesPerMethod"`
+	MaxCasesPerMethod     int     `json:"maxCasesPerMethod"`
+	AverageCasesPerMethod float64 `json:"averageCasesPerMethod"`
+	FailureRatio          float64 `json:"failureRatio"`
+}
+
+// CountMethodCases counts the cases of every method of the contract, sorted by service and method
+func CountMethodCases(contract entities.Contract) []MethodCaseCounts {
+	counts := make([]MethodCaseCounts, 0)
+	for _, serviceKey := range sortedKeys(contract.Services) {
+		serviceContract := contract.Services[serviceKey]
+		for _, methodName := range sortedKeys(serviceContract) {
+			methodContract := serviceContract[methodName]
+			counts = append(counts, MethodCaseCounts{
+				Service:               serviceKey,
+				Method:                methodName,
+				SuccessCases:          len(methodContract.SuccessCases),
+				ApplicationErrorCases: len(methodContract.ApplicationErrorCases),
+				FailureCases:          len(methodContract.FailureCases),
+			})
+		}
+	}
+
+	return counts
+}
+
+// ComputeContractStats aggregates the cases of the contract
+func ComputeContractStats(contract entities.Contract) ContractStats {
+	stats := ContractStats{Services: len(contract.Services)}
+	for index, counts := range CountMethodCases(contract) {
+		stats.Methods++
+		stats.Cases += counts.Cases()
+		stats.SuccessCases += counts.SuccessCases
+		stats.ApplicationErrorCases += counts.ApplicationErrorCases
+		stats.FailureCases += counts.FailureCases
+
+		if index == 0 || counts.Cases() < stats.MinCasesPerMethod {
+			stats.MinCasesPerMethod = counts.Cases()
+		}
+		if counts.Cases() > stats.MaxCasesPerMethod {
+			stats.MaxCasesPerMethod = counts.Cases()
+		}
+	}
+
+	if stats.Methods > 0 {
+		stats.AverageCasesPerMethod = float64(stats.Cases) / float64(stats.Methods)
+	}
+	if stats.SuccessCases > 0 {
+		stats.FailureRatio = float64(stats.FailureCases+stats.ApplicationErrorCases) /
+			float64(stats.SuccessCases)
+	}
+
+	return stats
+}
@@ -0,0 +1,65 @@
+package processors_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestContractStats(t *testing.T) {
+	t.Parallel()
+
+	contract := entities.Contract{Services: map[string]entities.Service{
+		"UserService": {
+			"GetUser": {
+				SuccessCases:          make([]entities.SuccessCase, 3),
+				ApplicationErrorCases: make([]entities.ApplicationErrorCase, 1),
+				FailureCases:          make([]entities.FailureCase, 2),
+			},
+			"DeleteUser": {FailureCases: make([]entities.FailureCase, 1)},
+		},
+		"OrderService": {"GetOrder": {SuccessCases: make([]entities.SuccessCase, 1)}},
+	}}
+
+	expectedCounts := []processors.MethodCaseCounts{
+		{Service: "OrderService", Method: "GetOrder", SuccessCases: 1},
+		{Service: "UserService", Method: "DeleteUser", FailureCases: 1},
+		{
+			Service:               "UserService",
+			Method:                "GetUser",
+			SuccessCases:          3,
+			ApplicationErrorCases: 1,
+			FailureCases:          2,
+		},
+	}
+	if counts := processors.CountMethodCases(contract); !reflect.DeepEqual(counts, expectedCounts) {
+		t.Errorf("Given: %+v, expected: %+v", counts, expectedCounts)
+	}
+
+	expectedStats := processors.ContractStats{
+		Services:              2,
+		Methods:               3,
+		Cases:                 8,
+		SuccessCases:          4,
+		ApplicationErrorCases: 1,
+		FailureCases:          3,
+		MinCasesPerMethod:     1,
+		MaxCasesPerMethod:     6,
+		AverageCasesPerMethod: 8.0 / 3,
+		FailureRatio:          1,
+	}
+	if stats := processors.ComputeContractStats(contract); stats != expectedStats {
+		t.Errorf("Given: %+v, expected: %+v", stats, expectedStats)
+	}
+}
+
+func TestContractStatsWithoutCases(t *testing.T) {
+	t.Parallel()
+
+	stats := processors.ComputeContractStats(entities.Contract{})
+	if stats != (processors.ContractStats{}) {
+		t.Errorf("Expected empty stats, given: %+v", stats)
+	}
+}
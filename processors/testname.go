@@ -0,0 +1,43 @@
+package processors
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxTestNameLength limits the length of the description used in a test name
+const maxTestNameLength = 64
+
+// TestCaseName returns the name of the subtest verifying a contract case. The characters of the
+// description breaking the test output or the `-run` filter (e.g. slashes, which create nested
+// subtests) are replaced by underscores, the name is limited to maxTestNameLength runes and it
+// ends with the case index, so cases with the same description keep unique names.
+func TestCaseName(description string, index int) string {
+	name := strings.Builder{}
+	lastWasUnderscore := true // avoids leading underscores
+	length := 0
+	for _, r := range description {
+		if length == maxTestNameLength {
+			break
+		}
+
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' {
+			r = '_'
+		}
+		if r == '_' && lastWasUnderscore {
+			continue
+		}
+
+		name.WriteRune(r)
+		lastWasUnderscore = r == '_'
+		length++
+	}
+
+	sanitized := strings.TrimSuffix(name.String(), "_")
+	if sanitized == "" {
+		sanitized = "case"
+	}
+
+	return fmt.Sprintf("%s_%d", sanitized, index)
+}
@@ -0,0 +1,67 @@
+package processors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestTestCaseName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		description  string
+		index        int
+		expectedName string
+	}{
+		{
+			name:         "should replace the spaces and append the index",
+			description:  "Should return the user",
+			index:        0,
+			expectedName: "Should_return_the_user_0",
+		},
+		{
+			name:         "should replace slashes and percent signs",
+			description:  "GET /users/{id} 100%",
+			index:        3, //nolint:revive // random index
+			expectedName: "GET_users_id_100_3",
+		},
+		{
+			name:         "should replace quotes and new lines",
+			description:  "\"quoted\"\nvalue",
+			index:        1,
+			expectedName: "quoted_value_1",
+		},
+		{
+			name:         "should keep unicode letters",
+			description:  "usuário válido",
+			index:        2, //nolint:revive // random index
+			expectedName: "usuário_válido_2",
+		},
+		{
+			name:         "should name a case without a valid description",
+			description:  "???",
+			index:        4, //nolint:revive // random index
+			expectedName: "case_4",
+		},
+		{
+			name:         "should limit the length of long descriptions",
+			description:  strings.Repeat("a", 100), //nolint:revive // longer than the limit
+			index:        5,                        //nolint:revive // random index
+			expectedName: strings.Repeat("a", 64) + "_5",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualName := processors.TestCaseName(test.description, test.index)
+			if actualName != test.expectedName {
+				t.Errorf(
+					"Wrong test name, given: %s, expected: %s", actualName, test.expectedName,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package processors
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// ContractIssue is a problem found in a contract, the path locates it in the contract file,
+// e.g. `services.MyService.MyMethod.successCases[0].request`
+type ContractIssue struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (i ContractIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Path, i.Message)
+}
+
+// contractValidator collects the issues of a contract checked against the proto files
+type contractValidator struct {
+	files  *protoregistry.Files
+	types  *dynamicpb.Types
+	issues []ContractIssue
+}
+
+// ValidateContract verifies the contract against the compiled proto files without generating
+// any code: the services and methods must exist, the messages must match their descriptors
+// (fields, enum values, etc.), and the error codes, durations and field paths must be valid.
+// The issues are sorted by path.
+func ValidateContract(contract entities.Contract, files *protoregistry.Files) []ContractIssue {
+	validator := &contractValidator{
+		files:  files,
+		types:  dynamicpb.NewTypes(files),
+		issues: make([]ContractIssue, 0),
+	}
+
+	for serviceKey, serviceContract := range contract.Services {
+		servicePath := ContractServicePath(serviceKey)
+
+		service, err := FindService(files, serviceKey)
+		if err != nil {
+			validator.report(servicePath, err.Error())
+			continue
+		}
+
+		for methodName, methodContract := range serviceContract {
+			method := service.Methods().ByName(protoreflect.Name(methodName))
+			if method == nil {
+				validator.report(
+					servicePath+"."+methodName,
+					fmt.Sprintf("method %s not found in service %s", methodName, service.FullName()),
+				)
+				continue
+			}
+
+			validator.validateMethod(servicePath+"."+methodName, method, methodContract)
+		}
+	}
+
+	sort.SliceStable(validator.issues, func(i, j int) bool {
+		return validator.issues[i].Path < validator.issues[j].Path
+	})
+
+	return validator.issues
+}
+
+// ContractServicePath returns the path of the service in the contract file,
+// the fully-qualified names contain dots so they're quoted to keep the path readable
+func ContractServicePath(serviceKey string) string {
+	if strings.Contains(serviceKey, ".") {
+		return fmt.Sprintf("services[%q]", serviceKey)
+	}
+
+	return "services." + serviceKey
+}
+
+func (v *contractValidator) report(path, message string) {
+	v.issues = append(v.issues, ContractIssue{Path: path, Message: message})
+}
+
+func (v *contractValidator) validateMethod(
+	path string,
+	method protoreflect.MethodDescriptor,
+	methodContract entities.Method,
+) {
+	for index, successCase := range methodContract.SuccessCases {
+		casePath := fmt.Sprintf("%s.successCases[%d]", path, index)
+		v.validateRequest(casePath, method, successCase.Request, successCase.Oneofs)
+		v.validateDurations(casePath, successCase.Delay, successCase.Timeout)
+
+		if len(successCase.Responses) == 0 {
+			v.validateMessage(casePath+".response", method.Output(), successCase.Response, true)
+		}
+		for stepIndex, step := range successCase.Responses {
+			stepPath := fmt.Sprintf("%s.responses[%d]", casePath, stepIndex)
+			if step.Error != nil {
+				v.validateError(stepPath+".error", method, *step.Error)
+				continue
+			}
+			v.validateMessage(stepPath+".response", method.Output(), step.Response, true)
+		}
+		if successCase.FailFirst != nil {
+			if successCase.FailFirst.Times <= 0 {
+				v.report(casePath+".failFirst.times", "the number of failures must be positive")
+			}
+			if successCase.FailFirst.Error != nil {
+				v.validateError(casePath+".failFirst.error", method, *successCase.FailFirst.Error)
+			}
+		}
+	}
+
+	for index, applicationErrorCase := range methodContract.ApplicationErrorCases {
+		casePath := fmt.Sprintf("%s.applicationErrorCases[%d]", path, index)
+		v.validateRequest(casePath, method, applicationErrorCase.Request, applicationErrorCase.Oneofs)
+		v.validateDurations(casePath, applicationErrorCase.Delay, applicationErrorCase.Timeout)
+		v.validateMessage(
+			casePath+".response", method.Output(), applicationErrorCase.Response, true,
+		)
+		if _, err := FindFieldPath(method.Output(), applicationErrorCase.ErrorField); err != nil {
+			v.report(casePath+".errorField", err.Error())
+		}
+	}
+
+	for index, failureCase := range methodContract.FailureCases {
+		casePath := fmt.Sprintf("%s.failureCases[%d]", path, index)
+		v.validateRequest(casePath, method, failureCase.Request, failureCase.Oneofs)
+		v.validateDurations(casePath, failureCase.Delay, failureCase.Timeout)
+		v.validateError(casePath+".error", method, failureCase.Error)
+		v.validateMessageMatch(casePath, failureCase)
+	}
+}
+
+// validateRequest verifies the request of a case and the oneof variants it selects
+func (v *contractValidator) validateRequest(
+	casePath string,
+	method protoreflect.MethodDescriptor,
+	request interface{},
+	oneofs map[string]string,
+) {
+	v.validateMessage(casePath+".request", method.Input(), request, false)
+
+	oneofNames := make([]string, 0, len(oneofs))
+	for oneofName := range oneofs {
+		oneofNames = append(oneofNames, oneofName)
+	}
+	sort.Strings(oneofNames)
+
+	for _, oneofName := range oneofNames {
+		oneof := method.Input().Oneofs().ByName(protoreflect.Name(oneofName))
+		if oneof == nil || oneof.IsSynthetic() {
+			v.report(
+				casePath+".oneofs."+oneofName,
+				fmt.Sprintf("oneof %s not found in message %s", oneofName, method.Input().FullName()),
+			)
+			continue
+		}
+
+		if findOneofField(oneof, oneofs[oneofName]) == nil {
+			v.report(
+				casePath+".oneofs."+oneofName,
+				fmt.Sprintf("variant %s not found in oneof %s", oneofs[oneofName], oneof.FullName()),
+			)
+		}
+	}
+}
+
+// validateMessage verifies the value is the JSON mapping of the message,
+// the fake placeholders are only allowed in the responses
+func (v *contractValidator) validateMessage(
+	path string,
+	message protoreflect.MessageDescriptor,
+	value interface{},
+	allowFakes bool,
+) {
+	if allowFakes {
+		resolvedValue, _, err := ResolveFakeValues(value, 0)
+		if err != nil {
+			v.report(path, err.Error())
+			return
+		}
+		value = resolvedValue
+	}
+
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		v.report(path, err.Error())
+		return
+	}
+
+	unmarshalOptions := protojson.UnmarshalOptions{Resolver: v.types}
+	if err = unmarshalOptions.Unmarshal(jsonData, dynamicpb.NewMessage(message)); err != nil {
+		v.report(path, fmt.Sprintf("invalid %s: %v", message.FullName(), err))
+	}
+}
+
+func (v *contractValidator) validateDurations(casePath, delay, timeout string) {
+	if _, err := ParseDuration(delay); err != nil {
+		v.report(casePath+".delay", err.Error())
+	}
+	if _, err := ParseDuration(timeout); err != nil {
+		v.report(casePath+".timeout", err.Error())
+	}
+}
+
+// validateError verifies the codes of the error, the request fields given as message
+// arguments and the retry delay of its details
+func (v *contractValidator) validateError(
+	path string,
+	method protoreflect.MethodDescriptor,
+	grpcError entities.GRPCError,
+) {
+	for _, errorCode := range grpcError.Codes() {
+		if !IsErrorCodeValid(string(errorCode)) {
+			v.report(path+".errorCode", fmt.Sprintf("invalid error code: %s", errorCode))
+		}
+	}
+
+	for index, messageArg := range grpcError.MessageArgs {
+		if _, err := FindFieldPath(method.Input(), messageArg); err != nil {
+			v.report(fmt.Sprintf("%s.messageArgs[%d]", path, index), err.Error())
+		}
+	}
+
+	if grpcError.Details != nil {
+		if _, err := ParseDuration(grpcError.Details.RetryDelay); err != nil {
+			v.report(path+".details.retryDelay", err.Error())
+		}
+	}
+}
+
+func (v *contractValidator) validateMessageMatch(
+	casePath string,
+	failureCase entities.FailureCase,
+) {
+	err := ValidateMessageArgsMatch(failureCase.MessageMatch, failureCase.Error.MessageArgs)
+	if err != nil {
+		v.report(casePath+".messageMatch", err.Error())
+	}
+
+	switch failureCase.MessageMatch {
+	case "", entities.MessageMatchExact, entities.MessageMatchContains, entities.MessageMatchIgnore:
+	case entities.MessageMatchRegex:
+		if _, err := regexp.Compile(failureCase.Error.Message); err != nil {
+			v.report(casePath+".error.message", err.Error())
+		}
+	default:
+		v.report(
+			casePath+".messageMatch",
+			fmt.Sprintf("invalid message match %q", failureCase.MessageMatch),
+		)
+	}
+}
+
+// FindFieldPath returns the fields referenced by the dot separated path,
+// fields can be referenced by their proto or JSON names
+func FindFieldPath(
+	message protoreflect.MessageDescriptor,
+	path string,
+) ([]protoreflect.FieldDescriptor, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the field path must be provided")
+	}
+
+	fields := make([]protoreflect.FieldDescriptor, 0)
+	current := message
+	for _, name := range strings.Split(path, ".") {
+		if current == nil {
+			return nil, fmt.Errorf("field %s not found in the path %s", name, path)
+		}
+
+		field := current.Fields().ByName(protoreflect.Name(name))
+		if field == nil {
+			field = current.Fields().ByJSONName(name)
+		}
+		if field == nil {
+			return nil, fmt.Errorf("field %s not found in message %s", name, current.Name())
+		}
+
+		fields = append(fields, field)
+		current = field.Message()
+	}
+
+	return fields, nil
+}
+
+func findOneofField(oneof protoreflect.OneofDescriptor, name string) protoreflect.FieldDescriptor {
+	for index := 0; index < oneof.Fields().Len(); index++ {
+		field := oneof.Fields().Get(index)
+		if string(field.Name()) == name || field.JSONName() == name {
+			return field
+		}
+	}
+
+	return nil
+}
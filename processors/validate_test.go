@@ -0,0 +1,177 @@
+package processors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestValidateContract(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	tests := []struct {
+		name     string
+		contract entities.Contract
+		// the messages of the expected issues are matched as substrings
+		expectedIssues []processors.ContractIssue
+	}{
+		{
+			name: "Valid contract",
+			contract: entities.Contract{Services: map[string]entities.Service{
+				"test.v1.UserService": {"GetUser": {
+					SuccessCases: []entities.SuccessCase{{
+						Request: map[string]interface{}{"id": "1", "phoneNumber": "555"},
+						Oneofs:  map[string]string{"contact": "phone_number"},
+						Timeout: "1s",
+						Response: map[string]interface{}{
+							"name":      map[string]interface{}{processors.FakePlaceholderKey: "name"},
+							"status":    "STATUS_ACTIVE",
+							"createdAt": "2022-03-01T10:00:00Z",
+						},
+					}},
+					ApplicationErrorCases: []entities.ApplicationErrorCase{{
+						Request:    map[string]interface{}{},
+						Response:   map[string]interface{}{"name": "error"},
+						ErrorField: "name",
+					}},
+					FailureCases: []entities.FailureCase{{
+						Request: map[string]interface{}{"id": "2"},
+						Error: entities.GRPCError{
+							ErrorCode:   "NotFound",
+							Message:     "user %s not found",
+							MessageArgs: []string{"id"},
+						},
+						MessageMatch: entities.MessageMatchExact,
+					}},
+				}},
+			}},
+			expectedIssues: []processors.ContractIssue{},
+		},
+		{
+			name: "Unknown service and method",
+			contract: entities.Contract{Services: map[string]entities.Service{
+				"MissingService": {"GetUser": {}},
+				"UserService":    {"DeleteUser": {}},
+			}},
+			expectedIssues: []processors.ContractIssue{
+				{Path: "services.MissingService", Message: "service MissingService not found"},
+				{
+					Path:    "services.UserService.DeleteUser",
+					Message: "method DeleteUser not found in service test.v1.UserService",
+				},
+			},
+		},
+		{
+			name: "Invalid messages",
+			contract: entities.Contract{Services: map[string]entities.Service{
+				"UserService": {"GetUser": {
+					SuccessCases: []entities.SuccessCase{{
+						Request:  map[string]interface{}{"unknown": "1"},
+						Response: map[string]interface{}{"status": "STATUS_DELETED"},
+					}},
+				}},
+			}},
+			expectedIssues: []processors.ContractIssue{
+				{
+					Path:    "services.UserService.GetUser.successCases[0].request",
+					Message: `invalid test.v1.Request`,
+				},
+				{
+					Path:    "services.UserService.GetUser.successCases[0].response",
+					Message: `invalid value for enum type: "STATUS_DELETED"`,
+				},
+			},
+		},
+		{
+			name: "Invalid case settings",
+			contract: entities.Contract{Services: map[string]entities.Service{
+				"UserService": {"GetUser": {
+					SuccessCases: []entities.SuccessCase{{
+						Request: map[string]interface{}{},
+						Oneofs:  map[string]string{"contact": "address", "unknown": "id"},
+						Delay:   "soon",
+						Responses: []entities.SequenceStep{
+							{Error: &entities.GRPCError{ErrorCode: "Unknown"}},
+							{Error: &entities.GRPCError{ErrorCode: "Broken"}},
+						},
+					}},
+					ApplicationErrorCases: []entities.ApplicationErrorCase{{
+						Request:    map[string]interface{}{},
+						Response:   map[string]interface{}{},
+						ErrorField: "error.reason",
+					}},
+					FailureCases: []entities.FailureCase{{
+						Request: map[string]interface{}{},
+						Error: entities.GRPCError{
+							ErrorCode:        "NotFound",
+							AlternativeCodes: []entities.ErrorCode{"99"},
+							Message:          "user (",
+							MessageArgs:      []string{"user.id"},
+						},
+						MessageMatch: entities.MessageMatchRegex,
+					}},
+				}},
+			}},
+			expectedIssues: []processors.ContractIssue{
+				{
+					Path:    "services.UserService.GetUser.applicationErrorCases[0].errorField",
+					Message: "field error not found in message Response",
+				},
+				{
+					Path:    "services.UserService.GetUser.failureCases[0].error.errorCode",
+					Message: "invalid error code: 99",
+				},
+				{
+					Path:    "services.UserService.GetUser.failureCases[0].error.message",
+					Message: "error parsing regexp: missing closing ): `user (`",
+				},
+				{
+					Path:    "services.UserService.GetUser.failureCases[0].error.messageArgs[0]",
+					Message: "field user not found in message Request",
+				},
+				{
+					Path:    "services.UserService.GetUser.failureCases[0].messageMatch",
+					Message: `the message match "regex" can't be used with message arguments`,
+				},
+				{
+					Path:    "services.UserService.GetUser.successCases[0].delay",
+					Message: `time: invalid duration "soon"`,
+				},
+				{
+					Path:    "services.UserService.GetUser.successCases[0].oneofs.contact",
+					Message: "variant address not found in oneof test.v1.Request.contact",
+				},
+				{
+					Path:    "services.UserService.GetUser.successCases[0].oneofs.unknown",
+					Message: "oneof unknown not found in message test.v1.Request",
+				},
+				{
+					Path:    "services.UserService.GetUser.successCases[0].responses[1].error.errorCode",
+					Message: "invalid error code: Broken",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			issues := processors.ValidateContract(test.contract, files)
+			if len(issues) != len(test.expectedIssues) {
+				t.Fatalf("Given: %v, expected: %v", issues, test.expectedIssues)
+			}
+			for index, issue := range issues {
+				expectedIssue := test.expectedIssues[index]
+				if issue.Path != expectedIssue.Path ||
+					!strings.Contains(issue.Message, expectedIssue.Message) {
+					t.Errorf("Given: %v, expected: %v", issue, expectedIssue)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package processors
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// VerificationCase is a contract case verified against a running provider, the calls are
+// made in the order of the steps
+type VerificationCase struct {
+	// Path locates the case in the contract file, e.g. `services.MyService.MyMethod.successCases[0]`
+	Path        string
+	Description string
+	Method      protoreflect.MethodDescriptor
+	// Request selects the oneof variants expected by the case, even without a value
+	Request  proto.Message
+	Timeout  time.Duration
+	Headers  entities.Metadata
+	Trailers entities.Metadata
+	Steps    []VerificationStep
+}
+
+// VerificationStep is the result expected from a single call, either the response or the error
+type VerificationStep struct {
+	Response proto.Message
+	// IgnoredFields are the fields of the response that aren't compared, e.g. the faked ones
+	IgnoredFields []string
+	// ErrorField is the only field of the response compared, for the application errors
+	ErrorField string
+
+	Error *entities.GRPCError
+	// MessageMatch tells how the error message is compared to ExpectedMessage,
+	// see the entities.MessageMatch values
+	MessageMatch    string
+	ExpectedMessage string
+}
+
+// VerificationCases returns the cases of the contract to verify against a provider, the cases
+// are sorted by service and method, then follow the declaration order of the contract
+func VerificationCases(
+	contract entities.Contract,
+	files *protoregistry.Files,
+) ([]VerificationCase, error) {
+	parser := mockParser{types: dynamicpb.NewTypes(files)}
+
+	serviceKeys := make([]string, 0, len(contract.Services))
+	for serviceKey := range contract.Services {
+		serviceKeys = append(serviceKeys, serviceKey)
+	}
+	sort.Strings(serviceKeys)
+
+	cases := make([]VerificationCase, 0)
+	for _, serviceKey := range serviceKeys {
+		service, err := FindService(files, serviceKey)
+		if err != nil {
+			return nil, err
+		}
+
+		serviceContract := contract.Services[serviceKey]
+		methodNames := make([]string, 0, len(serviceContract))
+		for methodName := range serviceContract {
+			methodNames = append(methodNames, methodName)
+		}
+		sort.Strings(methodNames)
+
+		for _, methodName := range methodNames {
+			method := service.Methods().ByName(protoreflect.Name(methodName))
+			if method == nil {
+				return nil, fmt.Errorf(
+					"method %s not found in service %s", methodName, service.FullName(),
+				)
+			}
+
+			path := ContractServicePath(serviceKey) + "." + methodName
+			methodCases, err := parser.verificationCases(path, method, serviceContract[methodName])
+			if err != nil {
+				return nil, err
+			}
+			cases = append(cases, methodCases...)
+		}
+	}
+
+	return cases, nil
+}
+
+func (p mockParser) verificationCases(
+	path string,
+	method protoreflect.MethodDescriptor,
+	methodContract entities.Method,
+) ([]VerificationCase, error) {
+	cases := make([]VerificationCase, 0)
+	for index, successCase := range methodContract.SuccessCases {
+		verificationCase, err := p.verificationCase(
+			fmt.Sprintf("%s.successCases[%d]", path, index), successCase.Description, method,
+			successCase.Request, successCase.Oneofs, successCase.Timeout,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		steps := successCase.Sequence()
+		if len(steps) == 0 {
+			steps = []entities.SequenceStep{{Response: successCase.Response}}
+			verificationCase.Headers = successCase.Headers
+			verificationCase.Trailers = successCase.Trailers
+		}
+		for _, step := range steps {
+			verificationStep, err := p.verificationStep(method, step)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", verificationCase.Path, err)
+			}
+			verificationCase.Steps = append(verificationCase.Steps, verificationStep)
+		}
+
+		cases = append(cases, verificationCase)
+	}
+
+	for index, applicationErrorCase := range methodContract.ApplicationErrorCases {
+		verificationCase, err := p.verificationCase(
+			fmt.Sprintf("%s.applicationErrorCases[%d]", path, index),
+			applicationErrorCase.Description, method, applicationErrorCase.Request,
+			applicationErrorCase.Oneofs, applicationErrorCase.Timeout,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		verificationStep, err := p.verificationStep(
+			method, entities.SequenceStep{Response: applicationErrorCase.Response},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", verificationCase.Path, err)
+		}
+		if _, err = FindFieldPath(method.Output(), applicationErrorCase.ErrorField); err != nil {
+			return nil, fmt.Errorf("%s: %w", verificationCase.Path, err)
+		}
+		verificationStep.ErrorField = applicationErrorCase.ErrorField
+
+		verificationCase.Steps = []VerificationStep{verificationStep}
+		cases = append(cases, verificationCase)
+	}
+
+	for index, failureCase := range methodContract.FailureCases {
+		verificationCase, err := p.verificationCase(
+			fmt.Sprintf("%s.failureCases[%d]", path, index), failureCase.Description, method,
+			failureCase.Request, failureCase.Oneofs, failureCase.Timeout,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		failure := failureCase.Error
+		verificationStep, err := p.verificationStep(method, entities.SequenceStep{Error: &failure})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", verificationCase.Path, err)
+		}
+		if failureCase.MessageMatch == entities.MessageMatchIgnore {
+			verificationStep.MessageMatch = entities.MessageMatchIgnore
+		} else if len(failure.MessageArgs) == 0 && failureCase.MessageMatch != "" {
+			verificationStep.MessageMatch = failureCase.MessageMatch
+		}
+
+		verificationCase.Steps = []VerificationStep{verificationStep}
+		verificationCase.Headers, verificationCase.Trailers = failureCase.Headers, failureCase.Trailers
+		cases = append(cases, verificationCase)
+	}
+
+	return cases, nil
+}
+
+// verificationCase parses the request of the case, the oneof variants without a value in the
+// contract request are set with their zero value so the request still selects them
+func (p mockParser) verificationCase(
+	path, description string,
+	method protoreflect.MethodDescriptor,
+	request interface{},
+	oneofs map[string]string,
+	timeout string,
+) (VerificationCase, error) {
+	parsedRequest, err := p.parseMessage(method.Input(), request)
+	if err != nil {
+		return VerificationCase{}, fmt.Errorf("%s: invalid request: %w", path, err)
+	}
+
+	reflectRequest := parsedRequest.ProtoReflect()
+	for oneofName, variantName := range oneofs {
+		oneof := method.Input().Oneofs().ByName(protoreflect.Name(oneofName))
+		if oneof == nil || oneof.IsSynthetic() {
+			return VerificationCase{}, fmt.Errorf(
+				"%s: oneof %s not found in message %s", path, oneofName, method.Input().FullName(),
+			)
+		}
+		variant := findOneofField(oneof, variantName)
+		if variant == nil {
+			return VerificationCase{}, fmt.Errorf(
+				"%s: variant %s not found in oneof %s", path, variantName, oneof.FullName(),
+			)
+		}
+
+		if reflectRequest.WhichOneof(oneof) != nil {
+			continue
+		}
+		if variant.Message() != nil {
+			reflectRequest.Set(variant, reflectRequest.NewField(variant))
+		} else {
+			reflectRequest.Set(variant, variant.Default())
+		}
+	}
+
+	parsedTimeout, err := ParseDuration(timeout)
+	if err != nil {
+		return VerificationCase{}, fmt.Errorf("%s: invalid timeout: %w", path, err)
+	}
+
+	return VerificationCase{
+		Path:        path,
+		Description: description,
+		Method:      method,
+		Request:     parsedRequest,
+		Timeout:     parsedTimeout,
+	}, nil
+}
+
+// verificationStep parses the expected response of the step, the faked fields are ignored
+// since the provider generates its own values
+func (p mockParser) verificationStep(
+	method protoreflect.MethodDescriptor,
+	step entities.SequenceStep,
+) (VerificationStep, error) {
+	if step.Error != nil {
+		// The error is verified the same way as the mock ones
+		if _, err := p.parseStep(method, step); err != nil {
+			return VerificationStep{}, err
+		}
+
+		verificationStep := VerificationStep{
+			Error:           step.Error,
+			MessageMatch:    entities.MessageMatchExact,
+			ExpectedMessage: step.Error.Message,
+		}
+		if len(step.Error.MessageArgs) > 0 {
+			verificationStep.MessageMatch = entities.MessageMatchRegex
+			verificationStep.ExpectedMessage, _ = MessagePattern(step.Error.Message)
+		}
+
+		return verificationStep, nil
+	}
+
+	resolvedResponse, fakedPaths, err := ResolveFakeValues(step.Response, 0)
+	if err != nil {
+		return VerificationStep{}, err
+	}
+
+	response, err := p.parseMessage(method.Output(), resolvedResponse)
+	if err != nil {
+		return VerificationStep{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return VerificationStep{Response: response, IgnoredFields: fakedPaths}, nil
+}
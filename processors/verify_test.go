@@ -0,0 +1,157 @@
+package processors_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestVerificationCases(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	notFound := entities.GRPCError{
+		ErrorCode: "NotFound", Message: "user %s not found", MessageArgs: []string{"id"},
+	}
+	contract := entities.Contract{Services: map[string]entities.Service{
+		"UserService": {"GetUser": {
+			SuccessCases: []entities.SuccessCase{
+				{
+					Description: "any email",
+					Request:     map[string]interface{}{"id": "1"},
+					Oneofs:      map[string]string{"contact": "email"},
+					Response: map[string]interface{}{
+						"name": map[string]interface{}{"$fake": "name"}, "status": "STATUS_ACTIVE",
+					},
+					Timeout: "2s",
+					Headers: entities.Metadata{"x-trace": {"t1"}},
+				},
+				{
+					Description: "retried",
+					Request:     map[string]interface{}{"id": "2"},
+					Response:    map[string]interface{}{"name": "john"},
+					FailFirst:   &entities.FailFirst{Times: 1, Error: &notFound},
+				},
+			},
+			FailureCases: []entities.FailureCase{
+				{Request: map[string]interface{}{"id": "3"}, Error: notFound},
+				{
+					Request:      map[string]interface{}{"id": "4"},
+					Error:        entities.GRPCError{ErrorCode: "Internal", Message: "oops"},
+					MessageMatch: entities.MessageMatchContains,
+				},
+			},
+		}},
+		"OtherService": {"GetUser": {
+			SuccessCases: []entities.SuccessCase{{Response: map[string]interface{}{"name": "mary"}}},
+		}},
+	}}
+
+	cases, err := processors.VerificationCases(contract, files)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedPaths := []string{
+		"services.OtherService.GetUser.successCases[0]",
+		"services.UserService.GetUser.successCases[0]",
+		"services.UserService.GetUser.successCases[1]",
+		"services.UserService.GetUser.failureCases[0]",
+		"services.UserService.GetUser.failureCases[1]",
+	}
+	paths := make([]string, 0, len(cases))
+	for _, verificationCase := range cases {
+		paths = append(paths, verificationCase.Path)
+	}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Fatalf("Given: %v, expected: %v", paths, expectedPaths)
+	}
+
+	anyEmail := cases[1]
+	expectedRequest := newTestMessage(t, files, "test.v1.Request", `{"id": "1", "email": ""}`)
+	if !proto.Equal(anyEmail.Request, expectedRequest) {
+		t.Errorf("Given: %v, expected: %v", anyEmail.Request, expectedRequest)
+	}
+	if anyEmail.Timeout != 2*time.Second {
+		t.Errorf("Given: %v, expected: %v", anyEmail.Timeout, 2*time.Second)
+	}
+	if !reflect.DeepEqual(anyEmail.Headers, entities.Metadata{"x-trace": {"t1"}}) {
+		t.Errorf("Given: %v, expected: %v", anyEmail.Headers, entities.Metadata{"x-trace": {"t1"}})
+	}
+	if !reflect.DeepEqual(anyEmail.Steps[0].IgnoredFields, []string{"name"}) {
+		t.Errorf("Given: %v, expected: %v", anyEmail.Steps[0].IgnoredFields, []string{"name"})
+	}
+
+	retried := cases[2]
+	if len(retried.Steps) != 2 || retried.Steps[0].Error == nil || retried.Steps[1].Error != nil {
+		t.Errorf("Given: %+v, expected: an error then a response", retried.Steps)
+	}
+
+	expectedSteps := []processors.VerificationStep{
+		{
+			Error:           &notFound,
+			MessageMatch:    entities.MessageMatchRegex,
+			ExpectedMessage: "^user (.*) not found$",
+		},
+		{
+			Error:           &entities.GRPCError{ErrorCode: "Internal", Message: "oops"},
+			MessageMatch:    entities.MessageMatchContains,
+			ExpectedMessage: "oops",
+		},
+	}
+	for index, expectedStep := range expectedSteps {
+		step := cases[3+index].Steps[0]
+		if !reflect.DeepEqual(step, expectedStep) {
+			t.Errorf("Given: %+v, expected: %+v", step, expectedStep)
+		}
+	}
+}
+
+func TestVerificationCasesRejectsInvalidContracts(t *testing.T) {
+	t.Parallel()
+
+	files := readTestDescriptorSet(t)
+	tests := []struct {
+		name     string
+		contract entities.Contract
+	}{
+		{
+			name: "should fail when the method doesn't exist",
+			contract: entities.Contract{Services: map[string]entities.Service{
+				"UserService": {"MissingMethod": {}},
+			}},
+		},
+		{
+			name: "should fail when the oneof variant doesn't exist",
+			contract: entities.Contract{Services: map[string]entities.Service{
+				"UserService": {"GetUser": {SuccessCases: []entities.SuccessCase{
+					{Oneofs: map[string]string{"contact": "address"}},
+				}}},
+			}},
+		},
+		{
+			name: "should fail when the error field doesn't exist",
+			contract: entities.Contract{Services: map[string]entities.Service{
+				"UserService": {"GetUser": {ApplicationErrorCases: []entities.ApplicationErrorCase{
+					{ErrorField: "missing"},
+				}}},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := processors.VerificationCases(test.contract, files); err == nil {
+				t.Error("Expected an error, given nil")
+			}
+		})
+	}
+}
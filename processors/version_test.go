@@ -0,0 +1,104 @@
+package processors_test
+
+import (
+	"testing"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		version         string
+		expectedVersion processors.Version
+		expectedError   bool
+	}{
+		{
+			name:            "should parse a major.minor version",
+			version:         "1.63",
+			expectedVersion: processors.Version{Major: 1, Minor: 63}, //nolint:revive // grpc version
+		},
+		{
+			name:            "should parse a version with patch and prefix",
+			version:         "v1.63.2",
+			expectedVersion: processors.Version{Major: 1, Minor: 63, Patch: 2}, //nolint:revive // grpc version
+		},
+		{
+			name:          "should fail when the version has a single part",
+			version:       "1",
+			expectedError: true,
+		},
+		{
+			name:          "should fail when the version isn't numeric",
+			version:       "1.x",
+			expectedError: true,
+		},
+		{
+			name:          "should fail when the version has too many parts",
+			version:       "1.2.3.4",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actualVersion, err := processors.ParseVersion(test.version)
+			if (err != nil) != test.expectedError {
+				t.Fatalf("Unexpected error result, given: %v", err)
+			}
+
+			if actualVersion != test.expectedVersion {
+				t.Errorf(
+					"Wrong version, given: %v, expected: %v",
+					actualVersion, test.expectedVersion,
+				)
+			}
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	t.Parallel()
+
+	minimum := processors.Version{Major: 1, Minor: 63} //nolint:revive // grpc version
+
+	tests := []struct {
+		name     string
+		version  processors.Version
+		expected bool
+	}{
+		{
+			name:     "should accept the same version",
+			version:  processors.Version{Major: 1, Minor: 63}, //nolint:revive // grpc version
+			expected: true,
+		},
+		{
+			name:     "should accept a newer patch",
+			version:  processors.Version{Major: 1, Minor: 63, Patch: 1}, //nolint:revive // grpc version
+			expected: true,
+		},
+		{
+			name:     "should accept a newer major",
+			version:  processors.Version{Major: 2}, //nolint:revive // grpc version
+			expected: true,
+		},
+		{
+			name:     "should reject an older minor",
+			version:  processors.Version{Major: 1, Minor: 62, Patch: 9}, //nolint:revive // grpc version
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.version.AtLeast(minimum); actual != test.expected {
+				t.Errorf(
+					"Wrong result for %v, given: %v, expected: %v",
+					test.version, actual, test.expected,
+				)
+			}
+		})
+	}
+}
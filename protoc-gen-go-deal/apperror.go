@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+func generateApplicationErrorCases(
+	file *protogen.GeneratedFile,
+	method *protogen.Method,
+	cases []entities.ApplicationErrorCase,
+	target caseTarget,
+) ([]clientCase, error) {
+	clientCases := make([]clientCase, 0, len(cases))
+	for index, applicationErrorCase := range cases {
+		if _, err := findFieldPath(method.Output, applicationErrorCase.ErrorField); err != nil {
+			return nil, fmt.Errorf("case %q: %w", applicationErrorCase.Description, err)
+		}
+
+		requestMatcher, err := generateRequestMatcher(
+			file, method.Input, applicationErrorCase.Request, applicationErrorCase.Oneofs,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		delay, err := generateDelay(file, applicationErrorCase.Delay)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := generateResponseResult(file, method, applicationErrorCase.Response)
+		if err != nil {
+			return nil, err
+		}
+
+		clientCases = append(clientCases, clientCase{
+			priority: applicationErrorCase.Priority,
+			code: fmt.Sprintf(
+				"case %s:\n%s%s%s%s%s\n",
+				requestMatcher,
+				caseProvenance(
+					method, applicationErrorCasesKey, index, applicationErrorCase.Description,
+				),
+				delay,
+				generateMetadata(
+					file, target, applicationErrorCase.Headers, applicationErrorCase.Trailers,
+				),
+				generateCallback(method, applicationErrorCase.Description, target),
+				result,
+			),
+		})
+	}
+
+	return clientCases, nil
+}
+
+// generateApplicationErrorTestForServer verifies the server returns the application error
+// of each case, since the rest of the response may vary only the error field is compared
+func generateApplicationErrorTestForServer(
+	file *protogen.GeneratedFile,
+	method *protogen.Method,
+	cases []entities.ApplicationErrorCase,
+) error {
+	if len(cases) == 0 {
+		return nil
+	}
+
+	file.P()
+	file.P(
+		fmt.Sprintf(
+			`t.Run("Application Error Cases", func(t *%s) {`,
+			file.QualifiedGoIdent(testingT),
+		),
+	)
+
+	tests := make([]string, 0, len(cases))
+	hasMetadata, hasTimeouts := false, false
+	for index, applicationErrorCase := range cases {
+		requestRepresentation, err := getRequestRepresentation(
+			file, method.Input, applicationErrorCase.Request, applicationErrorCase.Oneofs,
+		)
+		if err != nil {
+			return err
+		}
+
+		responseRepresentation, _, err := getResponseRepresentation(
+			applicationErrorCase.Response, method.Output, file,
+		)
+		if err != nil {
+			return err
+		}
+
+		test := fmt.Sprintf(
+			"%s{\nname: %q,\nrequest: %s,\nexpectedResponse: %s,\nerrorField: %q,\n",
+			caseProvenance(
+				method, applicationErrorCasesKey, index, applicationErrorCase.Description,
+			),
+			processors.TestCaseName(applicationErrorCase.Description, index),
+			requestRepresentation,
+			responseRepresentation,
+			applicationErrorCase.ErrorField,
+		)
+		if len(applicationErrorCase.Headers) > 0 || len(applicationErrorCase.Trailers) > 0 {
+			hasMetadata = true
+			test += metadataTestValues(
+				file, applicationErrorCase.Headers, applicationErrorCase.Trailers,
+			)
+		}
+		timeout, err := timeoutTestValue(file, applicationErrorCase.Timeout)
+		if err != nil {
+			return err
+		}
+		if timeout != "" {
+			hasTimeouts = true
+			test += timeout
+		}
+		tests = append(tests, test+"},")
+	}
+
+	metadataColumns := metadataTestColumns{}
+	if hasMetadata {
+		metadataColumns = generateMetadataTestColumns(file)
+	}
+
+	timeoutDeclaration, callContext := timeoutTestColumns(file, hasTimeouts, "test")
+
+	file.P(
+		fmt.Sprintf(
+			"tests := []struct {name string\nrequest *%s\nexpectedResponse *%s\n"+
+				"errorField string%s%s} {",
+			file.QualifiedGoIdent(method.Input.GoIdent),
+			file.QualifiedGoIdent(method.Output.GoIdent),
+			metadataColumns.declaration,
+			timeoutDeclaration,
+		),
+	)
+	for _, test := range tests {
+		file.P(test)
+	}
+	file.P("}")
+
+	file.P()
+	file.P(
+		fmt.Sprintf(`%s%s%sresponse, err := client.%s(callCtx, test.request%s)
+					%s
+
+					%[7]s(response, test.errorField)
+					%[7]s(test.expectedResponse, test.errorField)
+					%s
+					%s
+				})
+			}`,
+			subtestsLoop(file),
+			callContext,
+			metadataColumns.variables,
+			method.GoName,
+			metadataColumns.callOptions,
+			noErrorCheck(file, "an application error was expected, given error"),
+			file.QualifiedGoIdent(dealtestPackage.Ident("KeepFields")),
+			responseCheck(file, "test.expectedResponse", "response"),
+			metadataColumns.checks,
+		),
+	)
+	file.P("})")
+
+	return nil
+}
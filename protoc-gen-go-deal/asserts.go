@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// The assertion styles of the generated server tests, see the `asserts` option
+const (
+	dealtestAsserts = "dealtest"
+	testifyAsserts  = "testify"
+)
+
+const requirePackage = protogen.GoImportPath("github.com/stretchr/testify/require")
+
+func validateAsserts(style string) error {
+	if style != dealtestAsserts && style != testifyAsserts {
+		return fmt.Errorf(
+			"invalid asserts option %q, expected %s or %s", style, dealtestAsserts, testifyAsserts,
+		)
+	}
+
+	return nil
+}
+
+// noErrorCheck returns the statement failing the test when `err` isn't nil,
+// the error is appended to the given failure message
+func noErrorCheck(file *protogen.GeneratedFile, format string, args ...string) string {
+	if *asserts == testifyAsserts {
+		return fmt.Sprintf(
+			"%s(t, err, %q%s)",
+			file.QualifiedGoIdent(requirePackage.Ident("NoErrorf")),
+			format,
+			formatArgs(args),
+		)
+	}
+
+	return fmt.Sprintf(
+		"if err != nil {\nt.Fatalf(%q%s, err)\n}", format+": %v", formatArgs(args),
+	)
+}
+
+// errorCheck returns the statement failing the test when `err` is nil
+func errorCheck(file *protogen.GeneratedFile, message string) string {
+	if *asserts == testifyAsserts {
+		return fmt.Sprintf(
+			"%s(t, err, %q)", file.QualifiedGoIdent(requirePackage.Ident("Error")), message,
+		)
+	}
+
+	return fmt.Sprintf("if err == nil {\nt.Fatalf(%q)\n}", message)
+}
+
+// responseCheck returns the statement failing the test when the responses aren't equal,
+// the comparison options of the contract test config are applied
+func responseCheck(file *protogen.GeneratedFile, expected, actual string) string {
+	if *asserts == testifyAsserts {
+		return fmt.Sprintf(
+			"%s(t, %s(%s, %s, config.CompareOptions...), %q)",
+			file.QualifiedGoIdent(requirePackage.Ident("Empty")),
+			file.QualifiedGoIdent(dealtestPackage.Ident("ResponseDiff")),
+			expected,
+			actual,
+			"unexpected response (-expected +given)",
+		)
+	}
+
+	return fmt.Sprintf(
+		"%s(t, %s, %s, config.CompareOptions...)",
+		file.QualifiedGoIdent(dealtestPackage.Ident("AssertResponse")),
+		expected,
+		actual,
+	)
+}
+
+func formatArgs(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	return ", " + strings.Join(args, ", ")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+// generateBenchmark creates the function benchmarking each method of the server with the
+// requests of its success cases. The sequenced cases are skipped, since their calls may fail.
+func generateBenchmark(
+	file *protogen.GeneratedFile,
+	service *protogen.Service,
+	contractService entities.Service,
+) error {
+	benchmarks := bytes.NewBuffer(nil)
+	for _, method := range service.Methods {
+		requests := make([]string, 0)
+		for _, successCase := range contractService[method.GoName].SuccessCases {
+			if len(successCase.Responses) > 0 {
+				continue
+			}
+
+			requestRepresentation, err := getRequestRepresentation(
+				file, method.Input, successCase.Request, successCase.Oneofs,
+			)
+			if err != nil {
+				return err
+			}
+			requests = append(requests, requestRepresentation+",")
+		}
+		if len(requests) == 0 {
+			continue
+		}
+
+		benchmarks.WriteString(
+			fmt.Sprintf(`b.Run(%q, func(b *%s) {
+				requests := []*%s{
+					%s
+				}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := client.%s(ctx, requests[i%%len(requests)]); err != nil {
+						b.Fatalf("unexpected error happened: %%v", err)
+					}
+				}
+			})
+			`,
+				method.GoName,
+				file.QualifiedGoIdent(testingPackage.Ident("B")),
+				file.QualifiedGoIdent(method.Input.GoIdent),
+				strings.Join(requests, "\n"),
+				method.GoName,
+			),
+		)
+	}
+	if benchmarks.Len() == 0 {
+		return nil
+	}
+
+	functionName := fmt.Sprintf(
+		"%sContractBenchmark", processors.MakeExportedName(service.GoName),
+	)
+	file.P(
+		fmt.Sprintf(
+			"// %s benchmarks each method of the server with the contract requests, "+
+				"it's called by a benchmark, e.g.\n//\n"+
+				"//\tfunc Benchmark%s(b *testing.B) {\n"+
+				"//\t\t%s(b, context.Background(), server)\n"+
+				"//\t}",
+			functionName, service.GoName, functionName,
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"func %s(b *%s, ctx %s, server *%s, opts ...%s) {",
+			functionName,
+			file.QualifiedGoIdent(testingPackage.Ident("B")),
+			file.QualifiedGoIdent(contextContext),
+			file.QualifiedGoIdent(grpcPackage.Ident("Server")),
+			file.QualifiedGoIdent(dealtestPackage.Ident("ContractTestOption")),
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"config := %s(opts...)",
+			file.QualifiedGoIdent(dealtestPackage.Ident("NewContractTestConfig")),
+		),
+	)
+	file.P(fmt.Sprintf("client := start%sServer(b, ctx, server, config)", service.GoName))
+	file.P()
+	file.P(benchmarks.String())
+	file.P("}\n")
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"go/build/constraint"
+)
+
+// buildConstraintLines returns the lines restricting the generated files to the builds satisfying
+// the given tags expression, e.g. "contracttest". Both the `//go:build` line and the `// +build`
+// ones are returned, so older Go versions understand them as well.
+func buildConstraintLines(tags string) ([]string, error) {
+	if tags == "" {
+		return nil, nil
+	}
+
+	expression, err := constraint.Parse(fmt.Sprintf("//go:build %s", tags))
+	if err != nil {
+		return nil, fmt.Errorf("invalid build tags %q: %w", tags, err)
+	}
+
+	plusBuildLines, err := constraint.PlusBuildLines(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid build tags %q: %w", tags, err)
+	}
+
+	return append([]string{fmt.Sprintf("//go:build %s", expression)}, plusBuildLines...), nil
+}
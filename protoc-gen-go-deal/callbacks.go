@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+func clientOptionName(clientName string) string {
+	return fmt.Sprintf("%sOption", clientName)
+}
+
+func callbacksFieldName(method *protogen.Method) string {
+	return fmt.Sprintf("%sCallbacks", processors.MakeUnexportedName(method.GoName))
+}
+
+func callbackType(file *protogen.GeneratedFile, method *protogen.Method) string {
+	return fmt.Sprintf(
+		"func(%s, *%s) (*%s, error)",
+		file.QualifiedGoIdent(contextContext),
+		file.QualifiedGoIdent(method.Input.GoIdent),
+		file.QualifiedGoIdent(method.Output.GoIdent),
+	)
+}
+
+// generateCallbacksFields returns the client fields holding the callbacks of each method,
+// the callbacks are indexed by the case description
+func generateCallbacksFields(file *protogen.GeneratedFile, service *protogen.Service) string {
+	fields := ""
+	for _, method := range service.Methods {
+		fields += fmt.Sprintf(
+			"\n%s map[string]%s", callbacksFieldName(method), callbackType(file, method),
+		)
+	}
+
+	return fields
+}
+
+// generateClientOptions creates the client constructor and the options registering
+// the callbacks that compute the case results at runtime
+func generateClientOptions(
+	file *protogen.GeneratedFile,
+	service *protogen.Service,
+	clientName string,
+) {
+	optionName := clientOptionName(clientName)
+
+	file.P(fmt.Sprintf("// %s configures a %s", optionName, clientName))
+	file.P(fmt.Sprintf("type %s func(*%s)", optionName, clientName))
+	file.P()
+
+	file.P(
+		fmt.Sprintf(
+			"// New%[1]s creates a %[1]s configured by the given options, "+
+				"it records its own calls",
+			clientName,
+		),
+	)
+	file.P(
+		fmt.Sprintf(`func New%[1]s(opts ...%[2]s) *%[1]s {
+			client := &%[1]s{state: &%[3]s{}}
+			for _, opt := range opts {
+				opt(client)
+			}
+
+			return client
+		}`, clientName, optionName, clientStateName(service)),
+	)
+	file.P()
+
+	for _, method := range service.Methods {
+		optionFuncName := fmt.Sprintf("With%s%sCallback", service.GoName, method.GoName)
+		file.P(
+			fmt.Sprintf(
+				"// %s registers a callback computing the %s result of the cases with "+
+					"the given description,\n// it replaces the result declared by the contract",
+				optionFuncName, method.GoName,
+			),
+		)
+		file.P(
+			fmt.Sprintf(`func %[1]s(description string, callback %[2]s) %[3]s {
+				return func(c *%[4]s) {
+					if c.%[5]s == nil {
+						c.%[5]s = make(map[string]%[2]s)
+					}
+					c.%[5]s[description] = callback
+				}
+			}`,
+				optionFuncName,
+				callbackType(file, method),
+				optionName,
+				clientName,
+				callbacksFieldName(method),
+			),
+		)
+		file.P()
+	}
+}
+
+// generateCallback returns the statement calling the callback registered for the case,
+// only the generated client accepts callbacks
+func generateCallback(method *protogen.Method, description string, target caseTarget) string {
+	if target != clientTarget {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"if callback, exists := c.%s[%q]; exists {\nreturn callback(ctx, in)\n}\n",
+		callbacksFieldName(method),
+		description,
+	)
+}
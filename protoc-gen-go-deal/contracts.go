@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/faunists/deal-go/dealpb"
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+// the contract file of a service when the contracts are organized in a directory
+const defaultContractPattern = "{{.PackagePath}}/{{.Service}}.json"
+
+// contracts locates the contract of every service, it's created from the plugin options
+var contracts *contractResolver
+
+// serviceContractFiles maps a service to its own contract file, it's set by repeated
+// `contract=Service:path` options
+type serviceContractFiles map[string]string
+
+func (f serviceContractFiles) String() string {
+	mappings := make([]string, 0, len(f))
+	for service, contractPath := range f {
+		mappings = append(mappings, service+":"+contractPath)
+	}
+	sort.Strings(mappings)
+
+	return strings.Join(mappings, ",")
+}
+
+func (f serviceContractFiles) Set(value string) error {
+	separator := strings.Index(value, ":")
+	if separator <= 0 || separator == len(value)-1 {
+		return fmt.Errorf("invalid contract %q, expected Service:path", value)
+	}
+
+	service := value[:separator]
+	if _, exists := f[service]; exists {
+		return fmt.Errorf("contract of service %s provided more than once", service)
+	}
+	f[service] = value[separator+1:]
+
+	return nil
+}
+
+// contractResolver locates the contract of each service, either in its own contract file
+// (given by the `contract` option or the `deal.contract_file` proto option), in the single
+// contract file or in a directory of contracts whose files are named after a pattern
+type contractResolver struct {
+	filePath     string
+	dir          string
+	pattern      *template.Template
+	serviceFiles serviceContractFiles
+
+	files   map[string]entities.Contract
+	sources map[protoreflect.FullName]contractSource
+	hashes  map[string]string
+}
+
+// contractSource tells where the contract of a service was found
+type contractSource struct {
+	path string
+	key  string
+}
+
+// contractPatternData is given to the contract pattern, e.g. for the service
+// `UserService` of the file `acme/user/v1/user.proto` with the package `acme.user.v1`
+type contractPatternData struct {
+	// Package is the proto package, e.g. `acme.user.v1`
+	Package string
+	// PackagePath is the proto package as a path, e.g. `acme/user/v1`
+	PackagePath string
+	// File is the path of the proto file without its extension, e.g. `acme/user/v1/user`
+	File string
+	// Service is the name of the service, e.g. `UserService`
+	Service string
+}
+
+func newContractResolver(
+	filePath, dir, pattern string,
+	serviceFiles serviceContractFiles,
+) (*contractResolver, error) {
+	if filePath != "" && dir != "" {
+		return nil, fmt.Errorf("'contract-file' and 'contract-dir' options can't be used together")
+	}
+
+	parsedPattern, err := template.New("contract-pattern").
+		Option("missingkey=error").
+		Parse(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract-pattern option: %w", err)
+	}
+
+	return &contractResolver{
+		filePath:     filePath,
+		dir:          dir,
+		pattern:      parsedPattern,
+		serviceFiles: serviceFiles,
+		files:        make(map[string]entities.Contract),
+		sources:      make(map[protoreflect.FullName]contractSource),
+		hashes:       make(map[string]string),
+	}, nil
+}
+
+// serviceContract returns the contract of the service, false is returned when there's none.
+// A missing contract file only means the service has no contract when the contracts are
+// organized in a directory.
+func (r *contractResolver) serviceContract(
+	file *protogen.File,
+	service *protogen.Service,
+) (entities.Service, bool, error) {
+	contractPath, isOwnFile, err := r.contractPath(file, service)
+	if err != nil {
+		return nil, false, err
+	}
+	if contractPath == "" {
+		debugf("service %s: no contract file, skipped", service.Desc.FullName())
+		return nil, false, nil
+	}
+
+	contract, err := r.readContract(contractPath)
+	if errors.Is(err, os.ErrNotExist) && r.dir != "" && !isOwnFile {
+		debugf(
+			"service %s: contract file %s not found, skipped", service.Desc.FullName(), contractPath,
+		)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The fully-qualified name is preferred, it doesn't collide
+	// with the services of the same name in other packages
+	for _, key := range []string{string(service.Desc.FullName()), service.GoName} {
+		if serviceContract, hasContract := contract.Services[key]; hasContract {
+			r.sources[service.Desc.FullName()] = contractSource{path: contractPath, key: key}
+			debugf("service %s: contract %s found in %s", service.Desc.FullName(), key, contractPath)
+			return serviceContract, true, nil
+		}
+	}
+
+	debugf("service %s: not in the contract %s, skipped", service.Desc.FullName(), contractPath)
+	return nil, false, nil
+}
+
+// source returns where the contract of a service previously resolved was found
+func (r *contractResolver) source(service *protogen.Service) contractSource {
+	return r.sources[service.Desc.FullName()]
+}
+
+// contractPath returns the path of the contract file of the service, true is returned when
+// the service has its own contract file. An empty path means there's no contract file.
+func (r *contractResolver) contractPath(
+	file *protogen.File,
+	service *protogen.Service,
+) (string, bool, error) {
+	for _, name := range []string{string(service.Desc.FullName()), string(service.Desc.Name())} {
+		if contractPath, exists := r.serviceFiles[name]; exists {
+			return contractPath, true, nil
+		}
+	}
+
+	contractFile, _ := proto.GetExtension(service.Desc.Options(), dealpb.E_ContractFile).(string)
+	if contractFile != "" {
+		return contractFile, true, nil
+	}
+
+	if r.dir == "" {
+		return r.filePath, false, nil
+	}
+
+	protoPackage := string(file.Desc.Package())
+	contractPath := bytes.NewBuffer(nil)
+	err := r.pattern.Execute(contractPath, contractPatternData{
+		Package:     protoPackage,
+		PackagePath: strings.ReplaceAll(protoPackage, ".", "/"),
+		File:        strings.TrimSuffix(file.Desc.Path(), filepath.Ext(file.Desc.Path())),
+		Service:     string(service.Desc.Name()),
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("invalid contract-pattern option: %w", err)
+	}
+
+	return filepath.Join(r.dir, filepath.FromSlash(contractPath.String())), false, nil
+}
+
+// readContract reads the contract file once, the files are shared by the services
+func (r *contractResolver) readContract(contractPath string) (entities.Contract, error) {
+	if contract, exists := r.files[contractPath]; exists {
+		return contract, nil
+	}
+
+	contract, err := processors.ReadContractFile(contractPath)
+	if err != nil {
+		return entities.Contract{}, err
+	}
+	r.files[contractPath] = contract
+	debugf("contract file %s loaded with %d services", contractPath, len(contract.Services))
+
+	return contract, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// The ways of reporting a contract coverage issue, e.g. a service without a contract
+const (
+	ignoreCoverage = "ignore"
+	warnCoverage   = "warn"
+	failCoverage   = "fail"
+)
+
+func validateCoverageOption(option, value string) error {
+	switch value {
+	case ignoreCoverage, warnCoverage, failCoverage:
+		return nil
+	default:
+		return fmt.Errorf(
+			"invalid %s option %q, expected %s, %s or %s",
+			option, value, ignoreCoverage, warnCoverage, failCoverage,
+		)
+	}
+}
+
+// reportCoverage reports the coverage issue as configured, an error is only returned
+// when the generation must fail
+func reportCoverage(report, format string, args ...interface{}) error {
+	switch report {
+	case warnCoverage:
+		warnf(format, args...)
+	case failCoverage:
+		return fmt.Errorf(format, args...)
+	}
+
+	return nil
+}
+
+// unusedContractEntries returns the services and methods of the loaded contract files matching
+// nothing in the compiled proto files, e.g. a renamed method
+func unusedContractEntries(
+	contractFiles map[string]entities.Contract,
+	files []*protogen.File,
+) []string {
+	services := make(map[string]*protogen.Service)
+	for _, file := range files {
+		for _, service := range file.Services {
+			services[string(service.Desc.FullName())] = service
+			if _, exists := services[service.GoName]; !exists {
+				services[service.GoName] = service
+			}
+		}
+	}
+
+	unusedEntries := make([]string, 0)
+	for contractPath, contract := range contractFiles {
+		for serviceKey, serviceContract := range contract.Services {
+			service, exists := services[serviceKey]
+			if !exists {
+				unusedEntries = append(unusedEntries, fmt.Sprintf(
+					"%s: service %s matches no service (%d cases)",
+					contractPath, serviceKey, countServiceCases(serviceContract),
+				))
+				continue
+			}
+
+			methods := make(map[string]bool, len(service.Methods))
+			for _, method := range service.Methods {
+				methods[method.GoName] = true
+			}
+			for methodName, methodContract := range serviceContract {
+				if !methods[methodName] {
+					unusedEntries = append(unusedEntries, fmt.Sprintf(
+						"%s: method %s.%s matches no method (%d cases)",
+						contractPath, serviceKey, methodName, countMethodCases(methodContract),
+					))
+				}
+			}
+		}
+	}
+	sort.Strings(unusedEntries)
+
+	return unusedEntries
+}
+
+// reportUnusedContractEntries reports the unused entries of the contract files as configured
+func reportUnusedContractEntries(report string, unusedEntries []string) error {
+	if report == ignoreCoverage || len(unusedEntries) == 0 {
+		return nil
+	}
+
+	if report == warnCoverage {
+		for _, unusedEntry := range unusedEntries {
+			warnf("unused contract entry, %s", unusedEntry)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unused contract entries:\n%s", strings.Join(unusedEntries, "\n"))
+}
+
+func countServiceCases(serviceContract entities.Service) int {
+	cases := 0
+	for _, methodContract := range serviceContract {
+		cases += countMethodCases(methodContract)
+	}
+
+	return cases
+}
+
+func countMethodCases(methodContract entities.Method) int {
+	return len(methodContract.SuccessCases) +
+		len(methodContract.ApplicationErrorCases) +
+		len(methodContract.FailureCases)
+}
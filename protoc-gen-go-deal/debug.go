@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+// debugf logs the message to the standard error when the `debug` option is enabled,
+// the standard output can't be used since it carries the plugin response
+func debugf(format string, args ...interface{}) {
+	if !*debug {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "protoc-gen-go-deal: "+format+"\n", args...)
+}
+
+// warnf logs the warning to the standard error
+func warnf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "protoc-gen-go-deal: warning: "+format+"\n", args...)
+}
+
+// debugServiceContract logs the methods of the service matched by the contract
+// and the contract methods matching no method of the service
+func debugServiceContract(service *protogen.Service, serviceContract entities.Service) {
+	if !*debug {
+		return
+	}
+
+	methods := make(map[string]bool, len(service.Methods))
+	for _, method := range service.Methods {
+		methods[method.GoName] = true
+
+		methodContract, hasContract := serviceContract[method.GoName]
+		if !hasContract {
+			debugf("method %s.%s: no contract cases", service.GoName, method.GoName)
+			continue
+		}
+
+		debugf(
+			"method %s.%s: %d success, %d application error and %d failure cases",
+			service.GoName,
+			method.GoName,
+			len(methodContract.SuccessCases),
+			len(methodContract.ApplicationErrorCases),
+			len(methodContract.FailureCases),
+		)
+	}
+
+	methodNames := make([]string, 0, len(serviceContract))
+	for methodName := range serviceContract {
+		methodNames = append(methodNames, methodName)
+	}
+	sort.Strings(methodNames)
+
+	for _, methodName := range methodNames {
+		if !methods[methodName] {
+			debugf(
+				"method %s.%s: in the contract but not in the service, skipped",
+				service.GoName, methodName,
+			)
+		}
+	}
+}
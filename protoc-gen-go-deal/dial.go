@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+const grpcInsecure = protogen.GoImportPath("google.golang.org/grpc/credentials/insecure")
+
+var (
+	// grpc.NewClient was added by grpc-go 1.63
+	newClientVersion = processors.Version{Major: 1, Minor: 63}
+	// the insecure credentials package was added by grpc-go 1.34
+	insecureCredentialsVersion = processors.Version{Major: 1, Minor: 34}
+	// the generated clients accept a grpc.ClientConnInterface since grpc-go 1.27
+	clientConnInterfaceVersion = processors.Version{Major: 1, Minor: 27}
+)
+
+// generateClientConn returns the statements creating the connection to the server listener
+// (`dialer`), it avoids the APIs deprecated by the grpc-go version used by the generated code.
+// The message size limits and the extra dial options are taken from the contract test config,
+// the extra options come last so they can replace the default ones.
+func generateClientConn(file *protogen.GeneratedFile, grpcVersion string) (string, error) {
+	version, err := processors.ParseVersion(grpcVersion)
+	if err != nil {
+		return "", fmt.Errorf("invalid grpc-version option: %w", err)
+	}
+
+	dialOption := file.QualifiedGoIdent(grpcPackage.Ident("DialOption"))
+	dialOptions := fmt.Sprintf(
+		"dialOptions := []%s{\n%s(dialer),\n%s,\n"+
+			"%s(%s(config.MaxRecvMsgSize), %s(config.MaxSendMsgSize)),\n}\n"+
+			"dialOptions = append(dialOptions, config.DialOptions...)\n",
+		dialOption,
+		file.QualifiedGoIdent(grpcPackage.Ident("WithContextDialer")),
+		insecureCredentials(file, version),
+		file.QualifiedGoIdent(grpcPackage.Ident("WithDefaultCallOptions")),
+		file.QualifiedGoIdent(grpcPackage.Ident("MaxCallRecvMsgSize")),
+		file.QualifiedGoIdent(grpcPackage.Ident("MaxCallSendMsgSize")),
+	)
+	// The default resolver of NewClient is the DNS one, so the target
+	// must skip the resolution to reach the dialer
+	return dialOptions + newClientConn(file, version, `"passthrough:///bufnet"`), nil
+}
+
+// insecureCredentials returns the dial option disabling the transport security
+func insecureCredentials(file *protogen.GeneratedFile, version processors.Version) string {
+	if !version.AtLeast(insecureCredentialsVersion) {
+		return fmt.Sprintf("%s()", file.QualifiedGoIdent(grpcPackage.Ident("WithInsecure")))
+	}
+
+	return fmt.Sprintf(
+		"%s(%s())",
+		file.QualifiedGoIdent(grpcPackage.Ident("WithTransportCredentials")),
+		file.QualifiedGoIdent(grpcInsecure.Ident("NewCredentials")),
+	)
+}
+
+// newClientConn returns the statement creating `clientConn` to the given target expression
+// with the `dialOptions` variable, grpc.DialContext is used before grpc.NewClient exists
+func newClientConn(
+	file *protogen.GeneratedFile,
+	version processors.Version,
+	target string,
+) string {
+	if !version.AtLeast(newClientVersion) {
+		return fmt.Sprintf(
+			"clientConn, err := %s(ctx, %s, dialOptions...)",
+			file.QualifiedGoIdent(grpcPackage.Ident("DialContext")),
+			target,
+		)
+	}
+
+	return fmt.Sprintf(
+		"clientConn, err := %s(%s, dialOptions...)",
+		file.QualifiedGoIdent(grpcPackage.Ident("NewClient")),
+		target,
+	)
+}
+
+// generateServerTestWithConn creates a variant of the server test running the cases through
+// a connection created by the caller, e.g. with TLS or custom resolvers, instead of the
+// in-memory server
+func generateServerTestWithConn(
+	file *protogen.GeneratedFile,
+	serviceImportPath protogen.GoImportPath,
+	service *protogen.Service,
+	testFunctionName string,
+) error {
+	version, err := processors.ParseVersion(*grpcVersion)
+	if err != nil {
+		return fmt.Errorf("invalid grpc-version option: %w", err)
+	}
+
+	connType := fmt.Sprintf("*%s", file.QualifiedGoIdent(grpcPackage.Ident("ClientConn")))
+	if version.AtLeast(clientConnInterfaceVersion) {
+		connType = file.QualifiedGoIdent(grpcPackage.Ident("ClientConnInterface"))
+	}
+
+	functionName := fmt.Sprintf("%sWithConn", testFunctionName)
+	file.P(
+		fmt.Sprintf(
+			"// %s runs the contract cases through the given connection,\n"+
+				"// the options configuring the in-memory server and its client are ignored",
+			functionName,
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"func %s(t *%s, ctx %s, conn %s, opts ...%s) {",
+			functionName,
+			file.QualifiedGoIdent(testingT),
+			file.QualifiedGoIdent(contextContext),
+			connType,
+			file.QualifiedGoIdent(dealtestPackage.Ident("ContractTestOption")),
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"run%sTests(t, ctx, %s(conn), %s(opts...))",
+			service.GoName,
+			file.QualifiedGoIdent(serviceIdent(serviceImportPath, "New%sClient", service)),
+			file.QualifiedGoIdent(dealtestPackage.Ident("NewContractTestConfig")),
+		),
+	)
+	file.P("}\n")
+
+	return nil
+}
+
+// generateServerTestWithService creates the function serving the given implementation through a
+// server created with the server options of the contract test config
+func generateServerTestWithService(
+	file *protogen.GeneratedFile,
+	serviceImportPath protogen.GoImportPath,
+	service *protogen.Service,
+	testFunctionName string,
+) {
+	functionName := fmt.Sprintf("%sWithService", testFunctionName)
+	file.P(
+		fmt.Sprintf(
+			"// %s runs the contract cases against the given implementation,\n"+
+				"// served by a server created with the options given through %s",
+			functionName,
+			file.QualifiedGoIdent(dealtestPackage.Ident("WithServerOptions")),
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"func %s(t *%s, ctx %s, service %s, opts ...%s) {",
+			functionName,
+			file.QualifiedGoIdent(testingT),
+			file.QualifiedGoIdent(contextContext),
+			file.QualifiedGoIdent(serviceIdent(serviceImportPath, "%sServer", service)),
+			file.QualifiedGoIdent(dealtestPackage.Ident("ContractTestOption")),
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"config := %s(opts...)",
+			file.QualifiedGoIdent(dealtestPackage.Ident("NewContractTestConfig")),
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"server := %s(config.ServerOptions...)",
+			file.QualifiedGoIdent(grpcPackage.Ident("NewServer")),
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"%s(server, service)",
+			file.QualifiedGoIdent(serviceIdent(serviceImportPath, "Register%sServer", service)),
+		),
+	)
+	file.P(fmt.Sprintf("client := start%sServer(t, ctx, server, config)", service.GoName))
+	file.P(fmt.Sprintf("run%sTests(t, ctx, client, config)", service.GoName))
+	file.P("}\n")
+}
+
+// generateVerify creates the function running the contract cases against a server
+// that is already running, e.g. a staging deployment
+func generateVerify(
+	file *protogen.GeneratedFile,
+	service *protogen.Service,
+	testFunctionName string,
+) error {
+	version, err := processors.ParseVersion(*grpcVersion)
+	if err != nil {
+		return fmt.Errorf("invalid grpc-version option: %w", err)
+	}
+
+	functionName := fmt.Sprintf("%sContractVerify", processors.MakeExportedName(service.GoName))
+	dialOption := file.QualifiedGoIdent(grpcPackage.Ident("DialOption"))
+	file.P(
+		fmt.Sprintf(
+			"// %s runs the contract cases against the server listening on the target,\n"+
+				"// the connection is insecure unless credentials are given through the options",
+			functionName,
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"func %s(t *%s, ctx %s, target string, opts ...%s) {",
+			functionName,
+			file.QualifiedGoIdent(testingT),
+			file.QualifiedGoIdent(contextContext),
+			dialOption,
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"dialOptions := append([]%s{%s}, opts...)",
+			dialOption,
+			insecureCredentials(file, version),
+		),
+	)
+	file.P(newClientConn(file, version, "target"))
+	file.P(`if err != nil { t.Fatalf("Failed to dial %s: %v", target, err) }`)
+	file.P(`t.Cleanup(func() {
+		if err := clientConn.Close(); err != nil {
+			t.Errorf("Failed to close the client connection: %v", err)
+		}
+	})`)
+	file.P()
+	file.P(fmt.Sprintf("%sWithConn(t, ctx, clientConn)", testFunctionName))
+	file.P("}\n")
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+// exactCodeCheck verifies the error code, it's used when no case accepts alternative codes
+func exactCodeCheck(file *protogen.GeneratedFile) string {
+	if *asserts == testifyAsserts {
+		return fmt.Sprintf(
+			"%s(t, test.expectedCode, errorStatus.Code(), %q)",
+			file.QualifiedGoIdent(requirePackage.Ident("Equal")),
+			"unexpected error code",
+		)
+	}
+
+	return `if errorStatus.Code() != test.expectedCode {
+		t.Fatalf("expected code: %s, given code: %s", test.expectedCode, errorStatus.Code())
+	}`
+}
+
+// codesRepresentation returns a []codes.Code literal of the given error codes
+func codesRepresentation(
+	file *protogen.GeneratedFile,
+	errorCodes []entities.ErrorCode,
+) (string, error) {
+	identifiers := make([]string, 0, len(errorCodes))
+	for _, errorCode := range errorCodes {
+		normalizedCode, err := processors.NormalizeErrorCode(string(errorCode))
+		if err != nil {
+			return "", err
+		}
+
+		identifiers = append(identifiers, file.QualifiedGoIdent(grpcCodes.Ident(normalizedCode)))
+	}
+
+	return fmt.Sprintf(
+		"[]%s{%s}",
+		file.QualifiedGoIdent(grpcCodes.Ident("Code")),
+		strings.Join(identifiers, ", "),
+	), nil
+}
+
+// alternativeCodesTestColumns returns the table column declaration and the check accepting
+// either the expected code or one of the alternative codes
+func alternativeCodesTestColumns(file *protogen.GeneratedFile) (string, string) {
+	return fmt.Sprintf("\nalternativeCodes []%s", file.QualifiedGoIdent(grpcCodes.Ident("Code"))),
+		`codeAccepted := errorStatus.Code() == test.expectedCode
+		for _, alternativeCode := range test.alternativeCodes {
+			codeAccepted = codeAccepted || errorStatus.Code() == alternativeCode
+		}
+		if !codeAccepted {
+			t.Fatalf(
+				"expected code: %s (or one of %v), given code: %s",
+				test.expectedCode, test.alternativeCodes, errorStatus.Code(),
+			)
+		}`
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+const (
+	errdetailsPackage = protogen.GoImportPath("google.golang.org/genproto/googleapis/rpc/errdetails") //nolint:lll // import path
+	durationpbPackage = protogen.GoImportPath("google.golang.org/protobuf/types/known/durationpb")
+)
+
+// errorDetailsRepresentation returns the literals of the provided error details, they're
+// always written in the same order: BadRequest, PreconditionFailure, ErrorInfo,
+// QuotaFailure and RetryInfo
+func errorDetailsRepresentation(
+	file *protogen.GeneratedFile,
+	details *entities.ErrorDetails,
+) ([]string, error) {
+	if details.IsEmpty() {
+		return nil, nil
+	}
+
+	literals := make([]string, 0)
+	if len(details.BadRequest) > 0 {
+		violations := make([]string, 0, len(details.BadRequest))
+		for _, violation := range details.BadRequest {
+			violations = append(
+				violations,
+				fmt.Sprintf(
+					"{Field: %q, Description: %q}", violation.Field, violation.Description,
+				),
+			)
+		}
+
+		literals = append(
+			literals,
+			fmt.Sprintf(
+				"&%s{FieldViolations: []*%s{%s}}",
+				file.QualifiedGoIdent(errdetailsPackage.Ident("BadRequest")),
+				file.QualifiedGoIdent(errdetailsPackage.Ident("BadRequest_FieldViolation")),
+				strings.Join(violations, ", "),
+			),
+		)
+	}
+
+	if len(details.PreconditionFailure) > 0 {
+		violations := make([]string, 0, len(details.PreconditionFailure))
+		for _, violation := range details.PreconditionFailure {
+			violations = append(
+				violations,
+				fmt.Sprintf(
+					"{Type: %q, Subject: %q, Description: %q}",
+					violation.Type, violation.Subject, violation.Description,
+				),
+			)
+		}
+
+		literals = append(
+			literals,
+			fmt.Sprintf(
+				"&%s{Violations: []*%s{%s}}",
+				file.QualifiedGoIdent(errdetailsPackage.Ident("PreconditionFailure")),
+				file.QualifiedGoIdent(errdetailsPackage.Ident("PreconditionFailure_Violation")),
+				strings.Join(violations, ", "),
+			),
+		)
+	}
+
+	if details.ErrorInfo != nil {
+		literals = append(
+			literals,
+			fmt.Sprintf(
+				"&%s{Reason: %q, Domain: %q, Metadata: %s}",
+				file.QualifiedGoIdent(errdetailsPackage.Ident("ErrorInfo")),
+				details.ErrorInfo.Reason,
+				details.ErrorInfo.Domain,
+				stringMapRepresentation(details.ErrorInfo.Metadata),
+			),
+		)
+	}
+
+	if len(details.QuotaFailure) > 0 {
+		violations := make([]string, 0, len(details.QuotaFailure))
+		for _, violation := range details.QuotaFailure {
+			violations = append(
+				violations,
+				fmt.Sprintf(
+					"{Subject: %q, Description: %q}", violation.Subject, violation.Description,
+				),
+			)
+		}
+
+		literals = append(
+			literals,
+			fmt.Sprintf(
+				"&%s{Violations: []*%s{%s}}",
+				file.QualifiedGoIdent(errdetailsPackage.Ident("QuotaFailure")),
+				file.QualifiedGoIdent(errdetailsPackage.Ident("QuotaFailure_Violation")),
+				strings.Join(violations, ", "),
+			),
+		)
+	}
+
+	if details.RetryDelay != "" {
+		retryDelay, err := processors.ParseDuration(details.RetryDelay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry delay: %w", err)
+		}
+
+		literals = append(
+			literals,
+			fmt.Sprintf(
+				"&%s{RetryDelay: &%s{Seconds: %d, Nanos: %d}}",
+				file.QualifiedGoIdent(errdetailsPackage.Ident("RetryInfo")),
+				file.QualifiedGoIdent(durationpbPackage.Ident("Duration")),
+				retryDelay/time.Second,
+				retryDelay%time.Second,
+			),
+		)
+	}
+
+	return literals, nil
+}
+
+// stringMapRepresentation returns a map[string]string literal with sorted keys
+func stringMapRepresentation(values map[string]string) string {
+	if len(values) == 0 {
+		return "nil"
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]string, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, fmt.Sprintf("%q: %q", key, values[key]))
+	}
+
+	return fmt.Sprintf("map[string]string{%s}", strings.Join(entries, ", "))
+}
+
+// errorDetailsTestColumns returns the table column declaration and the checks
+// verifying the details sent by the server, entry is the variable holding the table entry
+func errorDetailsTestColumns(file *protogen.GeneratedFile, entry string) (string, string) {
+	protoMessage := file.QualifiedGoIdent(protoPackage.Ident("Message"))
+
+	return fmt.Sprintf("\nexpectedDetails []%s", protoMessage),
+		fmt.Sprintf(
+			"%s(t, %s.expectedDetails, %s(err).Details())\n",
+			file.QualifiedGoIdent(dealtestPackage.Ident("AssertDetails")),
+			entry,
+			file.QualifiedGoIdent(grpcStatus.Ident("Convert")),
+		)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// findFieldPath returns the fields referenced by the dot separated path,
+// fields can be referenced by their proto or JSON names
+func findFieldPath(message *protogen.Message, path string) ([]*protogen.Field, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the field path must be provided")
+	}
+
+	fields := make([]*protogen.Field, 0)
+	current := message
+	for _, name := range strings.Split(path, ".") {
+		if current == nil {
+			return nil, fmt.Errorf("field %s not found in the path %s", name, path)
+		}
+
+		var found *protogen.Field
+		for _, field := range current.Fields {
+			if string(field.Desc.Name()) == name || field.Desc.JSONName() == name {
+				found = field
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("field %s not found in message %s", name, current.Desc.Name())
+		}
+
+		fields = append(fields, found)
+		current = found.Message
+	}
+
+	return fields, nil
+}
+
+// fieldGetter returns the expression reading the field referenced by the path from the given
+// variable through the generated getters, e.g. in.GetUser().GetId()
+func fieldGetter(variable string, message *protogen.Message, path string) (string, error) {
+	fields, err := findFieldPath(message, path)
+	if err != nil {
+		return "", err
+	}
+
+	getter := variable
+	for index, field := range fields {
+		if index < len(fields)-1 && (field.Desc.IsList() || field.Desc.IsMap()) {
+			return "", fmt.Errorf(
+				"the path %s goes through the list or map %s", path, field.Desc.Name(),
+			)
+		}
+
+		getter += fmt.Sprintf(".Get%s()", field.GoName)
+	}
+
+	return getter, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The default suffixes of the generated files, appended to the proto file name
+const (
+	defaultFileSuffix     = "_contract.pb.go"
+	defaultTestFileSuffix = "_contract_test.go"
+)
+
+// validateFileSuffixes verifies the suffixes of the generated files, the contract file
+// can't be a test file since its code is used by other packages
+func validateFileSuffixes(fileSuffix, testFileSuffix string) error {
+	if !strings.HasSuffix(fileSuffix, ".go") || strings.HasSuffix(fileSuffix, "_test.go") {
+		return fmt.Errorf(
+			"invalid file-suffix option %q, it must end with .go but not _test.go", fileSuffix,
+		)
+	}
+
+	if !strings.HasSuffix(testFileSuffix, "_test.go") {
+		return fmt.Errorf(
+			"invalid test-file-suffix option %q, it must end with _test.go", testFileSuffix,
+		)
+	}
+
+	return nil
+}
+
+// generatedContractFilenames returns the files written for the filename prefix,
+// they're the same as the ones created by newContractFiles
+func generatedContractFilenames(filenamePrefix string) []string {
+	if !*testFiles {
+		return []string{filenamePrefix + *fileSuffix}
+	}
+
+	filenames := make([]string, 0, 2) //nolint:revive // the contract file and the test file
+	if *genClient {
+		filenames = append(filenames, filenamePrefix+*fileSuffix)
+	}
+	if *genServerTest {
+		filenames = append(filenames, filenamePrefix+*testFileSuffix)
+	}
+
+	return filenames
+}
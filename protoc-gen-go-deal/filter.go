@@ -0,0 +1,42 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// serviceNames is a set of services, given either by name or by fully-qualified name.
+// It's set by repeated options, e.g. `services=UserService,services=OrderService`, since
+// the plugin options are separated by commas.
+type serviceNames map[string]bool
+
+func (n serviceNames) String() string {
+	names := make([]string, 0, len(n))
+	for name := range n {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
+
+func (n serviceNames) Set(value string) error {
+	n[value] = true
+	return nil
+}
+
+func (n serviceNames) contains(service *protogen.Service) bool {
+	return n[string(service.Desc.FullName())] || n[string(service.Desc.Name())]
+}
+
+// isServiceSelected reports whether the contract code of the service must be generated,
+// which happens when the service is selected (or no service is) and isn't skipped
+func isServiceSelected(service *protogen.Service) bool {
+	if len(selectedServices) > 0 && !selectedServices.contains(service) {
+		return false
+	}
+
+	return !skippedServices.contains(service)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+// fuzzSeed is a contract request used to seed the fuzzing of a method
+type fuzzSeed struct {
+	request interface{}
+	oneofs  map[string]string
+}
+
+// contractRequests returns the requests of every case of the method
+func contractRequests(methodContract entities.Method) []fuzzSeed {
+	seeds := make([]fuzzSeed, 0)
+	for _, successCase := range methodContract.SuccessCases {
+		seeds = append(seeds, fuzzSeed{request: successCase.Request, oneofs: successCase.Oneofs})
+	}
+	for _, applicationErrorCase := range methodContract.ApplicationErrorCases {
+		seeds = append(seeds, fuzzSeed{
+			request: applicationErrorCase.Request,
+			oneofs:  applicationErrorCase.Oneofs,
+		})
+	}
+	for _, failureCase := range methodContract.FailureCases {
+		seeds = append(seeds, fuzzSeed{request: failureCase.Request, oneofs: failureCase.Oneofs})
+	}
+
+	return seeds
+}
+
+// generateFuzz creates the function fuzzing the server with the contract requests as the seed
+// corpus. The fuzzed values are the index of the method and the request encoded in the wire
+// format, the server only fails the fuzzing by crashing or hanging.
+func generateFuzz(
+	file *protogen.GeneratedFile,
+	service *protogen.Service,
+	contractService entities.Service,
+) error {
+	methods := make([]*protogen.Method, 0, len(service.Methods))
+	for _, method := range service.Methods {
+		if _, exists := contractService[method.GoName]; exists {
+			methods = append(methods, method)
+		}
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+
+	seeds := bytes.NewBuffer(nil)
+	calls := bytes.NewBuffer(nil)
+	for index, method := range methods {
+		for _, seed := range contractRequests(contractService[method.GoName]) {
+			requestRepresentation, err := getRequestRepresentation(
+				file, method.Input, seed.request, seed.oneofs,
+			)
+			if err != nil {
+				return err
+			}
+
+			seeds.WriteString(fmt.Sprintf("{method: %d, request: %s},\n", index, requestRepresentation))
+		}
+
+		calls.WriteString(
+			fmt.Sprintf(
+				"case %d:\nin := &%s{}\nif err := %s(payload, in); err != nil {\nt.Skip()\n}\n"+
+					"_, _ = client.%s(callCtx, in)\n",
+				index,
+				file.QualifiedGoIdent(method.Input.GoIdent),
+				file.QualifiedGoIdent(protoPackage.Ident("Unmarshal")),
+				method.GoName,
+			),
+		)
+	}
+
+	functionName := fmt.Sprintf("%sContractFuzz", processors.MakeExportedName(service.GoName))
+	file.P(
+		fmt.Sprintf(
+			"// %s fuzzes the server seeded by the contract requests, "+
+				"it's called by a fuzz test, e.g.\n//\n"+
+				"//\tfunc Fuzz%s(f *testing.F) {\n"+
+				"//\t\t%s(f, context.Background(), server)\n"+
+				"//\t}",
+			functionName, service.GoName, functionName,
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"func %s(f *%s, ctx %s, server *%s, opts ...%s) {",
+			functionName,
+			file.QualifiedGoIdent(testingPackage.Ident("F")),
+			file.QualifiedGoIdent(contextContext),
+			file.QualifiedGoIdent(grpcPackage.Ident("Server")),
+			file.QualifiedGoIdent(dealtestPackage.Ident("ContractTestOption")),
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"config := %s(opts...)",
+			file.QualifiedGoIdent(dealtestPackage.Ident("NewContractTestConfig")),
+		),
+	)
+	file.P(fmt.Sprintf("client := start%sServer(f, ctx, server, config)", service.GoName))
+	file.P()
+	file.P(
+		fmt.Sprintf(
+			"seeds := []struct {method uint\nrequest %s} {\n%s}",
+			file.QualifiedGoIdent(protoPackage.Ident("Message")),
+			seeds,
+		),
+	)
+	file.P(
+		fmt.Sprintf(`for _, seed := range seeds {
+			payload, err := %s(seed.request)
+			if err != nil {
+				f.Fatalf("Failed to encode the seed request: %%v", err)
+			}
+			f.Add(seed.method, payload)
+		}`,
+			file.QualifiedGoIdent(protoPackage.Ident("Marshal")),
+		),
+	)
+	file.P()
+	file.P(
+		fmt.Sprintf(`f.Fuzz(func(t *%s, method uint, payload []byte) {
+			callCtx, cancel := config.CallContext(ctx, 0)
+			defer cancel()
+
+			switch method %% %d {
+			%s}
+		})`,
+			file.QualifiedGoIdent(testingT),
+			len(methods),
+			calls,
+		),
+	)
+	file.P("}\n")
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+const entitiesPackage = protogen.GoImportPath("github.com/faunists/deal-go/entities")
+
+// grpcErrorRepresentation returns the entities.GRPCError literal of the contract error,
+// which is given to the ErrorComparer. The empty fields are left out.
+func grpcErrorRepresentation(file *protogen.GeneratedFile, grpcError entities.GRPCError) string {
+	fields := []string{fmt.Sprintf("ErrorCode: %q", grpcError.ErrorCode)}
+	if len(grpcError.AlternativeCodes) > 0 {
+		codes := make([]string, 0, len(grpcError.AlternativeCodes))
+		for _, code := range grpcError.AlternativeCodes {
+			codes = append(codes, fmt.Sprintf("%q", code))
+		}
+
+		fields = append(
+			fields,
+			fmt.Sprintf(
+				"AlternativeCodes: []%s{%s}",
+				file.QualifiedGoIdent(entitiesPackage.Ident("ErrorCode")),
+				strings.Join(codes, ", "),
+			),
+		)
+	}
+	if grpcError.Message != "" {
+		fields = append(fields, fmt.Sprintf("Message: %q", grpcError.Message))
+	}
+	if len(grpcError.MessageArgs) > 0 {
+		fields = append(
+			fields, fmt.Sprintf("MessageArgs: %s", stringListRepresentation(grpcError.MessageArgs)),
+		)
+	}
+	if !grpcError.Details.IsEmpty() {
+		fields = append(
+			fields, fmt.Sprintf("Details: %s", errorDetailsEntityRepresentation(file, grpcError.Details)),
+		)
+	}
+
+	return fmt.Sprintf(
+		"%s{\n%s,\n}",
+		file.QualifiedGoIdent(entitiesPackage.Ident("GRPCError")),
+		strings.Join(fields, ",\n"),
+	)
+}
+
+// errorDetailsEntityRepresentation returns the entities.ErrorDetails literal of the details
+func errorDetailsEntityRepresentation(
+	file *protogen.GeneratedFile,
+	details *entities.ErrorDetails,
+) string {
+	fields := make([]string, 0)
+	if len(details.BadRequest) > 0 {
+		violations := make([]string, 0, len(details.BadRequest))
+		for _, violation := range details.BadRequest {
+			violations = append(
+				violations,
+				fmt.Sprintf("{Field: %q, Description: %q}", violation.Field, violation.Description),
+			)
+		}
+
+		fields = append(
+			fields,
+			fmt.Sprintf(
+				"BadRequest: []%s{%s}",
+				file.QualifiedGoIdent(entitiesPackage.Ident("FieldViolation")),
+				strings.Join(violations, ", "),
+			),
+		)
+	}
+	if len(details.PreconditionFailure) > 0 {
+		violations := make([]string, 0, len(details.PreconditionFailure))
+		for _, violation := range details.PreconditionFailure {
+			violations = append(
+				violations,
+				fmt.Sprintf(
+					"{Type: %q, Subject: %q, Description: %q}",
+					violation.Type, violation.Subject, violation.Description,
+				),
+			)
+		}
+
+		fields = append(
+			fields,
+			fmt.Sprintf(
+				"PreconditionFailure: []%s{%s}",
+				file.QualifiedGoIdent(entitiesPackage.Ident("PreconditionViolation")),
+				strings.Join(violations, ", "),
+			),
+		)
+	}
+	if details.ErrorInfo != nil {
+		fields = append(
+			fields,
+			fmt.Sprintf(
+				"ErrorInfo: &%s{Reason: %q, Domain: %q, Metadata: %s}",
+				file.QualifiedGoIdent(entitiesPackage.Ident("ErrorInfo")),
+				details.ErrorInfo.Reason,
+				details.ErrorInfo.Domain,
+				stringMapRepresentation(details.ErrorInfo.Metadata),
+			),
+		)
+	}
+	if len(details.QuotaFailure) > 0 {
+		violations := make([]string, 0, len(details.QuotaFailure))
+		for _, violation := range details.QuotaFailure {
+			violations = append(
+				violations,
+				fmt.Sprintf(
+					"{Subject: %q, Description: %q}", violation.Subject, violation.Description,
+				),
+			)
+		}
+
+		fields = append(
+			fields,
+			fmt.Sprintf(
+				"QuotaFailure: []%s{%s}",
+				file.QualifiedGoIdent(entitiesPackage.Ident("QuotaViolation")),
+				strings.Join(violations, ", "),
+			),
+		)
+	}
+	if details.RetryDelay != "" {
+		fields = append(fields, fmt.Sprintf("RetryDelay: %q", details.RetryDelay))
+	}
+
+	return fmt.Sprintf(
+		"&%s{\n%s,\n}",
+		file.QualifiedGoIdent(entitiesPackage.Ident("ErrorDetails")),
+		strings.Join(fields, ",\n"),
+	)
+}
+
+// stringListRepresentation returns a []string literal of the values
+func stringListRepresentation(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+
+	return fmt.Sprintf("[]string{%s}", strings.Join(quoted, ", "))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+// the chained interceptors dial options were added by grpc-go 1.21
+var chainInterceptorsVersion = processors.Version{Major: 1, Minor: 21}
+
+// the chained interceptors server options were added by grpc-go 1.28
+var chainServerInterceptorsVersion = processors.Version{Major: 1, Minor: 28}
+
+// generateInterceptorOptions creates the contract test options adding interceptors to the
+// client and to the server of the server tests. Each option is skipped when the grpc-go
+// version can't chain its interceptors.
+func generateInterceptorOptions(file *protogen.GeneratedFile, service *protogen.Service) error {
+	version, err := processors.ParseVersion(*grpcVersion)
+	if err != nil {
+		return fmt.Errorf("invalid grpc-version option: %w", err)
+	}
+
+	interceptors := []struct {
+		kind       string
+		side       string
+		option     string
+		testOption string
+		since      processors.Version
+	}{
+		{
+			kind:       "Unary",
+			side:       "Client",
+			option:     "WithChainUnaryInterceptor",
+			testOption: "WithDialOptions",
+			since:      chainInterceptorsVersion,
+		},
+		{
+			kind:       "Stream",
+			side:       "Client",
+			option:     "WithChainStreamInterceptor",
+			testOption: "WithDialOptions",
+			since:      chainInterceptorsVersion,
+		},
+		{
+			kind:       "Unary",
+			side:       "Server",
+			option:     "ChainUnaryInterceptor",
+			testOption: "WithServerOptions",
+			since:      chainServerInterceptorsVersion,
+		},
+		{
+			kind:       "Stream",
+			side:       "Server",
+			option:     "ChainStreamInterceptor",
+			testOption: "WithServerOptions",
+			since:      chainServerInterceptorsVersion,
+		},
+	}
+	for _, interceptor := range interceptors {
+		if !version.AtLeast(interceptor.since) {
+			continue
+		}
+
+		optionName := fmt.Sprintf(
+			"With%s%s%sInterceptors",
+			processors.MakeExportedName(service.GoName),
+			interceptor.kind,
+			interceptor.side,
+		)
+		file.P(
+			fmt.Sprintf(
+				"// %s adds %s interceptors to the %s of the %s contract tests,\n"+
+					"// they run in the given order",
+				optionName,
+				processors.MakeUnexportedName(interceptor.kind),
+				processors.MakeUnexportedName(interceptor.side),
+				service.GoName,
+			),
+		)
+		if interceptor.side == "Server" {
+			file.P(
+				fmt.Sprintf(
+					"//\n// The server is the one created by %sContractTestWithService.",
+					processors.MakeExportedName(service.GoName),
+				),
+			)
+		}
+		file.P(
+			fmt.Sprintf(
+				"func %s(interceptors ...%s) %s {\nreturn %s(%s(interceptors...))\n}",
+				optionName,
+				file.QualifiedGoIdent(
+					grpcPackage.Ident(
+						fmt.Sprintf("%s%sInterceptor", interceptor.kind, interceptor.side),
+					),
+				),
+				file.QualifiedGoIdent(dealtestPackage.Ident("ContractTestOption")),
+				file.QualifiedGoIdent(dealtestPackage.Ident(interceptor.testOption)),
+				file.QualifiedGoIdent(grpcPackage.Ident(interceptor.option)),
+			),
+		)
+		file.P()
+	}
+
+	return nil
+}
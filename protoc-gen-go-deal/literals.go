@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+// fieldArgument returns the keyed element setting the field in the message literal,
+// e.g. `Name: "john"`
+func fieldArgument(
+	file *protogen.GeneratedFile,
+	field *protogen.Field,
+	value protoreflect.Value,
+) (string, error) {
+	fieldValue, err := fieldValueRepresentation(file, field, value)
+	if err != nil {
+		return "", err
+	}
+
+	// A oneof member isn't a field of the message struct, it must be set
+	// through the wrapper type generated for the variant
+	if isOneofMember(field) {
+		return fmt.Sprintf(
+			"%s: &%s{%s: %s}",
+			field.Oneof.GoName,
+			file.QualifiedGoIdent(field.GoIdent),
+			field.GoName,
+			fieldValue,
+		), nil
+	}
+
+	return fmt.Sprintf("%s: %s", field.GoName, fieldValue), nil
+}
+
+// fieldValueRepresentation returns the Go literal of the given field value,
+// e.g. `map[string]int64{"a": 1}` for a map field
+func fieldValueRepresentation(
+	file *protogen.GeneratedFile,
+	field *protogen.Field,
+	value protoreflect.Value,
+) (string, error) {
+	switch {
+	case field.Desc.Kind() == protoreflect.GroupKind:
+		return "", fmt.Errorf(
+			"field %s is a group or a delimited message, which isn't supported",
+			field.Desc.FullName(),
+		)
+	case field.Desc.IsMap():
+		return mapRepresentation(file, field, value.Map())
+	case field.Desc.IsList():
+		return listRepresentation(file, field, value.List())
+	case isPointerField(field):
+		return pointerRepresentation(file, field, value)
+	default:
+		return singularValueRepresentation(file, field, value)
+	}
+}
+
+// isPointerField reports whether the struct field of a scalar is a pointer, which happens
+// when the field tracks its presence, e.g. a proto3 `optional` field. The bytes fields are
+// the exception, a nil slice already represents the absence.
+func isPointerField(field *protogen.Field) bool {
+	return field.Desc.HasPresence() &&
+		field.Message == nil &&
+		field.Desc.Kind() != protoreflect.BytesKind &&
+		!isOneofMember(field)
+}
+
+// pointerRepresentation returns a pointer to the scalar value of the field,
+// e.g. `proto.Int32(5)` or `Status_ACTIVE.Enum()`
+func pointerRepresentation(
+	file *protogen.GeneratedFile,
+	field *protogen.Field,
+	value protoreflect.Value,
+) (string, error) {
+	fieldValue, err := singularValueRepresentation(file, field, value)
+	if err != nil {
+		return "", err
+	}
+
+	if field.Enum != nil {
+		return fmt.Sprintf("%s.Enum()", fieldValue), nil
+	}
+
+	return fmt.Sprintf(
+		"%s(%s)",
+		file.QualifiedGoIdent(protoPackage.Ident(processors.MakeExportedName(goType(file, field)))),
+		fieldValue,
+	), nil
+}
+
+// listRepresentation returns the Go literal of a repeated field, the type of the message
+// elements is elided unless they're built by a constructor, e.g. `[]*Foo{{Bar: 1}}`
+func listRepresentation(
+	file *protogen.GeneratedFile,
+	field *protogen.Field,
+	value protoreflect.List,
+) (string, error) {
+	elements := make([]string, 0, value.Len())
+	for index := 0; index < value.Len(); index++ {
+		if field.Message == nil || isWellKnown(field.Message) {
+			element, err := singularValueRepresentation(file, field, value.Get(index))
+			if err != nil {
+				return "", err
+			}
+			elements = append(elements, element)
+			continue
+		}
+
+		messageArguments, err := inputOutputToString(value.Get(index).Message(), field.Message, file)
+		if err != nil {
+			return "", err
+		}
+		elements = append(elements, fmt.Sprintf("{%s}", strings.Join(messageArguments, ",")))
+	}
+
+	return fmt.Sprintf("[]%s{%s}", goType(file, field), strings.Join(elements, ",")), nil
+}
+
+// mapRepresentation returns the Go literal of a map field, the entries are sorted by key and
+// the type of the message values is elided as in the lists, e.g. `map[string]*Foo{"a": {Bar: 1}}`
+func mapRepresentation(
+	file *protogen.GeneratedFile,
+	field *protogen.Field,
+	value protoreflect.Map,
+) (string, error) {
+	keyField, valueField := field.Message.Fields[0], field.Message.Fields[1]
+
+	entries := make([]string, 0, value.Len())
+	for _, key := range processors.SortedMapKeys(value) {
+		entryValue, err := mapValueRepresentation(file, valueField, value.Get(key))
+		if err != nil {
+			return "", err
+		}
+
+		entries = append(
+			entries,
+			fmt.Sprintf("%s: %s", processors.FormatFieldValue(key.Value()), entryValue),
+		)
+	}
+
+	return fmt.Sprintf(
+		"map[%s]%s{%s}",
+		goType(file, keyField),
+		goType(file, valueField),
+		strings.Join(entries, ","),
+	), nil
+}
+
+// mapValueRepresentation returns the Go literal of a map value, eliding the type of messages
+// which aren't built by a constructor
+func mapValueRepresentation(
+	file *protogen.GeneratedFile,
+	valueField *protogen.Field,
+	value protoreflect.Value,
+) (string, error) {
+	if valueField.Message == nil || isWellKnown(valueField.Message) {
+		return singularValueRepresentation(file, valueField, value)
+	}
+
+	messageArguments, err := inputOutputToString(value.Message(), valueField.Message, file)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(messageArguments, ",")), nil
+}
+
+// singularValueRepresentation returns the Go literal of a single value of the field,
+// messages are represented by a pointer to their struct, recursing into their fields
+func singularValueRepresentation(
+	file *protogen.GeneratedFile,
+	field *protogen.Field,
+	value protoreflect.Value,
+) (string, error) {
+	switch {
+	case field.Message != nil:
+		representation, isWellKnown, err := wellKnownRepresentation(
+			file, field.Message, value.Message(),
+		)
+		if err != nil || isWellKnown {
+			return representation, err
+		}
+		return messageRepresentation(value.Message(), field.Message, file)
+	case field.Enum != nil:
+		return enumRepresentation(file, field.Enum, value.Enum()), nil
+	case field.Desc.Kind() == protoreflect.FloatKind || field.Desc.Kind() == protoreflect.DoubleKind:
+		return floatRepresentation(file, field, value), nil
+	default:
+		return processors.FormatFieldValue(value), nil
+	}
+}
+
+// floatRepresentation returns the literal of a float value, NaN and the infinities have no
+// literal so they're built by the math package, e.g. `float32(math.Inf(-1))`
+func floatRepresentation(
+	file *protogen.GeneratedFile,
+	field *protogen.Field,
+	value protoreflect.Value,
+) string {
+	var representation string
+	switch number := value.Float(); {
+	case math.IsNaN(number):
+		representation = file.QualifiedGoIdent(mathPackage.Ident("NaN")) + "()"
+	case math.IsInf(number, 1):
+		representation = file.QualifiedGoIdent(mathPackage.Ident("Inf")) + "(1)"
+	case math.IsInf(number, -1):
+		representation = file.QualifiedGoIdent(mathPackage.Ident("Inf")) + "(-1)"
+	default:
+		return processors.FormatFieldValue(value)
+	}
+
+	if field.Desc.Kind() == protoreflect.FloatKind {
+		return fmt.Sprintf("float32(%s)", representation)
+	}
+
+	return representation
+}
+
+// enumRepresentation returns the constant of the enum value, the number is converted to
+// the enum type when the value isn't declared, e.g. `Status(7)`
+func enumRepresentation(
+	file *protogen.GeneratedFile,
+	enum *protogen.Enum,
+	number protoreflect.EnumNumber,
+) string {
+	for _, value := range enum.Values {
+		if value.Desc.Number() == number {
+			return file.QualifiedGoIdent(value.GoIdent)
+		}
+	}
+
+	return fmt.Sprintf("%s(%d)", file.QualifiedGoIdent(enum.GoIdent), number)
+}
+
+// goType returns the Go type of a single value of the field
+func goType(file *protogen.GeneratedFile, field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return "bool"
+	case protoreflect.EnumKind:
+		return file.QualifiedGoIdent(field.Enum.GoIdent)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64"
+	case protoreflect.FloatKind:
+		return "float32"
+	case protoreflect.DoubleKind:
+		return "float64"
+	case protoreflect.StringKind:
+		return "string"
+	case protoreflect.BytesKind:
+		return "[]byte"
+	default:
+		return "*" + file.QualifiedGoIdent(field.Message.GoIdent)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// contractLocation tells where the contract code of a proto file is generated
+type contractLocation struct {
+	filenamePrefix string
+	importPath     protogen.GoImportPath
+	packageName    protogen.GoPackageName
+}
+
+// newContractLocation returns the location of the contract code, it's the package of the proto
+// file unless a sub-package is provided, e.g. `userv1contract` generates the code into the
+// `userv1contract` directory next to the proto generated code.
+func newContractLocation(file *protogen.File, subPackage string) (contractLocation, error) {
+	if subPackage == "" {
+		return contractLocation{
+			filenamePrefix: file.GeneratedFilenamePrefix,
+			importPath:     file.GoImportPath,
+			packageName:    file.GoPackageName,
+		}, nil
+	}
+
+	if !token.IsIdentifier(subPackage) {
+		return contractLocation{}, fmt.Errorf("invalid contract package name %q", subPackage)
+	}
+
+	return contractLocation{
+		filenamePrefix: path.Join(
+			path.Dir(file.GeneratedFilenamePrefix),
+			subPackage,
+			path.Base(file.GeneratedFilenamePrefix),
+		),
+		importPath:  protogen.GoImportPath(path.Join(string(file.GoImportPath), subPackage)),
+		packageName: protogen.GoPackageName(subPackage),
+	}, nil
+}
+
+// serviceIdent returns an identifier generated by `protoc-gen-go-grpc` for the service,
+// the name format receives the service name, e.g. "New%sClient"
+func serviceIdent(
+	importPath protogen.GoImportPath,
+	nameFormat string,
+	service *protogen.Service,
+) protogen.GoIdent {
+	return importPath.Ident(fmt.Sprintf(nameFormat, service.GoName))
+}
@@ -5,12 +5,13 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
+	"sort"
 	"strings"
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
 	"google.golang.org/protobuf/types/dynamicpb"
 	"google.golang.org/protobuf/types/pluginpb"
 
@@ -22,12 +23,17 @@ const (
 	contextPackage = protogen.GoImportPath("context")
 	testingPackage = protogen.GoImportPath("testing")
 	logPackage     = protogen.GoImportPath("log")
+	mathPackage    = protogen.GoImportPath("math")
 	netPackage     = protogen.GoImportPath("net")
+	timePackage    = protogen.GoImportPath("time")
 	grpcPackage    = protogen.GoImportPath("google.golang.org/grpc")
 	grpcCodes      = protogen.GoImportPath("google.golang.org/grpc/codes")
 	grpcStatus     = protogen.GoImportPath("google.golang.org/grpc/status")
 	protoPackage   = protogen.GoImportPath("google.golang.org/protobuf/proto")
 	buffconPackage = protogen.GoImportPath("google.golang.org/grpc/test/bufconn")
+
+	dealtestPackage  = protogen.GoImportPath("github.com/faunists/deal-go/dealtest")
+	dealcallsPackage = protogen.GoImportPath("github.com/faunists/deal-go/dealcalls")
 )
 
 var (
@@ -35,72 +41,223 @@ var (
 	testingT       = testingPackage.Ident("T")
 )
 
-func main() { //nolint:gocognit // this function set flags and verify them, after generate the code
-	var flags flag.FlagSet
+var (
+	flags flag.FlagSet
+
+	contractFilePath = flags.String("contract-file", "", "Path to your contract file")
+	contractDir      = flags.String(
+		"contract-dir", "", "Directory of the contract files, named after the contract-pattern",
+	)
+	contractPattern = flags.String(
+		"contract-pattern", defaultContractPattern, "Path of a service contract in contract-dir",
+	)
+	serviceContracts = make(serviceContractFiles)
+	selectedServices = make(serviceNames)
+	skippedServices  = make(serviceNames)
+	fakeSeed         = flags.Int64("fake-seed", 0, "Seed used to generate the fake values")
+	testFiles        = flags.Bool(
+		"test-files", false, "Generate the server tests into a separated _test.go file",
+	)
+	contractPackage = flags.String(
+		"contract-package", "", "Generate the contract code into the given sub-package",
+	)
+	buildTags = flags.String(
+		"build-tags", "", "Build constraint added to the generated files, e.g. contracttest",
+	)
+	parallelTests = flags.Bool(
+		"parallel-tests", false, "Run the cases of the server tests in parallel",
+	)
+	perServiceFiles = flags.Bool(
+		"per-service-files", false, "Generate the contract code of each service in its own file",
+	)
+	grpcVersion = flags.String(
+		"grpc-version", "1.63", "Minimum grpc-go version supported by the generated code",
+	)
+	genClient = flags.Bool(
+		"gen-client", true, "Generate the contract client and the contract server",
+	)
+	genServerTest = flags.Bool(
+		"gen-server-test", true, "Generate the server tests verifying a server against the contract",
+	)
+	fileSuffix = flags.String(
+		"file-suffix", defaultFileSuffix, "Suffix of the generated contract files",
+	)
+	testFileSuffix = flags.String(
+		"test-file-suffix", defaultTestFileSuffix, "Suffix of the generated test files",
+	)
+	missingContract = flags.String(
+		"missing-contract",
+		ignoreCoverage,
+		"Report of the services without contract: ignore, warn or fail",
+	)
+	unusedContract = flags.String(
+		"unused-contract",
+		ignoreCoverage,
+		"Report of the contract entries matching no service or method: ignore, warn or fail",
+	)
+	debug = flags.Bool(
+		"debug", false, "Log the contract files, services and methods handled by the plugin",
+	)
+	asserts = flags.String(
+		"asserts", dealtestAsserts, "Assertion style of the server tests: dealtest or testify",
+	)
+	manifest = flags.Bool(
+		"manifest", false, "Write a JSON manifest describing the generated contract code",
+	)
+)
 
-	contractFilePath := flags.String("contract-file", "", "Path to your contract file")
+func init() {
+	flags.Var(serviceContracts, "contract", "Contract file of a service, e.g. UserService:user.json")
+	flags.Var(selectedServices, "services", "Service whose contract code is generated")
+	flags.Var(skippedServices, "skip-services", "Service whose contract code isn't generated")
+}
 
+func main() { //nolint:gocognit // this function set flags and verify them, after generate the code
 	protogen.Options{
 		ParamFunc: flags.Set,
 	}.Run(func(plugin *protogen.Plugin) error {
-		plugin.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		plugin.SupportedFeatures = uint64(
+			pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL |
+				pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS,
+		)
+		plugin.SupportedEditionsMinimum = descriptorpb.Edition_EDITION_PROTO2
+		plugin.SupportedEditionsMaximum = descriptorpb.Edition_EDITION_2023
+
+		var err error
+		contracts, err = newContractResolver(
+			*contractFilePath, *contractDir, *contractPattern, serviceContracts,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := validateAsserts(*asserts); err != nil {
+			return err
+		}
+
+		if err := validateCoverageOption("missing-contract", *missingContract); err != nil {
+			return err
+		}
+
+		if err := validateCoverageOption("unused-contract", *unusedContract); err != nil {
+			return err
+		}
 
-		if *contractFilePath == "" {
-			return fmt.Errorf("'contract-file' option not provided")
+		if err := validateFileSuffixes(*fileSuffix, *testFileSuffix); err != nil {
+			return err
+		}
+
+		if !*genClient && !*genServerTest {
+			return fmt.Errorf("'gen-client' and 'gen-server-test' options can't both be false")
 		}
 
+		knownMessages = newMessageRegistry(plugin.Files)
+
 		for _, file := range plugin.Files {
 			if file.Generate {
-				_, err := generateContracts(plugin, file, *contractFilePath)
+				_, err := generateContracts(plugin, file)
 				if err != nil {
 					return err
 				}
 			}
 		}
 
-		return nil
+		return reportUnusedContractEntries(
+			*unusedContract, unusedContractEntries(contracts.files, plugin.Files),
+		)
 	})
 }
 
 func generateContracts( //nolint:gocognit // This function is simple enough to keep it as is
 	plugin *protogen.Plugin,
 	file *protogen.File,
-	contractFilePath string,
 ) (*protogen.GeneratedFile, error) {
 	if len(file.Services) == 0 {
+		debugf("file %s: no services, skipped", file.Desc.Path())
 		return nil, nil
 	}
 
-	// Parse contract JSON file that was defined in the service options
-	rawContract, err := processors.ReadContractFile(contractFilePath)
+	location, err := newContractLocation(file, *contractPackage)
 	if err != nil {
 		return nil, err
 	}
 
-	filename := fmt.Sprintf("%s_contract.pb.go", file.GeneratedFilenamePrefix)
-	newFile := plugin.NewGeneratedFile(filename, file.GoImportPath)
+	constraintLines, err := buildConstraintLines(*buildTags)
+	if err != nil {
+		return nil, err
+	}
 
-	writeHeader(file, newFile)
+	// The contract code of every service is generated into the same file,
+	// unless each service must have its own file
+	var newFile, testFile *protogen.GeneratedFile
+	if !*perServiceFiles {
+		newFile, testFile = newContractFiles(
+			plugin, location, constraintLines, location.filenamePrefix,
+		)
+	}
+	fileManifest := newContractManifest(file, location)
 
 	for _, service := range file.Services {
-		// Verifies if the file has a contract for the given service
-		serviceContract, hasContract := rawContract.Services[service.GoName]
-		if !hasContract {
+		if !isServiceSelected(service) {
+			debugf("service %s: skipped by the services options", service.Desc.FullName())
 			continue
 		}
 
-		err = generateClient(newFile, service, serviceContract)
+		// Verifies if there's a contract for the given service
+		serviceContract, hasContract, err := contracts.serviceContract(file, service)
 		if err != nil {
 			return nil, err
 		}
+		if !hasContract {
+			err = reportCoverage(
+				*missingContract, "service %s has no contract", service.Desc.FullName(),
+			)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		debugServiceContract(service, serviceContract)
 
-		err = generateStubServer(newFile, service, serviceContract)
-		if err != nil {
-			return nil, err
+		filenamePrefix := location.filenamePrefix
+		if *perServiceFiles {
+			filenamePrefix = fmt.Sprintf(
+				"%s_%s", location.filenamePrefix, processors.MakeSnakeCaseName(service.GoName),
+			)
+			newFile, testFile = newContractFiles(plugin, location, constraintLines, filenamePrefix)
 		}
 
-		err = generateServerTest(newFile, service, serviceContract)
-		if err != nil {
+		if *manifest {
+			err = fileManifest.addService(service, serviceContract, filenamePrefix)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if *genClient {
+			generateCallCounter(newFile, service)
+
+			err = generateClient(newFile, file.GoImportPath, service, serviceContract)
+			if err != nil {
+				return nil, err
+			}
+
+			err = generateContractServer(newFile, file.GoImportPath, service, serviceContract)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if *genServerTest {
+			err = generateServerTest(testFile, file.GoImportPath, service, serviceContract)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if *manifest {
+		if err := writeContractManifest(plugin, location, fileManifest); err != nil {
 			return nil, err
 		}
 	}
@@ -108,25 +265,101 @@ func generateContracts( //nolint:gocognit // This function is simple enough to k
 	return newFile, nil
 }
 
-func writeHeader(originalFile *protogen.File, generatedFile *protogen.GeneratedFile) {
+// newContractFiles creates the file receiving the contract code and the one receiving the
+// server tests, they're the same file unless the tests must be generated into a test file
+func newContractFiles(
+	plugin *protogen.Plugin,
+	location contractLocation,
+	constraintLines []string,
+	filenamePrefix string,
+) (*protogen.GeneratedFile, *protogen.GeneratedFile) {
+	filename := filenamePrefix + *fileSuffix
+	newFile := plugin.NewGeneratedFile(filename, location.importPath)
+	writeHeader(location.packageName, constraintLines, newFile)
+
+	// The server tests can be kept out of the production builds
+	// by generating them in a test file of the same package
+	if !*testFiles {
+		return newFile, newFile
+	}
+
+	testFilename := filenamePrefix + *testFileSuffix
+	testFile := plugin.NewGeneratedFile(testFilename, location.importPath)
+	writeHeader(location.packageName, constraintLines, testFile)
+
+	// The file left empty by the generation options isn't written
+	if !*genClient {
+		newFile.Skip()
+	}
+	if !*genServerTest {
+		testFile.Skip()
+	}
+
+	return newFile, testFile
+}
+
+func writeHeader(
+	packageName protogen.GoPackageName,
+	constraintLines []string,
+	generatedFile *protogen.GeneratedFile,
+) {
+	if len(constraintLines) > 0 {
+		for _, line := range constraintLines {
+			generatedFile.P(line)
+		}
+		generatedFile.P()
+	}
+
 	generatedFile.P("// Code generated by protoc-gen-go-deal. DO NOT EDIT.")
 	generatedFile.P("//")
 	generatedFile.P("// versions:")
 	generatedFile.P("//   - protoc")
 	generatedFile.P()
-	generatedFile.P(fmt.Sprintf("package %s", originalFile.GoPackageName))
+	generatedFile.P(fmt.Sprintf("package %s", packageName))
 	generatedFile.P()
 }
 
 func generateClient(
 	file *protogen.GeneratedFile,
+	serviceImportPath protogen.GoImportPath,
 	service *protogen.Service,
 	contractService entities.Service,
 ) error {
 	clientName := fmt.Sprintf("%sContractClient", processors.MakeExportedName(service.GoName))
 
-	// Create client struct
-	file.P(fmt.Sprintf("type %s struct {}", clientName))
+	// Create client struct, its methods have value receivers so the zero value implements
+	// the client interface, the calls are recorded and the sequenced cases counted by its state
+	file.P(
+		fmt.Sprintf(
+			"// %s implements %s returning the results declared by the contract.\n"+
+				"// The zero values share their recorded calls and sequenced cases, "+
+				"New%s creates a client with its own.",
+			clientName,
+			serviceIdent(serviceImportPath, "%sClient", service).GoName,
+			clientName,
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"type %s struct {\nstate *%s%s\n}",
+			clientName,
+			clientStateName(service),
+			generateCallbacksFields(file, service),
+		),
+	)
+	file.P()
+	file.P(
+		fmt.Sprintf(
+			"var _ %s = %s{}",
+			file.QualifiedGoIdent(serviceIdent(serviceImportPath, "%sClient", service)),
+			clientName,
+		),
+	)
+	file.P()
+
+	generateClientState(file, service, clientName)
+	generateClientOptions(file, service, clientName)
+	generateRecorderMethods(file, clientName)
 
 	// Iterate over the service methods and generate the proper method containing a
 	// switch case based on the Request/Response provided by the user through JSON File
@@ -135,20 +368,21 @@ func generateClient(
 		// 'cause the method will be created with a default switch case in order to satisfy
 		// the client interface generated by `protoc-gen-go-grpc`.
 		methodContract := contractService[method.GoName]
-		switchCase, err := generateClientCases(file, method, methodContract)
+		switchCase, err := generateClientCases(file, method, methodContract, clientTarget)
 		if err != nil {
 			return err
 		}
 
 		file.P(
 			fmt.Sprintf(
-				"func (_ %s) %s(ctx %s, in *%s, opts ...%s) (*%s, error) {%s}",
+				"func (c %s) %s(ctx %s, in *%s, opts ...%s) (*%s, error) {%s%s}",
 				clientName,
 				method.GoName,
 				file.QualifiedGoIdent(contextContext),
 				file.QualifiedGoIdent(method.Input.GoIdent),
 				file.QualifiedGoIdent(grpcPackage.Ident("CallOption")),
 				file.QualifiedGoIdent(method.Output.GoIdent),
+				generateRecordCall(file, method),
 				switchCase,
 			),
 		)
@@ -157,32 +391,70 @@ func generateClient(
 	return nil
 }
 
-func generateStubServer(
+// generateContractServer creates a server implementing the service server interface
+// from the contract cases, so it can be registered as a fake of the real server
+func generateContractServer(
 	file *protogen.GeneratedFile,
+	serviceImportPath protogen.GoImportPath,
 	service *protogen.Service,
 	contractService entities.Service,
 ) error {
-	clientName := fmt.Sprintf("%sStubServer", processors.MakeExportedName(service.GoName))
+	serverName := fmt.Sprintf("%sContractServer", processors.MakeExportedName(service.GoName))
 
-	// Create client struct
-	file.P(fmt.Sprintf("type %s struct {\nUnimplemented%sServer\n}", clientName, service.GoName))
+	// Create server struct, its methods have value receivers so the zero value implements
+	// the server interface, the sequenced cases are counted by the counter it points to
+	file.P(
+		fmt.Sprintf(
+			"// %s implements %s returning the results declared by the contract.\n"+
+				"// The zero values share their sequenced cases, "+
+				"New%s creates a server with its own.",
+			serverName,
+			serviceIdent(serviceImportPath, "%sServer", service).GoName,
+			serverName,
+		),
+	)
+	file.P(
+		fmt.Sprintf(
+			"type %s struct {\n%s\ncalls *%s\n}",
+			serverName,
+			file.QualifiedGoIdent(serviceIdent(serviceImportPath, "Unimplemented%sServer", service)),
+			callCounterName(service),
+		),
+	)
+	file.P()
+	file.P(
+		fmt.Sprintf(
+			"var _ %s = %s{}",
+			file.QualifiedGoIdent(serviceIdent(serviceImportPath, "%sServer", service)),
+			serverName,
+		),
+	)
+	file.P()
+
+	generateServerCallCounter(file, service, serverName)
+
+	stubServerName := fmt.Sprintf("%sStubServer", processors.MakeExportedName(service.GoName))
+	file.P(fmt.Sprintf("// %s is the former name of %s.\n//", stubServerName, serverName))
+	file.P(fmt.Sprintf("// Deprecated: use %s instead.", serverName))
+	file.P(fmt.Sprintf("type %s = %s", stubServerName, serverName))
+	file.P()
 
 	// Iterate over the service methods and generate the proper method containing a
 	// switch case based on the Request/Response provided by the user through JSON File
 	for _, method := range service.Methods {
 		// We don't need to care about if a contract to the specific method exists or not,
 		// 'cause the method will be created with a default switch case in order to satisfy
-		// the client interface generated by `protoc-gen-go-grpc`.
+		// the server interface generated by `protoc-gen-go-grpc`.
 		methodContract := contractService[method.GoName]
-		switchCase, err := generateClientCases(file, method, methodContract)
+		switchCase, err := generateClientCases(file, method, methodContract, serverTarget)
 		if err != nil {
 			return err
 		}
 
 		file.P(
 			fmt.Sprintf(
-				"func (%s) %s(ctx %s, in *%s) (*%s, error) {%s}",
-				clientName,
+				"func (c %s) %s(ctx %s, in *%s) (*%s, error) {%s}",
+				serverName,
 				method.GoName,
 				file.QualifiedGoIdent(contextContext),
 				file.QualifiedGoIdent(method.Input.GoIdent),
@@ -195,24 +467,61 @@ func generateStubServer(
 	return nil
 }
 
+// clientCase represents a single switch case of a generated method
+type clientCase struct {
+	priority int
+	code     string
+}
+
 func generateClientCases(
 	file *protogen.GeneratedFile,
 	method *protogen.Method,
 	methodContract entities.Method,
+	target caseTarget,
 ) (string, error) {
-	switchCase := bytes.NewBufferString("switch {")
-
-	err := generateSuccessCases(file, method, methodContract.SuccessCases, switchCase)
+	successCases, err := generateSuccessCases(file, method, methodContract.SuccessCases, target)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate the success cases: %w", err)
 	}
 
-	err = generateFailureCases(file, method, methodContract.FailureCases, switchCase)
+	applicationErrorCases, err := generateApplicationErrorCases(
+		file, method, methodContract.ApplicationErrorCases, target,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate the application error cases: %w", err)
+	}
+
+	failureCases, err := generateFailureCases(file, method, methodContract.FailureCases, target)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate the failure cases: %w", err)
 	}
 
-	// Default case if no cases are provided
+	// Cases with a higher priority must be evaluated first, the stable sort keeps the
+	// declaration order (success, application error and failure cases) for equal priorities.
+	cases := append(successCases, applicationErrorCases...)
+	cases = append(cases, failureCases...)
+	sort.SliceStable(cases, func(i, j int) bool {
+		return cases[i].priority > cases[j].priority
+	})
+
+	switchCase := bytes.NewBufferString("switch {")
+	for _, c := range cases {
+		switchCase.WriteString(c.code)
+	}
+
+	// Default case if no cases are provided, the server must answer with an error
+	// since GRPC can't send a nil response
+	if target == serverTarget {
+		switchCase.WriteString(
+			fmt.Sprintf(
+				"default:\nreturn nil, %s(%s, %q) }",
+				file.QualifiedGoIdent(grpcStatus.Ident("Error")),
+				file.QualifiedGoIdent(grpcCodes.Ident("Unimplemented")),
+				fmt.Sprintf("no contract case matches the %s request", method.GoName),
+			),
+		)
+		return switchCase.String(), nil
+	}
 	switchCase.WriteString("default: return nil, nil }")
 
 	return switchCase.String(), nil
@@ -222,75 +531,216 @@ func generateSuccessCases(
 	file *protogen.GeneratedFile,
 	method *protogen.Method,
 	cases []entities.SuccessCase,
-	writer io.StringWriter,
-) error {
-	for _, successCase := range cases {
-		requestRepresentation, err := getProtoRepresentation(
-			successCase.Request, method.Input, file,
+	target caseTarget,
+) ([]clientCase, error) {
+	clientCases := make([]clientCase, 0, len(cases))
+	for index, successCase := range cases {
+		requestMatcher, err := generateRequestMatcher(
+			file, method.Input, successCase.Request, successCase.Oneofs,
 		)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		responseRepresentation, err := getProtoRepresentation(
-			successCase.Response, method.Output, file,
-		)
+		delay, err := generateDelay(file, successCase.Delay)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		_, err = writer.WriteString(
-			fmt.Sprintf(
-				"case %s(in, %s):\n// Description: %s\n return %s, nil\n",
-				file.QualifiedGoIdent(protoPackage.Ident("Equal")),
-				requestRepresentation,
+		if len(successCase.Responses) > 0 && successCase.Response != nil {
+			return nil, fmt.Errorf(
+				"case %q must provide either a response or responses", successCase.Description,
+			)
+		}
+
+		if successCase.FailFirst != nil && successCase.FailFirst.Times <= 0 {
+			return nil, fmt.Errorf(
+				"case %q must fail at least one time when failFirst is provided",
 				successCase.Description,
-				responseRepresentation,
-			),
-		)
+			)
+		}
+
+		// The failures declared by FailFirst are simulated by the client only, the
+		// contract server must pass the server tests generated from the same contract
+		sequencedCase := successCase
+		if target == serverTarget {
+			sequencedCase.FailFirst = nil
+		}
+
+		var result string
+		if steps := sequencedCase.Sequence(); len(steps) > 0 {
+			caseKey := fmt.Sprintf("%s/%s/%d", method.GoName, successCasesKey, index)
+			result, err = generateSequenceResults(file, method, caseKey, steps, target)
+		} else {
+			result, err = generateResponseResult(file, method, successCase.Response)
+		}
 		if err != nil {
-			return fmt.Errorf("error writing a success case: %w", err)
+			return nil, err
 		}
+
+		clientCases = append(clientCases, clientCase{
+			priority: successCase.Priority,
+			code: fmt.Sprintf(
+				"case %s:\n%s%s%s%s%s\n",
+				requestMatcher,
+				caseProvenance(method, successCasesKey, index, successCase.Description),
+				delay,
+				generateMetadata(file, target, successCase.Headers, successCase.Trailers),
+				generateCallback(method, successCase.Description, target),
+				result,
+			),
+		})
 	}
 
-	return nil
+	return clientCases, nil
 }
 
 func generateFailureCases(
 	file *protogen.GeneratedFile,
 	method *protogen.Method,
 	cases []entities.FailureCase,
-	writer io.StringWriter,
-) error {
-	for _, failureCase := range cases {
-		requestRepresentation, err := getProtoRepresentation(
-			failureCase.Request, method.Input, file,
+	target caseTarget,
+) ([]clientCase, error) {
+	clientCases := make([]clientCase, 0, len(cases))
+	for index, failureCase := range cases {
+		requestMatcher, err := generateRequestMatcher(
+			file, method.Input, failureCase.Request, failureCase.Oneofs,
 		)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if !processors.IsErrorCodeValid(failureCase.Error.ErrorCode) {
-			return fmt.Errorf("invalid error code: %s", failureCase.Error.ErrorCode)
+		delay, err := generateDelay(file, failureCase.Delay)
+		if err != nil {
+			return nil, err
 		}
 
-		_, err = writer.WriteString(
-			fmt.Sprintf(
-				"case %s(in, %s):\n// Description: %s\n return nil, %s(%s, %q)\n",
-				file.QualifiedGoIdent(protoPackage.Ident("Equal")),
-				requestRepresentation,
-				failureCase.Description,
-				file.QualifiedGoIdent(grpcStatus.Ident("Errorf")),
-				file.QualifiedGoIdent(grpcCodes.Ident(failureCase.Error.ErrorCode)),
-				failureCase.Error.Message,
-			),
-		)
+		result, err := generateErrorResult(file, method, failureCase.Error)
 		if err != nil {
-			return fmt.Errorf("error writing a success case: %w", err)
+			return nil, err
 		}
+
+		clientCases = append(clientCases, clientCase{
+			priority: failureCase.Priority,
+			code: fmt.Sprintf(
+				"case %s:\n%s%s%s%s%s\n",
+				requestMatcher,
+				caseProvenance(method, failureCasesKey, index, failureCase.Description),
+				delay,
+				generateMetadata(file, target, failureCase.Headers, failureCase.Trailers),
+				generateCallback(method, failureCase.Description, target),
+				result,
+			),
+		})
 	}
 
-	return nil
+	return clientCases, nil
+}
+
+// generateDelay returns the statements that wait for the case delay before returning,
+// the wait is interrupted with the context error when the context is done first
+func generateDelay(file *protogen.GeneratedFile, delay string) (string, error) {
+	duration, err := processors.ParseDuration(delay)
+	if err != nil {
+		return "", fmt.Errorf("invalid delay: %w", err)
+	}
+
+	if duration == 0 {
+		return "", nil
+	}
+
+	amount, unit := processors.DurationUnit(duration)
+	return fmt.Sprintf(
+		"select {\ncase <-ctx.Done():\nreturn nil, %s(ctx.Err()).Err()\ncase <-%s(%d * %s):\n}\n",
+		file.QualifiedGoIdent(grpcStatus.Ident("FromContextError")),
+		file.QualifiedGoIdent(timePackage.Ident("After")),
+		amount,
+		file.QualifiedGoIdent(timePackage.Ident(unit)),
+	), nil
+}
+
+// generateResponseResult returns the statement that returns the given response
+func generateResponseResult(
+	file *protogen.GeneratedFile,
+	method *protogen.Method,
+	response interface{},
+) (string, error) {
+	responseRepresentation, _, err := getResponseRepresentation(response, method.Output, file)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("return %s, nil", responseRepresentation), nil
+}
+
+// generateErrorResult returns the statements that return the given GRPC error,
+// the error details are attached to the status when provided
+func generateErrorResult(
+	file *protogen.GeneratedFile,
+	method *protogen.Method,
+	grpcError entities.GRPCError,
+) (string, error) {
+	errorCode, err := processors.NormalizeErrorCode(string(grpcError.ErrorCode))
+	if err != nil {
+		return "", err
+	}
+
+	messageArgs, err := generateMessageArgs(method, grpcError)
+	if err != nil {
+		return "", err
+	}
+
+	// The message is only a format when it has arguments
+	newStatus, newError := "New", "Error"
+	if messageArgs != "" {
+		newStatus, newError = "Newf", "Errorf"
+	}
+
+	details, err := errorDetailsRepresentation(file, grpcError.Details)
+	if err != nil {
+		return "", err
+	}
+
+	if len(details) > 0 {
+		return fmt.Sprintf(
+			"errorStatus, err := %s(%s, %q%s).WithDetails(%s)\n"+
+				"if err != nil {\nreturn nil, err\n}\nreturn nil, errorStatus.Err()",
+			file.QualifiedGoIdent(grpcStatus.Ident(newStatus)),
+			file.QualifiedGoIdent(grpcCodes.Ident(errorCode)),
+			grpcError.Message,
+			messageArgs,
+			strings.Join(details, ", "),
+		), nil
+	}
+
+	return fmt.Sprintf(
+		"return nil, %s(%s, %q%s)",
+		file.QualifiedGoIdent(grpcStatus.Ident(newError)),
+		file.QualifiedGoIdent(grpcCodes.Ident(errorCode)),
+		grpcError.Message,
+		messageArgs,
+	), nil
+}
+
+// getResponseRepresentation replaces the fake placeholders of the response before
+// representing it, the paths of the faked fields are returned as well, since they
+// can't be verified against the server
+func getResponseRepresentation(
+	response interface{},
+	message *protogen.Message,
+	file *protogen.GeneratedFile,
+) (string, []string, error) {
+	resolvedResponse, fakedPaths, err := processors.ResolveFakeValues(response, *fakeSeed)
+	if err != nil {
+		return "", nil, err
+	}
+
+	responseRepresentation, err := getProtoRepresentation(resolvedResponse, message, file)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return responseRepresentation, fakedPaths, nil
 }
 
 func getProtoRepresentation(
@@ -298,12 +748,37 @@ func getProtoRepresentation(
 	message *protogen.Message,
 	file *protogen.GeneratedFile,
 ) (string, error) {
-	marshaledRequest, err := json.Marshal(r)
+	parsedMessage, err := parseMessage(r, message)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to generate message representation: %w", err)
 	}
 
-	messageArguments, err := inputOutputToString(marshaledRequest, message)
+	return messageRepresentation(parsedMessage, message, file)
+}
+
+// parseMessage validates the data provided by the user through JSON file
+// against the message descriptor, returning the populated message
+func parseMessage(r interface{}, message *protogen.Message) (*dynamicpb.Message, error) {
+	marshaledMessage, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedMessage := dynamicpb.NewMessage(message.Desc)
+	unmarshalOptions := protojson.UnmarshalOptions{Resolver: knownMessages}
+	if err = unmarshalOptions.Unmarshal(marshaledMessage, parsedMessage); err != nil {
+		return nil, err
+	}
+
+	return parsedMessage, nil
+}
+
+func messageRepresentation(
+	parsedMessage protoreflect.Message,
+	message *protogen.Message,
+	file *protogen.GeneratedFile,
+) (string, error) {
+	messageArguments, err := inputOutputToString(parsedMessage, message, file)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate message representation: %w", err)
 	}
@@ -315,23 +790,22 @@ func getProtoRepresentation(
 	), nil
 }
 
-func inputOutputToString(data []byte, message *protogen.Message) ([]string, error) {
-	// This step validates the data provided by the user through JSON file
-	methodInputMessage := dynamicpb.NewMessage(message.Desc)
-	err := protojson.Unmarshal(data, methodInputMessage)
-	if err != nil {
-		return nil, err
-	}
-
+func inputOutputToString(
+	parsedMessage protoreflect.Message,
+	message *protogen.Message,
+	file *protogen.GeneratedFile,
+) ([]string, error) {
 	// Making this map we're able to correlate a field with a field descriptor
 	fieldsMapByNumber := make(map[protoreflect.FieldNumber]*protogen.Field)
 	for _, field := range message.Fields {
 		fieldsMapByNumber[field.Desc.Number()] = field
 	}
 
-	// Try to get all of the populated fields (name and value)
-	messageArguments := make([]string, 0)
-	methodInputMessage.Range(
+	// Try to get all of the populated fields (name and value), the arguments are indexed by
+	// the field number since Range doesn't follow any order and the output must be reproducible
+	var err error
+	argumentsByNumber := make(map[protoreflect.FieldNumber]string)
+	parsedMessage.Range(
 		func(descriptor protoreflect.FieldDescriptor, value protoreflect.Value) bool {
 			field, exists := fieldsMapByNumber[descriptor.Number()]
 			if !exists {
@@ -342,102 +816,207 @@ func inputOutputToString(data []byte, message *protogen.Message) ([]string, erro
 				return false
 			}
 
-			messageArguments = append(
-				messageArguments,
-				fmt.Sprintf("%s: %s", field.GoName, processors.FormatFieldValue(value)),
-			)
-
-			return true
+			argumentsByNumber[descriptor.Number()], err = fieldArgument(file, field, value)
+			return err == nil
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	numbers := make([]protoreflect.FieldNumber, 0, len(argumentsByNumber))
+	for number := range argumentsByNumber {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	messageArguments := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		messageArguments = append(messageArguments, argumentsByNumber[number])
+	}
+
 	return messageArguments, nil
 }
 
 func generateServerTest(
 	file *protogen.GeneratedFile,
+	serviceImportPath protogen.GoImportPath,
 	service *protogen.Service,
 	contractService entities.Service,
 ) error {
 	functionName := fmt.Sprintf("%sContractTest", processors.MakeExportedName(service.GoName))
 	file.P(
 		fmt.Sprintf(
-			"func %s(t *%s, ctx %s, server *%s) {",
+			"func %s(t *%s, ctx %s, server *%s, opts ...%s) {",
 			functionName,
 			file.QualifiedGoIdent(testingT),
 			file.QualifiedGoIdent(contextContext),
 			file.QualifiedGoIdent(grpcPackage.Ident("Server")),
+			file.QualifiedGoIdent(dealtestPackage.Ident("ContractTestOption")),
 		),
 	)
-
-	file.P("// gRPC Server setup")
-	file.P("bufSize := 1024 * 1024")
 	file.P(
 		fmt.Sprintf(
-			"bufferListener := %s(bufSize)",
-			file.QualifiedGoIdent(buffconPackage.Ident("Listen")),
+			"config := %s(opts...)",
+			file.QualifiedGoIdent(dealtestPackage.Ident("NewContractTestConfig")),
 		),
 	)
+	file.P(
+		fmt.Sprintf(
+			"if len(config.ServerOptions) > 0 {\n"+
+				"t.Fatalf(\"The server options can't be applied to the given server, use %sWithService\")\n}",
+			functionName,
+		),
+	)
+	file.P(fmt.Sprintf("client := start%sServer(t, ctx, server, config)", service.GoName))
+	file.P(fmt.Sprintf("run%sTests(t, ctx, client, config)", service.GoName))
+	file.P("}\n")
 
-	file.P("go func() {")
+	err := generateStartServer(file, serviceImportPath, service)
+	if err != nil {
+		return err
+	}
+
+	generateServerTestWithService(file, serviceImportPath, service, functionName)
+
+	err = generateServerTestWithConn(file, serviceImportPath, service, functionName)
+	if err != nil {
+		return err
+	}
+
+	err = generateVerify(file, service, functionName)
+	if err != nil {
+		return err
+	}
+
+	err = generateFuzz(file, service, contractService)
+	if err != nil {
+		return err
+	}
+
+	err = generateBenchmark(file, service, contractService)
+	if err != nil {
+		return err
+	}
+
+	err = generateInterceptorOptions(file, service)
+	if err != nil {
+		return err
+	}
+
+	return generateSuccessAndFailureTests(file, serviceImportPath, service, contractService)
+}
+
+// generateStartServer creates the function serving the server given to the server tests and
+// returning a client connected to it, the server and the connection are closed by the cleanups
+func generateStartServer(
+	file *protogen.GeneratedFile,
+	serviceImportPath protogen.GoImportPath,
+	service *protogen.Service,
+) error {
 	file.P(
-		fmt.Sprintf(`if err := server.Serve(bufferListener); err != nil {
-				%s("Contract Server test exited with error: %%v", err)
-			}`,
-			file.QualifiedGoIdent(logPackage.Ident("Fatalf")),
+		fmt.Sprintf(
+			"func start%sServer(t %s, ctx %s, server *%s, config %s) %s {",
+			service.GoName,
+			file.QualifiedGoIdent(testingPackage.Ident("TB")),
+			file.QualifiedGoIdent(contextContext),
+			file.QualifiedGoIdent(grpcPackage.Ident("Server")),
+			file.QualifiedGoIdent(dealtestPackage.Ident("ContractTestConfig")),
+			file.QualifiedGoIdent(serviceIdent(serviceImportPath, "%sClient", service)),
 		),
 	)
-	file.P("}()")
-	file.P("defer server.Stop()")
+
+	// Registered first, so it runs after the server is stopped
+	file.P(`if config.LeakChecker != nil {
+		t.Cleanup(func() {
+			if err := config.LeakChecker(); err != nil {
+				t.Errorf("Goroutines leaked by the server: %v", err)
+			}
+		})
+	}`)
 	file.P()
 
-	file.P("// gRPC Client setup")
+	file.P("// gRPC Server setup")
+	// The server is reached through the in-memory listener,
+	// unless a listener is provided by the caller
 	file.P(
-		fmt.Sprintf(
-			"dialer := func(_ %s, _ string) (%s, error) { return bufferListener.Dial() }",
+		fmt.Sprintf(`listener, dialer := config.Listener, %s(config.Listener)
+			if listener == nil {
+				bufferListener := %s(config.BufferSize)
+				listener = bufferListener
+				dialer = func(_ %s, _ string) (%s, error) { return bufferListener.Dial() }
+			}`,
+			file.QualifiedGoIdent(dealtestPackage.Ident("ListenerDialer")),
+			file.QualifiedGoIdent(buffconPackage.Ident("Listen")),
 			file.QualifiedGoIdent(contextContext),
 			file.QualifiedGoIdent(netPackage.Ident("Conn")),
 		),
 	)
+
+	file.P("go func() {")
+	file.P(
+		fmt.Sprintf(`if err := server.Serve(listener); err != nil {
+				%s("Contract Server test exited with error: %%v", err)
+			}`,
+			file.QualifiedGoIdent(logPackage.Ident("Fatalf")),
+		),
+	)
+	file.P("}()")
+	// Cleanups run after the parallel subtests and in the reverse order, so the
+	// connection is closed before the server waits for the in-flight calls
 	file.P(
 		fmt.Sprintf(
-			`clientConn, err := %s(ctx, "bufnet", %s(dialer), %s())`,
-			file.QualifiedGoIdent(grpcPackage.Ident("DialContext")),
-			file.QualifiedGoIdent(grpcPackage.Ident("WithContextDialer")),
-			file.QualifiedGoIdent(grpcPackage.Ident("WithInsecure")),
+			"t.Cleanup(func() {\n%s(server.GracefulStop, server.Stop, config.GracefulStopTimeout)\n})",
+			file.QualifiedGoIdent(dealtestPackage.Ident("StopGracefully")),
 		),
 	)
+	file.P()
+
+	file.P("// gRPC Client setup")
+	clientConn, err := generateClientConn(file, *grpcVersion)
+	if err != nil {
+		return err
+	}
+	file.P(clientConn)
 	file.P(`if err != nil { t.Fatalf("Failed to dial bufnet: %v", err) }`)
-	file.P("defer clientConn.Close()")
+	file.P(`t.Cleanup(func() {
+		if err := clientConn.Close(); err != nil {
+			t.Errorf("Failed to close the client connection: %v", err)
+		}
+	})`)
 	file.P()
 
 	// We're creating a client this way believing on what go-grpc will generate
 	// and both will be in the same package.
-	file.P(fmt.Sprintf("client := New%sClient(clientConn)", service.GoName))
-	file.P(fmt.Sprintf("run%sTests(t, ctx, client)", service.GoName))
-
+	file.P(
+		fmt.Sprintf(
+			"return %s(clientConn)",
+			file.QualifiedGoIdent(serviceIdent(serviceImportPath, "New%sClient", service)),
+		),
+	)
 	file.P("}\n")
 
-	return generateSuccessAndFailureTests(file, service, contractService)
+	return nil
 }
 
 func generateSuccessAndFailureTests(
 	file *protogen.GeneratedFile,
+	serviceImportPath protogen.GoImportPath,
 	service *protogen.Service,
 	contractService entities.Service,
 ) error {
 	file.P(
 		fmt.Sprintf(
-			"func run%sTests(t *%s, ctx %s, client %sClient) {",
+			"func run%sTests(t *%s, ctx %s, client %s, config %s) {",
 			service.GoName,
 			file.QualifiedGoIdent(testingT),
 			file.QualifiedGoIdent(contextContext),
-			service.GoName,
+			file.QualifiedGoIdent(serviceIdent(serviceImportPath, "%sClient", service)),
+			file.QualifiedGoIdent(dealtestPackage.Ident("ContractTestConfig")),
 		),
 	)
+	file.P("config.StartVerification(ctx, t)")
+	file.P()
 
 	for _, method := range service.Methods {
 		methodContract, exists := contractService[method.GoName]
@@ -463,6 +1042,18 @@ func generateSuccessAndFailureTests(
 			return err
 		}
 
+		err = generateSequenceTestForServer(file, method, methodContract.SuccessCases)
+		if err != nil {
+			return err
+		}
+
+		err = generateApplicationErrorTestForServer(
+			file, method, methodContract.ApplicationErrorCases,
+		)
+		if err != nil {
+			return err
+		}
+
 		file.P("})")
 	}
 
@@ -482,58 +1073,106 @@ func generateSuccessTestForServer(
 			file.QualifiedGoIdent(testingT),
 		),
 	)
-	file.P(
-		fmt.Sprintf(
-			"tests := []struct {name string\nrequest *%s\nexpectedResponse *%s} {",
-			file.QualifiedGoIdent(method.Input.GoIdent),
-			file.QualifiedGoIdent(method.Output.GoIdent),
-		),
-	)
 
-	for _, successCase := range successCases {
-		requestRepresentation, err := getProtoRepresentation(
-			successCase.Request, method.Input, file,
+	tests := make([]string, 0, len(successCases))
+	hasFakedFields, hasMetadata, hasTimeouts := false, false, false
+	for index, successCase := range successCases {
+		// Sequenced cases have their own test, see generateSequenceTestForServer
+		if len(successCase.Responses) > 0 {
+			continue
+		}
+
+		requestRepresentation, err := getRequestRepresentation(
+			file, method.Input, successCase.Request, successCase.Oneofs,
 		)
 		if err != nil {
 			return err
 		}
 
-		responseRepresentation, err := getProtoRepresentation(
+		responseRepresentation, fakedPaths, err := getResponseRepresentation(
 			successCase.Response, method.Output, file,
 		)
 		if err != nil {
 			return err
 		}
 
-		file.P(
-			fmt.Sprintf(
-				"{\nname: \"%s\",\nrequest: %s,\nexpectedResponse: %s,\n},",
-				successCase.Description,
-				requestRepresentation,
-				responseRepresentation,
-			),
+		test := fmt.Sprintf(
+			"%s{\nname: %q,\nrequest: %s,\nexpectedResponse: %s,\n",
+			caseProvenance(method, successCasesKey, index, successCase.Description),
+			processors.TestCaseName(successCase.Description, index),
+			requestRepresentation,
+			responseRepresentation,
+		)
+		if len(fakedPaths) > 0 {
+			hasFakedFields = true
+			test += fmt.Sprintf("ignoredFields: %#v,\n", fakedPaths)
+		}
+		if len(successCase.Headers) > 0 || len(successCase.Trailers) > 0 {
+			hasMetadata = true
+			test += metadataTestValues(file, successCase.Headers, successCase.Trailers)
+		}
+		timeout, err := timeoutTestValue(file, successCase.Timeout)
+		if err != nil {
+			return err
+		}
+		if timeout != "" {
+			hasTimeouts = true
+			test += timeout
+		}
+		tests = append(tests, test+"},")
+	}
+
+	metadataColumns := metadataTestColumns{}
+	if hasMetadata {
+		metadataColumns = generateMetadataTestColumns(file)
+	}
+
+	ignoredFieldsDeclaration, clearIgnoredFields := "", ""
+	if hasFakedFields {
+		// Fake values are generated, so the server isn't expected to return them
+		ignoredFieldsDeclaration = "\nignoredFields []string"
+		clearIgnoredFields = fmt.Sprintf(
+			"%[1]s(response, test.ignoredFields...)\n"+
+				"%[1]s(test.expectedResponse, test.ignoredFields...)",
+			file.QualifiedGoIdent(dealtestPackage.Ident("ClearFields")),
 		)
 	}
+
+	timeoutDeclaration, callContext := timeoutTestColumns(file, hasTimeouts, "test")
+
+	file.P(
+		fmt.Sprintf(
+			"tests := []struct {name string\nrequest *%s\nexpectedResponse *%s%s%s%s} {",
+			file.QualifiedGoIdent(method.Input.GoIdent),
+			file.QualifiedGoIdent(method.Output.GoIdent),
+			ignoredFieldsDeclaration,
+			metadataColumns.declaration,
+			timeoutDeclaration,
+		),
+	)
+	for _, test := range tests {
+		file.P(test)
+	}
 	file.P("}")
 
 	file.P()
 	file.P(
-		fmt.Sprintf(`for _, test := range tests {
-				t.Run(test.name, func(t *testing.T) {
-					response, err := client.%s(ctx, test.request)
-					if err != nil {
-						t.Fatalf("unexpected error happened: %%w", err)
-					}
-
-					if !proto.Equal(response, test.expectedResponse) {
-						t.Fatalf(
-							"expected response: %%v, given response: %%v",
-							test.expectedResponse, response,
-						)
-					}
+		fmt.Sprintf(`%s%s%sresponse, err := client.%s(callCtx, test.request%s)
+					%s
+					%s
+					%s
+					%s
 				})
 			}`,
+			subtestsLoop(file),
+			callContext,
+			metadataColumns.variables,
 			method.GoName,
+			metadataColumns.callOptions,
+			noErrorCheck(file, "unexpected error happened"),
+			clearIgnoredFields,
+			responseCheck(file, "test.expectedResponse", "response"),
+			metadataColumns.checks,
 		),
 	)
 	file.P("})")
@@ -553,47 +1192,148 @@ func generateFailureTestForServer(
 			file.QualifiedGoIdent(testingT),
 		),
 	)
-	file.P(
-		fmt.Sprintf(
-			"tests := []struct {name string\nrequest *%s\nexpectedError string} {",
-			file.QualifiedGoIdent(method.Input.GoIdent),
-		),
-	)
-
-	for _, failureCase := range failureCases {
-		requestRepresentation, err := getProtoRepresentation(
-			failureCase.Request, method.Input, file,
+	tests := make([]string, 0, len(failureCases))
+	hasMetadata, hasDetails, hasAlternativeCodes, hasTimeouts := false, false, false, false
+	withMessageMatch := hasMessageMatch(failureCases)
+	for index, failureCase := range failureCases {
+		requestRepresentation, err := getRequestRepresentation(
+			file, method.Input, failureCase.Request, failureCase.Oneofs,
 		)
 		if err != nil {
 			return err
 		}
 
-		file.P(
-			fmt.Sprintf(
-				"{\nname: \"%s\",\nrequest: %s,\nexpectedError: \"%s\",\n},",
-				failureCase.Description,
-				requestRepresentation,
-				failureCase.Error,
-			),
+		errorCode, err := processors.NormalizeErrorCode(string(failureCase.Error.ErrorCode))
+		if err != nil {
+			return err
+		}
+
+		test := fmt.Sprintf(
+			"%s{\nname: %q,\nrequest: %s,\nexpectedCode: %s,\nexpectedMessage: %q,\n"+
+				"contractError: %s,\n",
+			caseProvenance(method, failureCasesKey, index, failureCase.Description),
+			processors.TestCaseName(failureCase.Description, index),
+			requestRepresentation,
+			file.QualifiedGoIdent(grpcCodes.Ident(errorCode)),
+			expectedMessage(failureCase),
+			grpcErrorRepresentation(file, failureCase.Error),
 		)
+		if err = validateMessageMatch(failureCase); err != nil {
+			return err
+		}
+		if len(failureCase.Error.AlternativeCodes) > 0 {
+			alternativeCodes, err := codesRepresentation(file, failureCase.Error.AlternativeCodes)
+			if err != nil {
+				return err
+			}
+
+			hasAlternativeCodes = true
+			test += fmt.Sprintf("alternativeCodes: %s,\n", alternativeCodes)
+		}
+		if withMessageMatch {
+			test += fmt.Sprintf("messageMatch: %q,\n", messageMatch(failureCase))
+		}
+		if len(failureCase.Headers) > 0 || len(failureCase.Trailers) > 0 {
+			hasMetadata = true
+			test += metadataTestValues(file, failureCase.Headers, failureCase.Trailers)
+		}
+		details, err := errorDetailsRepresentation(file, failureCase.Error.Details)
+		if err != nil {
+			return err
+		}
+		if len(details) > 0 {
+			hasDetails = true
+			test += fmt.Sprintf(
+				"expectedDetails: []%s{%s},\n",
+				file.QualifiedGoIdent(protoPackage.Ident("Message")),
+				strings.Join(details, ", "),
+			)
+		}
+		timeout, err := timeoutTestValue(file, failureCase.Timeout)
+		if err != nil {
+			return err
+		}
+		if timeout != "" {
+			hasTimeouts = true
+			test += timeout
+		}
+		tests = append(tests, test+"},")
+	}
+
+	metadataColumns := metadataTestColumns{}
+	if hasMetadata {
+		metadataColumns = generateMetadataTestColumns(file)
+	}
+
+	detailsDeclaration, detailsChecks := "", ""
+	if hasDetails {
+		detailsDeclaration, detailsChecks = errorDetailsTestColumns(file, "test")
+	}
+
+	messageMatchDeclaration, messageCheck := "", exactMessageCheck(file)
+	if withMessageMatch {
+		messageMatchDeclaration, messageCheck = messageMatchTestColumns(file)
+	}
+
+	alternativeCodesDeclaration, codeCheck := "", exactCodeCheck(file)
+	if hasAlternativeCodes {
+		alternativeCodesDeclaration, codeCheck = alternativeCodesTestColumns(file)
+	}
+
+	timeoutDeclaration, callContext := timeoutTestColumns(file, hasTimeouts, "test")
+
+	file.P(
+		fmt.Sprintf(
+			"tests := []struct {name string\nrequest *%s\nexpectedCode %s\n"+
+				"expectedMessage string\ncontractError %s%s%s%s%s%s} {",
+			file.QualifiedGoIdent(method.Input.GoIdent),
+			file.QualifiedGoIdent(grpcCodes.Ident("Code")),
+			file.QualifiedGoIdent(entitiesPackage.Ident("GRPCError")),
+			alternativeCodesDeclaration,
+			messageMatchDeclaration,
+			metadataColumns.declaration,
+			detailsDeclaration,
+			timeoutDeclaration,
+		),
+	)
+	for _, test := range tests {
+		file.P(test)
 	}
 	file.P("}")
 
 	file.P()
 	file.P(
-		fmt.Sprintf(`for _, test := range tests {
-				t.Run(test.name, func(t *testing.T) {
-					_, err := client.%s(ctx, test.request)
-					if err == nil {
-						t.Fatalf("an error was expected but no one was returned")
-					}
-
-					if err.Error() != test.expectedError {
-						t.Fatalf("expected error: %%s, given error: %%s", test.expectedError, err)
+		fmt.Sprintf(`%s%s%s_, err := client.%s(callCtx, test.request%s)
+					%s
+
+					if config.ErrorComparer != nil {
+						compareErr := config.ErrorComparer(test.contractError, err)
+						if compareErr != nil {
+							t.Fatalf("unexpected error: %%v", compareErr)
+						}
+					} else {
+						errorStatus, isStatus := %s(err)
+						if !isStatus {
+							t.Fatalf("a gRPC status error was expected, given error: %%v", err)
+						}
+						%s
+						%s
+						%s
 					}
+					%s
 				})
 			}`,
+			subtestsLoop(file),
+			callContext,
+			metadataColumns.variables,
 			method.GoName,
+			metadataColumns.callOptions,
+			errorCheck(file, "an error was expected but no one was returned"),
+			file.QualifiedGoIdent(grpcStatus.Ident("FromError")),
+			codeCheck,
+			messageCheck,
+			detailsChecks,
+			metadataColumns.checks,
 		),
 	)
 	file.P("})")
@@ -0,0 +1,423 @@
+package main_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var update = flag.Bool("update", false, "Replace the golden files with the generated ones")
+
+// The fixture is generated from testdata/proto by protoc-gen-go and protoc-gen-go-grpc,
+// fixture.protoset is its descriptor set. The contract code is generated for fixture.proto
+// by default, the messages of common.proto are imported from another Go package.
+// protoc-gen-go-grpc v1.2.0 predates the editions, so the code of modern.proto's service
+// is generated from its proto3 equivalent.
+const (
+	fixtureModule = "fixture"
+	fixtureProto  = "fixture/v1/fixture.proto"
+	legacyProto   = "fixture/legacy/v1/legacy.proto"
+	modernProto   = "fixture/modern/v1/modern.proto"
+)
+
+// fixturePackages are the directories of the fixture Go packages, in testdata
+var fixturePackages = []string{"fixturev1", "commonv1", "legacyv1", "modernv1"}
+
+// fixtureGoMod is the go.mod of the module compiling the generated code, the grpc-go
+// version matches the grpc-version option given to the plugin
+const fixtureGoMod = `module fixture
+
+go 1.16
+
+require (
+	github.com/faunists/deal-go v0.0.0
+	github.com/stretchr/testify v1.7.0
+	google.golang.org/grpc v1.43.0
+	google.golang.org/protobuf v1.34.1
+)
+
+replace github.com/faunists/deal-go => %s
+`
+
+func TestGeneratedCode(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("The generated code is compiled by the go command")
+	}
+
+	plugin := buildPlugin(t)
+
+	tests := []struct {
+		name string
+		// golden is the directory of the expected files, in testdata/golden
+		golden string
+		params []string
+		// layout are the options placing the generated files, module=fixture by default
+		layout []string
+		// generatedDir is where the layout places the files of fixture.proto, they're moved
+		// into testdata/fixturev1 to be compiled along with the fixture
+		generatedDir string
+		// files are the proto files to generate, fixture.proto by default
+		files []string
+		// run runs the tests of testdata/<tests> against the generated code
+		run bool
+		// tests is the directory of the tests, contracttest by default
+		tests string
+	}{
+		{
+			name:   "default options",
+			golden: "default",
+			run:    true,
+		},
+		{
+			name:   "testify asserts",
+			golden: "testify",
+			params: []string{"asserts=testify"},
+			run:    true,
+		},
+		{
+			name:   "server tests in test files",
+			golden: "test-files",
+			params: []string{"test-files=true"},
+		},
+		{
+			name:   "contract code in a sub-package",
+			golden: "contract-package",
+			params: []string{"contract-package=contract"},
+		},
+		{
+			name:   "parallel server tests",
+			golden: "parallel-tests",
+			params: []string{"parallel-tests=true"},
+			run:    true,
+		},
+		{
+			name:   "one file per service",
+			golden: "per-service-files",
+			params: []string{"per-service-files=true"},
+			run:    true,
+		},
+		{
+			name:   "client only",
+			golden: "gen-client",
+			params: []string{"gen-server-test=false"},
+		},
+		{
+			name:   "server tests only",
+			golden: "gen-server-test",
+			params: []string{"gen-client=false"},
+		},
+		{
+			name:   "custom file suffixes",
+			golden: "file-suffix",
+			params: []string{
+				"file-suffix=.deal.go", "test-files=true", "test-file-suffix=.deal_test.go",
+			},
+		},
+		{
+			name:         "source relative paths",
+			golden:       "source-relative",
+			layout:       []string{"paths=source_relative"},
+			generatedDir: "fixture/v1",
+			run:          true,
+		},
+		{
+			name:         "source relative paths and a contract sub-package",
+			golden:       "source-relative-contract-package",
+			layout:       []string{"paths=source_relative"},
+			generatedDir: "fixture/v1",
+			params:       []string{"contract-package=contract"},
+		},
+		{
+			name:         "import paths",
+			golden:       "import-paths",
+			layout:       []string{"paths=import"},
+			generatedDir: "fixture/fixturev1",
+		},
+		{
+			name:   "proto2 and editions",
+			golden: "editions",
+			// The last contract-file replaces the default one
+			params: []string{"contract-file=" + filepath.Join("testdata", "editions_contract.json")},
+			files:  []string{legacyProto, modernProto},
+			run:    true,
+			tests:  "editionstest",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			protoFiles := test.files
+			if protoFiles == nil {
+				protoFiles = []string{fixtureProto}
+			}
+			layout := test.layout
+			if layout == nil {
+				layout = []string{"module=" + fixtureModule}
+			}
+			files := runPlugin(t, plugin, protoFiles, append(layout, test.params...))
+			compareGolden(t, filepath.Join("testdata", "golden", test.golden), files)
+
+			if test.generatedDir != "" {
+				files = moveFiles(files, test.generatedDir, "fixturev1")
+			}
+
+			tests := ""
+			if test.run {
+				tests = test.tests
+				if tests == "" {
+					tests = "contracttest"
+				}
+			}
+			moduleDir := writeFixtureModule(t, files, tests)
+
+			goCommand(t, moduleDir, "vet", "./...")
+			if test.run {
+				goCommand(t, moduleDir, "test", "./...")
+			}
+		})
+	}
+}
+
+func TestGeneratedCodeRejectsGroups(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("The plugin is built by the go command")
+	}
+
+	response := generate(
+		t,
+		buildPlugin(t),
+		[]string{legacyProto},
+		[]string{"contract-file=" + filepath.Join("testdata", "group_contract.json")},
+	)
+
+	expectedError := "field fixture.legacy.v1.LegacyRequest.extra is a group " +
+		"or a delimited message, which isn't supported"
+	if !strings.Contains(response.GetError(), expectedError) {
+		t.Errorf("Given: %+v, expected: %+v", response.GetError(), expectedError)
+	}
+}
+
+// compareGolden compares the generated files with the ones of the golden directory,
+// which are replaced by the generated ones when the -update flag is set
+func compareGolden(t *testing.T, goldenDir string, files map[string]string) {
+	t.Helper()
+
+	if *update {
+		if err := os.RemoveAll(goldenDir); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		writeFiles(t, goldenDir, files, ".golden")
+	}
+
+	expectedFiles := make(map[string]string)
+	err := filepath.Walk(goldenDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(goldenDir, strings.TrimSuffix(path, ".golden"))
+		if err != nil {
+			return err
+		}
+		expectedFiles[filepath.ToSlash(name)] = string(content)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(expectedFiles, files); diff != "" {
+		t.Errorf("Unexpected generated files, run go test -update (-expected +given):\n%s", diff)
+	}
+}
+
+// buildPlugin builds protoc-gen-go-deal and returns the path of the binary
+func buildPlugin(t *testing.T) string {
+	t.Helper()
+
+	plugin := filepath.Join(t.TempDir(), "protoc-gen-go-deal")
+	goCommand(t, ".", "build", "-o", plugin, ".")
+
+	return plugin
+}
+
+// runPlugin generates the contract code of the fixture files, as protoc would do,
+// and returns the generated files by name
+func runPlugin(
+	t *testing.T,
+	plugin string,
+	protoFiles []string,
+	params []string,
+) map[string]string {
+	t.Helper()
+
+	response := generate(t, plugin, protoFiles, params)
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %s", response.GetError())
+	}
+
+	files := make(map[string]string)
+	for _, file := range response.File {
+		files[file.GetName()] = file.GetContent()
+	}
+
+	return files
+}
+
+// generate runs the plugin with the fixture files and returns its response
+func generate(
+	t *testing.T,
+	plugin string,
+	protoFiles []string,
+	params []string,
+) *pluginpb.CodeGeneratorResponse {
+	t.Helper()
+
+	descriptorSet := &descriptorpb.FileDescriptorSet{}
+	content, err := ioutil.ReadFile(filepath.Join("testdata", "fixture.protoset"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := proto.Unmarshal(content, descriptorSet); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	parameters := append(
+		[]string{
+			"contract-file=" + filepath.Join("testdata", "contract.json"),
+			"grpc-version=1.43",
+		},
+		params...,
+	)
+	request, err := proto.Marshal(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: protoFiles,
+		Parameter:      proto.String(strings.Join(parameters, ",")),
+		ProtoFile:      descriptorSet.File,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	command := exec.Command(plugin)
+	command.Stdin, command.Stdout, command.Stderr = bytes.NewReader(request), stdout, stderr
+	if err := command.Run(); err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, stderr)
+	}
+
+	response := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(stdout.Bytes(), response); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return response
+}
+
+// moveFiles returns the files with the directory at the start of their names replaced
+func moveFiles(files map[string]string, from, to string) map[string]string {
+	movedFiles := make(map[string]string, len(files))
+	for name, content := range files {
+		if strings.HasPrefix(name, from+"/") {
+			name = to + strings.TrimPrefix(name, from)
+		}
+		movedFiles[name] = content
+	}
+
+	return movedFiles
+}
+
+// writeFixtureModule writes the fixture and the generated files into a new module,
+// along with the tests of the testdata/<tests> directory when it's given
+func writeFixtureModule(t *testing.T, files map[string]string, tests string) string {
+	t.Helper()
+
+	repository, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	goSum, err := ioutil.ReadFile(filepath.Join(repository, "go.sum"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	moduleDir := t.TempDir()
+	moduleFiles := map[string]string{
+		"go.mod": fmt.Sprintf(fixtureGoMod, repository),
+		"go.sum": string(goSum),
+	}
+	sources := fixturePackages
+	if tests != "" {
+		sources = append(append([]string{}, fixturePackages...), tests)
+	}
+	for _, source := range sources {
+		entries, err := ioutil.ReadDir(filepath.Join("testdata", source))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, entry := range entries {
+			content, err := ioutil.ReadFile(filepath.Join("testdata", source, entry.Name()))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			moduleFiles[filepath.Join(source, entry.Name())] = string(content)
+		}
+	}
+	for name, content := range files {
+		moduleFiles[name] = content
+	}
+
+	writeFiles(t, moduleDir, moduleFiles, "")
+
+	return moduleDir
+}
+
+// writeFiles writes the files into the directory, the suffix is added to their names
+func writeFiles(t *testing.T, dir string, files map[string]string, suffix string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name)+suffix)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+// goCommand runs the go command in the directory, the missing go.sum entries are added
+func goCommand(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	command := exec.Command("go", args...)
+	command.Dir = dir
+	command.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
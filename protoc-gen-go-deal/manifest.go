@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+// manifestSuffix is appended to the proto file name to name its contract manifest
+const manifestSuffix = "_contract_manifest.json"
+
+// contractManifest describes the contract code generated for a proto file, it's written next to
+// the code for the tools consuming the generation output, e.g. a coverage report or a broker
+type contractManifest struct {
+	File      string            `json:"file"`
+	GoPackage string            `json:"goPackage"`
+	Services  []serviceManifest `json:"services"`
+}
+
+// serviceManifest describes the contract code generated for a service, the files are named
+// relative to the manifest
+type serviceManifest struct {
+	Name           string           `json:"name"`
+	Files          []string         `json:"files"`
+	Client         string           `json:"client,omitempty"`
+	ContractServer string           `json:"contractServer,omitempty"`
+	ServerTest     string           `json:"serverTest,omitempty"`
+	Contract       manifestContract `json:"contract"`
+	Methods        []methodManifest `json:"methods"`
+}
+
+// manifestContract tells where the contract of a service was found, the hash of the
+// contract file tells whether the generated code is outdated
+type manifestContract struct {
+	Path   string `json:"path"`
+	Key    string `json:"key"`
+	SHA256 string `json:"sha256"`
+}
+
+// methodManifest counts the contract cases of a method, the methods without a contract
+// have no cases
+type methodManifest struct {
+	Name                  string `json:"name"`
+	SuccessCases          int    `json:"successCases"`
+	ApplicationErrorCases int    `json:"applicationErrorCases"`
+	FailureCases          int    `json:"failureCases"`
+}
+
+func newContractManifest(file *protogen.File, location contractLocation) *contractManifest {
+	return &contractManifest{
+		File:      file.Desc.Path(),
+		GoPackage: string(location.importPath),
+		Services:  make([]serviceManifest, 0, len(file.Services)),
+	}
+}
+
+// addService describes the contract code generated for the service into the files of the prefix
+func (m *contractManifest) addService(
+	service *protogen.Service,
+	contractService entities.Service,
+	filenamePrefix string,
+) error {
+	source := contracts.source(service)
+	contractHash, err := contracts.fileHash(source.path)
+	if err != nil {
+		return err
+	}
+
+	serviceName := processors.MakeExportedName(service.GoName)
+	generatedService := serviceManifest{
+		Name:     string(service.Desc.FullName()),
+		Files:    make([]string, 0),
+		Contract: manifestContract{Path: source.path, Key: source.key, SHA256: contractHash},
+		Methods:  make([]methodManifest, 0, len(service.Methods)),
+	}
+	for _, filename := range generatedContractFilenames(filenamePrefix) {
+		generatedService.Files = append(generatedService.Files, path.Base(filename))
+	}
+	if *genClient {
+		generatedService.Client = serviceName + "ContractClient"
+		generatedService.ContractServer = serviceName + "ContractServer"
+	}
+	if *genServerTest {
+		generatedService.ServerTest = serviceName + "ContractTest"
+	}
+
+	for _, method := range service.Methods {
+		methodContract := contractService[method.GoName]
+		generatedService.Methods = append(generatedService.Methods, methodManifest{
+			Name:                  method.GoName,
+			SuccessCases:          len(methodContract.SuccessCases),
+			ApplicationErrorCases: len(methodContract.ApplicationErrorCases),
+			FailureCases:          len(methodContract.FailureCases),
+		})
+	}
+	m.Services = append(m.Services, generatedService)
+
+	return nil
+}
+
+// writeContractManifest writes the manifest next to the contract code of the proto file
+func writeContractManifest(
+	plugin *protogen.Plugin,
+	location contractLocation,
+	manifest *contractManifest,
+) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the contract manifest of %s: %w", manifest.File, err)
+	}
+
+	manifestFile := plugin.NewGeneratedFile(location.filenamePrefix+manifestSuffix, "")
+	_, err = manifestFile.Write(append(content, '\n'))
+
+	return err
+}
+
+// fileHash returns the SHA-256 hash of the contract file, in hexadecimal
+func (r *contractResolver) fileHash(contractPath string) (string, error) {
+	if hash, exists := r.hashes[contractPath]; exists {
+		return hash, nil
+	}
+
+	content, err := ioutil.ReadFile(contractPath)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(content)
+	r.hashes[contractPath] = hex.EncodeToString(hash[:])
+
+	return r.hashes[contractPath], nil
+}
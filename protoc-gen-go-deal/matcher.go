@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// generateRequestMatcher returns the boolean expression used by the generated switch
+// cases to verify if the incoming request (`in`) satisfies the contract request.
+//
+// Without oneof variants the request must be equal to the contract one. Every oneof
+// variant generates a type switch over the oneof wrapper types, when the contract request
+// doesn't set a value for the variant any value is accepted, so the oneof is ignored
+// while comparing the rest of the request.
+func generateRequestMatcher(
+	file *protogen.GeneratedFile,
+	message *protogen.Message,
+	request interface{},
+	oneofs map[string]string,
+) (string, error) {
+	parsedRequest, err := parseMessage(request, message)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate message representation: %w", err)
+	}
+
+	variants, err := resolveOneofVariants(message, oneofs)
+	if err != nil {
+		return "", err
+	}
+
+	requestRepresentation, err := messageRepresentation(parsedRequest, message, file)
+	if err != nil {
+		return "", err
+	}
+
+	protoEqual := file.QualifiedGoIdent(protoPackage.Ident("Equal"))
+	if len(variants) == 0 {
+		return fmt.Sprintf("%s(in, %s)", protoEqual, requestRepresentation), nil
+	}
+
+	matcher := bytes.NewBufferString("func() bool {\n")
+	ignoredOneofs := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		matcher.WriteString(
+			fmt.Sprintf(
+				"switch in.Get%s().(type) {\ncase *%s:\ndefault:\nreturn false\n}\n",
+				variant.Oneof.GoName,
+				file.QualifiedGoIdent(variant.GoIdent),
+			),
+		)
+
+		switch populated := parsedRequest.WhichOneof(variant.Oneof.Desc); {
+		case populated == nil:
+			ignoredOneofs = append(ignoredOneofs, variant.Oneof.GoName)
+		case populated.Number() != variant.Desc.Number():
+			return "", fmt.Errorf(
+				"the request sets the variant %s but the oneof %s expects %s",
+				populated.Name(), variant.Oneof.Desc.Name(), variant.Desc.Name(),
+			)
+		}
+	}
+
+	if len(ignoredOneofs) == 0 {
+		matcher.WriteString(
+			fmt.Sprintf("return %s(in, %s)\n}()", protoEqual, requestRepresentation),
+		)
+		return matcher.String(), nil
+	}
+
+	matcher.WriteString(
+		fmt.Sprintf(
+			"compared := %s(in).(*%s)\n",
+			file.QualifiedGoIdent(protoPackage.Ident("Clone")),
+			file.QualifiedGoIdent(message.GoIdent),
+		),
+	)
+	for _, oneofName := range ignoredOneofs {
+		matcher.WriteString(fmt.Sprintf("compared.%s = nil\n", oneofName))
+	}
+	matcher.WriteString(
+		fmt.Sprintf("return %s(compared, %s)\n}()", protoEqual, requestRepresentation),
+	)
+
+	return matcher.String(), nil
+}
+
+// getRequestRepresentation works like getProtoRepresentation, but the oneof variants
+// without a value in the contract request are set with their zero value. This way the
+// request sent to the server still selects the variant expected by the contract.
+func getRequestRepresentation(
+	file *protogen.GeneratedFile,
+	message *protogen.Message,
+	request interface{},
+	oneofs map[string]string,
+) (string, error) {
+	parsedRequest, err := parseMessage(request, message)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate message representation: %w", err)
+	}
+
+	variants, err := resolveOneofVariants(message, oneofs)
+	if err != nil {
+		return "", err
+	}
+
+	for _, variant := range variants {
+		if parsedRequest.WhichOneof(variant.Oneof.Desc) == nil {
+			setZeroValue(parsedRequest, variant)
+		}
+	}
+
+	return messageRepresentation(parsedRequest, message, file)
+}
+
+func setZeroValue(parsedMessage *dynamicpb.Message, field *protogen.Field) {
+	if field.Message != nil {
+		parsedMessage.Set(field.Desc, parsedMessage.NewField(field.Desc))
+		return
+	}
+
+	parsedMessage.Set(field.Desc, field.Desc.Default())
+}
+
+// resolveOneofVariants finds the fields that represent the variants expected for each oneof,
+// variants can be referenced by their proto or JSON names.
+// The variants are returned following the oneofs declaration order.
+func resolveOneofVariants(
+	message *protogen.Message,
+	oneofs map[string]string,
+) ([]*protogen.Field, error) {
+	variants := make([]*protogen.Field, 0, len(oneofs))
+	resolved := make(map[string]bool, len(oneofs))
+
+	for _, oneof := range message.Oneofs {
+		if oneof.Desc.IsSynthetic() {
+			continue
+		}
+
+		oneofName := string(oneof.Desc.Name())
+		variantName, exists := oneofs[oneofName]
+		if !exists {
+			continue
+		}
+
+		variant := findOneofVariant(oneof, variantName)
+		if variant == nil {
+			return nil, fmt.Errorf(
+				"variant %s not found in oneof %s of message %s",
+				variantName, oneofName, message.Desc.Name(),
+			)
+		}
+
+		variants = append(variants, variant)
+		resolved[oneofName] = true
+	}
+
+	unknownOneofs := make([]string, 0)
+	for oneofName := range oneofs {
+		if !resolved[oneofName] {
+			unknownOneofs = append(unknownOneofs, oneofName)
+		}
+	}
+	if len(unknownOneofs) > 0 {
+		sort.Strings(unknownOneofs)
+		return nil, fmt.Errorf(
+			"oneofs %v not found in message %s", unknownOneofs, message.Desc.Name(),
+		)
+	}
+
+	return variants, nil
+}
+
+func findOneofVariant(oneof *protogen.Oneof, variantName string) *protogen.Field {
+	for _, field := range oneof.Fields {
+		if string(field.Desc.Name()) == variantName || field.Desc.JSONName() == variantName {
+			return field
+		}
+	}
+
+	return nil
+}
+
+func isOneofMember(field *protogen.Field) bool {
+	return field.Oneof != nil && !field.Oneof.Desc.IsSynthetic()
+}
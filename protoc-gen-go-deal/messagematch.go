@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/dealtest"
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+// exactMessageCheck verifies the error message, it's used when every case expects
+// the exact error message
+func exactMessageCheck(file *protogen.GeneratedFile) string {
+	if *asserts == testifyAsserts {
+		return fmt.Sprintf(
+			"%s(t, test.expectedMessage, errorStatus.Message(), %q)",
+			file.QualifiedGoIdent(requirePackage.Ident("Equal")),
+			"unexpected error message",
+		)
+	}
+
+	return fmt.Sprintf(
+		"%s(t, %q, test.expectedMessage, errorStatus.Message())",
+		file.QualifiedGoIdent(dealtestPackage.Ident("AssertMessage")),
+		entities.MessageMatchExact,
+	)
+}
+
+// hasMessageMatch tells if any failure case verifies the error message in a non exact way
+func hasMessageMatch(failureCases []entities.FailureCase) bool {
+	for _, failureCase := range failureCases {
+		if messageMatch(failureCase) != entities.MessageMatchExact {
+			return true
+		}
+	}
+
+	return false
+}
+
+// messageMatch returns the message match of a failure case, messages with arguments
+// are matched through the pattern derived from them unless the message is ignored
+func messageMatch(failureCase entities.FailureCase) string {
+	switch {
+	case failureCase.MessageMatch == entities.MessageMatchIgnore:
+		return entities.MessageMatchIgnore
+	case len(failureCase.Error.MessageArgs) > 0:
+		return entities.MessageMatchRegex
+	case failureCase.MessageMatch == "":
+		return entities.MessageMatchExact
+	default:
+		return failureCase.MessageMatch
+	}
+}
+
+// expectedMessage returns the message expected from the server, the message pattern
+// when the message has arguments
+func expectedMessage(failureCase entities.FailureCase) string {
+	if len(failureCase.Error.MessageArgs) > 0 {
+		pattern, _ := processors.MessagePattern(failureCase.Error.Message)
+		return pattern
+	}
+
+	return failureCase.Error.Message
+}
+
+// validateMessageMatch verifies the message match mode and its pattern
+func validateMessageMatch(failureCase entities.FailureCase) error {
+	err := processors.ValidateMessageArgsMatch(
+		failureCase.MessageMatch, failureCase.Error.MessageArgs,
+	)
+	if err != nil {
+		return fmt.Errorf("case %q: %w", failureCase.Description, err)
+	}
+
+	// The match is done once with an empty message to validate the mode and the pattern
+	_, err = dealtest.MatchMessage(messageMatch(failureCase), expectedMessage(failureCase), "")
+	if err != nil {
+		return fmt.Errorf("case %q has an invalid message match: %w", failureCase.Description, err)
+	}
+
+	return nil
+}
+
+// messageMatchTestColumns returns the table column declaration and the check verifying
+// the error message following the message match of each case
+func messageMatchTestColumns(file *protogen.GeneratedFile) (string, string) {
+	return "\nmessageMatch string",
+		fmt.Sprintf(
+			"%s(t, test.messageMatch, test.expectedMessage, errorStatus.Message())",
+			file.QualifiedGoIdent(dealtestPackage.Ident("AssertMessage")),
+		)
+}
+
+// generateMessageArgs returns the arguments formatting the error message, read from the
+// request fields. Nothing is returned when the error has no arguments.
+func generateMessageArgs(method *protogen.Method, grpcError entities.GRPCError) (string, error) {
+	if len(grpcError.MessageArgs) == 0 {
+		return "", nil
+	}
+
+	_, placeholders := processors.MessagePattern(grpcError.Message)
+	if placeholders != len(grpcError.MessageArgs) {
+		return "", fmt.Errorf(
+			"the message %q has %d placeholders but %d arguments were provided",
+			grpcError.Message, placeholders, len(grpcError.MessageArgs),
+		)
+	}
+
+	args := ""
+	for _, path := range grpcError.MessageArgs {
+		getter, err := fieldGetter("in", method.Input, path)
+		if err != nil {
+			return "", fmt.Errorf("invalid message argument: %w", err)
+		}
+
+		args += fmt.Sprintf(", %s", getter)
+	}
+
+	return args, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+)
+
+const grpcMetadata = protogen.GoImportPath("google.golang.org/grpc/metadata")
+
+// caseTarget tells where the generated cases will be used,
+// since the client and the contract server deliver metadata in different ways
+type caseTarget int
+
+const (
+	clientTarget caseTarget = iota
+	serverTarget
+)
+
+// generateMetadata returns the statements that deliver the case metadata. The client fills
+// the grpc.Header/grpc.Trailer call options while the contract server sends them through
+// grpc.SetHeader/grpc.SetTrailer.
+func generateMetadata(
+	file *protogen.GeneratedFile,
+	target caseTarget,
+	headers, trailers entities.Metadata,
+) string {
+	if len(headers) == 0 && len(trailers) == 0 {
+		return ""
+	}
+
+	statements := bytes.NewBuffer(nil)
+	if target == serverTarget {
+		if len(headers) > 0 {
+			statements.WriteString(
+				fmt.Sprintf(
+					"if err := %s(ctx, %s); err != nil {\nreturn nil, err\n}\n",
+					file.QualifiedGoIdent(grpcPackage.Ident("SetHeader")),
+					metadataRepresentation(file, headers),
+				),
+			)
+		}
+		if len(trailers) > 0 {
+			statements.WriteString(
+				fmt.Sprintf(
+					"if err := %s(ctx, %s); err != nil {\nreturn nil, err\n}\n",
+					file.QualifiedGoIdent(grpcPackage.Ident("SetTrailer")),
+					metadataRepresentation(file, trailers),
+				),
+			)
+		}
+
+		return statements.String()
+	}
+
+	statements.WriteString("for _, opt := range opts {\nswitch option := opt.(type) {\n")
+	if len(headers) > 0 {
+		statements.WriteString(
+			fmt.Sprintf(
+				"case %s:\n*option.HeaderAddr = %s\n",
+				file.QualifiedGoIdent(grpcPackage.Ident("HeaderCallOption")),
+				metadataRepresentation(file, headers),
+			),
+		)
+	}
+	if len(trailers) > 0 {
+		statements.WriteString(
+			fmt.Sprintf(
+				"case %s:\n*option.TrailerAddr = %s\n",
+				file.QualifiedGoIdent(grpcPackage.Ident("TrailerCallOption")),
+				metadataRepresentation(file, trailers),
+			),
+		)
+	}
+	statements.WriteString("}\n}\n")
+
+	return statements.String()
+}
+
+// metadataRepresentation returns a metadata.MD literal, keys are sorted and lowercased
+// the same way the metadata package does.
+func metadataRepresentation(file *protogen.GeneratedFile, metadata entities.Metadata) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := make([]string, 0, len(metadata[key]))
+		for _, value := range metadata[key] {
+			values = append(values, fmt.Sprintf("%q", value))
+		}
+
+		entries = append(
+			entries,
+			fmt.Sprintf("%q: {%s}", strings.ToLower(key), strings.Join(values, ", ")),
+		)
+	}
+
+	return fmt.Sprintf(
+		"%s{%s}",
+		file.QualifiedGoIdent(grpcMetadata.Ident("MD")),
+		strings.Join(entries, ", "),
+	)
+}
+
+// metadataTestColumns holds the code added to the server tests to verify the metadata sent
+// by the server: the table columns declaration, the variables receiving the metadata, the call
+// options filling them and the checks against the expected values
+type metadataTestColumns struct {
+	declaration string
+	variables   string
+	callOptions string
+	checks      string
+}
+
+func generateMetadataTestColumns(file *protogen.GeneratedFile) metadataTestColumns {
+	md := file.QualifiedGoIdent(grpcMetadata.Ident("MD"))
+
+	return metadataTestColumns{
+		declaration: fmt.Sprintf("\nexpectedHeader %s\nexpectedTrailer %s", md, md),
+		variables:   fmt.Sprintf("var header, trailer %s\n", md),
+		callOptions: fmt.Sprintf(
+			", %s(&header), %s(&trailer)",
+			file.QualifiedGoIdent(grpcPackage.Ident("Header")),
+			file.QualifiedGoIdent(grpcPackage.Ident("Trailer")),
+		),
+		checks: fmt.Sprintf(
+			"%[1]s(t, \"header\", test.expectedHeader, header)\n"+
+				"%[1]s(t, \"trailer\", test.expectedTrailer, trailer)\n",
+			file.QualifiedGoIdent(dealtestPackage.Ident("AssertMetadata")),
+		),
+	}
+}
+
+// metadataTestValues returns the expected metadata of a single test entry
+func metadataTestValues(file *protogen.GeneratedFile, headers, trailers entities.Metadata) string {
+	values := ""
+	if len(headers) > 0 {
+		values += fmt.Sprintf("expectedHeader: %s,\n", metadataRepresentation(file, headers))
+	}
+	if len(trailers) > 0 {
+		values += fmt.Sprintf("expectedTrailer: %s,\n", metadataRepresentation(file, trailers))
+	}
+
+	return values
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// subtestsLoop returns the opening of the loop running a subtest for each table entry.
+// When the `parallel-tests` option is enabled the subtests run in parallel, the entry is copied
+// since the loop variable is shared by the iterations. The loop is closed by the caller.
+// Every subtest records the result of its case, see dealtest.WithVerificationPublisher.
+func subtestsLoop(file *protogen.GeneratedFile) string {
+	if !*parallelTests {
+		return fmt.Sprintf(
+			"for _, test := range tests {\nt.Run(test.name, func(t *%s) {\nconfig.RecordCase(t)\n",
+			file.QualifiedGoIdent(testingT),
+		)
+	}
+
+	return fmt.Sprintf(
+		"for _, test := range tests {\ntest := test\nt.Run(test.name, func(t *%s) {\nt.Parallel()\n"+
+			"config.RecordCase(t)\n",
+		file.QualifiedGoIdent(testingT),
+	)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// The keys of the case lists in the contract file
+const (
+	successCasesKey          = "successCases"
+	applicationErrorCasesKey = "applicationErrorCases"
+	failureCasesKey          = "failureCases"
+)
+
+// caseProvenance returns the comment linking the generated code back to its contract case:
+// the contract file, the JSON path of the case and its description
+func caseProvenance(
+	method *protogen.Method,
+	casesKey string,
+	index int,
+	description string,
+) string {
+	source := contracts.source(method.Parent)
+
+	// The fully-qualified service names contain dots, they're quoted to keep the path readable
+	servicePath := "." + source.key
+	if strings.Contains(source.key, ".") {
+		servicePath = fmt.Sprintf("[%q]", source.key)
+	}
+
+	return fmt.Sprintf(
+		"// Contract: %s services%s.%s.%s[%d]\n// Description: %q\n",
+		source.path, servicePath, method.GoName, casesKey, index, description,
+	)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+func clientStateName(service *protogen.Service) string {
+	return fmt.Sprintf("%sClientState", processors.MakeUnexportedName(service.GoName))
+}
+
+// generateClientState creates the state recording the calls of a client and counting its
+// sequenced cases. The client methods have value receivers, so the client keeps a pointer to
+// its state, the zero values of the client share the same state.
+func generateClientState(
+	file *protogen.GeneratedFile,
+	service *protogen.Service,
+	clientName string,
+) {
+	stateName := clientStateName(service)
+	sharedStateName := "shared" + processors.MakeExportedName(stateName)
+
+	file.P(fmt.Sprintf("// %s holds the calls recorded by %s", stateName, clientName))
+	file.P(
+		fmt.Sprintf(
+			"type %s struct {\n%s\nrecorder %s\n}",
+			stateName,
+			callCounterName(service),
+			file.QualifiedGoIdent(dealcallsPackage.Ident("Recorder")),
+		),
+	)
+	file.P()
+	file.P(fmt.Sprintf("// %s is the state of the zero values of %s", sharedStateName, clientName))
+	file.P(fmt.Sprintf("var %s %s", sharedStateName, stateName))
+	file.P()
+	file.P(
+		fmt.Sprintf(`func (c %s) callState() *%s {
+			if c.state == nil {
+				return &%s
+			}
+
+			return c.state
+		}`, clientName, stateName, sharedStateName),
+	)
+	file.P()
+}
+
+// generateRecorderMethods creates the client methods exposing the recorded calls
+func generateRecorderMethods(file *protogen.GeneratedFile, clientName string) {
+	call := file.QualifiedGoIdent(dealcallsPackage.Ident("Call"))
+
+	file.P("// Calls returns the calls received by the client in the order they were received")
+	file.P(
+		fmt.Sprintf(
+			"func (c %s) Calls() []%s {\nreturn c.callState().recorder.Calls()\n}", clientName, call,
+		),
+	)
+	file.P()
+	file.P("// CallCount returns how many calls the given method received")
+	file.P(
+		fmt.Sprintf(
+			"func (c %s) CallCount(method string) int {\n"+
+				"return c.callState().recorder.CallCount(method)\n}",
+			clientName,
+		),
+	)
+	file.P()
+	file.P("// Reset forgets the recorded calls and restarts the sequenced cases")
+	file.P(
+		fmt.Sprintf(
+			"func (c %s) Reset() {\nc.callState().recorder.Reset()\nc.callState().resetCalls()\n}",
+			clientName,
+		),
+	)
+	file.P()
+}
+
+// generateRecordCall returns the statement recording the call received by a client method
+func generateRecordCall(file *protogen.GeneratedFile, method *protogen.Method) string {
+	return fmt.Sprintf(
+		"md, _ := %s(ctx)\nc.callState().recorder.Record(%q, in, md)\n",
+		file.QualifiedGoIdent(grpcMetadata.Ident("FromOutgoingContext")),
+		method.GoName,
+	)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// knownMessages holds the messages of every proto file given to the plugin,
+// it resolves the messages packed in the google.protobuf.Any values of the contract
+var knownMessages = newMessageRegistry(nil)
+
+// messageRegistry resolves the messages of the compiled proto files, both their descriptor
+// (to parse the contract values) and their generated Go type (to represent them)
+type messageRegistry struct {
+	types    *protoregistry.Types
+	messages map[protoreflect.FullName]*protogen.Message
+}
+
+func newMessageRegistry(files []*protogen.File) *messageRegistry {
+	registry := &messageRegistry{
+		types:    new(protoregistry.Types),
+		messages: make(map[protoreflect.FullName]*protogen.Message),
+	}
+
+	var register func(messages []*protogen.Message)
+	register = func(messages []*protogen.Message) {
+		for _, message := range messages {
+			if _, exists := registry.messages[message.Desc.FullName()]; exists {
+				continue
+			}
+
+			registry.messages[message.Desc.FullName()] = message
+			// The registration can't fail, every message is only registered once
+			_ = registry.types.RegisterMessage(dynamicpb.NewMessageType(message.Desc))
+			register(message.Messages)
+		}
+	}
+	for _, file := range files {
+		register(file.Messages)
+	}
+
+	return registry
+}
+
+// FindMessageByName looks up a message by its full name, e.g. "example.v1.User"
+func (r *messageRegistry) FindMessageByName(
+	name protoreflect.FullName,
+) (protoreflect.MessageType, error) {
+	messageType, err := r.types.FindMessageByName(name)
+	if errors.Is(err, protoregistry.NotFound) {
+		return nil, fmt.Errorf("message %s isn't in the compiled proto files", name)
+	}
+
+	return messageType, err
+}
+
+// FindMessageByURL looks up a message by its type URL, e.g.
+// "type.googleapis.com/example.v1.User"
+func (r *messageRegistry) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	return r.FindMessageByName(messageNameOf(url))
+}
+
+// FindExtensionByName looks up an extension field by its full name
+func (r *messageRegistry) FindExtensionByName(
+	field protoreflect.FullName,
+) (protoreflect.ExtensionType, error) {
+	return r.types.FindExtensionByName(field)
+}
+
+// FindExtensionByNumber looks up an extension field by the message and field number
+func (r *messageRegistry) FindExtensionByNumber(
+	message protoreflect.FullName,
+	field protoreflect.FieldNumber,
+) (protoreflect.ExtensionType, error) {
+	return r.types.FindExtensionByNumber(message, field)
+}
+
+// message returns the Go type of the message identified by the type URL
+func (r *messageRegistry) message(url string) (*protogen.Message, error) {
+	message, exists := r.messages[messageNameOf(url)]
+	if !exists {
+		return nil, fmt.Errorf("message %s isn't in the compiled proto files", messageNameOf(url))
+	}
+
+	return message, nil
+}
+
+// messageNameOf returns the message name of the type URL, which is everything after the last slash
+func messageNameOf(url string) protoreflect.FullName {
+	return protoreflect.FullName(url[strings.LastIndex(url, "/")+1:])
+}
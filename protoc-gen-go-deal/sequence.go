@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/entities"
+	"github.com/faunists/deal-go/processors"
+)
+
+const syncPackage = protogen.GoImportPath("sync")
+
+func callCounterName(service *protogen.Service) string {
+	return fmt.Sprintf("%sCallCounter", processors.MakeUnexportedName(service.GoName))
+}
+
+// callCounterOf returns the expression of the call counter in the methods of the target,
+// the client keeps it in its state
+func callCounterOf(target caseTarget) string {
+	if target == clientTarget {
+		return "c.callState()"
+	}
+
+	return "c.callCounter()"
+}
+
+// generateServerCallCounter creates the constructor of the contract server and the method
+// returning its call counter, the zero values of the server share the same counter.
+func generateServerCallCounter(
+	file *protogen.GeneratedFile,
+	service *protogen.Service,
+	serverName string,
+) {
+	counterName := callCounterName(service)
+	sharedCounterName := fmt.Sprintf(
+		"shared%sServerCallCounter", processors.MakeExportedName(service.GoName),
+	)
+
+	file.P(
+		fmt.Sprintf(
+			"// %s counts the cases matched by the zero values of %s", sharedCounterName, serverName,
+		),
+	)
+	file.P(fmt.Sprintf("var %s %s", sharedCounterName, counterName))
+	file.P()
+	file.P(
+		fmt.Sprintf(
+			"// New%[1]s creates a %[1]s counting its own sequenced cases\n"+
+				"func New%[1]s() *%[1]s {\nreturn &%[1]s{calls: &%[2]s{}}\n}",
+			serverName,
+			counterName,
+		),
+	)
+	file.P()
+	file.P(
+		fmt.Sprintf(`func (c %s) callCounter() *%s {
+			if c.calls == nil {
+				return &%s
+			}
+
+			return c.calls
+		}`, serverName, counterName, sharedCounterName),
+	)
+	file.P()
+}
+
+// generateCallCounter creates the type used by the client state and the contract server
+// to count how many times each case was matched, allowing sequenced responses.
+func generateCallCounter(file *protogen.GeneratedFile, service *protogen.Service) {
+	counterName := callCounterName(service)
+
+	file.P(fmt.Sprintf("// %s counts the calls matched by each contract case", counterName))
+	file.P(
+		fmt.Sprintf(
+			"type %s struct {\nmu %s\ncalls map[string]int\n}",
+			counterName,
+			file.QualifiedGoIdent(syncPackage.Ident("Mutex")),
+		),
+	)
+	file.P()
+	file.P(fmt.Sprintf(`func (c *%s) nextCall(caseKey string) int {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.calls == nil {
+			c.calls = make(map[string]int)
+		}
+
+		call := c.calls[caseKey]
+		c.calls[caseKey]++
+
+		return call
+	}`, counterName))
+	file.P()
+	file.P(fmt.Sprintf(`func (c *%s) resetCalls() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.calls = nil
+	}`, counterName))
+	file.P()
+}
+
+// generateSequenceResults returns a switch over the number of calls already matched by
+// the case, the last step is used as the default one, so it keeps being returned.
+func generateSequenceResults(
+	file *protogen.GeneratedFile,
+	method *protogen.Method,
+	caseKey string,
+	steps []entities.SequenceStep,
+	target caseTarget,
+) (string, error) {
+	results := bytes.NewBufferString(
+		fmt.Sprintf("switch %s.nextCall(%q) {\n", callCounterOf(target), caseKey),
+	)
+
+	for call, step := range steps {
+		result, err := generateStepResult(file, method, step)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate the step %d: %w", call, err)
+		}
+
+		if call == len(steps)-1 {
+			results.WriteString(fmt.Sprintf("default:\n%s\n", result))
+			continue
+		}
+		results.WriteString(fmt.Sprintf("case %d:\n%s\n", call, result))
+	}
+	results.WriteString("}")
+
+	return results.String(), nil
+}
+
+func generateStepResult(
+	file *protogen.GeneratedFile,
+	method *protogen.Method,
+	step entities.SequenceStep,
+) (string, error) {
+	if step.Error != nil {
+		return generateErrorResult(file, method, *step.Error)
+	}
+
+	return generateResponseResult(file, method, step.Response)
+}
+
+func generateSequenceTestForServer(
+	file *protogen.GeneratedFile,
+	method *protogen.Method,
+	successCases []entities.SuccessCase,
+) error {
+	// The failures declared by FailFirst are simulated by the client only,
+	// so just the responses are verified against the server
+	// The indexes of the cases are kept to name the tests
+	sequenceIndexes := make([]int, 0)
+	for index, successCase := range successCases {
+		if len(successCase.Responses) > 0 {
+			sequenceIndexes = append(sequenceIndexes, index)
+		}
+	}
+	if len(sequenceIndexes) == 0 {
+		return nil
+	}
+
+	file.P()
+	file.P(
+		fmt.Sprintf(
+			`t.Run("Sequence Cases", func(t *%s) {`,
+			file.QualifiedGoIdent(testingT),
+		),
+	)
+
+	tests := make([]string, 0, len(sequenceIndexes))
+	hasFakedFields, hasDetails, hasTimeouts := false, false, false
+	for _, index := range sequenceIndexes {
+		sequenceCase := successCases[index]
+		requestRepresentation, err := getRequestRepresentation(
+			file, method.Input, sequenceCase.Request, sequenceCase.Oneofs,
+		)
+		if err != nil {
+			return err
+		}
+
+		steps := bytes.NewBufferString("[]sequenceStep{\n")
+		for _, step := range sequenceCase.Responses {
+			if step.Error != nil {
+				if len(step.Error.MessageArgs) > 0 {
+					return fmt.Errorf(
+						"case %q: message arguments aren't supported in sequenced responses",
+						sequenceCase.Description,
+					)
+				}
+
+				errorCode, err := processors.NormalizeErrorCode(string(step.Error.ErrorCode))
+				if err != nil {
+					return err
+				}
+
+				details, err := errorDetailsRepresentation(file, step.Error.Details)
+				if err != nil {
+					return err
+				}
+
+				detailsValue := ""
+				if len(details) > 0 {
+					hasDetails = true
+					detailsValue = fmt.Sprintf(
+						", expectedDetails: []%s{%s}",
+						file.QualifiedGoIdent(protoPackage.Ident("Message")),
+						strings.Join(details, ", "),
+					)
+				}
+
+				steps.WriteString(
+					fmt.Sprintf(
+						"{expectedStatus: %s(%s, %q)%s},\n",
+						file.QualifiedGoIdent(grpcStatus.Ident("New")),
+						file.QualifiedGoIdent(grpcCodes.Ident(errorCode)),
+						step.Error.Message,
+						detailsValue,
+					),
+				)
+				continue
+			}
+
+			responseRepresentation, fakedPaths, err := getResponseRepresentation(
+				step.Response, method.Output, file,
+			)
+			if err != nil {
+				return err
+			}
+
+			if len(fakedPaths) > 0 {
+				hasFakedFields = true
+				steps.WriteString(
+					fmt.Sprintf(
+						"{expectedResponse: %s, ignoredFields: %#v},\n",
+						responseRepresentation, fakedPaths,
+					),
+				)
+				continue
+			}
+			steps.WriteString(fmt.Sprintf("{expectedResponse: %s},\n", responseRepresentation))
+		}
+		steps.WriteString("}")
+
+		timeout, err := timeoutTestValue(file, sequenceCase.Timeout)
+		if err != nil {
+			return err
+		}
+		if timeout != "" {
+			hasTimeouts = true
+		}
+
+		tests = append(
+			tests,
+			fmt.Sprintf(
+				"%s{\nname: %q,\nrequest: %s,\nsteps: %s,\n%s},",
+				caseProvenance(method, successCasesKey, index, sequenceCase.Description),
+				processors.TestCaseName(sequenceCase.Description, index),
+				requestRepresentation,
+				steps,
+				timeout,
+			),
+		)
+	}
+
+	ignoredFieldsDeclaration, clearIgnoredFields := "", ""
+	if hasFakedFields {
+		ignoredFieldsDeclaration = "\nignoredFields []string"
+		clearIgnoredFields = fmt.Sprintf(
+			"%[1]s(response, step.ignoredFields...)\n"+
+				"%[1]s(step.expectedResponse, step.ignoredFields...)",
+			file.QualifiedGoIdent(dealtestPackage.Ident("ClearFields")),
+		)
+	}
+
+	detailsDeclaration, detailsChecks := "", ""
+	if hasDetails {
+		detailsDeclaration, detailsChecks = errorDetailsTestColumns(file, "step")
+	}
+
+	file.P(
+		fmt.Sprintf(
+			"type sequenceStep struct {expectedResponse *%s\nexpectedStatus *%s%s%s}",
+			file.QualifiedGoIdent(method.Output.GoIdent),
+			file.QualifiedGoIdent(grpcStatus.Ident("Status")),
+			ignoredFieldsDeclaration,
+			detailsDeclaration,
+		),
+	)
+	timeoutDeclaration, callContext := timeoutTestColumns(file, hasTimeouts, "test")
+
+	file.P(
+		fmt.Sprintf(
+			"tests := []struct {name string\nrequest *%s\nsteps []sequenceStep%s} {",
+			file.QualifiedGoIdent(method.Input.GoIdent),
+			timeoutDeclaration,
+		),
+	)
+	for _, test := range tests {
+		file.P(test)
+	}
+	file.P("}")
+
+	file.P()
+	file.P(
+		fmt.Sprintf(`%sfor call, step := range test.steps {
+						%sresponse, err := client.%s(callCtx, test.request)
+						if step.expectedStatus != nil {
+							errorStatus := %s(err)
+							if err == nil ||
+								errorStatus.Code() != step.expectedStatus.Code() ||
+								errorStatus.Message() != step.expectedStatus.Message() {
+								t.Fatalf(
+									"call %%d: expected error: %%v, given error: %%v",
+									call, step.expectedStatus.Err(), err,
+								)
+							}
+							%s
+							continue
+						}
+
+						%s
+						%s
+
+						%s
+					}
+				})
+			}`,
+			subtestsLoop(file),
+			callContext,
+			method.GoName,
+			file.QualifiedGoIdent(grpcStatus.Ident("Convert")),
+			detailsChecks,
+			noErrorCheck(file, "call %d: unexpected error happened", "call"),
+			clearIgnoredFields,
+			sequenceResponseCheck(file),
+		),
+	)
+	file.P("})")
+
+	return nil
+}
+
+// sequenceResponseCheck verifies the response of a sequence step, the failure message
+// tells which call of the sequence failed
+func sequenceResponseCheck(file *protogen.GeneratedFile) string {
+	responseDiff := fmt.Sprintf(
+		"%s(step.expectedResponse, response, config.CompareOptions...)",
+		file.QualifiedGoIdent(dealtestPackage.Ident("ResponseDiff")),
+	)
+	if *asserts == testifyAsserts {
+		return fmt.Sprintf(
+			"%s(t, %s, %q, call)",
+			file.QualifiedGoIdent(requirePackage.Ident("Emptyf")),
+			responseDiff,
+			"call %d: unexpected response (-expected +given)",
+		)
+	}
+
+	return fmt.Sprintf(
+		"if diff := %s; diff != \"\" {\n"+
+			"t.Fatalf(\"call %%d: unexpected response (-expected +given):\\n%%s\", call, diff)\n}",
+		responseDiff,
+	)
+}
@@ -0,0 +1,152 @@
+package contracttest_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/faunists/deal-go/dealtest"
+	"github.com/faunists/deal-go/entities"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"fixture/fixturev1"
+)
+
+// The zero value of the former stub server name is still usable as the server
+var _ fixturev1.ItemServiceServer = fixturev1.ItemServiceStubServer{}
+
+// The contract servers are verified by the server tests generated from the same contract
+func TestItemServiceContractServer(t *testing.T) {
+	server := grpc.NewServer()
+	fixturev1.RegisterItemServiceServer(server, fixturev1.NewItemServiceContractServer())
+
+	fixturev1.ItemServiceContractTest(t, context.Background(), server)
+}
+
+func TestStockServiceContractServer(t *testing.T) {
+	server := grpc.NewServer()
+	fixturev1.RegisterStockServiceServer(server, fixturev1.NewStockServiceContractServer())
+
+	fixturev1.StockServiceContractTest(t, context.Background(), server)
+}
+
+// The literals of every kind of field are verified by the server tests against the contract server
+func TestCatalogServiceContractServer(t *testing.T) {
+	server := grpc.NewServer()
+	fixturev1.RegisterCatalogServiceServer(server, fixturev1.NewCatalogServiceContractServer())
+
+	fixturev1.CatalogServiceContractTest(t, context.Background(), server)
+}
+
+func TestItemServiceContractTestWithService(t *testing.T) {
+	var calls int32
+	interceptor := func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return handler(ctx, req)
+	}
+
+	t.Run("Contract test", func(t *testing.T) {
+		fixturev1.ItemServiceContractTestWithService(
+			t, context.Background(), fixturev1.NewItemServiceContractServer(),
+			dealtest.WithServerOptions(grpc.UnaryInterceptor(interceptor)),
+		)
+	})
+
+	if atomic.LoadInt32(&calls) == 0 {
+		t.Errorf("Expected the server options to be applied")
+	}
+}
+
+func TestItemServiceServerInterceptors(t *testing.T) {
+	var methods sync.Map
+	interceptor := func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		methods.Store(info.FullMethod, true)
+		return handler(ctx, req)
+	}
+
+	t.Run("Contract test", func(t *testing.T) {
+		fixturev1.ItemServiceContractTestWithService(
+			t, context.Background(), fixturev1.NewItemServiceContractServer(),
+			fixturev1.WithItemServiceUnaryServerInterceptors(interceptor),
+		)
+	})
+
+	if _, called := methods.Load("/fixture.v1.ItemService/GetItem"); !called {
+		t.Errorf("Expected the interceptor to see the GetItem calls")
+	}
+}
+
+func TestItemServiceErrorComparer(t *testing.T) {
+	var expectedErrors sync.Map
+	comparer := func(expected entities.GRPCError, actual error) error {
+		expectedErrors.Store(expected.Message, expected.ErrorCode)
+		return nil
+	}
+
+	t.Run("Contract test", func(t *testing.T) {
+		fixturev1.ItemServiceContractTestWithService(
+			t, context.Background(), fixturev1.NewItemServiceContractServer(),
+			dealtest.WithErrorComparer(comparer),
+		)
+	})
+
+	code, compared := expectedErrors.Load("item not found")
+	if !compared || code != entities.ErrorCode("NotFound") {
+		t.Errorf("Given: %+v, expected: %+v", code, "NotFound")
+	}
+}
+
+// The zero value of the client is usable as the interface,
+// it records the calls and counts the steps of the sequenced cases
+func TestItemServiceContractClient(t *testing.T) {
+	client := fixturev1.ItemServiceContractClient{}
+	var serviceClient fixturev1.ItemServiceClient = client
+
+	for call, expected := range []int64{0, 10, 10} {
+		response, err := serviceClient.GetItem(context.Background(), &fixturev1.GetItemRequest{Id: "2"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if response.Quantity != expected {
+			t.Errorf("Call %d, given: %+v, expected: %+v", call, response.Quantity, expected)
+		}
+	}
+
+	if count := client.CallCount("GetItem"); count != 3 {
+		t.Errorf("Given: %+v, expected: %+v", count, 3)
+	}
+}
+
+func TestCatalogServiceContractClient(t *testing.T) {
+	client := fixturev1.NewCatalogServiceContractClient()
+
+	response, err := client.SearchItems(
+		context.Background(),
+		&fixturev1.SearchItemsRequest{
+			Filter: &fixturev1.SearchItemsRequest_Category{
+				Category: fixturev1.Category_CATEGORY_BOOKS,
+			},
+			Limit: proto.Int32(5),
+		},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := &fixturev1.SearchItemsResponse{Score: 1.5}
+	if !proto.Equal(response, expected) {
+		t.Errorf("Given: %+v, expected: %+v", response, expected)
+	}
+}
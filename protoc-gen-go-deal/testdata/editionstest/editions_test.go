@@ -0,0 +1,45 @@
+package editionstest_test
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"fixture/legacyv1"
+	"fixture/modernv1"
+)
+
+// The proto2 and editions contract servers are verified by the server tests
+// generated from the same contract
+func TestLegacyServiceContractServer(t *testing.T) {
+	server := grpc.NewServer()
+	legacyv1.RegisterLegacyServiceServer(server, legacyv1.NewLegacyServiceContractServer())
+
+	legacyv1.LegacyServiceContractTest(t, context.Background(), server)
+}
+
+func TestModernServiceContractServer(t *testing.T) {
+	server := grpc.NewServer()
+	modernv1.RegisterModernServiceServer(server, modernv1.NewModernServiceContractServer())
+
+	modernv1.ModernServiceContractTest(t, context.Background(), server)
+}
+
+// The fields missing from the contract response keep their proto2 default
+func TestLegacyServiceContractClientDefaults(t *testing.T) {
+	client := legacyv1.NewLegacyServiceContractClient()
+
+	response, err := client.Echo(
+		context.Background(),
+		&legacyv1.LegacyRequest{Id: proto.String("2"), Count: proto.Int32(0)},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if response.Count != nil || response.GetCount() != 7 {
+		t.Errorf("Given: %+v, expected: %+v", response.GetCount(), 7)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+// timeoutTestValue returns the timeout of a single test entry,
+// nothing is returned when the case has no timeout
+func timeoutTestValue(file *protogen.GeneratedFile, timeout string) (string, error) {
+	duration, err := processors.ParseDuration(timeout)
+	if err != nil {
+		return "", fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	if duration == 0 {
+		return "", nil
+	}
+
+	amount, unit := processors.DurationUnit(duration)
+	return fmt.Sprintf(
+		"timeout: %d * %s,\n", amount, file.QualifiedGoIdent(timePackage.Ident(unit)),
+	), nil
+}
+
+// timeoutTestColumns returns the table column declaring the case timeouts and the statements
+// creating `callCtx`, the context of a call bounded by the case timeout or the default one.
+// The column is only declared when a case has a timeout.
+func timeoutTestColumns(
+	file *protogen.GeneratedFile,
+	hasTimeouts bool,
+	entry string,
+) (string, string) {
+	if !hasTimeouts {
+		return "", "callCtx, cancel := config.CallContext(ctx, 0)\ndefer cancel()\n"
+	}
+
+	return fmt.Sprintf("\ntimeout %s", file.QualifiedGoIdent(timePackage.Ident("Duration"))),
+		fmt.Sprintf(
+			"callCtx, cancel := config.CallContext(ctx, %s.timeout)\ndefer cancel()\n", entry,
+		)
+}
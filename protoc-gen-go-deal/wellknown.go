@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/faunists/deal-go/processors"
+)
+
+const (
+	timestamppbPackage = protogen.GoImportPath(
+		"google.golang.org/protobuf/types/known/timestamppb",
+	)
+	anypbPackage       = protogen.GoImportPath("google.golang.org/protobuf/types/known/anypb")
+	fieldmaskpbPackage = protogen.GoImportPath(
+		"google.golang.org/protobuf/types/known/fieldmaskpb",
+	)
+)
+
+// isWellKnown reports whether the message is a well-known type with a dedicated representation
+func isWellKnown(message *protogen.Message) bool {
+	switch message.Desc.FullName() {
+	case "google.protobuf.Timestamp", "google.protobuf.Duration", "google.protobuf.Any",
+		"google.protobuf.FieldMask":
+		return true
+	default:
+		return false
+	}
+}
+
+// wellKnownRepresentation returns the construction of the well-known types that have
+// a dedicated representation, e.g. `timestamppb.New(...)`. False is returned for the other
+// messages, which are represented by their struct literal.
+func wellKnownRepresentation(
+	file *protogen.GeneratedFile,
+	message *protogen.Message,
+	value protoreflect.Message,
+) (string, bool, error) {
+	switch message.Desc.FullName() {
+	case "google.protobuf.Timestamp":
+		return timestampRepresentation(file, value), true, nil
+	case "google.protobuf.Duration":
+		representation, ok := durationRepresentation(file, value)
+		return representation, ok, nil
+	case "google.protobuf.Any":
+		representation, err := anyRepresentation(file, value)
+		return representation, err == nil, err
+	case "google.protobuf.FieldMask":
+		return fieldMaskRepresentation(file, value), true, nil
+	default:
+		return "", false, nil
+	}
+}
+
+// timestampRepresentation returns the construction of a timestamp in UTC, e.g.
+// `timestamppb.New(time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC))`
+func timestampRepresentation(file *protogen.GeneratedFile, value protoreflect.Message) string {
+	seconds, nanos := secondsAndNanos(value)
+	timestamp := time.Unix(seconds, int64(nanos)).UTC()
+
+	return fmt.Sprintf(
+		"%s(%s(%d, %s, %d, %d, %d, %d, %d, %s))",
+		file.QualifiedGoIdent(timestamppbPackage.Ident("New")),
+		file.QualifiedGoIdent(timePackage.Ident("Date")),
+		timestamp.Year(),
+		file.QualifiedGoIdent(timePackage.Ident(timestamp.Month().String())),
+		timestamp.Day(),
+		timestamp.Hour(),
+		timestamp.Minute(),
+		timestamp.Second(),
+		timestamp.Nanosecond(),
+		file.QualifiedGoIdent(timePackage.Ident("UTC")),
+	)
+}
+
+// durationRepresentation returns the construction of a duration, e.g.
+// `durationpb.New(150 * time.Millisecond)`. The durations that don't fit
+// in a time.Duration are represented by their struct literal.
+func durationRepresentation(
+	file *protogen.GeneratedFile,
+	value protoreflect.Message,
+) (string, bool) {
+	seconds, nanos := secondsAndNanos(value)
+	if seconds > math.MaxInt64/int64(time.Second) || seconds < math.MinInt64/int64(time.Second) {
+		return "", false
+	}
+
+	duration := time.Duration(seconds)*time.Second + time.Duration(nanos)
+	if duration == 0 {
+		return fmt.Sprintf("%s(0)", file.QualifiedGoIdent(durationpbPackage.Ident("New"))), true
+	}
+
+	amount, unit := processors.DurationUnit(duration)
+	return fmt.Sprintf(
+		"%s(%d * %s)",
+		file.QualifiedGoIdent(durationpbPackage.Ident("New")),
+		amount,
+		file.QualifiedGoIdent(timePackage.Ident(unit)),
+	), true
+}
+
+// anyRepresentation returns the construction of an Any value packing the message
+// identified by its type URL, the message must be part of the compiled proto files
+func anyRepresentation(file *protogen.GeneratedFile, value protoreflect.Message) (string, error) {
+	fields := value.Descriptor().Fields()
+	typeURL := value.Get(fields.ByName("type_url")).String()
+
+	message, err := knownMessages.message(typeURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the google.protobuf.Any value: %w", err)
+	}
+
+	packedMessage := dynamicpb.NewMessage(message.Desc)
+	unmarshalOptions := proto.UnmarshalOptions{Resolver: knownMessages}
+	err = unmarshalOptions.Unmarshal(value.Get(fields.ByName("value")).Bytes(), packedMessage)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the google.protobuf.Any value: %w", err)
+	}
+
+	packedRepresentation, isWellKnown, err := wellKnownRepresentation(file, message, packedMessage)
+	if err != nil {
+		return "", err
+	}
+	if !isWellKnown {
+		packedRepresentation, err = messageRepresentation(packedMessage, message, file)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// anypb.New also returns an error, so the value is built by a function literal,
+	// the marshaling of a generated message can't fail
+	return fmt.Sprintf(
+		"func() *%s {\nvalue, err := %s(%s)\nif err != nil {\npanic(err)\n}\nreturn value\n}()",
+		file.QualifiedGoIdent(anypbPackage.Ident("Any")),
+		file.QualifiedGoIdent(anypbPackage.Ident("New")),
+		packedRepresentation,
+	), nil
+}
+
+// fieldMaskRepresentation returns the literal of a field mask listing its paths, e.g.
+// `&fieldmaskpb.FieldMask{Paths: []string{"name", "address.city"}}`. The contracts write
+// them as the protojson comma-separated string, e.g. "name,address.city".
+func fieldMaskRepresentation(file *protogen.GeneratedFile, value protoreflect.Message) string {
+	pathsList := value.Get(value.Descriptor().Fields().ByName("paths")).List()
+	paths := make([]string, 0, pathsList.Len())
+	for index := 0; index < pathsList.Len(); index++ {
+		paths = append(paths, pathsList.Get(index).String())
+	}
+
+	return fmt.Sprintf(
+		"&%s{Paths: %s}",
+		file.QualifiedGoIdent(fieldmaskpbPackage.Ident("FieldMask")),
+		stringListRepresentation(paths),
+	)
+}
+
+// secondsAndNanos returns the fields shared by the Timestamp and Duration messages
+func secondsAndNanos(value protoreflect.Message) (int64, int32) {
+	fields := value.Descriptor().Fields()
+	return value.Get(fields.ByName("seconds")).Int(), int32(value.Get(fields.ByName("nanos")).Int())
+}